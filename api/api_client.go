@@ -22,6 +22,7 @@ var (
 
 // APIClient gives access to most avalanchego apis (or suitable wrappers)
 type APIClient struct {
+	uri          string
 	platform     platformvm.Client
 	xChain       avm.Client
 	xChainWallet avm.WalletClient
@@ -34,6 +35,9 @@ type APIClient struct {
 	admin        admin.Client
 	pindex       indexer.Client
 	cindex       indexer.Client
+	xtxindex     indexer.Client
+	xvtxindex    indexer.Client
+	xblockindex  indexer.Client
 }
 
 // Returns a new API client for a node at [ipAddr]:[port].
@@ -43,6 +47,7 @@ type NewAPIClientF func(ipAddr string, port uint16) Client
 func NewAPIClient(ipAddr string, port uint16) Client {
 	uri := fmt.Sprintf("http://%s:%d", ipAddr, port)
 	return &APIClient{
+		uri:          uri,
 		platform:     platformvm.NewClient(uri),
 		xChain:       avm.NewClient(uri, "X"),
 		xChainWallet: avm.NewWalletClient(uri, "X"),
@@ -55,6 +60,9 @@ func NewAPIClient(ipAddr string, port uint16) Client {
 		admin:        admin.NewClient(uri),
 		pindex:       indexer.NewClient(uri + "/ext/index/P/block"),
 		cindex:       indexer.NewClient(uri + "/ext/index/C/block"),
+		xtxindex:     indexer.NewClient(uri + "/ext/index/X/tx"),
+		xvtxindex:    indexer.NewClient(uri + "/ext/index/X/vtx"),
+		xblockindex:  indexer.NewClient(uri + "/ext/index/X/block"),
 	}
 }
 
@@ -105,3 +113,15 @@ func (c APIClient) PChainIndexAPI() indexer.Client {
 func (c APIClient) CChainIndexAPI() indexer.Client {
 	return c.cindex
 }
+
+func (c APIClient) XChainTxIndexAPI() indexer.Client {
+	return c.xtxindex
+}
+
+func (c APIClient) XChainVertexIndexAPI() indexer.Client {
+	return c.xvtxindex
+}
+
+func (c APIClient) XChainBlockIndexAPI() indexer.Client {
+	return c.xblockindex
+}
@@ -0,0 +1,260 @@
+// Package apitest provides a fake avalanchego node API, so code using
+// node API clients (eg health and bootstrap checks) can be unit tested
+// without running avalanchego binaries.
+package apitest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	"github.com/ava-labs/avalanchego/api/health"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	avajson "github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+)
+
+const (
+	// DefaultNodeVersion is the version answered by info.getNodeVersion
+	DefaultNodeVersion = "avalanche/1.10.15"
+	// DefaultNetworkID is the network ID answered by info.getNetworkID
+	DefaultNetworkID = 1337
+
+	// JSON-RPC error code of unknown methods
+	methodNotFoundCode = -32601
+	// JSON-RPC error code of the errors returned by handlers
+	handlerErrorCode = -32000
+)
+
+var (
+	_ api.NewAPIClientF = (*Server)(nil).NewAPIClient
+
+	// ErrChainNotFound is returned by info.isBootstrapped for the chains
+	// marked as missing with SetMissingChain
+	ErrChainNotFound = errors.New("there is no chain with alias/ID")
+
+	errMethodNotFound = errors.New("method not found")
+)
+
+// Handler answers a JSON-RPC method given its raw params. The returned
+// reply is encoded as the JSON-RPC result, and the returned error as a
+// JSON-RPC error.
+type Handler func(params json.RawMessage) (interface{}, error)
+
+// Server is a fake node API serving, over HTTP, the JSON-RPC methods of
+// the info, health and P-chain APIs used by the runner. Replies can be
+// programmed with the setters, or with Handle for any method.
+// The JSON-RPC methods are answered at any path.
+type Server struct {
+	server *httptest.Server
+
+	lock     sync.Mutex
+	handlers map[string]Handler
+	calls    map[string]int
+	healthy  bool
+	// chain alias or ID --> bootstrapped. Chains not present are bootstrapped.
+	bootstrapped map[string]bool
+	// chains not present on the node
+	missingChains map[string]bool
+	blockchains   []platformvm.APIBlockchain
+	nodeID        ids.NodeID
+}
+
+// NewServer starts a fake node API for a healthy node with all chains
+// bootstrapped. Close must be called once done.
+func NewServer() *Server {
+	s := &Server{
+		handlers:      map[string]Handler{},
+		calls:         map[string]int{},
+		healthy:       true,
+		bootstrapped:  map[string]bool{},
+		missingChains: map[string]bool{},
+		nodeID:        ids.GenerateTestNodeID(),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URI of the node API, as given to the avalanchego API clients
+func (s *Server) URI() string {
+	return s.server.URL
+}
+
+// Host and port of the node API
+func (s *Server) Addr() (string, uint16) {
+	host, portStr, _ := net.SplitHostPort(s.server.Listener.Addr().String())
+	port, _ := strconv.ParseUint(portStr, 10, 16)
+	return host, uint16(port)
+}
+
+// NewAPIClient returns an API client of this server, whatever the given
+// address. It is an api.NewAPIClientF, so every node of a network under
+// test can be given this fake API.
+func (s *Server) NewAPIClient(string, uint16) api.Client {
+	return api.NewAPIClient(s.Addr())
+}
+
+// NodeID answered by info.getNodeID
+func (s *Server) NodeID() ids.NodeID {
+	return s.nodeID
+}
+
+// SetHealthy sets the health answered by the health API
+func (s *Server) SetHealthy(healthy bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.healthy = healthy
+}
+
+// SetBootstrapped sets whether [chain] (alias or ID) is bootstrapped,
+// as answered by info.isBootstrapped
+func (s *Server) SetBootstrapped(chain string, bootstrapped bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.bootstrapped[chain] = bootstrapped
+}
+
+// SetMissingChain makes info.isBootstrapped fail for [chain], as for
+// chains the node doesn't know about
+func (s *Server) SetMissingChain(chain string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.missingChains[chain] = true
+}
+
+// SetBlockchains sets the blockchains answered by platform.getBlockchains
+func (s *Server) SetBlockchains(blockchains []platformvm.APIBlockchain) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.blockchains = append([]platformvm.APIBlockchain{}, blockchains...)
+}
+
+// Handle makes [handler] answer [method] (eg "info.peers"), instead of
+// the default reply
+func (s *Server) Handle(method string, handler Handler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.handlers[method] = handler
+}
+
+// Calls returns the number of times [method] was called
+func (s *Server) Calls(method string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.calls[method]
+}
+
+type request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	// GET health requests answer the health reply, with a 503 status code
+	// if unhealthy
+	if r.Method == http.MethodGet {
+		reply := s.healthReply()
+		if !reply.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(reply)
+		return
+	}
+	req := request{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := response{Version: "2.0", ID: req.ID}
+	result, err := s.call(req.Method, req.Params)
+	switch {
+	case errors.Is(err, errMethodNotFound):
+		resp.Error = &responseError{Code: methodNotFoundCode, Message: err.Error()}
+	case err != nil:
+		resp.Error = &responseError{Code: handlerErrorCode, Message: err.Error()}
+	default:
+		resp.Result = result
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Answers JSON-RPC [method] with [params]
+func (s *Server) call(method string, params json.RawMessage) (interface{}, error) {
+	s.lock.Lock()
+	s.calls[method]++
+	handler, ok := s.handlers[method]
+	s.lock.Unlock()
+	if ok {
+		return handler(params)
+	}
+
+	switch method {
+	case "health.health", "health.readiness", "health.liveness":
+		return s.healthReply(), nil
+	case "info.isBootstrapped":
+		args := info.IsBootstrappedArgs{}
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if s.missingChains[args.Chain] {
+			return nil, fmt.Errorf("%w %q", ErrChainNotFound, args.Chain)
+		}
+		bootstrapped, ok := s.bootstrapped[args.Chain]
+		return info.IsBootstrappedResponse{IsBootstrapped: !ok || bootstrapped}, nil
+	case "info.getNodeID":
+		return info.GetNodeIDReply{NodeID: s.nodeID}, nil
+	case "info.getNodeVersion":
+		return info.GetNodeVersionReply{Version: DefaultNodeVersion, VMVersions: map[string]string{}}, nil
+	case "info.getNetworkID":
+		return info.GetNetworkIDReply{NetworkID: avajson.Uint32(DefaultNetworkID)}, nil
+	case "info.peers":
+		return info.PeersReply{Peers: []info.Peer{}}, nil
+	case "platform.getBlockchains":
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return platformvm.GetBlockchainsResponse{Blockchains: s.blockchains}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errMethodNotFound, method)
+	}
+}
+
+func (s *Server) healthReply() health.APIReply {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return health.APIReply{Checks: map[string]health.Result{}, Healthy: s.healthy}
+}
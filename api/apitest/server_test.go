@@ -0,0 +1,80 @@
+package apitest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	require := require.New(t)
+	s := NewServer()
+	defer s.Close()
+	client := s.NewAPIClient("", 0)
+	ctx := context.Background()
+
+	reply, err := client.HealthAPI().Health(ctx, nil)
+	require.NoError(err)
+	require.True(reply.Healthy)
+	s.SetHealthy(false)
+	reply, err = client.HealthAPI().Health(ctx, nil)
+	require.NoError(err)
+	require.False(reply.Healthy)
+	require.Equal(2, s.Calls("health.health"))
+	resp, err := http.Get(s.URI() + "/ext/health")
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+
+	bootstrapped, err := client.InfoAPI().IsBootstrapped(ctx, "P")
+	require.NoError(err)
+	require.True(bootstrapped)
+	s.SetBootstrapped("P", false)
+	bootstrapped, err = client.InfoAPI().IsBootstrapped(ctx, "P")
+	require.NoError(err)
+	require.False(bootstrapped)
+	s.SetMissingChain("Z")
+	_, err = client.InfoAPI().IsBootstrapped(ctx, "Z")
+	require.ErrorContains(err, ErrChainNotFound.Error())
+
+	nodeID, _, err := client.InfoAPI().GetNodeID(ctx)
+	require.NoError(err)
+	require.Equal(s.NodeID(), nodeID)
+	version, err := client.InfoAPI().GetNodeVersion(ctx)
+	require.NoError(err)
+	require.Equal(DefaultNodeVersion, version.Version)
+	networkID, err := client.InfoAPI().GetNetworkID(ctx)
+	require.NoError(err)
+	require.Equal(uint32(DefaultNetworkID), networkID)
+
+	chain := platformvm.APIBlockchain{ID: ids.GenerateTestID(), Name: "subnetevm", SubnetID: ids.GenerateTestID()}
+	s.SetBlockchains([]platformvm.APIBlockchain{chain})
+	blockchains, err := client.PChainAPI().GetBlockchains(ctx)
+	require.NoError(err)
+	require.Equal([]platformvm.APIBlockchain{chain}, blockchains)
+
+	// programmed replies
+	s.Handle("info.peers", func(json.RawMessage) (interface{}, error) {
+		return info.PeersReply{NumPeers: 1, Peers: []info.Peer{{}}}, nil
+	})
+	peers, err := client.InfoAPI().Peers(ctx)
+	require.NoError(err)
+	require.Len(peers, 1)
+	s.Handle("health.liveness", func(json.RawMessage) (interface{}, error) {
+		return nil, errors.New("liveness error")
+	})
+	_, err = client.HealthAPI().Liveness(ctx, nil)
+	require.ErrorContains(err, "liveness error")
+
+	// unknown methods
+	_, err = client.InfoAPI().GetTxFee(ctx)
+	require.ErrorContains(err, "method not found")
+	require.Equal(1, s.Calls("info.getTxFee"))
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+
 	"github.com/ava-labs/avalanchego/api/admin"
 	"github.com/ava-labs/avalanchego/api/health"
 	"github.com/ava-labs/avalanchego/api/info"
@@ -27,5 +29,13 @@ type Client interface {
 	AdminAPI() admin.Client
 	PChainIndexAPI() indexer.Client
 	CChainIndexAPI() indexer.Client
+	XChainTxIndexAPI() indexer.Client
+	XChainVertexIndexAPI() indexer.Client
+	XChainBlockIndexAPI() indexer.Client
+	// Returns the API client of the chain with the given ID or alias, as
+	// registered for its VM with RegisterVMClient, or else a ChainClient
+	// of its DefaultChainEndpoint. The chain VM is got from the P-chain.
+	// See GetChainClient to get typed clients.
+	ChainAPI(ctx context.Context, chain string) (interface{}, error)
 	// TODO add methods
 }
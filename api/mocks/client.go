@@ -6,6 +6,8 @@ import (
 	api "github.com/ava-labs/avalanche-network-runner/api"
 	admin "github.com/ava-labs/avalanchego/api/admin"
 
+	context "context"
+
 	avm "github.com/ava-labs/avalanchego/vms/avm"
 
 	evm "github.com/ava-labs/coreth/plugin/evm"
@@ -94,6 +96,29 @@ func (_m *Client) CChainIndexAPI() indexer.Client {
 	return r0
 }
 
+// ChainAPI provides a mock function with given fields: ctx, chain
+func (_m *Client) ChainAPI(ctx context.Context, chain string) (interface{}, error) {
+	ret := _m.Called(ctx, chain)
+
+	var r0 interface{}
+	if rf, ok := ret.Get(0).(func(context.Context, string) interface{}); ok {
+		r0 = rf(ctx, chain)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, chain)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // HealthAPI provides a mock function with given fields:
 func (_m *Client) HealthAPI() health.Client {
 	ret := _m.Called()
@@ -206,6 +231,54 @@ func (_m *Client) XChainAPI() avm.Client {
 	return r0
 }
 
+// XChainBlockIndexAPI provides a mock function with given fields:
+func (_m *Client) XChainBlockIndexAPI() indexer.Client {
+	ret := _m.Called()
+
+	var r0 indexer.Client
+	if rf, ok := ret.Get(0).(func() indexer.Client); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(indexer.Client)
+		}
+	}
+
+	return r0
+}
+
+// XChainTxIndexAPI provides a mock function with given fields:
+func (_m *Client) XChainTxIndexAPI() indexer.Client {
+	ret := _m.Called()
+
+	var r0 indexer.Client
+	if rf, ok := ret.Get(0).(func() indexer.Client); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(indexer.Client)
+		}
+	}
+
+	return r0
+}
+
+// XChainVertexIndexAPI provides a mock function with given fields:
+func (_m *Client) XChainVertexIndexAPI() indexer.Client {
+	ret := _m.Called()
+
+	var r0 indexer.Client
+	if rf, ok := ret.Get(0).(func() indexer.Client); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(indexer.Client)
+		}
+	}
+
+	return r0
+}
+
 // XChainWalletAPI provides a mock function with given fields:
 func (_m *Client) XChainWalletAPI() avm.WalletClient {
 	ret := _m.Called()
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+)
+
+const (
+	// ChainPlaceholder is replaced by the chain ID or alias in the
+	// endpoints of VMClientSpec
+	ChainPlaceholder = "{chain}"
+	// DefaultChainEndpoint is the endpoint of the chain APIs of the VMs
+	// with no VMClientSpec, or with no endpoint on it
+	DefaultChainEndpoint = "/ext/bc/" + ChainPlaceholder + "/rpc"
+)
+
+// VMClientSpec tells how to create API clients for the chains of a VM
+type VMClientSpec struct {
+	// Endpoint of the chain API, relative to the node URI, eg
+	// "/ext/bc/{chain}" (see ChainPlaceholder).
+	// Defaults to DefaultChainEndpoint.
+	Endpoint string
+	// Returns a typed client of the chain API at [uri], eg the one of the
+	// VM client package. If nil, a ChainClient is used.
+	NewClient func(uri string) interface{}
+}
+
+var (
+	vmClientSpecsLock sync.RWMutex
+	// VM ID --> spec
+	vmClientSpecs = map[ids.ID]VMClientSpec{}
+)
+
+// RegisterVMClient makes Client.ChainAPI return clients created as given
+// by [spec] for the chains of VM [vmID], replacing any previous spec for it
+func RegisterVMClient(vmID ids.ID, spec VMClientSpec) {
+	vmClientSpecsLock.Lock()
+	defer vmClientSpecsLock.Unlock()
+
+	vmClientSpecs[vmID] = spec
+}
+
+// Returns the spec registered for VM [vmID], or the default one
+func getVMClientSpec(vmID ids.ID) VMClientSpec {
+	vmClientSpecsLock.RLock()
+	spec := vmClientSpecs[vmID]
+	vmClientSpecsLock.RUnlock()
+
+	if spec.Endpoint == "" {
+		spec.Endpoint = DefaultChainEndpoint
+	}
+	if spec.NewClient == nil {
+		spec.NewClient = func(uri string) interface{} {
+			return NewChainClient(uri)
+		}
+	}
+	return spec
+}
+
+// ChainClient issues JSON-RPC requests to a chain API.
+// It is the client of the chains of VMs with no typed client registered.
+type ChainClient interface {
+	// URI of the chain API
+	URI() string
+	// Calls [method] (eg "timestampvm.getBlock") with [params], decoding
+	// the result into [reply]
+	Call(ctx context.Context, method string, params interface{}, reply interface{}) error
+}
+
+type chainClient struct {
+	uri       string
+	requester rpc.EndpointRequester
+}
+
+// NewChainClient returns a JSON-RPC client of the chain API at [uri]
+func NewChainClient(uri string) ChainClient {
+	return &chainClient{
+		uri:       uri,
+		requester: rpc.NewEndpointRequester(uri),
+	}
+}
+
+func (c *chainClient) URI() string {
+	return c.uri
+}
+
+func (c *chainClient) Call(ctx context.Context, method string, params interface{}, reply interface{}) error {
+	return c.requester.SendRequest(ctx, method, params, reply)
+}
+
+// GetChainClient returns the client of chain [chain] (ID or alias) given
+// by [client], which must be of type T, eg ChainClient for VMs with no
+// typed client registered.
+func GetChainClient[T any](ctx context.Context, client Client, chain string) (T, error) {
+	var typed T
+	untyped, err := client.ChainAPI(ctx, chain)
+	if err != nil {
+		return typed, err
+	}
+	typed, ok := untyped.(T)
+	if !ok {
+		return typed, fmt.Errorf("client of chain %q is a %T, not a %s", chain, untyped, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typed, nil
+}
+
+func (c APIClient) ChainAPI(ctx context.Context, chain string) (interface{}, error) {
+	chainID, err := ids.FromString(chain)
+	if err != nil {
+		chainID, err = c.info.GetBlockchainID(ctx, chain)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get ID of chain %q: %w", chain, err)
+		}
+	}
+	vmID, err := c.chainVMID(ctx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get VM of chain %q: %w", chain, err)
+	}
+	spec := getVMClientSpec(vmID)
+	return spec.NewClient(c.uri + strings.ReplaceAll(spec.Endpoint, ChainPlaceholder, chain)), nil
+}
+
+// Returns the ID of the VM of chain [chainID], as given by the P-chain
+func (c APIClient) chainVMID(ctx context.Context, chainID ids.ID) (ids.ID, error) {
+	// the P-chain is not given by itself
+	if chainID == constants.PlatformChainID {
+		return constants.PlatformVMID, nil
+	}
+	blockchains, err := c.platform.GetBlockchains(ctx)
+	if err != nil {
+		return ids.Empty, err
+	}
+	for _, blockchain := range blockchains {
+		if blockchain.ID == chainID {
+			return blockchain.VMID, nil
+		}
+	}
+	return ids.Empty, fmt.Errorf("chain %s not found", chainID)
+}
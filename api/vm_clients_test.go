@@ -0,0 +1,63 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	"github.com/ava-labs/avalanche-network-runner/api/apitest"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+type timestampClient struct {
+	uri string
+}
+
+func TestChainAPI(t *testing.T) {
+	require := require.New(t)
+	s := apitest.NewServer()
+	defer s.Close()
+	client := s.NewAPIClient("", 0)
+	ctx := context.Background()
+
+	genericChain := platformvm.APIBlockchain{ID: ids.GenerateTestID(), Name: "generic", VMID: ids.GenerateTestID()}
+	typedChain := platformvm.APIBlockchain{ID: ids.GenerateTestID(), Name: "timestamp", VMID: ids.GenerateTestID()}
+	s.SetBlockchains([]platformvm.APIBlockchain{genericChain, typedChain})
+	s.Handle("info.getBlockchainID", func(json.RawMessage) (interface{}, error) {
+		return info.GetBlockchainIDReply{BlockchainID: typedChain.ID}, nil
+	})
+	s.Handle("generic.getValue", func(json.RawMessage) (interface{}, error) {
+		return map[string]int{"value": 10}, nil
+	})
+
+	// no client registered for the VM
+	genericClient, err := api.GetChainClient[api.ChainClient](ctx, client, genericChain.ID.String())
+	require.NoError(err)
+	require.Equal(s.URI()+"/ext/bc/"+genericChain.ID.String()+"/rpc", genericClient.URI())
+	reply := map[string]int{}
+	require.NoError(genericClient.Call(ctx, "generic.getValue", struct{}{}, &reply))
+	require.Equal(10, reply["value"])
+
+	api.RegisterVMClient(typedChain.VMID, api.VMClientSpec{
+		Endpoint: "/ext/bc/" + api.ChainPlaceholder,
+		NewClient: func(uri string) interface{} {
+			return &timestampClient{uri: uri}
+		},
+	})
+	typedClient, err := api.GetChainClient[*timestampClient](ctx, client, typedChain.ID.String())
+	require.NoError(err)
+	require.Equal(s.URI()+"/ext/bc/"+typedChain.ID.String(), typedClient.uri)
+	// by alias
+	typedClient, err = api.GetChainClient[*timestampClient](ctx, client, "timestamp")
+	require.NoError(err)
+	require.Equal(s.URI()+"/ext/bc/timestamp", typedClient.uri)
+	_, err = api.GetChainClient[api.ChainClient](ctx, client, typedChain.ID.String())
+	require.ErrorContains(err, "is a *api_test.timestampClient, not a api.ChainClient")
+
+	_, err = client.ChainAPI(ctx, ids.GenerateTestID().String())
+	require.ErrorContains(err, "not found")
+}
@@ -0,0 +1,437 @@
+// Package binaries downloads avalanchego releases by version, and caches
+// them so they can be used as node binaries
+package binaries
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// DefaultReleasesURL is the GitHub API URL of the avalanchego releases,
+	// by tag
+	DefaultReleasesURL = "https://api.github.com/repos/ava-labs/avalanchego/releases/tags"
+
+	binaryName     = "avalanchego"
+	digestPrefix   = "sha256:"
+	downloadPrefix = ".download-"
+)
+
+var (
+	// default cache dir, relative to the user home dir
+	cacheRelPath = filepath.Join(".avalanche-network-runner", "binaries")
+
+	errNoChecksum = errors.New("release asset has no published checksum")
+
+	// ErrBinaryMismatch is returned when a binary doesn't have the
+	// expected SHA256
+	ErrBinaryMismatch = errors.New("binary checksum mismatch")
+)
+
+// Downloader gets avalanchego binaries by version, downloading the
+// matching release for the host OS/arch the first time a version is
+// asked for, and caching it after verifying its checksum.
+// It is safe for concurrent use.
+type Downloader struct {
+	log logging.Logger
+	// Resolved on first use if empty. Guarded by [lock].
+	cacheDir    string
+	releasesURL string
+	client      *http.Client
+
+	lock sync.Mutex
+}
+
+// NewDownloader returns a downloader caching the releases at [cacheDir],
+// which defaults to ~/.avalanche-network-runner/binaries if empty. The
+// default is resolved on first use, so the home dir is only needed once
+// releases are asked for.
+// Release metadata is fetched from [releasesURL], which defaults to
+// DefaultReleasesURL if empty.
+func NewDownloader(log logging.Logger, cacheDir string, releasesURL string) (*Downloader, error) {
+	if releasesURL == "" {
+		releasesURL = DefaultReleasesURL
+	}
+	return &Downloader{
+		log:         log,
+		cacheDir:    cacheDir,
+		releasesURL: strings.TrimSuffix(releasesURL, "/"),
+		client:      http.DefaultClient,
+	}, nil
+}
+
+// CacheDir returns the dir where releases are cached, or an empty string
+// if it is the default one and the home dir can't be found
+func (d *Downloader) CacheDir() string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	cacheDir, _ := d.resolveCacheDir()
+	return cacheDir
+}
+
+// Returns the cache dir, resolving the default one if not resolved yet.
+// Assumes [d.lock] is held.
+func (d *Downloader) resolveCacheDir() (string, error) {
+	if d.cacheDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("couldn't get home dir: %w", err)
+		}
+		d.cacheDir = filepath.Join(homeDir, cacheRelPath)
+	}
+	return d.cacheDir, nil
+}
+
+// BinaryPath returns the path of the avalanchego binary of [version]
+// (eg v1.10.15), downloading its release if not cached yet.
+// Other files of the release (eg plugins) are kept next to the binary.
+func (d *Downloader) BinaryPath(ctx context.Context, version string) (string, error) {
+	return d.VerifiedBinaryPath(ctx, version, "")
+}
+
+// VerifiedBinaryPath is like BinaryPath, but also checks that the binary
+// has SHA256 [expectedSHA256] (in hex), if not empty. A cached binary that
+// doesn't match (eg corrupted) is downloaded again, while a mismatch of
+// the downloaded one fails with ErrBinaryMismatch.
+func (d *Downloader) VerifiedBinaryPath(ctx context.Context, version string, expectedSHA256 string) (string, error) {
+	if !semver.IsValid(version) {
+		return "", fmt.Errorf("invalid avalanchego version %q", version)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	cacheDir, err := d.resolveCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("couldn't get binaries cache dir: %w", err)
+	}
+	versionDir := filepath.Join(cacheDir, version)
+	binaryPath := filepath.Join(versionDir, binaryName)
+
+	if _, err := os.Stat(binaryPath); err == nil {
+		if expectedSHA256 == "" {
+			return binaryPath, nil
+		}
+		err := VerifyBinary(binaryPath, expectedSHA256)
+		if !errors.Is(err, ErrBinaryMismatch) {
+			return binaryPath, err
+		}
+		d.log.Warn("cached avalanchego doesn't match, downloading it again",
+			zap.String("version", version),
+			zap.Error(err),
+		)
+		if err := os.RemoveAll(versionDir); err != nil {
+			return "", err
+		}
+	}
+	if err := os.MkdirAll(cacheDir, 0o750); err != nil {
+		return "", fmt.Errorf("couldn't create binaries cache dir: %w", err)
+	}
+	if err := d.download(ctx, version, versionDir); err != nil {
+		return "", fmt.Errorf("couldn't download avalanchego %s: %w", version, err)
+	}
+	if expectedSHA256 != "" {
+		if err := VerifyBinary(binaryPath, expectedSHA256); err != nil {
+			// not kept, so the next use doesn't take it as good
+			_ = os.RemoveAll(versionDir)
+			return "", err
+		}
+	}
+	return binaryPath, nil
+}
+
+// VerifyBinary returns ErrBinaryMismatch if the SHA256 of the file at
+// [binaryPath] is not [expectedSHA256], given in hex
+func VerifyBinary(binaryPath string, expectedSHA256 string) error {
+	sum, err := FileSHA256(binaryPath)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(sum, expectedSHA256) {
+		return fmt.Errorf("%w for %s: expected sha256 %s, got %s", ErrBinaryMismatch, binaryPath, expectedSHA256, sum)
+	}
+	return nil
+}
+
+// FileSHA256 returns the SHA256 of the file at [path], in hex
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("couldn't read %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Downloads and extracts the release of [version] into [versionDir].
+// Assumes [d.lock] is held.
+func (d *Downloader) download(ctx context.Context, version string, versionDir string) error {
+	name, err := AssetName(version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	asset, err := d.getAsset(ctx, version, name)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(asset.Digest, digestPrefix) {
+		return fmt.Errorf("%w: %s", errNoChecksum, name)
+	}
+	expectedSum := strings.TrimPrefix(asset.Digest, digestPrefix)
+
+	d.log.Info("downloading avalanchego",
+		zap.String("version", version),
+		zap.String("url", asset.URL),
+	)
+	// downloads and extracts next to the final dir, so the release
+	// becomes visible at once, by renaming
+	archiveFile, err := os.CreateTemp(d.cacheDir, downloadPrefix+"*"+filepath.Ext(name))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archiveFile.Name())
+	defer archiveFile.Close()
+	sum, err := d.get(ctx, asset.URL, archiveFile)
+	if err != nil {
+		return err
+	}
+	if sum != expectedSum {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expectedSum, sum)
+	}
+
+	extractDir, err := os.MkdirTemp(d.cacheDir, downloadPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(extractDir)
+	if strings.HasSuffix(name, ".zip") {
+		err = extractZip(archiveFile.Name(), extractDir)
+	} else {
+		err = extractTarGz(archiveFile.Name(), extractDir)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't extract %s: %w", name, err)
+	}
+	// archives wrap the release files into a dir of their own
+	releaseDir, err := findBinaryDir(extractDir)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(releaseDir, versionDir); err != nil {
+		return err
+	}
+	d.log.Info("cached avalanchego", zap.String("version", version), zap.String("dir", versionDir))
+	return nil
+}
+
+// release asset, as returned by the GitHub API
+type releaseAsset struct {
+	Name   string `json:"name"`
+	URL    string `json:"browser_download_url"`
+	Digest string `json:"digest"`
+}
+
+// Returns asset [name] of the release of [version]
+func (d *Downloader) getAsset(ctx context.Context, version string, name string) (releaseAsset, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.releasesURL+"/"+version, nil)
+	if err != nil {
+		return releaseAsset{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return releaseAsset{}, fmt.Errorf("couldn't get release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return releaseAsset{}, fmt.Errorf("couldn't get release: %s", resp.Status)
+	}
+	var release struct {
+		Assets []releaseAsset `json:"assets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return releaseAsset{}, fmt.Errorf("couldn't decode release: %w", err)
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset, nil
+		}
+	}
+	return releaseAsset{}, fmt.Errorf("release has no asset %s", name)
+}
+
+// Writes the content at [url] to [w], and returns its SHA256 in hex
+func (d *Downloader) get(ctx context.Context, url string, w io.Writer) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("couldn't download %s: %s", url, resp.Status)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, hash), resp.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// AssetName returns the name of the avalanchego release asset of
+// [version] for OS [goos] and arch [goarch]
+func AssetName(version string, goos string, goarch string) (string, error) {
+	switch {
+	case goos == "linux" && (goarch == "amd64" || goarch == "arm64"):
+		return fmt.Sprintf("avalanchego-linux-%s-%s.tar.gz", goarch, version), nil
+	case goos == "darwin":
+		return fmt.Sprintf("avalanchego-macos-%s.zip", version), nil
+	default:
+		return "", fmt.Errorf("no avalanchego releases for %s/%s", goos, goarch)
+	}
+}
+
+// Returns the dir under [dir] that holds the avalanchego binary
+func findBinaryDir(dir string) (string, error) {
+	binaryDir := ""
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() && entry.Name() == binaryName {
+			binaryDir = filepath.Dir(path)
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if binaryDir == "" {
+		return "", fmt.Errorf("release has no %s binary", binaryName)
+	}
+	return binaryDir, nil
+}
+
+// Returns the path under [dir] of archive entry [name], refusing the
+// entries that would be written out of [dir]
+func extractPath(dir string, name string) (string, error) {
+	path := filepath.Join(dir, name)
+	if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid archive entry %q", name)
+	}
+	return path, nil
+}
+
+// Writes the content of [r] to file [path] with mode [mode]
+func writeFile(path string, r io.Reader, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { //nolint:gosec
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func extractTarGz(archivePath string, dir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path, err := extractPath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(path, tarReader, header.FileInfo().Mode()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractZip(archivePath string, dir string) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+	for _, file := range zipReader.File {
+		path, err := extractPath(dir, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0o750); err != nil {
+				return err
+			}
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(path, r, file.Mode())
+		_ = r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package binaries
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+const testBinary = "#!/bin/sh\necho avalanche/1.10.15\n"
+
+// Returns a release archive of the host OS, as published by avalanchego
+func testArchive(t *testing.T, name string, version string) []byte {
+	require := require.New(t)
+	files := map[string]string{
+		"avalanchego-" + version + "/avalanchego": testBinary,
+		"avalanchego-" + version + "/plugins/evm": "evm",
+	}
+	buf := &bytes.Buffer{}
+	if strings.HasSuffix(name, ".zip") {
+		zipWriter := zip.NewWriter(buf)
+		for path, content := range files {
+			header := &zip.FileHeader{Name: path, Method: zip.Deflate}
+			header.SetMode(0o755)
+			w, err := zipWriter.CreateHeader(header)
+			require.NoError(err)
+			_, err = w.Write([]byte(content))
+			require.NoError(err)
+		}
+		require.NoError(zipWriter.Close())
+		return buf.Bytes()
+	}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for path, content := range files {
+		require.NoError(tarWriter.WriteHeader(&tar.Header{
+			Name:     path,
+			Typeflag: tar.TypeReg,
+			Mode:     0o755,
+			Size:     int64(len(content)),
+		}))
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(err)
+	}
+	require.NoError(tarWriter.Close())
+	require.NoError(gzipWriter.Close())
+	return buf.Bytes()
+}
+
+// Serves the release of [version] for the host OS, with checksum [digest]
+// (the actual one if empty). Returns the server and its download count.
+func testReleasesServer(t *testing.T, version string, digest string) (*httptest.Server, *int) {
+	name, err := AssetName(version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		t.Skip(err)
+	}
+	archive := testArchive(t, name, version)
+	if digest == "" {
+		sum := sha256.Sum256(archive)
+		digest = digestPrefix + hex.EncodeToString(sum[:])
+	}
+	downloads := 0
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	mux.HandleFunc("/releases/"+version, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"assets": []releaseAsset{{
+				Name:   name,
+				URL:    server.URL + "/download/" + name,
+				Digest: digest,
+			}},
+		})
+	})
+	mux.HandleFunc("/download/"+name, func(w http.ResponseWriter, _ *http.Request) {
+		downloads++
+		_, _ = w.Write(archive)
+	})
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func TestBinaryPath(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	version := "v1.10.15"
+	server, downloads := testReleasesServer(t, version, "")
+	cacheDir := t.TempDir()
+	downloader, err := NewDownloader(logging.NoLog{}, cacheDir, server.URL+"/releases")
+	require.NoError(err)
+
+	binaryPath, err := downloader.BinaryPath(ctx, version)
+	require.NoError(err)
+	require.Equal(filepath.Join(cacheDir, version, binaryName), binaryPath)
+	content, err := os.ReadFile(binaryPath)
+	require.NoError(err)
+	require.Equal(testBinary, string(content))
+	info, err := os.Stat(binaryPath)
+	require.NoError(err)
+	require.NotZero(info.Mode().Perm() & 0o100)
+	require.FileExists(filepath.Join(cacheDir, version, "plugins", "evm"))
+
+	// the cached release is reused
+	_, err = downloader.BinaryPath(ctx, version)
+	require.NoError(err)
+	require.Equal(1, *downloads)
+	// and nothing else is left in the cache dir
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(err)
+	require.Len(entries, 1)
+
+	_, err = downloader.BinaryPath(ctx, "1.10.15")
+	require.Error(err)
+	_, err = downloader.BinaryPath(ctx, "v1.10.16")
+	require.Error(err)
+}
+
+func TestBinaryPathChecksumMismatch(t *testing.T) {
+	require := require.New(t)
+	version := "v1.10.15"
+	server, _ := testReleasesServer(t, version, digestPrefix+strings.Repeat("0", 64))
+	cacheDir := t.TempDir()
+	downloader, err := NewDownloader(logging.NoLog{}, cacheDir, server.URL+"/releases")
+	require.NoError(err)
+
+	_, err = downloader.BinaryPath(context.Background(), version)
+	require.ErrorContains(err, "checksum mismatch")
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(err)
+	require.Empty(entries)
+}
+
+func TestVerifiedBinaryPath(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	version := "v1.10.15"
+	server, downloads := testReleasesServer(t, version, "")
+	cacheDir := t.TempDir()
+	downloader, err := NewDownloader(logging.NoLog{}, cacheDir, server.URL+"/releases")
+	require.NoError(err)
+	sum := sha256.Sum256([]byte(testBinary))
+	expectedSHA256 := hex.EncodeToString(sum[:])
+
+	binaryPath, err := downloader.VerifiedBinaryPath(ctx, version, expectedSHA256)
+	require.NoError(err)
+	require.NoError(VerifyBinary(binaryPath, strings.ToUpper(expectedSHA256)))
+
+	// a corrupted cached binary is downloaded again
+	require.NoError(os.WriteFile(binaryPath, []byte("corrupted"), 0o600))
+	require.ErrorIs(VerifyBinary(binaryPath, expectedSHA256), ErrBinaryMismatch)
+	_, err = downloader.VerifiedBinaryPath(ctx, version, expectedSHA256)
+	require.NoError(err)
+	require.Equal(2, *downloads)
+	require.NoError(VerifyBinary(binaryPath, expectedSHA256))
+
+	// while a downloaded binary that doesn't match is not kept
+	require.NoError(os.RemoveAll(filepath.Join(cacheDir, version)))
+	_, err = downloader.VerifiedBinaryPath(ctx, version, strings.Repeat("0", 64))
+	require.ErrorIs(err, ErrBinaryMismatch)
+	require.NoDirExists(filepath.Join(cacheDir, version))
+}
+
+// TestDefaultCacheDir tests that the default cache dir is resolved on
+// first use, so downloaders can be created without a home dir
+func TestDefaultCacheDir(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("HOME", "")
+	downloader, err := NewDownloader(logging.NoLog{}, "", "")
+	require.NoError(err)
+	require.Empty(downloader.CacheDir())
+	_, err = downloader.BinaryPath(context.Background(), "v1.10.15")
+	require.ErrorContains(err, "couldn't get home dir")
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	require.Equal(filepath.Join(homeDir, cacheRelPath), downloader.CacheDir())
+}
+
+func TestAssetName(t *testing.T) {
+	require := require.New(t)
+
+	name, err := AssetName("v1.7.4", "linux", "amd64")
+	require.NoError(err)
+	require.Equal("avalanchego-linux-amd64-v1.7.4.tar.gz", name)
+	name, err = AssetName("v1.7.4", "darwin", "arm64")
+	require.NoError(err)
+	require.Equal("avalanchego-macos-v1.7.4.zip", name)
+	_, err = AssetName("v1.7.4", "plan9", "amd64")
+	require.Error(err)
+}
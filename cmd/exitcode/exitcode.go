@@ -0,0 +1,84 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package exitcode gives the exit codes of the CLI by kind of failure,
+// so wrapper scripts and CI steps can branch on them.
+package exitcode
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	OK = 0
+	// Runtime failures, eg a node crash or a server error
+	Runtime = 1
+	// Invalid args, flags or config files
+	Validation = 2
+	// The network, or the server, not ready in time
+	Timeout = 3
+)
+
+// Name of each exit code, as given on json errors
+var kinds = map[int]string{
+	OK:         "ok",
+	Runtime:    "runtime",
+	Validation: "validation",
+	Timeout:    "timeout",
+}
+
+// Error gives the exit code of the CLI failing with [Err]
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewValidationError returns [err] with the Validation exit code
+func NewValidationError(err error) error {
+	return &Error{Code: Validation, Err: err}
+}
+
+// NewTimeoutError returns [err] with the Timeout exit code
+func NewTimeoutError(err error) error {
+	return &Error{Code: Timeout, Err: err}
+}
+
+// Of returns the exit code of the CLI failing with [err]: the one given
+// by the *Error in its chain, or Timeout for deadlines exceeded (also on
+// the server), or Runtime.
+func Of(err error) int {
+	if err == nil {
+		return OK
+	}
+	var exitErr *Error
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Timeout
+	}
+	if s, ok := status.FromError(err); ok && s.Code() == codes.DeadlineExceeded {
+		return Timeout
+	}
+	return Runtime
+}
+
+// Kind returns the name of exit [code]
+func Kind(code int) string {
+	if kind, ok := kinds[code]; ok {
+		return kind
+	}
+	return kinds[Runtime]
+}
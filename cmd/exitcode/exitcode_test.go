@@ -0,0 +1,30 @@
+package exitcode_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOf(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(exitcode.OK, exitcode.Of(nil))
+	require.Equal(exitcode.Runtime, exitcode.Of(errors.New("node crashed")))
+	require.Equal(exitcode.Validation, exitcode.Of(exitcode.NewValidationError(errors.New("no genesis given"))))
+	// the code given is kept when wrapped, even on deadlines exceeded
+	require.Equal(exitcode.Validation, exitcode.Of(fmt.Errorf("run: %w", exitcode.NewValidationError(context.DeadlineExceeded))))
+	require.Equal(exitcode.Timeout, exitcode.Of(exitcode.NewTimeoutError(errors.New("network not healthy"))))
+	require.Equal(exitcode.Timeout, exitcode.Of(fmt.Errorf("health: %w", context.DeadlineExceeded)))
+	require.Equal(exitcode.Timeout, exitcode.Of(status.Error(codes.DeadlineExceeded, "context deadline exceeded")))
+	require.Equal(exitcode.Runtime, exitcode.Of(status.Error(codes.Unknown, "network stopped")))
+
+	require.Equal("validation", exitcode.Kind(exitcode.Validation))
+	require.Equal("runtime", exitcode.Kind(42))
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package exportcompose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/ava-labs/avalanche-network-runner/docker"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/spf13/cobra"
+)
+
+var (
+	image  string
+	mounts []string
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-compose network-config-file output-dir [options]",
+		Short: "Exports a network config file as a docker-compose.yml with the node files.",
+		RunE:  exportComposeFunc,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.PersistentFlags().StringVar(&image, "image", docker.DefaultImage, "avalanchego image of the nodes")
+	cmd.PersistentFlags().StringSliceVar(&mounts, "mounts", nil, "additional bind mounts of the nodes, in docker host-path:container-path format")
+
+	return cmd
+}
+
+func exportComposeFunc(_ *cobra.Command, args []string) error {
+	configBytes, err := os.ReadFile(args[0])
+	if err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	config, err := network.LoadConfig(configBytes)
+	if err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	// env vars override the file config
+	if err := config.ApplyEnv(os.Environ()); err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	if err := os.MkdirAll(args[1], os.ModePerm); err != nil {
+		return err
+	}
+	if err := docker.ExportCompose(config, docker.Config{Image: image, Mounts: mounts}, args[1]); err != nil {
+		return err
+	}
+	fmt.Println("compose file written to", filepath.Join(args[1], docker.ComposeFileName))
+	return nil
+}
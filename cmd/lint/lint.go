@@ -0,0 +1,68 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/spf13/cobra"
+)
+
+var format string
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint network-config-file [options]",
+		Short: "Validates a network config file and warns about suspicious settings.",
+		RunE:  lintFunc,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.PersistentFlags().StringVar(&format, "format", "text", "output format (text, json)")
+
+	return cmd
+}
+
+func lintFunc(_ *cobra.Command, args []string) error {
+	configBytes, err := os.ReadFile(args[0])
+	if err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	config, err := network.LoadConfig(configBytes)
+	if err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	// env vars override the file config
+	if err := config.ApplyEnv(os.Environ()); err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	result := network.LintConfig(&config)
+
+	switch format {
+	case "text":
+		for _, warning := range result.Warnings {
+			fmt.Println("warning:", warning)
+		}
+		if result.Error == "" && len(result.Warnings) == 0 {
+			fmt.Println("no issues found")
+		}
+	case "json":
+		resultBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(resultBytes))
+	default:
+		return exitcode.NewValidationError(fmt.Errorf("unknown format %q", format))
+	}
+	if result.Error != "" {
+		return exitcode.NewValidationError(errors.New(result.Error))
+	}
+	return nil
+}
@@ -4,16 +4,26 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/ava-labs/avalanche-network-runner/cmd/control"
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/ava-labs/avalanche-network-runner/cmd/exportcompose"
+	"github.com/ava-labs/avalanche-network-runner/cmd/lint"
 	"github.com/ava-labs/avalanche-network-runner/cmd/ping"
+	"github.com/ava-labs/avalanche-network-runner/cmd/run"
+	"github.com/ava-labs/avalanche-network-runner/cmd/scalingbenchmark"
 	"github.com/ava-labs/avalanche-network-runner/cmd/server"
+	"github.com/ava-labs/avalanche-network-runner/cmd/snapshotdiff"
 	"github.com/spf13/cobra"
 )
 
-var Version = ""
+var (
+	Version    = ""
+	jsonErrors bool
+)
 
 var rootCmd = &cobra.Command{
 	Use:        "avalanche-network-runner",
@@ -24,6 +34,7 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	cobra.EnablePrefixMatching = true
+	cobra.OnInitialize(silenceJSONErrors)
 }
 
 func init() {
@@ -31,13 +42,70 @@ func init() {
 		server.NewCommand(),
 		ping.NewCommand(),
 		control.NewCommand(),
+		lint.NewCommand(),
+		snapshotdiff.NewCommand(),
+		exportcompose.NewCommand(),
+		run.NewCommand(),
+		scalingbenchmark.NewCommand(),
 	)
+
+	rootCmd.PersistentFlags().BoolVar(&jsonErrors, "json-errors", false, "print errors to stderr as json, with their kind and exit code")
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		silenceJSONErrors()
+		return exitcode.NewValidationError(err)
+	})
+	setArgsValidationErrors(rootCmd)
+}
+
+// Gives the Validation exit code to the errors of the args validators
+// of [cmd] and its subcommands
+func setArgsValidationErrors(cmd *cobra.Command) {
+	if validateArgs := cmd.Args; validateArgs != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validateArgs(cmd, args); err != nil {
+				return exitcode.NewValidationError(err)
+			}
+			return nil
+		}
+	}
+	for _, subCmd := range cmd.Commands() {
+		setArgsValidationErrors(subCmd)
+	}
 }
 
+// Keeps cobra from printing errors and usage if they are printed as json
+func silenceJSONErrors() {
+	if jsonErrors {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+	}
+}
+
+// jsonError is the error printed by the --json-errors mode
+type jsonError struct {
+	Error    string `json:"error"`
+	Kind     string `json:"kind"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// Execute runs the command given by the args, and exits with
+// exitcode.Validation, exitcode.Timeout or exitcode.Runtime if it fails
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if err == nil {
+		os.Exit(exitcode.OK)
+	}
+	code := exitcode.Of(err)
+	if !jsonErrors {
 		fmt.Fprintf(os.Stderr, "avalanche-network-runner failed %v\n", err)
-		os.Exit(1)
+		os.Exit(code)
 	}
-	os.Exit(0)
+	// can't fail on strings and ints
+	errBytes, _ := json.Marshal(jsonError{
+		Error:    err.Error(),
+		Kind:     exitcode.Kind(code),
+		ExitCode: code,
+	})
+	fmt.Fprintln(os.Stderr, string(errBytes))
+	os.Exit(code)
 }
@@ -0,0 +1,129 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/ava-labs/avalanche-network-runner/local"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLevel       string
+	rootDir        string
+	snapshotsDir   string
+	format         string
+	healthyTimeout time.Duration
+	redirectOutput bool
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run network-config-file [options]",
+		Short: "Starts a local network from a network config file, and stops it on SIGINT or SIGTERM.",
+		RunE:  runFunc,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.PersistentFlags().StringVar(&logLevel, "log-level", logging.Info.String(), "log level")
+	cmd.PersistentFlags().StringVar(&rootDir, "root-dir", "", "root dir of the node files (defaults to a new temporary dir)")
+	cmd.PersistentFlags().StringVar(&snapshotsDir, "snapshots-dir", "", "snapshots dir (defaults to ~/.avalanche-network-runner/snapshots)")
+	cmd.PersistentFlags().StringVar(&format, "format", "text", "output format of the network summary (text, json, markdown)")
+	cmd.PersistentFlags().DurationVar(&healthyTimeout, "healthy-timeout", 5*time.Minute, "max time to wait for the network to be healthy")
+	cmd.PersistentFlags().BoolVar(&redirectOutput, "redirect-output", false, "redirect the node stdout and stderr to the runner ones")
+
+	return cmd
+}
+
+func runFunc(_ *cobra.Command, args []string) error {
+	configBytes, err := os.ReadFile(args[0])
+	if err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	config, err := network.LoadConfig(configBytes)
+	if err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	// env vars override the file config
+	if err := config.ApplyEnv(os.Environ()); err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	if err := config.Validate(); err != nil {
+		return exitcode.NewValidationError(fmt.Errorf("config failed validation: %w", err))
+	}
+	// fail before starting any node if the summary can't be printed
+	if _, err := network.FormatSummary(network.Summary{}, format); err != nil {
+		return exitcode.NewValidationError(err)
+	}
+
+	lvl, err := logging.ToLevel(logLevel)
+	if err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	logFactory := logging.NewFactory(logging.Config{
+		DisplayLevel: lvl,
+		LogLevel:     logging.Off,
+	})
+	log, err := logFactory.Make(constants.LogNameMain)
+	if err != nil {
+		return err
+	}
+
+	nw, err := local.NewNetwork(context.Background(), log, config, rootDir, snapshotsDir, true, redirectOutput, redirectOutput)
+	if err != nil {
+		if nw != nil {
+			_ = nw.Stop(context.Background())
+		}
+		return err
+	}
+	stoppedCh, unregister := network.RegisterSignalHandlers(nw, network.WithStopCallback(func(sig os.Signal, err error) {
+		if err != nil {
+			log.Warn(fmt.Sprintf("network stop on %s failed: %s", sig, err))
+			return
+		}
+		log.Info(fmt.Sprintf("network stopped on %s", sig))
+	}))
+	// stops the network on errors before it is ready
+	abort := func(err error) error {
+		unregister()
+		_ = nw.Stop(context.Background())
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthyTimeout)
+	err = nw.Healthy(ctx)
+	timedOut := ctx.Err() == context.DeadlineExceeded
+	cancel()
+	if err != nil {
+		err = fmt.Errorf("network not healthy: %w", err)
+		if timedOut {
+			err = exitcode.NewTimeoutError(err)
+		}
+		return abort(err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), healthyTimeout)
+	summary, err := network.NewSummary(ctx, nw, nil)
+	cancel()
+	if err != nil {
+		return abort(err)
+	}
+	summaryBytes, err := network.FormatSummary(summary, format)
+	if err != nil {
+		return abort(err)
+	}
+	fmt.Println(string(summaryBytes))
+
+	log.Info("network running, press Ctrl+C to stop it")
+	<-stoppedCh
+	return nil
+}
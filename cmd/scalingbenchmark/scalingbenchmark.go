@@ -0,0 +1,89 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package scalingbenchmark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/ava-labs/avalanche-network-runner/local"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLevel       string
+	sizes          []int
+	format         string
+	healthyTimeout time.Duration
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scaling-benchmark avalanchego-path [options]",
+		Short: "Starts local networks of increasing size, and reports the time they take to be healthy and the host resources they use.",
+		RunE:  scalingBenchmarkFunc,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.PersistentFlags().StringVar(&logLevel, "log-level", logging.Info.String(), "log level")
+	cmd.PersistentFlags().IntSliceVar(&sizes, "sizes", network.DefaultScalingSizes, "numbers of nodes of the networks started, in order")
+	cmd.PersistentFlags().StringVar(&format, "format", "text", "output format (text, json)")
+	cmd.PersistentFlags().DurationVar(&healthyTimeout, "healthy-timeout", 10*time.Minute, "max time to wait for each network to be healthy")
+
+	return cmd
+}
+
+func scalingBenchmarkFunc(_ *cobra.Command, args []string) error {
+	if format != "text" && format != "json" {
+		return exitcode.NewValidationError(fmt.Errorf("unknown format %q", format))
+	}
+	for _, numNodes := range sizes {
+		if numNodes <= 0 {
+			return exitcode.NewValidationError(fmt.Errorf("invalid number of nodes %d", numNodes))
+		}
+	}
+	lvl, err := logging.ToLevel(logLevel)
+	if err != nil {
+		return exitcode.NewValidationError(err)
+	}
+	logFactory := logging.NewFactory(logging.Config{
+		DisplayLevel: lvl,
+		LogLevel:     logging.Off,
+	})
+	log, err := logFactory.Make(constants.LogNameMain)
+	if err != nil {
+		return err
+	}
+
+	binaryPath := args[0]
+	report, benchmarkErr := network.RunScalingBenchmark(context.Background(), network.ScalingSpec{
+		Sizes: sizes,
+		NewConfig: func(numNodes int) (network.Config, error) {
+			return local.NewDefaultConfigNNodes(binaryPath, uint32(numNodes))
+		},
+		NewNetwork: func(ctx context.Context, config network.Config) (network.Network, error) {
+			log.Info(fmt.Sprintf("starting network of %d nodes", len(config.NodeConfigs)))
+			return local.NewNetwork(ctx, log, config, "", "", true, false, false)
+		},
+		HealthyTimeout: healthyTimeout,
+	})
+	// the report of the networks measured is printed even on failure
+	switch format {
+	case "text":
+		fmt.Print(report.String())
+	case "json":
+		reportBytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(reportBytes))
+	}
+	return benchmarkErr
+}
@@ -5,12 +5,15 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"regexp"
 	"syscall"
 	"time"
 
+	"github.com/ava-labs/avalanche-network-runner/network"
 	"github.com/ava-labs/avalanche-network-runner/server"
 	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanche-network-runner/utils/constants"
@@ -34,6 +37,21 @@ var (
 	dialTimeout        time.Duration
 	disableNodesOutput bool
 	snapshotsDir       string
+	summaryFormat      string
+	summaryFile        string
+	snapshotsMaxAge    time.Duration
+	snapshotsMaxCount  int
+	networkName        string
+	networkDescription string
+	networkTags        map[string]string
+	networkTTL         time.Duration
+	eventBridgeURL     string
+	eventBridgeTopic   string
+	eventBridgeLogs    []string
+	webhookURL         string
+	webhookEvents      []string
+	diskUsageThreshold int64
+	nodeOpenFilesLimit uint64
 )
 
 func NewCommand() *cobra.Command {
@@ -52,6 +70,21 @@ func NewCommand() *cobra.Command {
 	cmd.PersistentFlags().DurationVar(&dialTimeout, "dial-timeout", 10*time.Second, "server dial timeout")
 	cmd.PersistentFlags().BoolVar(&disableNodesOutput, "disable-nodes-output", false, "true to disable nodes stdout/stderr")
 	cmd.PersistentFlags().StringVar(&snapshotsDir, "snapshots-dir", "", "directory for snapshots")
+	cmd.PersistentFlags().StringVar(&summaryFormat, "summary-format", "", "if set, print a network summary in this format (text, json, markdown) each time the network becomes healthy")
+	cmd.PersistentFlags().StringVar(&summaryFile, "summary-file", "", "if set, write the network summary to this file each time the network becomes healthy")
+	cmd.PersistentFlags().DurationVar(&snapshotsMaxAge, "snapshots-max-age", 0, "if set, remove snapshots older than this after each snapshot save")
+	cmd.PersistentFlags().IntVar(&snapshotsMaxCount, "snapshots-max-count", 0, "if set, keep only this number of newest snapshots after each snapshot save")
+	cmd.PersistentFlags().StringVar(&networkName, "network-name", "", "name given to the networks started by the server, to identify them on shared hosts")
+	cmd.PersistentFlags().StringVar(&networkDescription, "network-description", "", "description given to the networks started by the server (eg owner or purpose)")
+	cmd.PersistentFlags().DurationVar(&networkTTL, "network-ttl", 0, "if set, stop and remove the networks started by the server once this time passes")
+	cmd.PersistentFlags().StringVar(&eventBridgeURL, "event-bridge-url", "", "if set, publish the network events into this broker: nats://host:port for NATS, http(s)://host:port for a Kafka REST proxy")
+	cmd.PersistentFlags().StringVar(&eventBridgeTopic, "event-bridge-topic", "anr", "prefix of the event bridge topics: events are published into <prefix>.events and node log lines into <prefix>.logs")
+	cmd.PersistentFlags().StringSliceVar(&eventBridgeLogs, "event-bridge-log-patterns", nil, "regular expressions of the node log lines published by the event bridge")
+	cmd.PersistentFlags().StringVar(&webhookURL, "notifications-webhook-url", "", "if set, post notifications about the networks started by the server to this webhook (Slack compatible)")
+	cmd.PersistentFlags().StringSliceVar(&webhookEvents, "notifications-events", nil, "events notified to the webhook (default network-healthy,node-crashed,disk-threshold-exceeded)")
+	cmd.PersistentFlags().Int64Var(&diskUsageThreshold, "disk-usage-threshold", 0, "if set, notify when the root dir of a network started by the server grows larger than this number of bytes")
+	cmd.PersistentFlags().Uint64Var(&nodeOpenFilesLimit, "node-open-files-limit", 0, "if set, open files limit (RLIMIT_NOFILE) of the nodes of the networks started by the server. Fails if larger than the host hard limit")
+	cmd.PersistentFlags().StringToStringVar(&networkTags, "network-tags", nil, "tags given to the networks started by the server, as key=value pairs")
 
 	return cmd
 }
@@ -87,6 +120,23 @@ func serverFunc(*cobra.Command, []string) (err error) {
 		return err
 	}
 
+	logPatterns := []*regexp.Regexp{}
+	for _, pattern := range eventBridgeLogs {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid event bridge log pattern %q: %w", pattern, err)
+		}
+		logPatterns = append(logPatterns, re)
+	}
+
+	var notifications *network.NotificationsConfig
+	if webhookURL != "" {
+		notifications = &network.NotificationsConfig{WebhookURL: webhookURL}
+		for _, eventType := range webhookEvents {
+			notifications.Events = append(notifications.Events, network.EventType(eventType))
+		}
+	}
+
 	s, err := server.New(server.Config{
 		Port:                port,
 		GwPort:              gwPort,
@@ -95,6 +145,27 @@ func serverFunc(*cobra.Command, []string) (err error) {
 		RedirectNodesOutput: !disableNodesOutput,
 		SnapshotsDir:        snapshotsDir,
 		LogLevel:            logLevel,
+		SummaryFormat:       summaryFormat,
+		SummaryFile:         summaryFile,
+		SnapshotsRetention: network.SnapshotRetentionPolicy{
+			MaxAge:   snapshotsMaxAge,
+			MaxCount: snapshotsMaxCount,
+		},
+		NetworkMetadata: network.Metadata{
+			Name:        networkName,
+			Description: networkDescription,
+			Tags:        networkTags,
+		},
+		NetworkTTL:         networkTTL,
+		Notifications:      notifications,
+		DiskUsageThreshold: diskUsageThreshold,
+		NodeOpenFilesLimit: nodeOpenFilesLimit,
+		EventBridgeURL:     eventBridgeURL,
+		EventBridge: network.EventBridgeConfig{
+			EventsTopic: eventBridgeTopic + ".events",
+			LogsTopic:   eventBridgeTopic + ".logs",
+			LogPatterns: logPatterns,
+		},
 	}, log)
 	if err != nil {
 		return err
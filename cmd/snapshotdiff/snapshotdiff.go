@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshotdiff
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/ava-labs/avalanche-network-runner/local"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotsDir string
+	format       string
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot-diff from-snapshot-name to-snapshot-name [options]",
+		Short: "Summarizes what changed between two snapshots of a network.",
+		RunE:  snapshotDiffFunc,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.PersistentFlags().StringVar(&snapshotsDir, "snapshots-dir", "", "directory for snapshots (default ~/.avalanche-network-runner/snapshots)")
+	cmd.PersistentFlags().StringVar(&format, "format", "text", "output format (text, json)")
+
+	return cmd
+}
+
+func snapshotDiffFunc(_ *cobra.Command, args []string) error {
+	diff, err := local.DiffSnapshots(snapshotsDir, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "text":
+		fmt.Print(diff.String())
+	case "json":
+		diffBytes, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(diffBytes))
+	default:
+		return exitcode.NewValidationError(fmt.Errorf("unknown format %q", format))
+	}
+	return nil
+}
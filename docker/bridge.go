@@ -0,0 +1,188 @@
+package docker
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"os/exec"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/config"
+	"go.uber.org/zap"
+)
+
+const (
+	// bridge networks are given a random /24 of this range, unless
+	// Config.Subnet is set
+	defaultSubnetsPrefix = "10.213"
+	// networks tried before giving up, in case of subnet overlaps
+	maxBridgeAttempts = 10
+)
+
+var errPortsNotGiven = errors.New("node API and P2P ports must be given as flags to be published")
+
+// bridge is the docker network the node containers are connected to,
+// created with the first container and removed once all of them exit
+type bridge struct {
+	name   string
+	subnet netip.Prefix
+	// next container IP to give
+	next netip.Addr
+	// node name --> IP of its container, kept across node restarts
+	ips map[string]netip.Addr
+	// node P2P port --> IP of its container
+	p2pIPs map[string]netip.Addr
+	// containers connected to the network
+	containers int
+}
+
+// Returns the docker run args connecting the container of node [nodeName]
+// to the network, and [args] with the addresses the node has there.
+// With the bridge network, the container must be released with
+// releaseContainerNetwork when it exits.
+func (npc *nodeProcessCreator) containerNetwork(nodeName string, args []string) ([]string, []string, error) {
+	if npc.config.HostNetwork {
+		return []string{"--network", "host"}, args, nil
+	}
+	publicIP := flagValue(args, config.PublicIPKey)
+	apiPort := flagValue(args, config.HTTPPortKey)
+	p2pPort := flagValue(args, config.StakingPortKey)
+	if apiPort == "" || p2pPort == "" {
+		return nil, nil, fmt.Errorf("node %q: %w", nodeName, errPortsNotGiven)
+	}
+
+	npc.bridgeLock.Lock()
+	defer npc.bridgeLock.Unlock()
+
+	if npc.bridge == nil {
+		b, err := npc.createBridge()
+		if err != nil {
+			return nil, nil, err
+		}
+		npc.bridge = b
+	}
+	b := npc.bridge
+	ip, ok := b.ips[nodeName]
+	if !ok {
+		if !b.subnet.Contains(b.next) {
+			return nil, nil, fmt.Errorf("no IPs left on subnet %s", b.subnet)
+		}
+		ip = b.next
+		b.next = b.next.Next()
+		b.ips[nodeName] = ip
+	}
+	b.p2pIPs[p2pPort] = ip
+	b.containers++
+
+	// the ports are published on the IP the node has for the runner
+	hostIP := ""
+	if publicIP != "" {
+		hostIP = publicIP + ":"
+	}
+	netArgs := []string{
+		"--network", b.name,
+		"--ip", ip.String(),
+		"--publish", hostIP + apiPort + ":" + apiPort,
+		"--publish", hostIP + p2pPort + ":" + p2pPort,
+	}
+	return netArgs, b.containerArgs(ip, args), nil
+}
+
+// Releases the bridge network of a container that exited, removing the
+// network if it was the last one
+func (npc *nodeProcessCreator) releaseContainerNetwork() {
+	npc.bridgeLock.Lock()
+	defer npc.bridgeLock.Unlock()
+
+	if npc.bridge == nil {
+		return
+	}
+	npc.bridge.containers--
+	if npc.bridge.containers > 0 {
+		return
+	}
+	if out, err := exec.Command(npc.config.DockerPath, "network", "rm", npc.bridge.name).CombinedOutput(); err != nil { //nolint
+		npc.log.Warn("removing docker network errored", zap.String("network", npc.bridge.name), zap.String("output", string(out)), zap.Error(err))
+	}
+	npc.bridge = nil
+}
+
+// Creates the bridge network of the node containers
+func (npc *nodeProcessCreator) createBridge() (*bridge, error) {
+	var errs []string
+	for attempt := 0; attempt < maxBridgeAttempts; attempt++ {
+		subnet := npc.config.Subnet
+		if subnet == "" {
+			n, err := rand.Int(rand.Reader, big.NewInt(256))
+			if err != nil {
+				return nil, err
+			}
+			subnet = fmt.Sprintf("%s.%d.0/24", defaultSubnetsPrefix, n.Int64())
+		}
+		prefix, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subnet %q: %w", subnet, err)
+		}
+		prefix = prefix.Masked()
+		out, err := exec.Command(npc.config.DockerPath, "network", "create", "--driver", "bridge", "--subnet", prefix.String(), npc.containerNamePrefix).CombinedOutput() //nolint
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", err, strings.TrimSpace(string(out))))
+			// a given subnet is not retried
+			if npc.config.Subnet != "" {
+				break
+			}
+			continue
+		}
+		return &bridge{
+			name:   npc.containerNamePrefix,
+			subnet: prefix,
+			// the first address is the gateway
+			next:   prefix.Addr().Next().Next(),
+			ips:    map[string]netip.Addr{},
+			p2pIPs: map[string]netip.Addr{},
+		}, nil
+	}
+	return nil, fmt.Errorf("couldn't create docker network %q: %s", npc.containerNamePrefix, strings.Join(errs, "; "))
+}
+
+// Returns [args] with the public IP of the node set to its container IP
+// [ip], and the bootstrap IPs set to the container IPs of the beacons
+func (b *bridge) containerArgs(ip netip.Addr, args []string) []string {
+	publicIPPrefix := "--" + config.PublicIPKey + "="
+	bootstrapIPsPrefix := "--" + config.BootstrapIPsKey + "="
+	containerArgs := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, publicIPPrefix):
+			arg = publicIPPrefix + ip.String()
+		case strings.HasPrefix(arg, bootstrapIPsPrefix):
+			bootstrapIPs := strings.Split(strings.TrimPrefix(arg, bootstrapIPsPrefix), ",")
+			for i, bootstrapIP := range bootstrapIPs {
+				_, port, err := net.SplitHostPort(bootstrapIP)
+				if err != nil {
+					continue
+				}
+				if beaconIP, ok := b.p2pIPs[port]; ok {
+					bootstrapIPs[i] = net.JoinHostPort(beaconIP.String(), port)
+				}
+			}
+			arg = bootstrapIPsPrefix + strings.Join(bootstrapIPs, ",")
+		}
+		containerArgs = append(containerArgs, arg)
+	}
+	return containerArgs
+}
+
+// Returns the value of flag [key] in node [args], or "" if not given
+func flagValue(args []string, key string) string {
+	prefix := "--" + key + "="
+	for _, arg := range args {
+		if value := strings.TrimPrefix(arg, prefix); value != arg {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,310 @@
+package docker
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/local"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ComposeFileName is the name of the compose file written by ExportCompose
+	ComposeFileName = "docker-compose.yml"
+
+	composeNetworkName   = "anr"
+	composeSubnet        = "172.28.0.0/16"
+	composeDataDir       = "/data"
+	composeAPIPort       = 9650
+	composeP2PPort       = 9651
+	defaultNodeNameStart = "node"
+)
+
+var invalidServiceNameChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+type composeFile struct {
+	Name     string                    `yaml:"name,omitempty"`
+	Services map[string]composeService `yaml:"services"`
+	Networks map[string]composeNetwork `yaml:"networks"`
+}
+
+type composeService struct {
+	Image       string                           `yaml:"image"`
+	Command     []string                         `yaml:"command"`
+	Environment map[string]string                `yaml:"environment,omitempty"`
+	Volumes     []string                         `yaml:"volumes"`
+	Ports       []string                         `yaml:"ports"`
+	Networks    map[string]composeServiceNetwork `yaml:"networks"`
+}
+
+type composeServiceNetwork struct {
+	IPv4Address string `yaml:"ipv4_address"`
+}
+
+type composeNetwork struct {
+	IPAM composeIPAM `yaml:"ipam"`
+}
+
+type composeIPAM struct {
+	Config []composeIPAMConfig `yaml:"config"`
+}
+
+type composeIPAMConfig struct {
+	Subnet string `yaml:"subnet"`
+}
+
+// composeNode is a node of the exported network
+type composeNode struct {
+	config      node.Config
+	serviceName string
+	nodeID      string
+	ip          string
+	apiPort     string
+	p2pPort     string
+}
+
+// ExportCompose writes into [dir] a compose file (ComposeFileName) that
+// runs the network of [networkConfig] with the node image of [dockerConfig],
+// and the files each node needs on startup (staking keys, genesis, configs),
+// at a subdir named as the node service.
+// The nodes get static IPs on a compose network, and the API and P2P ports
+// given in their flags (or 9650+2*i and 9651+2*i for the node i) are
+// published on the host. Staking keys missing from the node configs are
+// generated.
+func ExportCompose(networkConfig network.Config, dockerConfig Config, dir string) error {
+	if err := networkConfig.Validate(); err != nil {
+		return fmt.Errorf("config failed validation: %w", err)
+	}
+	if dockerConfig.Image == "" {
+		dockerConfig.Image = DefaultImage
+	}
+	networkID, err := utils.NetworkIDFromGenesis([]byte(networkConfig.Genesis))
+	if err != nil {
+		return err
+	}
+	if networkConfig.NetworkID != 0 {
+		networkID = networkConfig.NetworkID
+	}
+	genesis, err := utils.SetGenesisNetworkID([]byte(networkConfig.Genesis), networkID)
+	if err != nil {
+		return fmt.Errorf("couldn't set network ID to genesis: %w", err)
+	}
+
+	nodes := make([]*composeNode, len(networkConfig.NodeConfigs))
+	serviceNames := set.Set[string]{}
+	for i, nodeConfig := range networkConfig.NodeConfigs {
+		n, err := newComposeNode(networkConfig, nodeConfig, i)
+		if err != nil {
+			return err
+		}
+		if serviceNames.Contains(n.serviceName) {
+			return fmt.Errorf("repeated node service name %q", n.serviceName)
+		}
+		serviceNames.Add(n.serviceName)
+		nodes[i] = n
+	}
+
+	compose := composeFile{
+		Name:     invalidServiceNameChars.ReplaceAllString(strings.ToLower(networkConfig.Name), "-"),
+		Services: map[string]composeService{},
+		Networks: map[string]composeNetwork{
+			composeNetworkName: {IPAM: composeIPAM{Config: []composeIPAMConfig{{Subnet: composeSubnet}}}},
+		},
+	}
+	for _, n := range nodes {
+		service, err := composeNodeService(networkID, genesis, dockerConfig, dir, n, nodes)
+		if err != nil {
+			return fmt.Errorf("couldn't export node %s: %w", n.config.Name, err)
+		}
+		compose.Services[n.serviceName] = service
+	}
+	composeBytes, err := yaml.Marshal(compose)
+	if err != nil {
+		return err
+	}
+	composePath := filepath.Join(dir, ComposeFileName)
+	if err := os.WriteFile(composePath, composeBytes, 0o600); err != nil {
+		return fmt.Errorf("couldn't write compose file: %w", err)
+	}
+	return nil
+}
+
+// Returns the node [i] of the network, with the network defaults,
+// the resource preset and generated staking keys applied to its config
+func newComposeNode(networkConfig network.Config, nodeConfig node.Config, i int) (*composeNode, error) {
+	if nodeConfig.Name == "" {
+		nodeConfig.Name = defaultNodeNameStart + strconv.Itoa(i+1)
+	}
+	if nodeConfig.BinaryPath == "" {
+		nodeConfig.BinaryPath = networkConfig.BinaryPath
+	}
+	nodeConfig.Flags = copyMap(nodeConfig.Flags)
+	nodeConfig.ChainConfigFiles = withDefaults(nodeConfig.ChainConfigFiles, networkConfig.ChainConfigFiles)
+	nodeConfig.UpgradeConfigFiles = withDefaults(nodeConfig.UpgradeConfigFiles, networkConfig.UpgradeConfigFiles)
+	nodeConfig.SubnetConfigFiles = withDefaults(nodeConfig.SubnetConfigFiles, networkConfig.SubnetConfigFiles)
+	// the node preset takes precedence over the network flags
+	if err := nodeConfig.ApplyResourcePreset(); err != nil {
+		return nil, err
+	}
+	for flagName, flagVal := range networkConfig.Flags {
+		if _, ok := nodeConfig.Flags[flagName]; !ok {
+			nodeConfig.Flags[flagName] = flagVal
+		}
+	}
+	if _, ok := nodeConfig.Flags[config.FdLimitKey]; !ok && networkConfig.NodeOpenFilesLimit > 0 {
+		nodeConfig.Flags[config.FdLimitKey] = networkConfig.NodeOpenFilesLimit
+	}
+	if nodeConfig.StakingCert == "" || nodeConfig.StakingKey == "" {
+		stakingCert, stakingKey, err := staking.NewCertAndKeyBytes()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate staking Cert/Key: %w", err)
+		}
+		nodeConfig.StakingCert = string(stakingCert)
+		nodeConfig.StakingKey = string(stakingKey)
+	}
+	if nodeConfig.StakingSigningKey == "" {
+		key, err := bls.NewSecretKey()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate new signing key: %w", err)
+		}
+		nodeConfig.StakingSigningKey = base64.StdEncoding.EncodeToString(bls.SecretKeyToBytes(key))
+	}
+	nodeID, err := utils.ToNodeID([]byte(nodeConfig.StakingKey), []byte(nodeConfig.StakingCert))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get node ID of node %s: %w", nodeConfig.Name, err)
+	}
+	// skips the gateway address
+	hostNum := i + 2
+	return &composeNode{
+		config:      nodeConfig,
+		serviceName: invalidServiceNameChars.ReplaceAllString(strings.ToLower(nodeConfig.Name), "_"),
+		nodeID:      nodeID.String(),
+		ip:          fmt.Sprintf("172.28.%d.%d", hostNum/256, hostNum%256),
+		apiPort:     flagOrDefault(nodeConfig.Flags, config.HTTPPortKey, composeAPIPort+2*i),
+		p2pPort:     flagOrDefault(nodeConfig.Flags, config.StakingPortKey, composeP2PPort+2*i),
+	}, nil
+}
+
+// Writes the files of node [n] and returns its compose service
+func composeNodeService(
+	networkID uint32,
+	genesis []byte,
+	dockerConfig Config,
+	dir string,
+	n *composeNode,
+	nodes []*composeNode,
+) (composeService, error) {
+	nodeDir := filepath.Join(dir, n.serviceName)
+	fileFlags, err := local.WriteNodeFiles(networkID, genesis, nodeDir, &n.config)
+	if err != nil {
+		return composeService{}, err
+	}
+	bootstrapIPs, bootstrapIDs := []string{}, []string{}
+	for _, other := range nodes {
+		if other.config.IsBeacon && other != n {
+			bootstrapIPs = append(bootstrapIPs, fmt.Sprintf("%s:%d", other.ip, composeP2PPort))
+			bootstrapIDs = append(bootstrapIDs, other.nodeID)
+		}
+	}
+	flags := map[string]string{
+		config.NetworkNameKey:  strconv.FormatUint(uint64(networkID), 10),
+		config.DataDirKey:      composeDataDir,
+		config.DBPathKey:       path.Join(composeDataDir, "db"),
+		config.LogsDirKey:      path.Join(composeDataDir, "logs"),
+		config.HTTPHostKey:     "",
+		config.BootstrapIPsKey: strings.Join(bootstrapIPs, ","),
+		config.BootstrapIDsKey: strings.Join(bootstrapIDs, ","),
+	}
+	// the node dir is mounted at the container data dir
+	for flagName, hostPath := range fileFlags {
+		relPath, err := filepath.Rel(nodeDir, hostPath)
+		if err != nil {
+			return composeService{}, err
+		}
+		flags[flagName] = path.Join(composeDataDir, filepath.ToSlash(relPath))
+	}
+	for flagName, flagVal := range n.config.Flags {
+		flags[flagName] = fmt.Sprintf("%v", flagVal)
+	}
+	// nodes are reached at their compose network IP, and the published
+	// ports are mapped into the default ones
+	flags[config.PublicIPKey] = n.ip
+	flags[config.HTTPPortKey] = strconv.Itoa(composeAPIPort)
+	flags[config.StakingPortKey] = strconv.Itoa(composeP2PPort)
+
+	flagNames := make([]string, 0, len(flags))
+	for flagName := range flags {
+		flagNames = append(flagNames, flagName)
+	}
+	sort.Strings(flagNames)
+	command := []string{n.config.BinaryPath}
+	for _, flagName := range flagNames {
+		command = append(command, fmt.Sprintf("--%s=%s", flagName, flags[flagName]))
+	}
+
+	environment := map[string]string{constants.NodeNameEnvVar: n.config.Name}
+	for key, value := range n.config.Env {
+		environment[key] = value
+	}
+	volumes := []string{"./" + n.serviceName + ":" + composeDataDir}
+	if pluginDir, ok := flags[config.PluginDirKey]; ok && pluginDir != "" {
+		volumes = append(volumes, pluginDir+":"+pluginDir)
+	}
+	volumes = append(volumes, dockerConfig.Mounts...)
+	return composeService{
+		Image:       dockerConfig.Image,
+		Command:     command,
+		Environment: environment,
+		Volumes:     volumes,
+		Ports: []string{
+			fmt.Sprintf("%s:%d", n.apiPort, composeAPIPort),
+			fmt.Sprintf("%s:%d", n.p2pPort, composeP2PPort),
+		},
+		Networks: map[string]composeServiceNetwork{
+			composeNetworkName: {IPv4Address: n.ip},
+		},
+	}, nil
+}
+
+// Returns the value of [flagName] in [flags] as a string, or [defaultVal]
+func flagOrDefault(flags map[string]interface{}, flagName string, defaultVal int) string {
+	if val, ok := flags[flagName]; ok {
+		return fmt.Sprintf("%v", val)
+	}
+	return strconv.Itoa(defaultVal)
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// Returns a copy of [files] with the entries of [defaults] it lacks
+func withDefaults(files map[string]string, defaults map[string]string) map[string]string {
+	merged := make(map[string]string, len(files)+len(defaults))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range files {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/local"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportCompose(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	networkConfig := local.NewDefaultConfig(BinaryPath)
+	networkConfig.Name = "My Devnet"
+	networkConfig.Flags["log-level"] = "debug"
+	networkConfig.NodeConfigs[1].Flags = map[string]interface{}{"http-port": 9700.0}
+	require.NoError(ExportCompose(networkConfig, Config{Image: "avaplatform/avalanchego:v1.10.15"}, dir))
+
+	composeBytes, err := os.ReadFile(filepath.Join(dir, ComposeFileName))
+	require.NoError(err)
+	compose := composeFile{}
+	require.NoError(yaml.Unmarshal(composeBytes, &compose))
+	require.Equal("my-devnet", compose.Name)
+	require.Len(compose.Services, len(networkConfig.NodeConfigs))
+
+	node1 := compose.Services["node1"]
+	require.Equal("avaplatform/avalanchego:v1.10.15", node1.Image)
+	require.Equal(BinaryPath, node1.Command[0])
+	command := strings.Join(node1.Command, " ")
+	require.Contains(command, "--log-level=debug")
+	require.Contains(command, "--http-port=9650")
+	require.Contains(command, "--public-ip=172.28.0.2")
+	require.Contains(command, "--staking-tls-cert-file=/data/staking.crt")
+	// the other beacons, but not the node itself
+	require.Contains(command, "172.28.0.3:9651")
+	require.NotContains(command, "172.28.0.2:9651")
+	require.Equal([]string{"./node1:/data"}, node1.Volumes)
+	require.Equal([]string{"9650:9650", "9651:9651"}, node1.Ports)
+	require.Equal("172.28.0.2", node1.Networks[composeNetworkName].IPv4Address)
+	require.FileExists(filepath.Join(dir, "node1", "staking.crt"))
+
+	node2 := compose.Services["node2"]
+	require.Equal([]string{"9700:9650", "9653:9651"}, node2.Ports)
+	require.Contains(strings.Join(node2.Command, " "), "--http-port=9650")
+}
@@ -0,0 +1,128 @@
+// Package docker implements network.Network running each node in a
+// Docker container, so networks can be run on hosts without avalanchego
+// binaries, with isolated node filesystems.
+package docker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-network-runner/local"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+const (
+	// DefaultImage is the node image used if Config.Image is empty
+	DefaultImage = "avaplatform/avalanchego:latest"
+	// BinaryPath is the path of avalanchego in DefaultImage, to be used
+	// as network.Config.BinaryPath
+	BinaryPath = "/avalanchego/build/avalanchego"
+
+	defaultDockerPath   = "docker"
+	containerNamePrefix = "anr"
+)
+
+var errUnsupportedNodeConfig = errors.New("not supported by docker networks")
+
+// Config defines how the node containers are run
+type Config struct {
+	// Image of the node containers. Defaults to DefaultImage.
+	// The binary paths of the network and node configs are paths inside
+	// this image.
+	Image string
+	// Path of the docker CLI. Defaults to "docker" in PATH.
+	DockerPath string
+	// Additional bind mounts of the node containers, in docker
+	// "host-path:container-path[:options]" format
+	Mounts []string
+	// If true, the containers use the host network, as local nodes do, so
+	// their ports are not isolated: every port a node opens is opened on
+	// the host. Otherwise the containers are connected to a bridge network
+	// of their own, and only the API and P2P ports of each node are
+	// published on the host.
+	HostNetwork bool
+	// Subnet of the bridge network, in CIDR notation. If empty, a free /24
+	// of 10.213.0.0/16 is used. Unused with HostNetwork.
+	Subnet string
+}
+
+// NewNetwork returns a new network whose nodes run in Docker containers.
+// Each container is given a bridge network IP, and its node API and P2P
+// ports are published on the host, so node ports are the same as the ones
+// of a local network. The node dirs are bind mounted at the same paths,
+// so node files are kept at [rootDir] as for local networks.
+// See local.NewNetwork for [rootDir], [snapshotsDir] and
+// [reassignPortsIfUsed]. Output of the containers is redirected as with
+// local networks, according to the node configs.
+// Node configs can't set a binary version, a binary sha256 or a process
+// priority, as these refer to the host.
+func NewNetwork(
+	ctx context.Context,
+	log logging.Logger,
+	networkConfig network.Config,
+	dockerConfig Config,
+	rootDir string,
+	snapshotsDir string,
+	reassignPortsIfUsed bool,
+) (network.Network, error) {
+	npc, err := newNodeProcessCreator(log, dockerConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, nodeConfig := range networkConfig.NodeConfigs {
+		if err := npc.ValidateNodeConfig(nodeConfig); err != nil {
+			return nil, err
+		}
+	}
+	return local.NewNetworkWithProcessCreator(
+		ctx,
+		log,
+		networkConfig,
+		rootDir,
+		snapshotsDir,
+		reassignPortsIfUsed,
+		npc,
+	)
+}
+
+// ValidateNodeConfig returns an error if [nodeConfig] sets a field that
+// refers to the host: the node binary is the one of the image, so it can't
+// be downloaded or hashed on the host, and the node process is the one of
+// the container, whose priority isn't set.
+func (*nodeProcessCreator) ValidateNodeConfig(nodeConfig node.Config) error {
+	switch {
+	case nodeConfig.BinaryVersion != "":
+		return fmt.Errorf("node %q binary version: %w", nodeConfig.Name, errUnsupportedNodeConfig)
+	case nodeConfig.BinarySHA256 != "":
+		return fmt.Errorf("node %q binary sha256: %w", nodeConfig.Name, errUnsupportedNodeConfig)
+	case nodeConfig.Priority != nil:
+		return fmt.Errorf("node %q priority: %w", nodeConfig.Name, errUnsupportedNodeConfig)
+	}
+	return nil
+}
+
+func newNodeProcessCreator(log logging.Logger, dockerConfig Config) (*nodeProcessCreator, error) {
+	if dockerConfig.Image == "" {
+		dockerConfig.Image = DefaultImage
+	}
+	if dockerConfig.DockerPath == "" {
+		dockerConfig.DockerPath = defaultDockerPath
+	}
+	// containers of different networks are told apart by a random id
+	networkID := make([]byte, 4)
+	if _, err := rand.Read(networkID); err != nil {
+		return nil, fmt.Errorf("couldn't generate network id: %w", err)
+	}
+	return &nodeProcessCreator{
+		log:                 log,
+		config:              dockerConfig,
+		containerNamePrefix: fmt.Sprintf("%s-%s", containerNamePrefix, hex.EncodeToString(networkID)),
+		colorPicker:         utils.NewColorPicker(),
+	}, nil
+}
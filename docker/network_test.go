@@ -0,0 +1,40 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNetworkUnsupportedNodeConfig(t *testing.T) {
+	tests := map[string]node.Config{
+		"binary version": {Name: "node1", BinaryVersion: "v1.10.15"},
+		"binary sha256":  {Name: "node1", BinarySHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		"priority":       {Name: "node1", Priority: &node.ProcessPriority{Nice: 10}},
+	}
+	for name, nodeConfig := range tests {
+		nodeConfig := nodeConfig
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			// rejected before docker is run
+			_, err := NewNetwork(
+				context.Background(),
+				logging.NoLog{},
+				network.Config{BinaryPath: BinaryPath, NodeConfigs: []node.Config{nodeConfig}},
+				Config{DockerPath: "/nonexistent/docker"},
+				t.TempDir(),
+				"",
+				false,
+			)
+			require.ErrorIs(err, errUnsupportedNodeConfig)
+		})
+	}
+
+	npc, err := newNodeProcessCreator(logging.NoLog{}, Config{})
+	require.NoError(t, err)
+	require.NoError(t, npc.ValidateNodeConfig(node.Config{Name: "node1", BinaryPath: BinaryPath}))
+}
@@ -0,0 +1,306 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/local"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+)
+
+var (
+	_ local.NodeProcessCreator = (*nodeProcessCreator)(nil)
+	_ local.NodeProcess        = (*nodeProcess)(nil)
+
+	// flags of the node dirs that are bind mounted into the container
+	mountedDirFlags = []string{
+		config.DataDirKey,
+		config.DBPathKey,
+		config.LogsDirKey,
+		config.PluginDirKey,
+	}
+
+	invalidContainerNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+)
+
+// nodeProcessCreator runs the nodes in containers
+type nodeProcessCreator struct {
+	log    logging.Logger
+	config Config
+	// prefix of the names of the node containers
+	containerNamePrefix string
+	// determines the color of the redirected container output
+	colorPicker utils.ColorPicker
+	bridgeLock  sync.Mutex
+	// network of the containers. Nil with the host network, or if no
+	// container is running.
+	bridge *bridge
+}
+
+// NewNodeProcess runs a container of the node image, with the
+// node binary and [args]
+func (npc *nodeProcessCreator) NewNodeProcess(nodeConfig node.Config, args ...string) (local.NodeProcess, error) {
+	containerName := npc.containerName(nodeConfig.Name)
+	netArgs, args, err := npc.containerNetwork(nodeConfig.Name, args)
+	if err != nil {
+		return nil, err
+	}
+	onExit := func() {}
+	if !npc.config.HostNetwork {
+		onExit = npc.releaseContainerNetwork
+	}
+	cmd := exec.Command(npc.config.DockerPath, npc.runArgs(containerName, nodeConfig, netArgs, args)...) //nolint
+	color := npc.colorPicker.NextColor()
+	if nodeConfig.RedirectStdout {
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			onExit()
+			return nil, fmt.Errorf("couldn't create stdout pipe: %w", err)
+		}
+		utils.ColorAndPrepend(stdout, os.Stdout, nodeConfig.Name, color)
+	}
+	if nodeConfig.RedirectStderr {
+		stderr, err := cmd.StderrPipe()
+		if err != nil {
+			onExit()
+			return nil, fmt.Errorf("couldn't create stderr pipe: %w", err)
+		}
+		utils.ColorAndPrepend(stderr, os.Stderr, nodeConfig.Name, color)
+	}
+	p := &nodeProcess{
+		name:          nodeConfig.Name,
+		containerName: containerName,
+		dockerPath:    npc.config.DockerPath,
+		log:           npc.log,
+		cmd:           cmd,
+		onExit:        onExit,
+		closedOnStop:  make(chan struct{}),
+	}
+	return p, p.start()
+}
+
+// GetNodeVersion gets the version of the node binary of the image
+// as per --version flag
+func (npc *nodeProcessCreator) GetNodeVersion(nodeConfig node.Config) (string, error) {
+	args := []string{"run", "--rm", npc.config.Image, nodeConfig.BinaryPath, "--" + config.VersionKey}
+	out, err := exec.Command(npc.config.DockerPath, args...).Output() //nolint
+	if err != nil {
+		return "", fmt.Errorf("couldn't get node version from image %q: %w", npc.config.Image, err)
+	}
+	return string(out), nil
+}
+
+// GetNodeFlagsUsage gets the flags usage of the node binary of the image
+// as per --help flag
+func (npc *nodeProcessCreator) GetNodeFlagsUsage(nodeConfig node.Config) (string, error) {
+	args := []string{"run", "--rm", npc.config.Image, nodeConfig.BinaryPath, "--help"}
+	// the exit code for help may be non zero, so just check the output
+	out, err := exec.Command(npc.config.DockerPath, args...).CombinedOutput() //nolint
+	if len(out) == 0 && err != nil {
+		return "", fmt.Errorf("couldn't get node flags usage from image %q: %w", npc.config.Image, err)
+	}
+	return string(out), nil
+}
+
+// Returns the name of the container of node [nodeName]
+func (npc *nodeProcessCreator) containerName(nodeName string) string {
+	return npc.containerNamePrefix + "-" + invalidContainerNameChars.ReplaceAllString(nodeName, "_")
+}
+
+// Returns the docker CLI args to run the container of a node, connected
+// to the network by [netArgs]
+func (npc *nodeProcessCreator) runArgs(containerName string, nodeConfig node.Config, netArgs []string, args []string) []string {
+	runArgs := []string{
+		"run",
+		"--rm",
+		"--name", containerName,
+	}
+	runArgs = append(runArgs, netArgs...)
+	runArgs = append(runArgs, "--env", constants.NodeNameEnvVar+"="+nodeConfig.Name)
+	envKeys := make([]string, 0, len(nodeConfig.Env))
+	for key := range nodeConfig.Env {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		runArgs = append(runArgs, "--env", key+"="+nodeConfig.Env[key])
+	}
+	// so the files written into the mounted dirs are owned by the runner user
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		runArgs = append(runArgs, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+	for _, dir := range mountedDirs(args) {
+		runArgs = append(runArgs, "--volume", dir+":"+dir)
+	}
+	if sharedDir := nodeConfig.Env[constants.SharedDirEnvVar]; sharedDir != "" {
+		runArgs = append(runArgs, "--volume", sharedDir+":"+sharedDir)
+	}
+	for _, mount := range npc.config.Mounts {
+		runArgs = append(runArgs, "--volume", mount)
+	}
+	runArgs = append(runArgs, npc.config.Image, nodeConfig.BinaryPath)
+	return append(runArgs, args...)
+}
+
+// Returns the node dirs given in the node [args] that should be mounted
+// into the container, skipping the ones inside other mounted dirs
+func mountedDirs(args []string) []string {
+	dirs := []string{}
+	for _, arg := range args {
+		for _, flag := range mountedDirFlags {
+			prefix := "--" + flag + "="
+			if value := strings.TrimPrefix(arg, prefix); value != arg && value != "" {
+				dirs = append(dirs, filepath.Clean(value))
+			}
+		}
+	}
+	// parents sort before their subdirs
+	sort.Strings(dirs)
+	mounted := []string{}
+	for _, dir := range dirs {
+		nested := false
+		for _, parent := range mounted {
+			if dir == parent || strings.HasPrefix(dir, parent+string(filepath.Separator)) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			mounted = append(mounted, dir)
+		}
+	}
+	return mounted
+}
+
+// nodeProcess is a node container, attached to a docker CLI process
+type nodeProcess struct {
+	name          string
+	containerName string
+	dockerPath    string
+	log           logging.Logger
+	lock          sync.RWMutex
+	// runs the container, and exits with it
+	cmd *exec.Cmd
+	// called once the container exits
+	onExit func()
+	// Process status
+	state status.Status
+	// Closed when the container exits.
+	closedOnStop chan struct{}
+}
+
+// Start the container.
+// Must only be called once.
+func (p *nodeProcess) start() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.state = status.Running
+	if err := p.cmd.Start(); err != nil {
+		p.state = status.Stopped
+		close(p.closedOnStop)
+		p.onExit()
+		return fmt.Errorf("couldn't start container: %w", err)
+	}
+	go p.awaitExit()
+	return nil
+}
+
+// Wait for the container to exit.
+// When it does, update the state and close [p.closedOnStop]
+func (p *nodeProcess) awaitExit() {
+	if err := p.cmd.Wait(); err != nil {
+		p.log.Debug("node container returned error on wait", zap.String("node", p.name), zap.Error(err))
+	}
+
+	p.log.Debug("node container finished", zap.String("node", p.name))
+	p.onExit()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.state = status.Stopped
+	close(p.closedOnStop)
+}
+
+// Sends a SIGINT to the node in the container and returns its exit code.
+// If [ctx] is cancelled, removes the container.
+// If [ctx] is already cancelled, removes it right away, as in a crash.
+func (p *nodeProcess) Stop(ctx context.Context) int {
+	p.lock.Lock()
+
+	// The container is already stopped.
+	if p.state == status.Stopped {
+		exitCode := p.cmd.ProcessState.ExitCode()
+		p.lock.Unlock()
+		return exitCode
+	}
+
+	// There's another call to Stop executing right now.
+	// Wait for it to finish.
+	if p.state == status.Stopping {
+		p.lock.Unlock()
+		<-p.closedOnStop
+		p.lock.RLock()
+		defer p.lock.RUnlock()
+
+		return p.cmd.ProcessState.ExitCode()
+	}
+
+	p.state = status.Stopping
+	// We have to unlock here so that [p.awaitExit] can grab the lock
+	// and close [p.closedOnStop].
+	p.lock.Unlock()
+
+	// with [ctx] already cancelled the container is removed right away
+	if ctx.Err() == nil {
+		if out, err := p.docker("kill", "--signal", "SIGINT", p.containerName); err != nil {
+			p.log.Warn("sending SIGINT errored", zap.String("node", p.name), zap.String("output", out), zap.Error(err))
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		p.log.Warn("context cancelled while waiting for node to stop", zap.String("node", p.name))
+		if out, err := p.docker("rm", "--force", p.containerName); err != nil {
+			p.log.Warn("removing container errored", zap.String("node", p.name), zap.String("output", out), zap.Error(err))
+		}
+		// the container can't be waited for if the docker CLI hangs
+		if err := p.cmd.Process.Kill(); err != nil {
+			p.log.Warn("killing docker CLI errored", zap.String("node", p.name), zap.Error(err))
+		}
+	case <-p.closedOnStop:
+	}
+
+	<-p.closedOnStop
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.cmd.ProcessState.ExitCode()
+}
+
+func (p *nodeProcess) Status() status.Status {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.state
+}
+
+// Runs a docker CLI command, returning its output
+func (p *nodeProcess) docker(args ...string) (string, error) {
+	out, err := exec.Command(p.dockerPath, args...).CombinedOutput() //nolint
+	return strings.TrimSpace(string(out)), err
+}
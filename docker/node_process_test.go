@@ -0,0 +1,156 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunArgs(t *testing.T) {
+	require := require.New(t)
+	npc, err := newNodeProcessCreator(logging.NoLog{}, Config{Mounts: []string{"/plugins:/plugins:ro"}})
+	require.NoError(err)
+	require.Equal(DefaultImage, npc.config.Image)
+
+	nodeArgs := []string{
+		"--data-dir=/tmp/net/node1",
+		"--db-dir=/tmp/net/node1/db",
+		"--log-dir=/tmp/logs/node1/",
+		"--http-port=9650",
+	}
+	nodeConfig := node.Config{
+		Name:       "node 1",
+		BinaryPath: BinaryPath,
+		Env:        map[string]string{"B": "2", "A": "1", constants.SharedDirEnvVar: "/tmp/net/shared"},
+	}
+	containerName := npc.containerName(nodeConfig.Name)
+	require.True(strings.HasPrefix(containerName, "anr-"))
+	require.True(strings.HasSuffix(containerName, "-node_1"))
+
+	args := strings.Join(npc.runArgs(containerName, nodeConfig, []string{"--network", "host"}, nodeArgs), " ")
+	require.Contains(args, "run --rm --name "+containerName+" --network host --env")
+	require.Contains(args, "--env ANR_NODE_NAME=node 1 --env A=1 --env ANR_SHARED_DIR=/tmp/net/shared --env B=2")
+	require.Contains(args, "--volume /tmp/logs/node1:/tmp/logs/node1 --volume /tmp/net/node1:/tmp/net/node1 --volume /tmp/net/shared:/tmp/net/shared --volume /plugins:/plugins:ro")
+	require.NotContains(args, "/tmp/net/node1/db:")
+	require.True(strings.HasSuffix(args, DefaultImage+" "+BinaryPath+" "+strings.Join(nodeArgs, " ")))
+}
+
+// TestNodeProcess tests the container lifecycle with a fake docker CLI
+func TestNodeProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker CLI is a shell script")
+	}
+	require := require.New(t)
+	dir := t.TempDir()
+	pidPath := filepath.Join(dir, "pid")
+	networkLogPath := filepath.Join(dir, "network.log")
+	dockerPath := filepath.Join(dir, "docker")
+	script := `#!/bin/sh
+case "$1" in
+run)
+	case "$*" in
+	*--version*) echo "avalanche/1.10.15"; exit 0;;
+	*--help*) echo "  --http-port uint    Port of the HTTP server (default 9650)"; exit 1;;
+	esac
+	echo $$ > ` + pidPath + `
+	trap 'exit 3' INT
+	while true; do sleep 0.1; done;;
+kill)
+	kill -INT $(cat ` + pidPath + `);;
+network)
+	echo "$*" >> ` + networkLogPath + `;;
+esac
+`
+	require.NoError(os.WriteFile(dockerPath, []byte(script), 0o700)) //nolint:gosec
+
+	npc, err := newNodeProcessCreator(logging.NoLog{}, Config{DockerPath: dockerPath})
+	require.NoError(err)
+	version, err := npc.GetNodeVersion(node.Config{BinaryPath: BinaryPath})
+	require.NoError(err)
+	require.Equal("avalanche/1.10.15\n", version)
+	// the flags usage is that of the binary of the image, not of the host
+	usage, err := npc.GetNodeFlagsUsage(node.Config{BinaryPath: BinaryPath})
+	require.NoError(err)
+	require.Contains(usage, "--http-port")
+
+	_, err = npc.NewNodeProcess(node.Config{Name: "node1", BinaryPath: BinaryPath})
+	require.ErrorIs(err, errPortsNotGiven)
+	p, err := npc.NewNodeProcess(node.Config{Name: "node1", BinaryPath: BinaryPath}, "--http-port=9650", "--staking-port=9651")
+	require.NoError(err)
+	require.Equal(status.Running, p.Status())
+	require.Eventually(func() bool {
+		_, err := os.Stat(pidPath)
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.Equal(3, p.Stop(ctx))
+	require.Equal(status.Stopped, p.Status())
+	// subsequent calls have no effect
+	require.Equal(3, p.Stop(ctx))
+
+	// the bridge network is created for the container, and removed with it
+	require.Eventually(func() bool {
+		networkLog, err := os.ReadFile(networkLogPath)
+		return err == nil && strings.Count(string(networkLog), "\n") == 2
+	}, 10*time.Second, 10*time.Millisecond)
+	networkLog, err := os.ReadFile(networkLogPath)
+	require.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(networkLog)), "\n")
+	require.True(strings.HasPrefix(lines[0], "network create --driver bridge --subnet 10.213."))
+	require.True(strings.HasSuffix(lines[0], " "+npc.containerNamePrefix))
+	require.Equal("network rm "+npc.containerNamePrefix, lines[1])
+}
+
+// TestContainerNetwork tests that, on the bridge network, node containers
+// get their own IPs, only publish their ports, and bootstrap from the
+// container IPs of the beacons
+func TestContainerNetwork(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker CLI is a shell script")
+	}
+	require := require.New(t)
+	dockerPath := filepath.Join(t.TempDir(), "docker")
+	require.NoError(os.WriteFile(dockerPath, []byte("#!/bin/sh\nexit 0\n"), 0o700)) //nolint:gosec
+
+	npc, err := newNodeProcessCreator(logging.NoLog{}, Config{DockerPath: dockerPath, Subnet: "172.30.0.0/24"})
+	require.NoError(err)
+	netArgs, args, err := npc.containerNetwork("node1", []string{"--public-ip=127.0.0.1", "--http-port=9650", "--staking-port=9651", "--bootstrap-ips="})
+	require.NoError(err)
+	require.Equal([]string{"--network", npc.containerNamePrefix, "--ip", "172.30.0.2", "--publish", "127.0.0.1:9650:9650", "--publish", "127.0.0.1:9651:9651"}, netArgs)
+	require.Equal([]string{"--public-ip=172.30.0.2", "--http-port=9650", "--staking-port=9651", "--bootstrap-ips="}, args)
+
+	_, args, err = npc.containerNetwork("node2", []string{"--public-ip=127.0.0.1", "--http-port=9652", "--staking-port=9653", "--bootstrap-ips=127.0.0.1:9651,127.0.0.1:9999"})
+	require.NoError(err)
+	require.Equal([]string{"--public-ip=172.30.0.3", "--http-port=9652", "--staking-port=9653", "--bootstrap-ips=172.30.0.2:9651,127.0.0.1:9999"}, args)
+
+	// restarted nodes keep their IP
+	npc.releaseContainerNetwork()
+	_, args, err = npc.containerNetwork("node1", []string{"--public-ip=127.0.0.1", "--http-port=9650", "--staking-port=9651"})
+	require.NoError(err)
+	require.Equal("--public-ip=172.30.0.2", args[0])
+
+	// the network is removed with the last container
+	npc.releaseContainerNetwork()
+	npc.releaseContainerNetwork()
+	require.Nil(npc.bridge)
+
+	// host network
+	npc, err = newNodeProcessCreator(logging.NoLog{}, Config{DockerPath: dockerPath, HostNetwork: true})
+	require.NoError(err)
+	netArgs, args, err = npc.containerNetwork("node1", []string{"--public-ip=127.0.0.1"})
+	require.NoError(err)
+	require.Equal([]string{"--network", "host"}, netArgs)
+	require.Equal([]string{"--public-ip=127.0.0.1"}, args)
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"go/build"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/local"
@@ -21,26 +19,6 @@ const (
 
 var goPath = os.ExpandEnv("$GOPATH")
 
-// Blocks until a signal is received on [signalChan], upon which
-// [n.Stop()] is called. If [signalChan] is closed, does nothing.
-// Closes [closedOnShutdownChan] amd [signalChan] when done shutting down network.
-// This function should only be called once.
-func shutdownOnSignal(
-	log logging.Logger,
-	n network.Network,
-	signalChan chan os.Signal,
-	closedOnShutdownChan chan struct{},
-) {
-	sig := <-signalChan
-	log.Info("got OS signal", zap.Stringer("signal", sig))
-	if err := n.Stop(context.Background()); err != nil {
-		log.Info("error stopping network", zap.Error(err))
-	}
-	signal.Reset()
-	close(signalChan)
-	close(closedOnShutdownChan)
-}
-
 // Shows example usage of the Avalanche Network Runner.
 // Creates a local five node Avalanche network
 // and waits for all nodes to become healthy.
@@ -68,7 +46,7 @@ func main() {
 
 func run(log logging.Logger, binaryPath string) error {
 	// Create the network
-	nw, err := local.NewDefaultNetwork(log, binaryPath, true, true, true)
+	nw, err := local.NewDefaultNetwork(context.Background(), log, binaryPath, true, true, true)
 	if err != nil {
 		return err
 	}
@@ -79,13 +57,12 @@ func run(log logging.Logger, binaryPath string) error {
 	}()
 
 	// When we get a SIGINT or SIGTERM, stop the network and close [closedOnShutdownCh]
-	signalsChan := make(chan os.Signal, 1)
-	signal.Notify(signalsChan, syscall.SIGINT)
-	signal.Notify(signalsChan, syscall.SIGTERM)
-	closedOnShutdownCh := make(chan struct{})
-	go func() {
-		shutdownOnSignal(log, nw, signalsChan, closedOnShutdownCh)
-	}()
+	closedOnShutdownCh, _ := network.RegisterSignalHandlers(nw, network.WithStopCallback(func(sig os.Signal, err error) {
+		log.Info("got OS signal", zap.Stringer("signal", sig))
+		if err != nil {
+			log.Info("error stopping network", zap.Error(err))
+		}
+	}))
 
 	// Wait until the nodes in the network are ready
 	ctx, cancel := context.WithTimeout(context.Background(), healthyTimeout)
@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/local"
@@ -24,26 +22,6 @@ const (
 
 var goPath = os.ExpandEnv("$GOPATH")
 
-// Blocks until a signal is received on [signalChan], upon which
-// [n.Stop()] is called. If [signalChan] is closed, does nothing.
-// Closes [closedOnShutdownChan] amd [signalChan] when done shutting down network.
-// This function should only be called once.
-func shutdownOnSignal(
-	log logging.Logger,
-	n network.Network,
-	signalChan chan os.Signal,
-	closedOnShutdownChan chan struct{},
-) {
-	sig := <-signalChan
-	log.Info("got OS signal", zap.Stringer("signal", sig))
-	if err := n.Stop(context.Background()); err != nil {
-		log.Info("error stopping network", zap.Error(err))
-	}
-	signal.Reset()
-	close(signalChan)
-	close(closedOnShutdownChan)
-}
-
 // Shows example usage of the Avalanche Network Runner.
 // Creates a local five node Avalanche network
 // and waits for all nodes to become healthy.
@@ -73,7 +51,7 @@ func main() {
 
 func run(log logging.Logger, binaryPath string) error {
 	// Create the network
-	nw, err := local.NewDefaultNetwork(log, binaryPath, true, true, true)
+	nw, err := local.NewDefaultNetwork(context.Background(), log, binaryPath, true, true, true)
 	if err != nil {
 		return err
 	}
@@ -84,13 +62,12 @@ func run(log logging.Logger, binaryPath string) error {
 	}()
 
 	// When we get a SIGINT or SIGTERM, stop the network and close [closedOnShutdownCh]
-	signalsChan := make(chan os.Signal, 1)
-	signal.Notify(signalsChan, syscall.SIGINT)
-	signal.Notify(signalsChan, syscall.SIGTERM)
-	closedOnShutdownCh := make(chan struct{})
-	go func() {
-		shutdownOnSignal(log, nw, signalsChan, closedOnShutdownCh)
-	}()
+	closedOnShutdownCh, _ := network.RegisterSignalHandlers(nw, network.WithStopCallback(func(sig os.Signal, err error) {
+		log.Info("got OS signal", zap.Stringer("signal", sig))
+		if err != nil {
+			log.Info("error stopping network", zap.Error(err))
+		}
+	}))
 
 	// Wait until the nodes in the network are ready
 	ctx, cancel := context.WithTimeout(context.Background(), healthyTimeout)
@@ -108,7 +85,7 @@ func run(log logging.Logger, binaryPath string) error {
 	log.Info("current network's nodes", zap.Strings("nodes", nodeNames))
 
 	// Get one node
-	node1, err := nw.GetNode(nodeNames[0])
+	node1, err := nw.GetNode(context.Background(), nodeNames[0])
 	if err != nil {
 		return err
 	}
@@ -137,7 +114,7 @@ func run(log logging.Logger, binaryPath string) error {
 			config.HTTPHostKey: "0.0.0.0",
 		},
 	}
-	if _, err := nw.AddNode(nodeConfig); err != nil {
+	if _, err := nw.AddNode(context.Background(), nodeConfig); err != nil {
 		return err
 	}
 
@@ -0,0 +1,132 @@
+// Package flags gives typed names and setters for frequently used
+// avalanchego flags, to build the Flags of network and node configs
+// without misspelling flag names or giving values of the wrong type.
+package flags
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+// Name is the name of an avalanchego flag
+type Name string
+
+const (
+	NetworkID              Name = config.NetworkNameKey
+	DBType                 Name = config.DBTypeKey
+	PublicIP               Name = config.PublicIPKey
+	HTTPHost               Name = config.HTTPHostKey
+	HTTPPort               Name = config.HTTPPortKey
+	HTTPAllowedHosts       Name = config.HTTPAllowedHostsKey
+	StakingPort            Name = config.StakingPortKey
+	BootstrapIPs           Name = config.BootstrapIPsKey
+	BootstrapIDs           Name = config.BootstrapIDsKey
+	SybilProtectionEnabled Name = config.SybilProtectionEnabledKey
+	LogLevel               Name = config.LogLevelKey
+	LogDisplayLevel        Name = config.LogDisplayLevelKey
+	SnowSampleSize         Name = config.SnowSampleSizeKey
+	TrackSubnets           Name = config.TrackSubnetsKey
+	AdminAPIEnabled        Name = config.AdminAPIEnabledKey
+	IndexEnabled           Name = config.IndexEnabledKey
+	HealthCheckFrequency   Name = config.HealthCheckFreqKey
+	PluginDir              Name = config.PluginDirKey
+)
+
+// Flags are avalanchego flags (flag name --> value), as given by the Flags
+// of network.Config and node.Config, which they can be assigned to.
+// The setters return the flags, so calls can be chained. They must not be
+// called on nil Flags.
+type Flags map[string]interface{}
+
+// New returns empty flags
+func New() Flags {
+	return Flags{}
+}
+
+// Set sets flag [name] to [value], for the flags without a setter
+func (f Flags) Set(name Name, value interface{}) Flags {
+	f[string(name)] = value
+	return f
+}
+
+// Get returns the value of flag [name], if set
+func (f Flags) Get(name Name) (interface{}, bool) {
+	value, ok := f[string(name)]
+	return value, ok
+}
+
+// SetHTTPHost sets the host the node API listens on
+func (f Flags) SetHTTPHost(host string) Flags {
+	return f.Set(HTTPHost, host)
+}
+
+// SetHTTPPort sets the port of the node API
+func (f Flags) SetHTTPPort(port uint16) Flags {
+	// ports are read by the runner as ints
+	return f.Set(HTTPPort, int(port))
+}
+
+// SetStakingPort sets the P2P port of the node
+func (f Flags) SetStakingPort(port uint16) Flags {
+	return f.Set(StakingPort, int(port))
+}
+
+// SetPublicIP sets the IP the node advertises to its peers
+func (f Flags) SetPublicIP(ip string) Flags {
+	return f.Set(PublicIP, ip)
+}
+
+// SetLogLevel sets the level of the node log files
+func (f Flags) SetLogLevel(level logging.Level) Flags {
+	return f.Set(LogLevel, level.LowerString())
+}
+
+// SetLogDisplayLevel sets the level of the node logs written to stdout
+func (f Flags) SetLogDisplayLevel(level logging.Level) Flags {
+	return f.Set(LogDisplayLevel, level.LowerString())
+}
+
+// SetTrackSubnets sets the subnets the node syncs, replacing the ones
+// already set
+func (f Flags) SetTrackSubnets(subnetIDs ...ids.ID) Flags {
+	subnetIDStrs := make([]string, len(subnetIDs))
+	for i, subnetID := range subnetIDs {
+		subnetIDStrs[i] = subnetID.String()
+	}
+	return f.Set(TrackSubnets, strings.Join(subnetIDStrs, ","))
+}
+
+// SetPluginDir sets the dir the node loads its VM plugins from
+func (f Flags) SetPluginDir(dir string) Flags {
+	return f.Set(PluginDir, dir)
+}
+
+// SetDBType sets the database of the node (eg leveldb, memdb)
+func (f Flags) SetDBType(dbType string) Flags {
+	return f.Set(DBType, dbType)
+}
+
+// SetSybilProtectionEnabled enables or disables sybil protection. Disabling
+// it makes every node a validator of the primary network.
+func (f Flags) SetSybilProtectionEnabled(enabled bool) Flags {
+	return f.Set(SybilProtectionEnabled, enabled)
+}
+
+// SetAdminAPIEnabled enables or disables the admin API
+func (f Flags) SetAdminAPIEnabled(enabled bool) Flags {
+	return f.Set(AdminAPIEnabled, enabled)
+}
+
+// SetIndexEnabled enables or disables the index API
+func (f Flags) SetIndexEnabled(enabled bool) Flags {
+	return f.Set(IndexEnabled, enabled)
+}
+
+// SetHealthCheckFrequency sets how often the node runs its health checks
+func (f Flags) SetHealthCheckFrequency(frequency time.Duration) Flags {
+	return f.Set(HealthCheckFrequency, frequency.String())
+}
@@ -0,0 +1,46 @@
+package flags_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/flags"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlags(t *testing.T) {
+	require := require.New(t)
+
+	subnetID1, subnetID2 := ids.GenerateTestID(), ids.GenerateTestID()
+	nodeConfig := node.Config{
+		Flags: flags.New().
+			SetHTTPPort(9650).
+			SetStakingPort(9651).
+			SetLogLevel(logging.Debug).
+			SetTrackSubnets(subnetID1, subnetID2).
+			SetHealthCheckFrequency(2*time.Second).
+			SetIndexEnabled(true).
+			Set(flags.SnowSampleSize, 1),
+	}
+	require.Equal(map[string]interface{}{
+		config.HTTPPortKey:        9650,
+		config.StakingPortKey:     9651,
+		config.LogLevelKey:        "debug",
+		config.TrackSubnetsKey:    subnetID1.String() + "," + subnetID2.String(),
+		config.HealthCheckFreqKey: "2s",
+		config.IndexEnabledKey:    true,
+		config.SnowSampleSizeKey:  1,
+	}, nodeConfig.Flags)
+
+	// existing flags can be updated in place
+	flags.Flags(nodeConfig.Flags).SetLogLevel(logging.Info)
+	value, ok := flags.Flags(nodeConfig.Flags).Get(flags.LogLevel)
+	require.True(ok)
+	require.Equal("info", value)
+	_, ok = flags.New().Get(flags.LogLevel)
+	require.False(ok)
+}
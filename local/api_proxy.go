@@ -0,0 +1,194 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api/auth"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	apiProxyHost = "127.0.0.1"
+	// api auth tokens given by avalanchego last 12 hours
+	apiAuthTokenLifespan    = 11 * time.Hour
+	apiAuthPasswordFileName = "api-auth-password"
+	apiAuthPasswordLen      = 32
+	apiAuthEndpoint         = "/ext/auth"
+)
+
+// apiProxy is an in process reverse proxy in front of a node API.
+// The API clients of the node point to it, so requests made by them
+// can be transparently modified by [transport] (eg adding an auth token)
+type apiProxy struct {
+	listener net.Listener
+	server   *http.Server
+	port     uint16
+}
+
+// Starts a proxy for the node API at [target], that sends the requests
+// using [transport]
+func newAPIProxy(log logging.Logger, nodeName string, target *url.URL, transport http.RoundTripper) (*apiProxy, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(apiProxyHost, "0"))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't listen for api proxy: %w", err)
+	}
+	reverseProxy := httputil.NewSingleHostReverseProxy(target)
+	reverseProxy.Transport = transport
+	reverseProxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.Debug("api proxy request failed",
+			zap.String("node-name", nodeName),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	proxy := &apiProxy{
+		listener: listener,
+		server: &http.Server{
+			Handler:           reverseProxy,
+			ReadHeaderTimeout: 30 * time.Second,
+		},
+		port: uint16(listener.Addr().(*net.TCPAddr).Port),
+	}
+	go func() {
+		if err := proxy.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warn("api proxy stopped", zap.String("node-name", nodeName), zap.Error(err))
+		}
+	}()
+	return proxy, nil
+}
+
+func (p *apiProxy) close() error {
+	return p.server.Close()
+}
+
+// Creates a random api auth password for a node, and writes it
+// into [dataDir]. Returns the password and the path of the file.
+func writeAPIAuthPassword(dataDir string) (string, string, error) {
+	passwordBytes := make([]byte, apiAuthPasswordLen)
+	if _, err := rand.Read(passwordBytes); err != nil {
+		return "", "", fmt.Errorf("couldn't generate api auth password: %w", err)
+	}
+	password := hex.EncodeToString(passwordBytes)
+	passwordPath := filepath.Join(dataDir, apiAuthPasswordFileName)
+	if err := os.WriteFile(passwordPath, []byte(password), 0o600); err != nil {
+		return "", "", fmt.Errorf("couldn't write api auth password file: %w", err)
+	}
+	return password, passwordPath, nil
+}
+
+// apiAuthTransport adds an api auth token to each request, asking the node
+// for a new one when not available or expired
+type apiAuthTransport struct {
+	base     http.RoundTripper
+	authURI  string
+	password string
+
+	lock        sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func newAPIAuthTransport(base http.RoundTripper, target *url.URL, password string) *apiAuthTransport {
+	return &apiAuthTransport{
+		base:     base,
+		authURI:  target.JoinPath(apiAuthEndpoint).String(),
+		password: password,
+	}
+}
+
+func (t *apiAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == apiAuthEndpoint {
+		return t.base.RoundTrip(req)
+	}
+	token, err := t.getToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// eg the node was restarted with a new password
+		t.lock.Lock()
+		t.token = ""
+		t.lock.Unlock()
+	}
+	return resp, err
+}
+
+func (t *apiAuthTransport) getToken(ctx context.Context) (string, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.token != "" && time.Now().Before(t.tokenExpiry) {
+		return t.token, nil
+	}
+	token, err := t.newToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("couldn't get api auth token: %w", err)
+	}
+	t.token = token
+	t.tokenExpiry = time.Now().Add(apiAuthTokenLifespan)
+	return token, nil
+}
+
+// Asks the node for a token valid for all the endpoints
+func (t *apiAuthTransport) newToken(ctx context.Context) (string, error) {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "auth.newToken",
+		"params": auth.NewTokenArgs{
+			Password:  auth.Password{Password: t.password},
+			Endpoints: []string{"*"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.authURI, bytes.NewReader(reqBody))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := (&http.Client{Transport: t.base}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var reply struct {
+		Result *auth.Token `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", err
+	}
+	if reply.Error != nil {
+		return "", errors.New(reply.Error.Message)
+	}
+	if reply.Result == nil || reply.Result.Token == "" {
+		return "", errors.New("empty token")
+	}
+	return reply.Result.Token, nil
+}
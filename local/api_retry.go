@@ -0,0 +1,101 @@
+package local
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+)
+
+// health endpoints answer 503 when the node is unhealthy, which
+// is a valid answer and not a transient error
+const apiHealthEndpointPrefix = "/ext/health"
+
+// Returns [config] with its zero fields set to their defaults
+func withAPIRetryDefaults(config network.APIRetryConfig) *network.APIRetryConfig {
+	if config.Window == 0 {
+		config.Window = network.DefaultAPIRetryWindow
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = network.DefaultAPIRetryMaxRetries
+	}
+	if config.InitialBackoff == 0 {
+		config.InitialBackoff = network.DefaultAPIRetryInitialBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = network.DefaultAPIRetryMaxBackoff
+	}
+	return &config
+}
+
+// apiRetryTransport retries the requests that fail with transient errors,
+// while the node is starting
+type apiRetryTransport struct {
+	base   http.RoundTripper
+	config network.APIRetryConfig
+	// requests are not retried after this time
+	windowEnd time.Time
+}
+
+func newAPIRetryTransport(base http.RoundTripper, config network.APIRetryConfig) *apiRetryTransport {
+	return &apiRetryTransport{
+		base:      base,
+		config:    config,
+		windowEnd: time.Now().Add(config.Window),
+	}
+}
+
+func (t *apiRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if time.Now().After(t.windowEnd) {
+		return t.base.RoundTrip(req)
+	}
+	// the body is sent again on each retry
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	backoff := t.config.InitialBackoff
+	for retries := 0; ; retries++ {
+		attemptReq := req.Clone(req.Context())
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		if !isTransientAPIError(req, resp, err) || retries >= t.config.MaxRetries || time.Now().After(t.windowEnd) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > t.config.MaxBackoff {
+			backoff = t.config.MaxBackoff
+		}
+	}
+}
+
+// Returns true if the result of [req] is a connection refused error, or
+// a 5xx response not given by a health endpoint
+func isTransientAPIError(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError &&
+		!strings.HasPrefix(req.URL.Path, apiHealthEndpointPrefix)
+}
@@ -0,0 +1,152 @@
+package local
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	apiCACertFileName  = "api-ca.crt"
+	apiTLSCertFileName = "api-tls.crt"
+	apiTLSKeyFileName  = "api-tls.key"
+	apiTLSCertValidity = 10 * 365 * 24 * time.Hour
+)
+
+// apiCA is a certificate authority generated by the runner, that signs
+// the certificates used by the node APIs when TLS is enabled
+type apiCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	pool    *x509.CertPool
+}
+
+func newAPICA() (*apiCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate api CA key: %w", err)
+	}
+	serialNumber, err := newCertSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: "avalanche-network-runner API CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(apiTLSCertValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create api CA cert: %w", err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &apiCA{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}),
+		pool:    pool,
+	}, nil
+}
+
+// Returns a new PEM encoded cert and key, signed by the CA, valid
+// for localhost and for [publicIP]
+func (ca *apiCA) newNodeCert(publicIP string) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't generate api TLS key: %w", err)
+	}
+	serialNumber, err := newCertSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	ipAddresses := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	if ip := net.ParseIP(publicIP); ip != nil {
+		ipAddresses = append(ipAddresses, ip)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: "avalanche-network-runner node API"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(apiTLSCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  ipAddresses,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't create api TLS cert: %w", err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+	return certPEM, keyPEM, nil
+}
+
+// Writes a new cert and key for a node API into [dataDir].
+// Returns the paths of the cert and key files.
+func (ca *apiCA) writeNodeCert(dataDir string, publicIP string) (string, string, error) {
+	certPEM, keyPEM, err := ca.newNodeCert(publicIP)
+	if err != nil {
+		return "", "", err
+	}
+	certPath := filepath.Join(dataDir, apiTLSCertFileName)
+	if err := os.WriteFile(certPath, certPEM, 0o600); err != nil {
+		return "", "", fmt.Errorf("couldn't write api TLS cert file: %w", err)
+	}
+	keyPath := filepath.Join(dataDir, apiTLSKeyFileName)
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		return "", "", fmt.Errorf("couldn't write api TLS key file: %w", err)
+	}
+	return certPath, keyPath, nil
+}
+
+// Returns a copy of [base] that trusts the CA. A custom [base] that
+// is not an *http.Transport is returned as is, as it is responsible
+// for its own TLS config.
+func (ca *apiCA) transport(base http.RoundTripper) http.RoundTripper {
+	baseTransport, ok := base.(*http.Transport)
+	if !ok {
+		return base
+	}
+	transport := baseTransport.Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    ca.pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	return transport
+}
+
+func newCertSerialNumber() (*big.Int, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate cert serial number: %w", err)
+	}
+	return serialNumber, nil
+}
@@ -0,0 +1,153 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/ips"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"go.uber.org/zap"
+)
+
+var errNoBeacons = errors.New("network has no beacons")
+
+// See network.Network
+func (ln *localNetwork) SetBeacons(ctx context.Context, nodeNames []string) error {
+	_, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if len(nodeNames) == 0 {
+		return errNoBeacons
+	}
+	beacons := set.Of(nodeNames...)
+	for nodeName := range beacons {
+		if _, ok := ln.nodes[nodeName]; !ok {
+			return fmt.Errorf("node %q not found", nodeName)
+		}
+	}
+	bootstraps := beacon.NewSet()
+	for nodeName, node := range ln.nodes {
+		node.config.IsBeacon = beacons.Contains(nodeName)
+		if !node.config.IsBeacon {
+			continue
+		}
+		if err := bootstraps.Add(beacon.New(node.nodeID, ips.IPPort{
+			IP:   net.ParseIP(node.publicIP),
+			Port: node.p2pPort,
+		})); err != nil {
+			return err
+		}
+	}
+	ln.bootstraps = bootstraps
+	ln.log.Info("beacons set", zap.Strings("beacons", beacons.List()))
+	return nil
+}
+
+// See network.Network
+//
+// avalanchego reads its bootstrap IPs and IDs on startup only, so nodes
+// are restarted one at a time, waiting for the network to be healthy
+// again before going on with the next node. Paused nodes get the current
+// beacons once resumed.
+func (ln *localNetwork) RefreshBootstraps(ctx context.Context) ([]string, error) {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return nil, network.ErrStopped
+	}
+	if ln.bootstraps.Len() == 0 {
+		ln.lock.RUnlock()
+		return nil, errNoBeacons
+	}
+	nodeNames := make([]string, 0, len(ln.nodes))
+	for nodeName := range ln.nodes {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	ln.lock.RUnlock()
+	sort.Strings(nodeNames)
+
+	restarted := []string{}
+	for _, nodeName := range nodeNames {
+		refreshed, err := ln.refreshNodeBootstraps(ctx, nodeName)
+		if err != nil {
+			return restarted, fmt.Errorf("couldn't refresh bootstraps of node %q: %w", nodeName, err)
+		}
+		if refreshed {
+			restarted = append(restarted, nodeName)
+		}
+	}
+	return restarted, nil
+}
+
+// Restarts [nodeName] if its bootstrap IPs or IDs are not the current
+// beacons, and waits for the network to be healthy.
+// Returns whether the node was restarted.
+func (ln *localNetwork) refreshNodeBootstraps(ctx context.Context, nodeName string) (bool, error) {
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return false, network.ErrStopped
+	}
+	node, ok := ln.nodes[nodeName]
+	if !ok || node.paused || !ln.hasStaleBootstraps(node) {
+		// removed in the meantime, or started with the current beacons
+		return false, nil
+	}
+	node.log.Info("restarting node to refresh its bootstraps", zap.String("node-name", nodeName))
+	if err := ln.restartNode(ctx, nodeName, "", "", "", nil, nil, nil); err != nil {
+		return false, err
+	}
+	return true, ln.healthy(ctx)
+}
+
+// Returns true if [node] was started with bootstrap IPs or IDs other than
+// the current beacons (but itself). Nodes given their bootstrap flags in
+// their config are left as they are.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) hasStaleBootstraps(node *localNode) bool {
+	_, ipsGiven := node.config.Flags[config.BootstrapIPsKey]
+	_, idsGiven := node.config.Flags[config.BootstrapIDsKey]
+	if ipsGiven || idsGiven {
+		return false
+	}
+	wantIPs := splitArg(ln.bootstraps.IPsArg())
+	wantIPs.Remove(ips.IPPort{IP: net.ParseIP(node.publicIP), Port: node.p2pPort}.String())
+	wantIDs := splitArg(ln.bootstraps.IDsArg())
+	wantIDs.Remove(node.nodeID.String())
+	return !splitArg(node.flags[config.BootstrapIPsKey]).Equals(wantIPs) ||
+		!splitArg(node.flags[config.BootstrapIDsKey]).Equals(wantIDs)
+}
+
+// Returns the values of comma separated flag value [arg]
+func splitArg(arg string) set.Set[string] {
+	values := set.Set[string]{}
+	for _, value := range strings.Split(arg, ",") {
+		if value != "" {
+			values.Add(value)
+		}
+	}
+	return values
+}
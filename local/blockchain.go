@@ -91,7 +91,7 @@ func (ln *localNetwork) getNode() node.Node {
 // get node client URI for an arbitrary node in the network
 func (ln *localNetwork) getClientURI() (string, error) { //nolint
 	node := ln.getNode()
-	clientURI := fmt.Sprintf("http://%s:%d", node.GetURL(), node.GetAPIPort())
+	clientURI := node.GetURI()
 	ln.log.Info("getClientURI",
 		zap.String("nodeName", node.GetName()),
 		zap.String("uri", clientURI))
@@ -146,7 +146,7 @@ func (ln *localNetwork) RegisterBlockchainAliases(
 			if node.paused {
 				continue
 			}
-			if err := node.client.AdminAPI().AliasChain(ctx, chainID, blockchainAlias); err != nil {
+			if err := node.GetAPIClient().AdminAPI().AliasChain(ctx, chainID, blockchainAlias); err != nil {
 				return fmt.Errorf("failure to register blockchain alias %v on node %v: %w", blockchainAlias, nodeName, err)
 			}
 		}
@@ -302,7 +302,7 @@ func (ln *localNetwork) installCustomChains(
 			_, ok := ln.nodes[nodeName]
 			if !ok {
 				ln.log.Info(logging.Green.Wrap(fmt.Sprintf("adding new participant %s", nodeName)))
-				if _, err := ln.addNode(node.Config{
+				if _, err := ln.addNode(ctx, node.Config{
 					Name:           nodeName,
 					RedirectStdout: ln.redirectStdout,
 					RedirectStderr: ln.redirectStderr,
@@ -457,7 +457,7 @@ func (ln *localNetwork) addSubnetValidators(
 			_, ok := ln.nodes[nodeName]
 			if !ok {
 				ln.log.Info(logging.Green.Wrap(fmt.Sprintf("adding new participant %s", nodeName)))
-				if _, err := ln.addNode(node.Config{
+				if _, err := ln.addNode(ctx, node.Config{
 					Name:           nodeName,
 					RedirectStdout: ln.redirectStdout,
 					RedirectStderr: ln.redirectStderr,
@@ -533,7 +533,7 @@ func (ln *localNetwork) installSubnets(
 			_, ok := ln.nodes[nodeName]
 			if !ok {
 				ln.log.Info(logging.Green.Wrap(fmt.Sprintf("adding new participant %s", nodeName)))
-				if _, err := ln.addNode(node.Config{
+				if _, err := ln.addNode(ctx, node.Config{
 					Name:           nodeName,
 					RedirectStdout: ln.redirectStdout,
 					RedirectStderr: ln.redirectStderr,
@@ -1190,7 +1190,7 @@ func (ln *localNetwork) addPermissionlessValidators(
 		_, ok := ln.nodes[validatorSpec.NodeName]
 		if !ok {
 			ln.log.Info(logging.Green.Wrap(fmt.Sprintf("adding new participant %s", validatorSpec.NodeName)))
-			if _, err := ln.addNode(node.Config{
+			if _, err := ln.addNode(ctx, node.Config{
 				Name:           validatorSpec.NodeName,
 				RedirectStdout: ln.redirectStdout,
 				RedirectStderr: ln.redirectStderr,
@@ -1568,8 +1568,7 @@ func (ln *localNetwork) reloadVMPlugins(ctx context.Context) error {
 		if node.paused {
 			continue
 		}
-		uri := fmt.Sprintf("http://%s:%d", node.GetURL(), node.GetAPIPort())
-		adminCli := admin.NewClient(uri)
+		adminCli := admin.NewClient(node.GetURI())
 		cctx, cancel := createDefaultCtx(ctx)
 		_, failedVMs, err := adminCli.LoadVMs(cctx)
 		cancel()
@@ -0,0 +1,128 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+const (
+	chainBootstrapCheckFreq = 2 * time.Second
+	// max time for the bootstrap checks of a tick
+	chainBootstrapCheckTimeout = 10 * time.Second
+)
+
+// primary network chains, checked on every node
+var primaryNetworkChains = []string{"P", "X", "C"}
+
+// chainToCheck is a chain whose bootstrap is reported
+type chainToCheck struct {
+	// alias for primary network chains, ID for subnet chains
+	chain string
+	// human readable description
+	desc string
+}
+
+// Every [chainBootstrapCheckFreq], checks which chains are bootstrapped on
+// each running node, and publishes an EventChainBootstrapped event for the
+// chains that became bootstrapped since the previous check.
+// Runs until the network is stopped.
+func (ln *localNetwork) watchChainBootstraps() {
+	ticker := time.NewTicker(chainBootstrapCheckFreq)
+	defer ticker.Stop()
+
+	// node --> chains bootstrapped on it. A restarted node is a new
+	// one, so its chains are reported again.
+	bootstrapped := map[*localNode]set.Set[string]{}
+	for {
+		select {
+		case <-ln.onStopCh:
+			return
+		case <-ticker.C:
+		}
+		ln.checkChainBootstraps(bootstrapped)
+	}
+}
+
+// Publishes an EventChainBootstrapped event for each chain bootstrapped on a
+// running node, and not yet in [bootstrapped]. Adds it to [bootstrapped].
+func (ln *localNetwork) checkChainBootstraps(bootstrapped map[*localNode]set.Set[string]) {
+	ln.lock.RLock()
+	nodes := map[*localNode]struct{}{}
+	for _, node := range ln.nodes {
+		if !node.paused && node.Status() == status.Running {
+			nodes[node] = struct{}{}
+		}
+	}
+	ln.lock.RUnlock()
+	for node := range bootstrapped {
+		if _, ok := nodes[node]; !ok {
+			delete(bootstrapped, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chainBootstrapCheckTimeout)
+	defer cancel()
+	ctx, cancel = ln.withStopCancel(ctx)
+	defer cancel()
+
+	chains := ln.chainsToCheck(ctx, nodes)
+	for node := range nodes {
+		nodeBootstrapped, ok := bootstrapped[node]
+		if !ok {
+			nodeBootstrapped = set.Set[string]{}
+			bootstrapped[node] = nodeBootstrapped
+		}
+		for _, chain := range chains {
+			if nodeBootstrapped.Contains(chain.chain) {
+				continue
+			}
+			// nodes not tracking a subnet give an error for its chains
+			isBootstrapped, err := node.GetAPIClient().InfoAPI().IsBootstrapped(ctx, chain.chain)
+			if err != nil || !isBootstrapped {
+				continue
+			}
+			nodeBootstrapped.Add(chain.chain)
+			ln.publishEvent(network.Event{
+				Type:     network.EventChainBootstrapped,
+				NodeName: node.name,
+				Chain:    chain.chain,
+				Message:  fmt.Sprintf("%s bootstrapped", chain.desc),
+			})
+		}
+	}
+}
+
+// Returns the primary network chains, and the subnet chains known by
+// some of [nodes]
+func (ln *localNetwork) chainsToCheck(ctx context.Context, nodes map[*localNode]struct{}) []chainToCheck {
+	chains := []chainToCheck{}
+	for _, chain := range primaryNetworkChains {
+		chains = append(chains, chainToCheck{chain: chain, desc: chain + "-chain"})
+	}
+	for node := range nodes {
+		blockchains, err := node.GetAPIClient().PChainAPI().GetBlockchains(ctx)
+		if err != nil {
+			continue
+		}
+		for _, blockchain := range blockchains {
+			if blockchain.SubnetID == constants.PrimaryNetworkID {
+				continue
+			}
+			chains = append(chains, chainToCheck{
+				chain: blockchain.ID.String(),
+				desc:  fmt.Sprintf("chain %s (%s)", blockchain.Name, blockchain.ID),
+			})
+		}
+		break
+	}
+	return chains
+}
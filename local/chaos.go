@@ -0,0 +1,181 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"go.uber.org/zap"
+)
+
+// chaosWindow is the active chaos window of a network
+type chaosWindow struct {
+	network.ChaosWindow
+	// ends the window after its duration. nil if it has no duration.
+	timer *time.Timer
+}
+
+// unhealthyNodeError is returned by health checks when [nodeName] is not healthy
+type unhealthyNodeError struct {
+	nodeName string
+	msg      string
+}
+
+func (e *unhealthyNodeError) Error() string {
+	return e.msg
+}
+
+// See network.Network
+func (ln *localNetwork) BeginChaos(ctx context.Context, window network.ChaosWindow) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if window.Duration < 0 {
+		return fmt.Errorf("chaos window duration must be non-negative, got %s", window.Duration)
+	}
+	ln.lock.Lock()
+	if ln.stopCalled() {
+		ln.lock.Unlock()
+		return network.ErrStopped
+	}
+	for _, nodeName := range window.NodeNames {
+		if _, ok := ln.nodes[nodeName]; !ok {
+			ln.lock.Unlock()
+			return fmt.Errorf("node %q not found", nodeName)
+		}
+	}
+	ln.endChaos()
+	chaos := &chaosWindow{ChaosWindow: window}
+	chaos.NodeNames = append([]string{}, window.NodeNames...)
+	if window.Duration > 0 {
+		chaos.timer = time.AfterFunc(window.Duration, func() {
+			ln.lock.Lock()
+			ended := ln.chaos == chaos && !ln.stopCalled()
+			if ended {
+				ln.endChaos()
+			}
+			ln.lock.Unlock()
+			if ended {
+				ln.publishChaosEnded(window)
+			}
+		})
+	}
+	ln.chaos = chaos
+	ln.lock.Unlock()
+
+	ln.log.Info("chaos window started",
+		zap.String("reason", window.Reason),
+		zap.Strings("nodes", window.NodeNames),
+		zap.Duration("duration", window.Duration),
+	)
+	ln.publishEvent(network.Event{
+		Type:     network.EventChaosStarted,
+		Message:  describeChaos(window),
+		Expected: true,
+	})
+	return nil
+}
+
+// See network.Network
+func (ln *localNetwork) EndChaos(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ln.lock.Lock()
+	if ln.stopCalled() {
+		ln.lock.Unlock()
+		return network.ErrStopped
+	}
+	chaos := ln.chaos
+	ln.endChaos()
+	ln.lock.Unlock()
+
+	if chaos != nil {
+		ln.publishChaosEnded(chaos.ChaosWindow)
+	}
+	return nil
+}
+
+// Clears the active chaos window.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) endChaos() {
+	if ln.chaos == nil {
+		return
+	}
+	if ln.chaos.timer != nil {
+		ln.chaos.timer.Stop()
+	}
+	ln.chaos = nil
+}
+
+func (ln *localNetwork) publishChaosEnded(window network.ChaosWindow) {
+	ln.log.Info("chaos window ended", zap.String("reason", window.Reason))
+	ln.publishEvent(network.Event{
+		Type:    network.EventChaosEnded,
+		Message: describeChaos(window),
+	})
+}
+
+// Returns the active chaos window if it affects the node with this name.
+// If [nodeName] is empty, the window must affect the whole network.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) chaosFor(nodeName string) (network.ChaosWindow, bool) {
+	if ln.chaos == nil {
+		return network.ChaosWindow{}, false
+	}
+	if nodeName == "" && len(ln.chaos.NodeNames) != 0 {
+		return network.ChaosWindow{}, false
+	}
+	return ln.chaos.ChaosWindow, ln.chaos.Affects(nodeName)
+}
+
+// Publishes the result [err] of a health check made by the runner.
+// An unhealthy result is reported as expected if it's caused by a node
+// affected by the active chaos window.
+func (ln *localNetwork) publishHealthCheck(err error, cause string) {
+	if err == nil {
+		ln.healthyReported.Store(true)
+		ln.publishEvent(network.Event{
+			Type:    network.EventNetworkHealthy,
+			Message: fmt.Sprintf("network healthy after %s", cause),
+		})
+		return
+	}
+	ln.healthyReported.Store(false)
+	nodeName := ""
+	var unhealthyErr *unhealthyNodeError
+	if errors.As(err, &unhealthyErr) {
+		nodeName = unhealthyErr.nodeName
+	}
+	ln.lock.RLock()
+	window, expected := ln.chaosFor(nodeName)
+	ln.lock.RUnlock()
+
+	msg := fmt.Sprintf("network unhealthy after %s: %s", cause, err)
+	if expected {
+		ln.log.Info("network unhealthy during chaos window", zap.String("reason", window.Reason), zap.Error(err))
+		msg = fmt.Sprintf("%s (expected, %s)", msg, describeChaos(window))
+	} else {
+		ln.log.Warn(fmt.Sprintf("network unhealthy after %s", cause), zap.Error(err))
+	}
+	ln.publishEvent(network.Event{
+		Type:     network.EventNetworkUnhealthy,
+		NodeName: nodeName,
+		Message:  msg,
+		Expected: expected,
+	})
+}
+
+func describeChaos(window network.ChaosWindow) string {
+	desc := "chaos"
+	if window.Reason != "" {
+		desc = fmt.Sprintf("chaos %q", window.Reason)
+	}
+	if len(window.NodeNames) != 0 {
+		desc = fmt.Sprintf("%s on %s", desc, strings.Join(window.NodeNames, ", "))
+	}
+	return desc
+}
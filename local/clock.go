@@ -0,0 +1,90 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"go.uber.org/zap"
+)
+
+const (
+	clockCheckFreq = 5 * time.Second
+	// wall clock differences, from the expected elapsed time, larger than this
+	// are considered a clock jump (i.e. host sleep/resume)
+	clockJumpThreshold = 30 * time.Second
+	// max time to wait for the network to become healthy after a clock jump
+	clockJumpHealthTimeout = 2 * time.Minute
+)
+
+// Every [clockCheckFreq], compares the elapsed wall clock time with the
+// elapsed monotonic time, to detect that the host was suspended (the monotonic
+// clock doesn't advance during suspension), or that the wall clock
+// was changed. In such case, API clients are recreated and
+// health is verified.
+// Runs until the network is stopped.
+func (ln *localNetwork) watchClock() {
+	ticker := time.NewTicker(clockCheckFreq)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ln.onStopCh:
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		wallElapsed := now.Round(0).Sub(last.Round(0))
+		monoElapsed := now.Sub(last)
+		last = now
+
+		jump := wallElapsed - monoElapsed
+		if jump < 0 {
+			jump = -jump
+		}
+		// the process may have been frozen without a wall clock difference
+		if frozen := monoElapsed - clockCheckFreq; frozen > jump {
+			jump = frozen
+		}
+		if jump < clockJumpThreshold {
+			continue
+		}
+		ln.onClockJump(jump)
+	}
+}
+
+// Reconnects node API clients and re-verifies network health
+// after a clock jump of [jump].
+func (ln *localNetwork) onClockJump(jump time.Duration) {
+	ln.log.Warn("clock jump detected, host may have been suspended", zap.Duration("jump", jump))
+	ln.publishEvent(network.Event{
+		Type:    network.EventClockJump,
+		Message: fmt.Sprintf("clock jumped %s", jump),
+	})
+
+	ln.lock.Lock()
+	if ln.stopCalled() {
+		ln.lock.Unlock()
+		return
+	}
+	for _, node := range ln.nodes {
+		if node.paused {
+			continue
+		}
+		node.resetAPIClient(ln.nodeAPIClientF(node.config))
+	}
+	ln.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), clockJumpHealthTimeout)
+	defer cancel()
+	// not using Healthy, the result is published below
+	ln.lock.RLock()
+	err := ln.healthy(ctx)
+	ln.lock.RUnlock()
+	if err == network.ErrStopped {
+		return
+	}
+	ln.publishHealthCheck(err, "clock jump")
+}
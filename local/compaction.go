@@ -0,0 +1,158 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/pebble"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// See network.Network
+//
+// avalanchego has no API to compact its database, so the compaction is done
+// offline: nodes are paused, compacted and resumed one at a time, waiting for
+// the network to be healthy again before going on with the next node.
+// Paused nodes are compacted without being resumed.
+func (ln *localNetwork) CompactDatabases(ctx context.Context) ([]network.DBCompaction, error) {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return nil, network.ErrStopped
+	}
+	nodeNames := make([]string, 0, len(ln.nodes))
+	for nodeName := range ln.nodes {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	ln.lock.RUnlock()
+	sort.Strings(nodeNames)
+
+	compactions := []network.DBCompaction{}
+	for _, nodeName := range nodeNames {
+		compaction, err := ln.compactNodeDatabase(ctx, nodeName)
+		if err != nil {
+			return compactions, fmt.Errorf("couldn't compact db of node %q: %w", nodeName, err)
+		}
+		compactions = append(compactions, compaction)
+	}
+	return compactions, nil
+}
+
+// Pauses [nodeName], compacts its database, resumes it, and waits for the
+// network to be healthy
+func (ln *localNetwork) compactNodeDatabase(ctx context.Context, nodeName string) (network.DBCompaction, error) {
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return network.DBCompaction{}, err
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.DBCompaction{}, network.ErrStopped
+	}
+	node, ok := ln.nodes[nodeName]
+	if !ok {
+		// removed in the meantime
+		return network.DBCompaction{NodeName: nodeName}, nil
+	}
+	dbType, err := nodeDBType(node.GetConfig())
+	if err != nil {
+		return network.DBCompaction{}, err
+	}
+	compaction := network.DBCompaction{NodeName: nodeName}
+	compaction.SizeBefore, err = dirSize(node.GetDbDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return compaction, err
+	}
+	if dbType == memdb.Name {
+		compaction.SizeAfter = compaction.SizeBefore
+		return compaction, nil
+	}
+	wasPaused := node.paused
+	if !wasPaused {
+		if err := ln.pauseNode(ctx, nodeName); err != nil {
+			return compaction, err
+		}
+	}
+	dbDir := filepath.Join(node.GetDbDir(), constants.NetworkName(ln.networkID))
+	node.log.Info("compacting node db", zap.String("node-name", nodeName), zap.String("db-type", dbType))
+	if err := compactDatabase(dbType, dbDir); err != nil {
+		return compaction, err
+	}
+	compaction.SizeAfter, err = dirSize(node.GetDbDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return compaction, err
+	}
+	if wasPaused {
+		return compaction, nil
+	}
+	if err := ln.resumeNode(ctx, nodeName); err != nil {
+		return compaction, err
+	}
+	return compaction, ln.healthy(ctx)
+}
+
+// Returns the db type of a node with config [nodeConfig]
+func nodeDBType(nodeConfig node.Config) (string, error) {
+	configFile := map[string]interface{}{}
+	if nodeConfig.ConfigFile != "" {
+		if err := json.Unmarshal([]byte(nodeConfig.ConfigFile), &configFile); err != nil {
+			return "", fmt.Errorf("couldn't unmarshal config file: %w", err)
+		}
+	}
+	return getConfigEntry(nodeConfig.Flags, configFile, config.DBTypeKey, leveldb.Name)
+}
+
+// Opens the database of type [dbType] stored at [dbDir] (the network
+// dir inside the node db dir), and compacts it.
+// Does nothing if there is no database yet.
+func compactDatabase(dbType string, dbDir string) error {
+	var dbPath string
+	switch dbType {
+	case leveldb.Name:
+		dbPath = filepath.Join(dbDir, version.CurrentDatabase.String())
+	case pebble.Name:
+		dbPath = filepath.Join(dbDir, pebble.Name)
+	default:
+		return fmt.Errorf("unsupported db type %q", dbType)
+	}
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	var (
+		db  database.Database
+		err error
+	)
+	switch dbType {
+	case leveldb.Name:
+		db, err = leveldb.New(dbPath, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	case pebble.Name:
+		db, err = pebble.New(dbPath, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't open %s db at %s: %w", dbType, dbPath, err)
+	}
+	if err := db.Compact(nil, nil); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("couldn't compact %s db at %s: %w", dbType, dbPath, err)
+	}
+	return db.Close()
+}
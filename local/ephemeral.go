@@ -0,0 +1,101 @@
+package local
+
+import (
+	"context"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"go.uber.org/zap"
+)
+
+const (
+	ephemeralNodeCheckFreq    = 3 * time.Second
+	ephemeralNodeCheckTimeout = 30 * time.Second
+)
+
+// Removes [node] from the network once [ttl] passes (if > 0), or once
+// [removeWhen] (if not nil) returns true.
+// Returns when the node is removed, replaced (eg restarted), or the network
+// is stopped.
+func (ln *localNetwork) removeEphemeralNode(
+	node *localNode,
+	ttl time.Duration,
+	removeWhen func(context.Context, node.Node) (bool, error),
+) {
+	var expiryCh <-chan time.Time
+	if ttl > 0 {
+		expiryTimer := time.NewTimer(ttl)
+		defer expiryTimer.Stop()
+		expiryCh = expiryTimer.C
+	}
+	ticker := time.NewTicker(ephemeralNodeCheckFreq)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ln.onStopCh:
+			return
+		case <-expiryCh:
+			ln.removeIfCurrentNode(node, "node TTL passed")
+			return
+		case <-ticker.C:
+		}
+		current, paused := ln.isCurrentNode(node)
+		if !current {
+			return
+		}
+		if removeWhen == nil || paused {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ephemeralNodeCheckTimeout)
+		ctx, stopCancel := ln.withStopCancel(ctx)
+		remove, err := removeWhen(ctx, node)
+		stopCancel()
+		cancel()
+		if err != nil {
+			node.log.Debug("node removal condition failed", zap.String("node-name", node.name), zap.Error(err))
+			continue
+		}
+		if remove {
+			ln.removeIfCurrentNode(node, "node removal condition met")
+			return
+		}
+	}
+}
+
+// Returns true if [node] is still part of the network, and
+// whether it is paused
+func (ln *localNetwork) isCurrentNode(node *localNode) (bool, bool) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	currentNode, ok := ln.nodes[node.name]
+	return ok && currentNode == node, node.paused
+}
+
+// Removes [node] from the network, unless it was already removed
+// or replaced
+func (ln *localNetwork) removeIfCurrentNode(node *localNode, reason string) {
+	ctx, endNodeOp, err := ln.beginNodeOp(context.Background())
+	if err != nil {
+		node.log.Warn("couldn't remove ephemeral node", zap.String("node-name", node.name), zap.Error(err))
+		return
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return
+	}
+	if currentNode, ok := ln.nodes[node.name]; !ok || currentNode != node {
+		return
+	}
+	node.log.Info("removing ephemeral node", zap.String("node-name", node.name), zap.String("reason", reason))
+	ctx, cancel := context.WithTimeout(ctx, stopTimeout)
+	defer cancel()
+	if err := ln.removeNode(ctx, node.name); err != nil {
+		node.log.Warn("couldn't remove ephemeral node", zap.String("node-name", node.name), zap.Error(err))
+	}
+}
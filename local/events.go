@@ -0,0 +1,71 @@
+package local
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+)
+
+// Number of events a subscriber may have pending before
+// new events are dropped for it
+const eventsBufferSize = 256
+
+// Fans out the network events to all subscribers
+type eventBroadcaster struct {
+	lock        sync.Mutex
+	subscribers []chan network.Event
+	closed      bool
+}
+
+// Returns a new channel receiving all the events published from now on.
+// The channel is closed when the broadcaster is closed.
+func (b *eventBroadcaster) subscribe() <-chan network.Event {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	ch := make(chan network.Event, eventsBufferSize)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subscribers = append(b.subscribers, ch)
+	return ch
+}
+
+// Sends [event] to all subscribers without blocking.
+// Subscribers that are not keeping up miss the event.
+// Returns false if some subscriber missed the event.
+func (b *eventBroadcaster) publish(event network.Event) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	delivered := true
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delivered = false
+		}
+	}
+	return delivered
+}
+
+// Closes all subscriber channels.
+// Subsequent publications are ignored.
+func (b *eventBroadcaster) close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, ch := range b.subscribers {
+		close(ch)
+	}
+	b.subscribers = nil
+}
@@ -0,0 +1,55 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"go.uber.org/zap"
+)
+
+// max time between the expiry warning and the network expiry
+const networkExpiryWarningLead = 5 * time.Minute
+
+// Publishes an expiry warning event shortly before [ttl] passes, and stops
+// the network once it passes, so forgotten networks don't keep consuming
+// host resources.
+// Runs until the network is stopped.
+func (ln *localNetwork) expireAfter(ttl time.Duration) {
+	expiry := time.Now().Add(ttl)
+	warningLead := networkExpiryWarningLead
+	if warningLead > ttl/2 {
+		warningLead = ttl / 2
+	}
+	warningTimer := time.NewTimer(ttl - warningLead)
+	defer warningTimer.Stop()
+	expiryTimer := time.NewTimer(ttl)
+	defer expiryTimer.Stop()
+
+	for {
+		select {
+		case <-ln.onStopCh:
+			return
+		case <-warningTimer.C:
+			remaining := time.Until(expiry).Round(time.Second)
+			ln.log.Warn("network is about to expire", zap.Duration("remaining", remaining))
+			ln.publishEvent(network.Event{
+				Type:    network.EventNetworkExpiring,
+				Message: fmt.Sprintf("network expires in %s", remaining),
+			})
+		case <-expiryTimer.C:
+			ln.log.Warn("network expired, stopping it", zap.Duration("ttl", ttl))
+			ln.publishEvent(network.Event{
+				Type:    network.EventNetworkExpired,
+				Message: fmt.Sprintf("network TTL %s passed", ttl),
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+			defer cancel()
+			if err := ln.Stop(ctx); err != nil {
+				ln.log.Warn("error stopping expired network", zap.Error(err))
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// extraFileTemplateData holds the fields that can be used
+// on the path templates of node extra files
+type extraFileTemplateData struct {
+	NodeName  string
+	NetworkID uint32
+}
+
+// Returns the path, inside [nodeRootDir], of the extra file with
+// path template [pathTemplate]
+func extraFilePath(nodeRootDir string, pathTemplate string, data extraFileTemplateData) (string, error) {
+	tmpl, err := template.New("path").Option("missingkey=error").Parse(pathTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid file path template %q: %w", pathTemplate, err)
+	}
+	relPath := &strings.Builder{}
+	if err := tmpl.Execute(relPath, data); err != nil {
+		return "", fmt.Errorf("invalid file path template %q: %w", pathTemplate, err)
+	}
+	if !filepath.IsLocal(relPath.String()) {
+		return "", fmt.Errorf("file path %q is not inside the node data dir", relPath.String())
+	}
+	return filepath.Join(nodeRootDir, relPath.String()), nil
+}
+
+// Writes the extra files of [nodeConfig] into [nodeRootDir]
+func writeExtraFiles(networkID uint32, nodeRootDir string, nodeConfig *node.Config) error {
+	data := extraFileTemplateData{
+		NodeName:  nodeConfig.Name,
+		NetworkID: networkID,
+	}
+	for pathTemplate, contents := range nodeConfig.Files {
+		path, err := extraFilePath(nodeRootDir, pathTemplate, data)
+		if err != nil {
+			return err
+		}
+		if err := createFileAndWrite(path, []byte(contents)); err != nil {
+			return fmt.Errorf("couldn't write file at %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Removes the extra files of [nodeConfig] from [nodeRootDir]
+func removeExtraFiles(networkID uint32, nodeRootDir string, nodeConfig node.Config) error {
+	data := extraFileTemplateData{
+		NodeName:  nodeConfig.Name,
+		NetworkID: networkID,
+	}
+	errs := wrappers.Errs{}
+	for pathTemplate := range nodeConfig.Files {
+		path, err := extraFilePath(nodeRootDir, pathTemplate, data)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs.Add(err)
+		}
+	}
+	return errs.Err
+}
+
+// Copies the binaries of [plugins] into [pluginDir], named after their VM IDs
+func installVMPlugins(pluginDir string, plugins []node.VMPlugin) error {
+	if err := os.MkdirAll(pluginDir, 0o750); err != nil {
+		return fmt.Errorf("couldn't create plugin dir: %w", err)
+	}
+	for _, plugin := range plugins {
+		vmID, err := plugin.VMID()
+		if err != nil {
+			return err
+		}
+		if err := copyExecutable(plugin.Path, filepath.Join(pluginDir, vmID.String())); err != nil {
+			return fmt.Errorf("couldn't install plugin of VM %q: %w", plugin.VM, err)
+		}
+	}
+	return nil
+}
+
+// Writes the aliases of the VM plugins of [nodeConfig], if any, to the VM
+// aliases file in [dataDir], and returns the flag pointing the node to it.
+// Returns an error if the node is also given VM aliases on its flags or
+// config file [configFile].
+func writeVMAliases(dataDir string, nodeConfig *node.Config, configFile map[string]interface{}) (map[string]string, error) {
+	// VM ID --> aliases
+	vmAliases := map[string][]string{}
+	for _, plugin := range nodeConfig.VMPlugins {
+		if len(plugin.Aliases) == 0 {
+			continue
+		}
+		vmID, err := plugin.VMID()
+		if err != nil {
+			return nil, err
+		}
+		vmAliases[vmID.String()] = plugin.Aliases
+	}
+	if len(vmAliases) == 0 {
+		return nil, nil
+	}
+	for _, key := range []string{config.VMAliasesFileKey, config.VMAliasesContentKey} {
+		if value, err := getConfigEntry(nodeConfig.Flags, configFile, key, ""); err != nil || value != "" {
+			return nil, fmt.Errorf("VM aliases given both on VM plugins and on flag %q", key)
+		}
+	}
+	vmAliasesBytes, err := json.MarshalIndent(vmAliases, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	vmAliasesPath := filepath.Join(dataDir, vmAliasesFileName)
+	if err := createFileAndWrite(vmAliasesPath, vmAliasesBytes); err != nil {
+		return nil, fmt.Errorf("couldn't write VM aliases file at %q: %w", vmAliasesPath, err)
+	}
+	return map[string]string{config.VMAliasesFileKey: vmAliasesPath}, nil
+}
+
+// Copies the executable at [srcPath] to [dstPath], replacing it if it exists
+func copyExecutable(srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	// written aside and renamed, so a plugin in use by a running
+	// node (eg sharing the plugin dir) is not modified
+	tmpPath := dstPath + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, dstPath)
+}
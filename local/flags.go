@@ -0,0 +1,103 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+)
+
+// flagDefaultsKey identifies an avalanchego binary, as run by a node
+// process creator (i.e. on the host or in a container image)
+type flagDefaultsKey struct {
+	nodeProcessCreator NodeProcessCreator
+	binaryPath         string
+}
+
+// flagDefaultsKey --> flag name --> default value
+var flagDefaultsCache sync.Map
+
+// Returns the flags the node is started with, given by the config
+// file entries [configFile] overridden by the command line flags [flags]
+func nodeFlags(configFile map[string]interface{}, flags map[string]string) map[string]string {
+	nodeFlags := make(map[string]string, len(configFile)+len(flags))
+	for k, v := range configFile {
+		nodeFlags[k] = fmt.Sprintf("%v", v)
+	}
+	for k, v := range flags {
+		nodeFlags[k] = v
+	}
+	return nodeFlags
+}
+
+// Returns the default value of each flag supported by the avalanchego
+// binary of [nodeConfig], as given by its `--help` when run by [npc]
+func getAvalancheGoFlagDefaults(npc NodeProcessCreator, nodeConfig node.Config) (map[string]string, error) {
+	key := flagDefaultsKey{nodeProcessCreator: npc, binaryPath: nodeConfig.BinaryPath}
+	if defaults, ok := flagDefaultsCache.Load(key); ok {
+		return defaults.(map[string]string), nil
+	}
+	usage, err := npc.GetNodeFlagsUsage(nodeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get flags usage from binary %q: %w", nodeConfig.BinaryPath, err)
+	}
+	defaults := utils.ParseFlagDefaults(usage)
+	if len(defaults) == 0 {
+		return nil, fmt.Errorf("couldn't parse flags usage from binary %q", nodeConfig.BinaryPath)
+	}
+	flagDefaultsCache.Store(key, defaults)
+	return defaults, nil
+}
+
+// DiffNodeFlags returns the flags that [n], a node of a local network,
+// was started with, that differ from the defaults of its avalanchego binary.
+// This includes the flags set by the runner itself (i.e. data dir, ports,
+// bootstrap nodes).
+func DiffNodeFlags(n node.Node) ([]utils.FlagDiff, error) {
+	ln, ok := n.(*localNode)
+	if !ok {
+		return nil, errors.New("node was not created by a local network")
+	}
+	defaults, err := getAvalancheGoFlagDefaults(ln.nodeProcessCreator, ln.current().config)
+	if err != nil {
+		return nil, err
+	}
+	return utils.DiffFlags(ln.flags, defaults), nil
+}
+
+// Returns an error naming the flags given to [nodeConfig] (including the
+// entries of its config file [configFile]) that are not supported by its
+// avalanchego binary, of version [avagoVersion], as run by [npc]
+func checkFlagNames(npc NodeProcessCreator, avagoVersion string, nodeConfig node.Config, configFile map[string]interface{}) error {
+	knownFlags, err := getAvalancheGoFlagDefaults(npc, nodeConfig)
+	if err != nil {
+		return err
+	}
+	// flag name --> placeholder value
+	givenFlags := map[string]string{}
+	for name := range nodeConfig.Flags {
+		givenFlags[name] = name
+	}
+	for name := range configFile {
+		givenFlags[name] = name
+	}
+	unknownFlags := []string{}
+	// as renamed for the binary version
+	for name := range getFlagsForAvagoVersion(avagoVersion, givenFlags) {
+		if _, ok := knownFlags[name]; !ok {
+			unknownFlags = append(unknownFlags, name)
+		}
+	}
+	if len(unknownFlags) == 0 {
+		return nil
+	}
+	sort.Strings(unknownFlags)
+	return fmt.Errorf(
+		"node %q given flags unknown to avalanchego %s: %s",
+		nodeConfig.Name, avagoVersion, strings.Join(unknownFlags, ", "),
+	)
+}
@@ -11,8 +11,10 @@ import (
 
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"go.uber.org/zap"
 )
 
@@ -119,9 +121,20 @@ func writeFiles(networkID uint32, genesis []byte, nodeRootDir string, nodeConfig
 			return nil, fmt.Errorf("couldn't write file at %q: %w", subnetConfigPath, err)
 		}
 	}
+	// extra files
+	if err := writeExtraFiles(networkID, nodeRootDir, nodeConfig); err != nil {
+		return nil, err
+	}
 	return flags, nil
 }
 
+// WriteNodeFiles writes into [nodeRootDir] the files a node of a network
+// with [networkID] and [genesis] needs on startup, eg to run it outside
+// of the runner. It returns flags used to point to those files.
+func WriteNodeFiles(networkID uint32, genesis []byte, nodeRootDir string, nodeConfig *node.Config) (map[string]string, error) {
+	return writeFiles(networkID, genesis, nodeRootDir, nodeConfig)
+}
+
 // getConfigEntry returns an entry in the config file if it is found, otherwise returns the default value
 func getConfigEntry(
 	nodeConfigFlags map[string]interface{},
@@ -145,8 +158,8 @@ func getConfigEntry(
 	return defaultVal, nil
 }
 
-// getPort looks up the port config in the config file, if there is none, it tries to get a random free port from the OS
-// if [reassingIfUsed] is true, and the port from config is not free, also tries to get a random free port
+// getPort looks up the port config in the node flags, and then in the config file.
+// Returns 0 if there is none.
 func getPort(
 	flags map[string]interface{},
 	configFile map[string]interface{},
@@ -158,6 +171,8 @@ func getPort(
 			port = uint16(gotPort)
 		case float64:
 			port = uint16(gotPort)
+		case uint16:
+			port = gotPort
 		default:
 			return 0, fmt.Errorf("expected flag %q to be int/float64 but got %T", portKey, portIntf)
 		}
@@ -167,11 +182,6 @@ func getPort(
 			return 0, fmt.Errorf("expected flag %q to be float64 but got %T", portKey, portIntf)
 		}
 		port = uint16(portFromConfigFile)
-	} else {
-		port, err = getFreePort()
-		if err != nil {
-			return 0, fmt.Errorf("couldn't get free port: %w", err)
-		}
 	}
 	return port, nil
 }
@@ -224,3 +234,34 @@ func addNetworkFlags(networkFlags map[string]interface{}, nodeFlags map[string]i
 		}
 	}
 }
+
+// Adds to the VM plugins of [nodeConfig] the ones of [networkPlugins]
+// of VMs it has no plugin of
+func addNetworkVMPlugins(networkPlugins []node.VMPlugin, nodeConfig *node.Config) error {
+	if len(networkPlugins) == 0 {
+		return nil
+	}
+	nodeVMIDs := set.Set[ids.ID]{}
+	for _, plugin := range nodeConfig.VMPlugins {
+		vmID, err := plugin.VMID()
+		if err != nil {
+			return err
+		}
+		nodeVMIDs.Add(vmID)
+	}
+	plugins := append([]node.VMPlugin{}, nodeConfig.VMPlugins...)
+	for _, plugin := range networkPlugins {
+		vmID, err := plugin.VMID()
+		if err != nil {
+			return err
+		}
+		if !nodeVMIDs.Contains(vmID) {
+			plugins = append(plugins, plugin)
+		}
+	}
+	if err := node.ValidateVMPlugins(plugins); err != nil {
+		return fmt.Errorf("invalid VM plugins of node %q: %w", nodeConfig.Name, err)
+	}
+	nodeConfig.VMPlugins = plugins
+	return nil
+}
@@ -0,0 +1,181 @@
+package local
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"go.uber.org/zap"
+)
+
+const (
+	nodeHistoryLogLines = 20
+	// max number of bytes read from the end of the log file
+	nodeHistoryLogTailSize = 64 * 1024
+	nodeMainLogFileName    = "main.log"
+)
+
+// processExitWatcher is implemented by node processes that can
+// notify and describe their exit
+type processExitWatcher interface {
+	// Closed when the process exits
+	exited() <-chan struct{}
+	// Only meaningful after the process exits
+	exitInfo() processExitInfo
+}
+
+// processExitInfo describes how a process exited
+type processExitInfo struct {
+	exitCode int
+	// empty if not terminated by a signal
+	signal string
+	time   time.Time
+}
+
+// See network.Network
+func (ln *localNetwork) GetNodeHistory(nodeName string) ([]network.NodeHistory, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	history, ok := ln.nodeHistory[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("no history for node %q", nodeName)
+	}
+	return append([]network.NodeHistory{}, history...), nil
+}
+
+// See network.Network
+func (ln *localNetwork) GetStopResult() (network.StopResult, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopResult == nil {
+		return network.StopResult{}, network.ErrNotStopped
+	}
+	result := *ln.stopResult
+	result.Nodes = append([]network.NodeStopResult{}, result.Nodes...)
+	return result, nil
+}
+
+// Waits for the process of [node] to exit, and records a crash if
+// the node was not asked to stop
+func (ln *localNetwork) watchNodeProcess(node *localNode, watcher processExitWatcher) {
+	select {
+	case <-watcher.exited():
+	case <-ln.onStopCh:
+		return
+	}
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	// nodes are removed from [ln.nodes] or paused before being stopped,
+	// and a restarted node is a new one
+	if currentNode, ok := ln.nodes[node.name]; !ok || currentNode != node || node.paused {
+		return
+	}
+	info := ln.recordNodeHistory(node, true, watcher.exitInfo().exitCode)
+	ln.healthyReported.Store(false)
+	ln.publishEvent(network.Event{
+		Type:     network.EventNodeCrashed,
+		NodeName: node.name,
+		Message:  fmt.Sprintf("exit code %d%s", info.ExitCode, describeSignal(info.Signal)),
+		Expected: info.Expected,
+	})
+	if info.Expected {
+		node.log.Info("node process exited during chaos window",
+			zap.String("node-name", node.name),
+			zap.Int("exit-code", info.ExitCode),
+			zap.String("signal", info.Signal),
+		)
+		ln.scheduleRestart(node, info.ExitCode)
+		return
+	}
+	node.log.Warn("node process exited unexpectedly",
+		zap.String("node-name", node.name),
+		zap.Int("exit-code", info.ExitCode),
+		zap.String("signal", info.Signal),
+	)
+	ln.scheduleRestart(node, info.ExitCode)
+}
+
+func describeSignal(signal string) string {
+	if signal == "" {
+		return ""
+	}
+	return ", signal " + signal
+}
+
+// Adds a record of the stopped process of [node] to the node history.
+// Does nothing if the process was already recorded.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) recordNodeHistory(node *localNode, crashed bool, exitCode int) network.NodeHistory {
+	if node.recorded {
+		return network.NodeHistory{}
+	}
+	node.recorded = true
+	info := network.NodeHistory{
+		Name:      node.name,
+		NodeID:    node.nodeID,
+		StartTime: node.startTime,
+		StopTime:  time.Now(),
+		Crashed:   crashed,
+		ExitCode:  exitCode,
+	}
+	if crashed {
+		_, info.Expected = ln.chaosFor(node.name)
+	}
+	if watcher, ok := node.process.(processExitWatcher); ok {
+		exitInfo := watcher.exitInfo()
+		info.Signal = exitInfo.signal
+		if !exitInfo.time.IsZero() {
+			info.StopTime = exitInfo.time
+		}
+	}
+	lastLogLines, err := tailFile(filepath.Join(node.logsDir, nodeMainLogFileName), nodeHistoryLogLines)
+	if err != nil {
+		node.log.Debug("couldn't read node log", zap.String("node-name", node.name), zap.Error(err))
+	}
+	info.LastLogLines = lastLogLines
+	ln.nodeHistory[node.name] = append(ln.nodeHistory[node.name], info)
+	return info
+}
+
+// Returns the last [n] lines of the file at [path]
+func tailFile(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := fileInfo.Size() - nodeHistoryLogTailSize
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, nodeHistoryLogTailSize), nodeHistoryLogTailSize)
+	// the first line is partial if the file is not read from the start
+	skipLine := offset > 0
+	for scanner.Scan() {
+		if skipLine {
+			skipLine = false
+			continue
+		}
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
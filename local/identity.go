@@ -0,0 +1,74 @@
+package local
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+)
+
+// NodeIdentityPaths are the paths of the identity files of an
+// existing avalanchego node
+type NodeIdentityPaths struct {
+	StakingKey  string
+	StakingCert string
+	// If empty, the BLS signing key is not imported
+	SigningKey string
+}
+
+// DefaultNodeIdentityPaths returns the paths where avalanchego keeps
+// the identity files of a node by default (~/.avalanchego/staking)
+func DefaultNodeIdentityPaths() (NodeIdentityPaths, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return NodeIdentityPaths{}, err
+	}
+	stakingDir := filepath.Join(homeDir, ".avalanchego", "staking")
+	return NodeIdentityPaths{
+		StakingKey:  filepath.Join(stakingDir, "staker.key"),
+		StakingCert: filepath.Join(stakingDir, "staker.crt"),
+		SigningKey:  filepath.Join(stakingDir, "signer.key"),
+	}, nil
+}
+
+// ImportNodeIdentity sets the staking key and cert (and BLS signing key, if
+// its path is given) of [nodeConfig] from the files of an existing node,
+// eg a mainnet or fuji one, so procedures can be rehearsed with its
+// identity on a local network.
+// Returns the node ID of the imported identity.
+func ImportNodeIdentity(nodeConfig *node.Config, paths NodeIdentityPaths) (ids.NodeID, error) {
+	stakingKey, err := os.ReadFile(paths.StakingKey)
+	if err != nil {
+		return ids.EmptyNodeID, fmt.Errorf("couldn't read staking key: %w", err)
+	}
+	stakingCert, err := os.ReadFile(paths.StakingCert)
+	if err != nil {
+		return ids.EmptyNodeID, fmt.Errorf("couldn't read staking cert: %w", err)
+	}
+	nodeID, err := utils.ToNodeID(stakingKey, stakingCert)
+	if err != nil {
+		return ids.EmptyNodeID, fmt.Errorf("invalid staking key/cert: %w", err)
+	}
+	var encodedSigningKey string
+	if paths.SigningKey != "" {
+		signingKey, err := os.ReadFile(paths.SigningKey)
+		if err != nil {
+			return ids.EmptyNodeID, fmt.Errorf("couldn't read signing key: %w", err)
+		}
+		if _, err := bls.SecretKeyFromBytes(signingKey); err != nil {
+			return ids.EmptyNodeID, fmt.Errorf("invalid signing key: %w", err)
+		}
+		encodedSigningKey = base64.StdEncoding.EncodeToString(signingKey)
+	}
+	nodeConfig.StakingKey = string(stakingKey)
+	nodeConfig.StakingCert = string(stakingCert)
+	if encodedSigningKey != "" {
+		nodeConfig.StakingSigningKey = encodedSigningKey
+	}
+	return nodeID, nil
+}
@@ -0,0 +1,112 @@
+package local
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultFundedKeys returns the X, P and C chain addresses pre-funded
+// by the default network genesis, formatted for [networkID], together with
+// their private key
+func DefaultFundedKeys(networkID uint32) []network.FundedKey {
+	hrp := constants.GetHRP(networkID)
+	addr := genesis.EWOQKey.Address()
+	xAddr, _ := address.Format("X", hrp, addr[:])
+	pAddr, _ := address.Format("P", hrp, addr[:])
+	cAddr := ethcrypto.PubkeyToAddress(genesis.EWOQKey.ToECDSA().PublicKey)
+	return []network.FundedKey{
+		{
+			Address:    xAddr,
+			PrivateKey: genesis.EWOQKey.String(),
+		},
+		{
+			Address:    pAddr,
+			PrivateKey: genesis.EWOQKey.String(),
+		},
+		{
+			Address:    cAddr.Hex(),
+			PrivateKey: hex.EncodeToString(genesis.EWOQKey.Bytes()),
+		},
+	}
+}
+
+// FundedKeys returns the keys of DefaultFundedKeys that the genesis of
+// local network [net] funds. Networks with a custom genesis may fund
+// them on some chains only, or not at all.
+func FundedKeys(net network.Network) ([]network.FundedKey, error) {
+	ln, ok := net.(*localNetwork)
+	if !ok {
+		return nil, fmt.Errorf("network of type %T has no known genesis", net)
+	}
+	ln.lock.RLock()
+	networkID, genesisBytes := ln.networkID, ln.genesis
+	ln.lock.RUnlock()
+	if networkID == constants.LocalID {
+		// avalanchego uses its own local genesis, which funds all of them
+		return DefaultFundedKeys(networkID), nil
+	}
+	return genesisFundedKeys(networkID, genesisBytes)
+}
+
+// Returns the keys of DefaultFundedKeys for the chains where [genesisBytes]
+// allocates funds to them
+func genesisFundedKeys(networkID uint32, genesisBytes []byte) ([]network.FundedKey, error) {
+	var config genesis.UnparsedConfig
+	if err := json.Unmarshal(genesisBytes, &config); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal genesis: %w", err)
+	}
+	addr := genesis.EWOQKey.Address()
+	xFunded, pFunded := false, false
+	for _, allocation := range config.Allocations {
+		_, _, allocationAddr, err := address.Parse(allocation.AVAXAddr)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse genesis allocation address %q: %w", allocation.AVAXAddr, err)
+		}
+		if !bytes.Equal(allocationAddr, addr[:]) {
+			continue
+		}
+		// the initial amount goes to the X-Chain, and the unlock schedule
+		// to the P-Chain
+		xFunded = xFunded || allocation.InitialAmount > 0
+		for _, locked := range allocation.UnlockSchedule {
+			pFunded = pFunded || locked.Amount > 0
+		}
+	}
+	cFunded := false
+	if config.CChainGenesis != "" {
+		var cChainGenesis struct {
+			Alloc map[string]struct {
+				Balance string `json:"balance"`
+			} `json:"alloc"`
+		}
+		if err := json.Unmarshal([]byte(config.CChainGenesis), &cChainGenesis); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal C-Chain genesis: %w", err)
+		}
+		cAddr := ethcrypto.PubkeyToAddress(genesis.EWOQKey.ToECDSA().PublicKey)
+		for allocAddr, account := range cChainGenesis.Alloc {
+			if common.HexToAddress(allocAddr) != cAddr {
+				continue
+			}
+			balance, ok := new(big.Int).SetString(account.Balance, 0)
+			cFunded = ok && balance.Sign() > 0
+		}
+	}
+	defaultKeys := DefaultFundedKeys(networkID)
+	fundedKeys := []network.FundedKey{}
+	for i, funded := range []bool{xFunded, pFunded, cFunded} {
+		if funded {
+			fundedKeys = append(fundedKeys, defaultKeys[i])
+		}
+	}
+	return fundedKeys, nil
+}
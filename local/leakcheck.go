@@ -0,0 +1,134 @@
+package local
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/shirou/gopsutil/process"
+	"go.uber.org/zap"
+)
+
+const (
+	// max time given to processes and ports to be released after stop
+	leakCheckTimeout = 5 * time.Second
+	leakCheckFreq    = 250 * time.Millisecond
+)
+
+// Resources a network is expected to release on stop
+type leakCheckResources struct {
+	// node processes and their descendants
+	pids []int32
+	// node API and P2P ports
+	ports []uint16
+}
+
+// Collects the resources used by the running nodes.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) collectLeakCheckResources() leakCheckResources {
+	resources := leakCheckResources{}
+	for _, node := range ln.nodes {
+		if node.paused {
+			continue
+		}
+		resources.ports = append(resources.ports, node.apiPort, node.p2pPort)
+		np, ok := node.process.(*nodeProcess)
+		if !ok || np.cmd.Process == nil {
+			continue
+		}
+		pid := int32(np.cmd.Process.Pid)
+		resources.pids = append(resources.pids, pid)
+		resources.pids = append(resources.pids, getDescendants(pid)...)
+	}
+	return resources
+}
+
+// Returns the pids of all descendants of process [pid]
+func getDescendants(pid int32) []int32 {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return nil
+	}
+	children, err := proc.Children()
+	if err != nil {
+		return nil
+	}
+	descendants := []int32{}
+	for _, child := range children {
+		descendants = append(descendants, child.Pid)
+		descendants = append(descendants, getDescendants(child.Pid)...)
+	}
+	return descendants
+}
+
+// Verifies that [resources] were released, and that the root
+// dir size is within the configured limit.
+// Returns an error wrapping network.ErrLeakDetected otherwise.
+func (ln *localNetwork) checkLeaks(resources leakCheckResources) error {
+	var leaks []string
+	deadline := time.Now().Add(leakCheckTimeout)
+	for {
+		leaks = nil
+		for _, pid := range resources.pids {
+			if exists, err := process.PidExists(pid); err == nil && exists {
+				leaks = append(leaks, fmt.Sprintf("process %d still running", pid))
+			}
+		}
+		for _, port := range resources.ports {
+			if !isPortFree(port) {
+				leaks = append(leaks, fmt.Sprintf("port %d still in use", port))
+			}
+		}
+		if len(leaks) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(leakCheckFreq)
+	}
+	if ln.leakCheck.MaxRootDirSize > 0 {
+		size, err := dirSize(ln.rootDir)
+		if err != nil {
+			ln.log.Warn("couldn't get root dir size", zap.String("root-dir", ln.rootDir), zap.Error(err))
+		} else if size > ln.leakCheck.MaxRootDirSize {
+			leaks = append(leaks, fmt.Sprintf("root dir %s size %d larger than %d", ln.rootDir, size, ln.leakCheck.MaxRootDirSize))
+		}
+	}
+	if len(leaks) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", network.ErrLeakDetected, strings.Join(leaks, ", "))
+}
+
+// Returns true if a TCP listener can be created at [port]
+func isPortFree(port uint16) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(int(port))))
+	if err != nil {
+		return false
+	}
+	_ = l.Close()
+	return true
+}
+
+// Returns the total size of the regular files under [dir]
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
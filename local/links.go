@@ -0,0 +1,428 @@
+package local
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"go.uber.org/zap"
+)
+
+const (
+	// Connections between nodes change as they reconnect, so the traffic
+	// shaping is updated this often
+	linkSyncFreq = time.Second
+	// Interface of the node traffic
+	linkDevice = "lo"
+	// Handle of the root qdisc added to [linkDevice]
+	linkRootHandle = "1:"
+	// First minor id of the link classes
+	linkFirstClass = 0x10
+	// TCP state of established connections, as listed by the kernel
+	tcpEstablished = "01"
+)
+
+var errLinksUnsupported = errors.New("link conditions are only supported on linux")
+
+// processWithPID is implemented by node processes running as a host
+// process, whose connections can be found
+type processWithPID interface {
+	pid() int
+}
+
+// link is the direction of the traffic between two nodes
+type link struct {
+	from string
+	to   string
+}
+
+// flow is the traffic of a TCP connection in one direction
+type flow struct {
+	srcPort uint16
+	dstPort uint16
+}
+
+// linkShaper degrades the traffic of the nodes as given by their link
+// conditions
+type linkShaper interface {
+	// Sets the conditions of each link, applied to the traffic of its
+	// flows. The links not given are restored.
+	apply(ctx context.Context, conditions map[link]network.LinkConditions, flows map[link][]flow) error
+	// Restores all the links
+	close() error
+}
+
+// See network.Network
+func (ln *localNetwork) SetLinkConditions(ctx context.Context, from string, to string, conditions network.LinkConditions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	return ln.setLinkConditions(ctx, network.LinkSpec{From: from, To: to, Conditions: conditions})
+}
+
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) setLinkConditions(ctx context.Context, spec network.LinkSpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	for _, nodeName := range []string{spec.From, spec.To} {
+		node, ok := ln.nodes[nodeName]
+		if !ok {
+			return fmt.Errorf("node %q not found", nodeName)
+		}
+		if _, ok := node.process.(processWithPID); !ok {
+			return fmt.Errorf("link conditions are not supported for the process of node %q", nodeName)
+		}
+	}
+	if ln.linkShaper == nil {
+		shaper, err := ln.newLinkShaper(ln.log)
+		if err != nil {
+			return err
+		}
+		ln.linkShaper = shaper
+		go ln.syncLinksPeriodically()
+	}
+	l := link{from: spec.From, to: spec.To}
+	if spec.Conditions.IsZero() {
+		delete(ln.linkConditions, l)
+	} else {
+		ln.linkConditions[l] = spec.Conditions
+	}
+	return ln.syncLinks(ctx)
+}
+
+// Every [linkSyncFreq], updates the traffic shaping to the current
+// connections between the nodes.
+// Runs until the network is stopped.
+func (ln *localNetwork) syncLinksPeriodically() {
+	ticker := time.NewTicker(linkSyncFreq)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ln.onStopCh:
+			return
+		case <-ticker.C:
+		}
+		ln.lock.Lock()
+		if !ln.stopCalled() {
+			if err := ln.syncLinks(context.Background()); err != nil {
+				ln.log.Warn("couldn't update link conditions", zap.Error(err))
+			}
+		}
+		ln.lock.Unlock()
+	}
+}
+
+// Applies the link conditions to the current connections between nodes.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) syncLinks(ctx context.Context) error {
+	sockets, err := hostTCPSockets(ln.procDir)
+	if err != nil {
+		return err
+	}
+	// node name --> inodes of its sockets
+	nodeInodes := map[string]set.Set[uint64]{}
+	for l := range ln.linkConditions {
+		for _, nodeName := range []string{l.from, l.to} {
+			if _, ok := nodeInodes[nodeName]; ok {
+				continue
+			}
+			inodes := set.Set[uint64]{}
+			// removed and paused nodes have no connections
+			if node, ok := ln.nodes[nodeName]; ok && !node.paused {
+				if process, ok := node.process.(processWithPID); ok {
+					inodes, err = processSocketInodes(ln.procDir, process.pid())
+					if err != nil {
+						ln.log.Debug("couldn't get node sockets", zap.String("node-name", nodeName), zap.Error(err))
+					}
+				}
+			}
+			nodeInodes[nodeName] = inodes
+		}
+	}
+	flows := map[link][]flow{}
+	for l := range ln.linkConditions {
+		flows[l] = linkFlows(sockets, nodeInodes[l.from], nodeInodes[l.to])
+	}
+	return ln.linkShaper.apply(ctx, ln.linkConditions, flows)
+}
+
+// tcpSocket is an end of a TCP connection of the host
+type tcpSocket struct {
+	localPort  uint16
+	remotePort uint16
+	inode      uint64
+}
+
+// Returns the established TCP sockets of the host, as listed at [procDir]
+func hostTCPSockets(procDir string) ([]tcpSocket, error) {
+	sockets := []tcpSocket{}
+	for _, name := range []string{"tcp", "tcp6"} {
+		f, err := os.Open(filepath.Join(procDir, "net", name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileSockets, err := parseTCPSockets(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse %s sockets: %w", name, err)
+		}
+		sockets = append(sockets, fileSockets...)
+	}
+	return sockets, nil
+}
+
+// Parses the established sockets of a /proc/net/tcp[6] listing
+func parseTCPSockets(r io.Reader) ([]tcpSocket, error) {
+	sockets := []tcpSocket{}
+	scanner := bufio.NewScanner(r)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		if fields[3] != tcpEstablished {
+			continue
+		}
+		localPort, err := parseSocketPort(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		remotePort, err := parseSocketPort(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		sockets = append(sockets, tcpSocket{localPort: localPort, remotePort: remotePort, inode: inode})
+	}
+	return sockets, scanner.Err()
+}
+
+// Parses the port of a hex encoded address:port
+func parseSocketPort(addr string) (uint16, error) {
+	i := strings.LastIndex(addr, ":")
+	port, err := strconv.ParseUint(addr[i+1:], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid socket address %q: %w", addr, err)
+	}
+	return uint16(port), nil
+}
+
+// Returns the inodes of the sockets open by process [pid]
+func processSocketInodes(procDir string, pid int) (set.Set[uint64], error) {
+	fdDir := filepath.Join(procDir, strconv.Itoa(pid), "fd")
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil, err
+	}
+	inodes := set.Set[uint64]{}
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+		if err != nil {
+			// closed in the meantime
+			continue
+		}
+		inodeStr := strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")
+		if inodeStr == target {
+			continue
+		}
+		if inode, err := strconv.ParseUint(inodeStr, 10, 64); err == nil {
+			inodes.Add(inode)
+		}
+	}
+	return inodes, nil
+}
+
+// Returns the flows of the traffic sent from the sockets with [fromInodes]
+// to the sockets with [toInodes], sorted by ports
+func linkFlows(sockets []tcpSocket, fromInodes set.Set[uint64], toInodes set.Set[uint64]) []flow {
+	// both ends of local connections are listed
+	toEnds := set.Set[flow]{}
+	for _, socket := range sockets {
+		if toInodes.Contains(socket.inode) {
+			toEnds.Add(flow{srcPort: socket.localPort, dstPort: socket.remotePort})
+		}
+	}
+	flows := []flow{}
+	for _, socket := range sockets {
+		if !fromInodes.Contains(socket.inode) {
+			continue
+		}
+		if toEnds.Contains(flow{srcPort: socket.remotePort, dstPort: socket.localPort}) {
+			flows = append(flows, flow{srcPort: socket.localPort, dstPort: socket.remotePort})
+		}
+	}
+	sort.Slice(flows, func(i, j int) bool {
+		if flows[i].srcPort != flows[j].srcPort {
+			return flows[i].srcPort < flows[j].srcPort
+		}
+		return flows[i].dstPort < flows[j].dstPort
+	})
+	return flows
+}
+
+// tcShaper shapes the traffic on the loopback interface with tc:
+// each link gets an htb class with a netem qdisc, and the flows of the
+// link are classified into it by port. Unclassified traffic is not
+// shaped. Requires CAP_NET_ADMIN, and replaces the root qdisc of the
+// loopback interface, so only a network per host can use it.
+type tcShaper struct {
+	// runs tc with the given args
+	tc func(ctx context.Context, args ...string) error
+	// true once the root qdisc is added
+	started bool
+	// link --> minor id of its class
+	classes map[link]uint16
+	// class minor id --> conditions of its netem qdisc
+	classConditions map[uint16]network.LinkConditions
+	// filters currently added, as tc args
+	filters []string
+}
+
+func newTCShaper(tc func(ctx context.Context, args ...string) error) *tcShaper {
+	return &tcShaper{
+		tc:              tc,
+		classes:         map[link]uint16{},
+		classConditions: map[uint16]network.LinkConditions{},
+	}
+}
+
+// Runs tc from PATH, killing it if [ctx] is done
+func runTC(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "tc", args...).CombinedOutput() //nolint
+	if err != nil {
+		return fmt.Errorf("tc %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Returns the tc args of a netem qdisc with [conditions]
+func netemArgs(conditions network.LinkConditions) []string {
+	args := []string{"netem"}
+	if conditions.Latency > 0 {
+		args = append(args, "delay", fmt.Sprintf("%dus", conditions.Latency.Microseconds()))
+		if conditions.Jitter > 0 {
+			args = append(args, fmt.Sprintf("%dus", conditions.Jitter.Microseconds()))
+		}
+	}
+	if conditions.Loss > 0 {
+		args = append(args, "loss", strconv.FormatFloat(conditions.Loss, 'f', -1, 64)+"%")
+	}
+	return args
+}
+
+func (s *tcShaper) apply(ctx context.Context, conditions map[link]network.LinkConditions, flows map[link][]flow) error {
+	if !s.started {
+		if len(conditions) == 0 {
+			return nil
+		}
+		if err := s.tc(ctx, "qdisc", "add", "dev", linkDevice, "root", "handle", linkRootHandle, "htb"); err != nil {
+			return err
+		}
+		s.started = true
+	}
+	links := make([]link, 0, len(conditions))
+	for l := range conditions {
+		links = append(links, l)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].from != links[j].from {
+			return links[i].from < links[j].from
+		}
+		return links[i].to < links[j].to
+	})
+	filters := []string{}
+	for _, l := range links {
+		classID, err := s.setClass(ctx, l, conditions[l])
+		if err != nil {
+			return err
+		}
+		for _, f := range flows[l] {
+			filters = append(filters, fmt.Sprintf(
+				"filter add dev %s parent %s protocol ip prio 1 u32 match ip sport %d 0xffff match ip dport %d 0xffff flowid %s%x",
+				linkDevice, linkRootHandle, f.srcPort, f.dstPort, linkRootHandle, classID,
+			))
+		}
+	}
+	if strings.Join(filters, "\n") == strings.Join(s.filters, "\n") {
+		return nil
+	}
+	if len(s.filters) != 0 {
+		if err := s.tc(ctx, "filter", "del", "dev", linkDevice, "parent", linkRootHandle, "prio", "1"); err != nil {
+			return err
+		}
+		s.filters = nil
+	}
+	for _, filter := range filters {
+		if err := s.tc(ctx, strings.Fields(filter)...); err != nil {
+			return err
+		}
+		s.filters = append(s.filters, filter)
+	}
+	return nil
+}
+
+// Adds the class of [l] with a netem qdisc, or updates its qdisc if
+// [conditions] changed. Returns the class minor id.
+func (s *tcShaper) setClass(ctx context.Context, l link, conditions network.LinkConditions) (uint16, error) {
+	classID, ok := s.classes[l]
+	if !ok {
+		classID = uint16(linkFirstClass + len(s.classes))
+		classArg := fmt.Sprintf("%s%x", linkRootHandle, classID)
+		if err := s.tc(ctx, "class", "add", "dev", linkDevice, "parent", linkRootHandle, "classid", classArg, "htb", "rate", "100gbit"); err != nil {
+			return 0, err
+		}
+		args := []string{"qdisc", "add", "dev", linkDevice, "parent", classArg, "handle", fmt.Sprintf("%x:", classID)}
+		if err := s.tc(ctx, append(args, netemArgs(conditions)...)...); err != nil {
+			return 0, err
+		}
+		s.classes[l] = classID
+		s.classConditions[classID] = conditions
+		return classID, nil
+	}
+	if s.classConditions[classID] == conditions {
+		return classID, nil
+	}
+	args := []string{"qdisc", "change", "dev", linkDevice, "parent", fmt.Sprintf("%s%x", linkRootHandle, classID), "handle", fmt.Sprintf("%x:", classID)}
+	if err := s.tc(ctx, append(args, netemArgs(conditions)...)...); err != nil {
+		return 0, err
+	}
+	s.classConditions[classID] = conditions
+	return classID, nil
+}
+
+func (s *tcShaper) close() error {
+	if !s.started {
+		return nil
+	}
+	s.started = false
+	// the links are restored even if the stop context is done
+	return s.tc(context.Background(), "qdisc", "del", "dev", linkDevice, "root")
+}
@@ -0,0 +1,10 @@
+//go:build linux
+
+package local
+
+import "github.com/ava-labs/avalanchego/utils/logging"
+
+// Node traffic is shaped with tc
+func newLinkShaper(logging.Logger) (linkShaper, error) {
+	return newTCShaper(runTC), nil
+}
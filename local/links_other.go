@@ -0,0 +1,10 @@
+//go:build !linux
+
+package local
+
+import "github.com/ava-labs/avalanchego/utils/logging"
+
+// There is no traffic shaping outside of linux
+func newLinkShaper(logging.Logger) (linkShaper, error) {
+	return nil, errLinksUnsupported
+}
@@ -0,0 +1,102 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+)
+
+// See network.Network
+func (ln *localNetwork) SetLogLevel(ctx context.Context, nodeName string, level string) error {
+	if _, err := logging.ToLevel(level); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	return ln.setLogLevel(ctx, nodeName, level)
+}
+
+// See network.Network
+func (ln *localNetwork) SetNetworkLogLevel(ctx context.Context, level string) error {
+	if _, err := logging.ToLevel(level); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	// for the nodes added from now on
+	flags := maps.Clone(ln.flags)
+	if flags == nil {
+		flags = map[string]interface{}{}
+	}
+	flags[config.LogLevelKey] = level
+	ln.flags = flags
+
+	nodeNames := maps.Keys(ln.nodes)
+	sort.Strings(nodeNames)
+	for _, nodeName := range nodeNames {
+		if err := ln.setLogLevel(ctx, nodeName, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sets the log level of [nodeName] to [level], through its admin API if
+// enabled, or else by restarting it. Paused nodes are not restarted.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) setLogLevel(ctx context.Context, nodeName string, level string) error {
+	node, ok := ln.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	// kept on restarts
+	node.config.Flags = maps.Clone(node.config.Flags)
+	if node.config.Flags == nil {
+		node.config.Flags = map[string]interface{}{}
+	}
+	node.config.Flags[config.LogLevelKey] = level
+	if node.paused {
+		return nil
+	}
+	if node.flags[config.AdminAPIEnabledKey] != "true" {
+		node.log.Info("restarting node to set its log level", zap.String("name", nodeName), zap.String("level", level))
+		return ln.restartNode(ctx, nodeName, "", "", "", nil, nil, nil)
+	}
+	// the display level follows the log level, unless given
+	displayLevel := level
+	if _, ok := node.flags[config.LogDisplayLevelKey]; ok {
+		displayLevel = ""
+	}
+	if err := node.GetAPIClient().AdminAPI().SetLoggerLevel(ctx, "", level, displayLevel); err != nil {
+		return fmt.Errorf("couldn't set log level of node %q: %w", nodeName, err)
+	}
+	node.flags[config.LogLevelKey] = level
+	node.log.Info("set node log level", zap.String("name", nodeName), zap.String("level", level))
+	return nil
+}
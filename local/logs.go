@@ -0,0 +1,226 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+)
+
+const (
+	// Number of lines a log stream may have pending before
+	// new lines are dropped for it
+	logsBufferSize = 1024
+	// Lines output by a node longer than this are split
+	maxLogLineSize = 64 * 1024
+	// Prefix of the notices of lines dropped for a slow log stream
+	logsDroppedPrefix = "network-runner"
+)
+
+// A line output by a node process, on its stdout or stderr
+type logLine struct {
+	nodeName string
+	text     string
+}
+
+// A log stream subscribed to the node output
+type logSubscriber struct {
+	lines chan logLine
+	// number of lines dropped since the last one delivered
+	dropped int
+}
+
+// Fans out the lines output by the node processes to all log streams.
+// Lines of all the nodes are delivered in the order they are published.
+type logBroadcaster struct {
+	lock        sync.Mutex
+	subscribers map[*logSubscriber]struct{}
+	closed      bool
+}
+
+// Returns a new subscriber receiving all the lines published from now on,
+// and the function to unsubscribe it.
+// Its channel is closed when the broadcaster is closed.
+func (b *logBroadcaster) subscribe() (*logSubscriber, func()) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	sub := &logSubscriber{lines: make(chan logLine, logsBufferSize)}
+	if b.closed {
+		close(sub.lines)
+		return sub, func() {}
+	}
+	if b.subscribers == nil {
+		b.subscribers = map[*logSubscriber]struct{}{}
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.lines)
+		}
+	}
+}
+
+// Returns the number of lines dropped for [sub] since it last received
+// one, and resets it
+func (b *logBroadcaster) takeDropped(sub *logSubscriber) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	dropped := sub.dropped
+	sub.dropped = 0
+	return dropped
+}
+
+// Sends the line [text] output by node [nodeName] to all subscribers
+// without blocking. Subscribers that are not keeping up miss the line.
+func (b *logBroadcaster) publish(nodeName string, text string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.subscribers) == 0 {
+		return
+	}
+	line := logLine{
+		nodeName: nodeName,
+		text:     text,
+	}
+	for sub := range b.subscribers {
+		select {
+		case sub.lines <- line:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// Closes all subscriber channels.
+// Subsequent publications are ignored.
+func (b *logBroadcaster) close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subscribers {
+		close(sub.lines)
+	}
+	b.subscribers = nil
+}
+
+// Returns a writer publishing each line written to it as output by node
+// [nodeName]. Each stream of a node (stdout, stderr) needs its own writer.
+func (b *logBroadcaster) writer(nodeName string) io.Writer {
+	return &logLineWriter{
+		logs:     b,
+		nodeName: nodeName,
+	}
+}
+
+// Splits what is written to it into lines, published to [logs]
+type logLineWriter struct {
+	logs     *logBroadcaster
+	nodeName string
+	lock     sync.Mutex
+	// start of the line not yet terminated
+	partial []byte
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	data := p
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i == -1 {
+			w.partial = append(w.partial, data...)
+			if len(w.partial) >= maxLogLineSize {
+				w.logs.publish(w.nodeName, string(w.partial))
+				w.partial = w.partial[:0]
+			}
+			break
+		}
+		line := data[:i]
+		if len(w.partial) > 0 {
+			line = append(w.partial, line...)
+			w.partial = w.partial[:0]
+		}
+		w.logs.publish(w.nodeName, string(bytes.TrimSuffix(line, []byte{'\r'})))
+		data = data[i+1:]
+	}
+	return len(p), nil
+}
+
+// See network.Network
+func (ln *localNetwork) StreamLogs(ctx context.Context, w io.Writer) error {
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	sub, unsubscribe := ln.logs.subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-sub.lines:
+			if !ok {
+				return nil
+			}
+			if dropped := ln.logs.takeDropped(sub); dropped > 0 {
+				if _, err := fmt.Fprintf(w, "[%s] %d lines dropped\n", logsDroppedPrefix, dropped); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintf(w, "[%s] %s\n", line.nodeName, line.text); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// See network.Network
+func (ln *localNetwork) LogRecords(ctx context.Context, filter network.LogFilter) (<-chan network.LogRecord, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+	sub, unsubscribe := ln.logs.subscribe()
+	records := make(chan network.LogRecord, logsBufferSize)
+	go func() {
+		defer close(records)
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case line, ok := <-sub.lines:
+				if !ok {
+					return
+				}
+				record, ok := network.ParseLogRecord(line.nodeName, line.text)
+				if !ok || !filter.Matches(record) {
+					continue
+				}
+				// while blocked, lines are dropped for [sub]
+				select {
+				case <-ctx.Done():
+					return
+				case records <- record:
+				}
+			}
+		}
+	}()
+	return records, nil
+}
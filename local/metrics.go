@@ -0,0 +1,89 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"golang.org/x/sync/errgroup"
+)
+
+const metricsEndpoint = "/ext/metrics"
+
+// See network.Network
+func (ln *localNetwork) ScrapeMetrics(ctx context.Context) (map[string]network.Metrics, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+
+	metricsLock := sync.Mutex{}
+	metrics := map[string]network.Metrics{}
+	errGr, ctx := errgroup.WithContext(ctx)
+	for _, node := range ln.nodes {
+		if node.paused {
+			continue
+		}
+		node := node
+		errGr.Go(func() error {
+			nodeMetrics, err := scrapeNodeMetrics(ctx, node)
+			if err != nil {
+				return fmt.Errorf("couldn't scrape metrics of node %q: %w", node.name, err)
+			}
+			metricsLock.Lock()
+			metrics[node.name] = nodeMetrics
+			metricsLock.Unlock()
+			return nil
+		})
+	}
+	if err := errGr.Wait(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// See network.Network
+func (ln *localNetwork) MetricsTargets(ctx context.Context) ([]network.ScrapeTarget, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+	targets := []network.ScrapeTarget{}
+	for nodeName, node := range ln.nodes {
+		if node.paused {
+			continue
+		}
+		targets = append(targets, network.NewScrapeTarget(nodeName, node.GetURI()))
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].NodeName < targets[j].NodeName
+	})
+	return targets, nil
+}
+
+// Fetches and parses the metrics exposed at the node metrics API
+func scrapeNodeMetrics(ctx context.Context, node *localNode) (network.Metrics, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, node.GetURI()+metricsEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return network.ParseMetrics(resp.Body)
+}
@@ -9,15 +9,21 @@ import (
 	"fmt"
 	"io/fs"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/api"
+	"github.com/ava-labs/avalanche-network-runner/binaries"
 	"github.com/ava-labs/avalanche-network-runner/network"
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/network/node/status"
@@ -38,6 +44,7 @@ import (
 	"golang.org/x/exp/maps"
 	"golang.org/x/mod/semver"
 	"golang.org/x/sync/errgroup"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 const (
@@ -48,13 +55,18 @@ const (
 	stakingCertFileName       = "staking.crt"
 	stakingSigningKeyFileName = "signer.key"
 	genesisFileName           = "genesis.json"
+	vmAliasesFileName         = "vm-aliases.json"
 	stopTimeout               = 30 * time.Second
 	healthCheckFreq           = 3 * time.Second
+	validatorSetCheckFreq     = 3 * time.Second
 	DefaultNumNodes           = 5
 	snapshotPrefix            = "anr-snapshot-"
 	networkRootDirPrefix      = "network"
 	defaultDBSubdir           = "db"
 	defaultLogsSubdir         = "logs"
+	defaultPluginsSubdir      = "plugins"
+	sharedDirName             = "shared"
+	procDir                   = "/proc"
 	// difference between unlock schedule locktime and startime in original genesis
 	genesisLocktimeStartimeDelta = 2836800
 )
@@ -93,7 +105,8 @@ type localNetwork struct {
 	stopOnce           sync.Once
 	// Closed when Stop begins.
 	onStopCh chan struct{}
-	// For node name generation
+	// For node name generation. Suffixes are never reused, even
+	// if the node with the generated name is removed.
 	nextNodeSuffix uint64
 	// Node Name --> Node
 	nodes map[string]*localNode
@@ -114,6 +127,8 @@ type localNetwork struct {
 	upgradeConfigFiles map[string]string
 	// subnet config files to use per default
 	subnetConfigFiles map[string]string
+	// VM plugins installed on all nodes
+	vmPlugins []node.VMPlugin
 	// if true, for ports given in conf that are already taken, assign new random ones
 	reassignPortsIfUsed bool
 	// if true, direct this node's Stdout to os.Stdout
@@ -122,6 +137,77 @@ type localNetwork struct {
 	redirectStderr bool
 	// map from subnet id to elastic subnet tx id
 	subnetID2ElasticSubnetID map[ids.ID]ids.ID
+	// publishes network events to subscribers
+	events eventBroadcaster
+	// publishes the lines output by the node processes to log streams
+	logs logBroadcaster
+	// node name --> file the node output is written to, if asked to
+	outputFiles map[string]*lumberjack.Logger
+	// node name --> ports reserved for the node
+	nodePorts map[string][]uint16
+	// if not nil, leaks are verified on Stop
+	leakCheck *network.LeakCheckConfig
+	// if not nil, node APIs are served over HTTPS with certs signed by this CA
+	apiCA *apiCA
+	// if not nil, node API clients retry requests on transient errors
+	apiRetry *network.APIRetryConfig
+	// if not nil, node API clients send their requests through it
+	apiTransport http.RoundTripper
+	// if not nil, receives node start progress
+	onProgress network.ProgressFunc
+	// if not nil, used for health check messages
+	healthLogger logging.Logger
+	// if not nil, gives the logger used for messages about a node
+	nodeLogger func(nodeName string) logging.Logger
+	// node name --> start phases already reported for the node
+	reportedPhases map[string]set.Set[network.StartPhase]
+	// reported progress not yet given to [onProgress]
+	pendingProgress []network.Progress
+	// signaled when [pendingProgress] gets new elements
+	progressCh chan struct{}
+	// guards [reportedPhases] and [pendingProgress]
+	progressLock sync.Mutex
+	// node name --> records of its stopped processes
+	nodeHistory map[string][]network.NodeHistory
+	// how the nodes were stopped by Stop(). Nil until then.
+	stopResult *network.StopResult
+	// conditions of the traffic between nodes
+	linkConditions map[link]network.LinkConditions
+	// shapes the node traffic. Nil until link conditions are set.
+	linkShaper    linkShaper
+	newLinkShaper func(logging.Logger) (linkShaper, error)
+	// where the connections of the node processes are looked up
+	procDir string
+	// node name --> paths of the node directories, kept after the node is removed
+	nodePaths map[string]network.NodeArtifactPaths
+	// if not nil, limits node operations
+	nodeOps *nodeOpsLimiter
+	// name, description and tags of the network
+	metadata network.Metadata
+	// active chaos window. nil if none.
+	chaos *chaosWindow
+	// configs of the nodes not started because of a failure on network creation
+	pendingNodeConfigs []node.Config
+	// if not nil, nodes are started in waves on network creation
+	startupWaves *network.StartupWavesConfig
+	// true if the network was found healthy, and no failure was seen since
+	healthyReported atomic.Bool
+	// if > 0, open files limit of the node processes
+	nodeOpenFilesLimit uint64
+	// gets the binaries of the nodes configured by binary version
+	binaries *binaries.Downloader
+	// if true, nodes given flags unknown to their binary fail to start
+	strictFlags bool
+	// if > 0, number of healthy nodes for Healthy to succeed
+	healthQuorum int
+	// if > 0, how often nodes are asked for their health on health checks
+	healthPollInterval time.Duration
+	// if > 0, max time each node is given to become healthy on health checks
+	nodeHealthTimeout time.Duration
+	// if > 0, bootstrap beacon connection timeout of the nodes
+	beaconConnectionTimeout time.Duration
+	// if not empty, dir shared by all the nodes
+	sharedDir string
 }
 
 type deprecatedFlagEsp struct {
@@ -212,6 +298,7 @@ func init() {
 	}
 
 	defaultNetworkConfig = network.Config{
+		Version:     network.CurrentConfigVersion,
 		NodeConfigs: make([]node.Config, DefaultNumNodes),
 		Flags:       flags,
 		Genesis:     string(updatedGenesis),
@@ -263,8 +350,11 @@ func init() {
 // Files (e.g. logs, databases) default to being written at directory [rootDir].
 // If there isn't a directory at [dir] one will be created.
 // If len([dir]) == 0, files will be written underneath a new temporary directory.
-// Snapshots are saved to snapshotsDir, defaults to defaultSnapshotsDir if not given
+// Snapshots are saved to snapshotsDir, defaults to defaultSnapshotsDir if not given.
+// If [rootDir] is given, no files are written out of it until a snapshot is saved.
+// [ctx] bounds the start of the network nodes, but not the network once started.
 func NewNetwork(
+	ctx context.Context,
 	log logging.Logger,
 	networkConfig network.Config,
 	rootDir string,
@@ -273,15 +363,16 @@ func NewNetwork(
 	redirectStdout bool,
 	redirectStderr bool,
 ) (network.Network, error) {
+	npc := &nodeProcessCreator{
+		colorPicker: utils.NewColorPicker(),
+		log:         log,
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+	}
 	net, err := newNetwork(
 		log,
 		api.NewAPIClient,
-		&nodeProcessCreator{
-			colorPicker: utils.NewColorPicker(),
-			log:         log,
-			stdout:      os.Stdout,
-			stderr:      os.Stderr,
-		},
+		npc,
 		rootDir,
 		snapshotsDir,
 		reassignPortsIfUsed,
@@ -291,7 +382,37 @@ func NewNetwork(
 	if err != nil {
 		return net, err
 	}
-	return net, net.loadConfig(context.Background(), networkConfig)
+	npc.nodeLog = net.nodeLog
+	npc.output = net.nodeOutput
+	return net, net.loadConfig(ctx, networkConfig)
+}
+
+// NewNetworkWithProcessCreator is like NewNetwork, but the node processes
+// are launched with [nodeProcessCreator], eg to run them in containers.
+// Output redirection is up to [nodeProcessCreator].
+func NewNetworkWithProcessCreator(
+	ctx context.Context,
+	log logging.Logger,
+	networkConfig network.Config,
+	rootDir string,
+	snapshotsDir string,
+	reassignPortsIfUsed bool,
+	nodeProcessCreator NodeProcessCreator,
+) (network.Network, error) {
+	net, err := newNetwork(
+		log,
+		api.NewAPIClient,
+		nodeProcessCreator,
+		rootDir,
+		snapshotsDir,
+		reassignPortsIfUsed,
+		false,
+		false,
+	)
+	if err != nil {
+		return net, err
+	}
+	return net, net.loadConfig(ctx, networkConfig)
 }
 
 // See NewNetwork.
@@ -320,11 +441,13 @@ func newNetwork(
 			return nil, err
 		}
 	}
+	// the snapshots dir is created on the first snapshot save, so nothing
+	// is written out of the root dir (eg into a read only home dir) unless
+	// snapshots are saved
 	if snapshotsDir == "" {
 		snapshotsDir = defaultSnapshotsDir
 	}
-	// create the snapshots dir if not present
-	err = os.MkdirAll(snapshotsDir, os.ModePerm)
+	binaryDownloader, err := binaries.NewDownloader(log, "", "")
 	if err != nil {
 		return nil, err
 	}
@@ -343,6 +466,14 @@ func newNetwork(
 		redirectStdout:           redirectStdout,
 		redirectStderr:           redirectStderr,
 		subnetID2ElasticSubnetID: map[ids.ID]ids.ID{},
+		reportedPhases:           map[string]set.Set[network.StartPhase]{},
+		progressCh:               make(chan struct{}, 1),
+		nodeHistory:              map[string][]network.NodeHistory{},
+		nodePaths:                map[string]network.NodeArtifactPaths{},
+		binaries:                 binaryDownloader,
+		linkConditions:           map[link]network.LinkConditions{},
+		newLinkShaper:            newLinkShaper,
+		procDir:                  procDir,
 	}
 	return net, nil
 }
@@ -367,6 +498,7 @@ func newNetwork(
 // * NodeID-GWPcbFJZFfZreETSoWjPimr846mXEKCtu
 // * NodeID-P7oB2McjBGgW2NXXWVYjV8JEDFoW9xDE5
 func NewDefaultNetwork(
+	ctx context.Context,
 	log logging.Logger,
 	binaryPath string,
 	reassignPortsIfUsed bool,
@@ -374,10 +506,12 @@ func NewDefaultNetwork(
 	redirectStderr bool,
 ) (network.Network, error) {
 	config := NewDefaultConfig(binaryPath)
-	return NewNetwork(log, config, "", "", reassignPortsIfUsed, redirectStdout, redirectStderr)
+	return NewNetwork(ctx, log, config, "", "", reassignPortsIfUsed, redirectStdout, redirectStderr)
 }
 
-// NewDefaultConfig creates a new default network config
+// NewDefaultConfig creates a new default network config.
+// Genesis, flags, staking keys and C-Chain config are taken from defaults
+// embedded in the binary, so no files on disk are needed.
 func NewDefaultConfig(binaryPath string) network.Config {
 	config := defaultNetworkConfig
 	config.BinaryPath = binaryPath
@@ -440,10 +574,68 @@ func NewDefaultConfigNNodes(binaryPath string, numNodes uint32) (network.Config,
 	return netConfig, nil
 }
 
+// Loads [networkConfig] and starts the nodes of the network.
+// If the load fails, the network is stopped.
 func (ln *localNetwork) loadConfig(ctx context.Context, networkConfig network.Config) error {
+	// nodes started may be restarted or removed in the background
+	// (eg by their restart policy) before the load ends
+	ln.lock.Lock()
+	err := ln.applyConfig(ctx, networkConfig)
+	ln.lock.Unlock()
+
+	return ln.stopOnLoadError(ctx, err)
+}
+
+// Stops the network if [err], the error of its load, is not nil, unless the
+// nodes started were kept (see network.Config.KeepPartialStart).
+// Returns [err].
+func (ln *localNetwork) stopOnLoadError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	ln.lock.RLock()
+	partialStartKept := ln.pendingNodeConfigs != nil
+	ln.lock.RUnlock()
+	if partialStartKept {
+		return err
+	}
+	ln.log.Info("rolling back network start", zap.Error(err))
+	if stopErr := ln.Stop(ctx); stopErr != nil {
+		ln.log.Debug("error stopping network", zap.Error(stopErr))
+	}
+	return err
+}
+
+// Starts the goroutines watching the network as given by [networkConfig].
+// They run until the network is stopped.
+func (ln *localNetwork) startWatchers(networkConfig network.Config) {
+	go ln.watchClock()
+	if networkConfig.TTL > 0 {
+		go ln.expireAfter(networkConfig.TTL)
+	}
+	if networkConfig.ChainEvents {
+		go ln.watchChainBootstraps()
+	}
+	if networkConfig.DiskUsageThreshold > 0 {
+		go ln.watchDiskUsage(networkConfig.DiskUsageThreshold)
+	}
+	if networkConfig.Notifications != nil {
+		ln.startNotifier(*networkConfig.Notifications)
+	}
+}
+
+// Applies [networkConfig] and starts the nodes of the network.
+// On error, the network is left to be stopped by the caller.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) applyConfig(ctx context.Context, networkConfig network.Config) error {
 	if err := networkConfig.Validate(); err != nil {
 		return fmt.Errorf("config failed validation: %w", err)
 	}
+	if networkConfig.NodeOpenFilesLimit > 0 {
+		if err := checkOpenFilesLimit(networkConfig.NodeOpenFilesLimit); err != nil {
+			return err
+		}
+	}
 	ln.log.Info("creating network", zap.Int("node-num", len(networkConfig.NodeConfigs)))
 
 	ln.genesis = []byte(networkConfig.Genesis)
@@ -482,6 +674,60 @@ func (ln *localNetwork) loadConfig(ctx context.Context, networkConfig network.Co
 	if ln.subnetConfigFiles == nil {
 		ln.subnetConfigFiles = map[string]string{}
 	}
+	ln.vmPlugins = networkConfig.VMPlugins
+	ln.leakCheck = networkConfig.LeakCheck
+	ln.onProgress = networkConfig.OnProgress
+	if ln.onProgress != nil {
+		go ln.deliverProgress()
+	}
+	ln.healthLogger = networkConfig.HealthLogger
+	ln.nodeLogger = networkConfig.NodeLogger
+	if networkConfig.NodeOps != nil {
+		ln.nodeOps = newNodeOpsLimiter(networkConfig.NodeOps)
+	}
+	ln.nodeOpenFilesLimit = networkConfig.NodeOpenFilesLimit
+	ln.strictFlags = networkConfig.StrictFlags
+	ln.healthQuorum = networkConfig.HealthQuorum
+	ln.healthPollInterval = networkConfig.HealthPollInterval
+	ln.nodeHealthTimeout = networkConfig.NodeHealthTimeout
+	ln.beaconConnectionTimeout = networkConfig.BeaconConnectionTimeout
+	if networkConfig.SharedDir {
+		sharedDir := filepath.Join(ln.rootDir, sharedDirName)
+		if err := os.MkdirAll(sharedDir, 0o750); err != nil {
+			return fmt.Errorf("couldn't create shared dir: %w", err)
+		}
+		ln.sharedDir = sharedDir
+	}
+	if networkConfig.APIRetry != nil {
+		ln.apiRetry = withAPIRetryDefaults(*networkConfig.APIRetry)
+	}
+	ln.apiTransport = networkConfig.APITransport
+	if networkConfig.StartupWaves != nil {
+		startupWaves := *networkConfig.StartupWaves
+		if startupWaves.HealthTimeout == 0 {
+			startupWaves.HealthTimeout = network.DefaultWaveHealthTimeout
+		}
+		ln.startupWaves = &startupWaves
+	}
+	ln.metadata = networkConfig.Metadata
+	if ln.metadata.Name != "" {
+		ln.log.Info("network metadata",
+			zap.String("name", ln.metadata.Name),
+			zap.String("description", ln.metadata.Description),
+			zap.Any("tags", ln.metadata.Tags),
+		)
+	}
+	if networkConfig.APITLS {
+		ln.apiCA, err = newAPICA()
+		if err != nil {
+			return err
+		}
+		caCertPath := filepath.Join(ln.rootDir, apiCACertFileName)
+		if err := os.WriteFile(caCertPath, ln.apiCA.certPEM, 0o600); err != nil {
+			return fmt.Errorf("couldn't write api CA cert file: %w", err)
+		}
+		ln.log.Info("node APIs use TLS", zap.String("ca-cert", caCertPath))
+	}
 
 	// Sort node configs so beacons start first
 	var nodeConfigs []node.Config
@@ -496,19 +742,89 @@ func (ln *localNetwork) loadConfig(ctx context.Context, networkConfig network.Co
 		}
 	}
 
-	for _, nodeConfig := range nodeConfigs {
-		if _, err := ln.addNode(nodeConfig); err != nil {
-			if err := ln.stop(ctx); err != nil {
-				// Clean up nodes already created
-				ln.log.Debug("error stopping network", zap.Error(err))
-			}
-			return fmt.Errorf("error adding node %s: %w", nodeConfig.Name, err)
+	for i := range nodeConfigs {
+		if err := ln.setVersionBinaryPath(ctx, &nodeConfigs[i]); err != nil {
+			return err
+		}
+	}
+	if err := ln.checkBinaryVersions(nodeConfigs); err != nil {
+		return err
+	}
+
+	if started, err := ln.startNodes(ctx, nodeConfigs); err != nil {
+		if networkConfig.KeepPartialStart {
+			ln.pendingNodeConfigs = nodeConfigs[started:]
+			ln.log.Warn("network partially started, keeping started nodes",
+				zap.Int("started", started),
+				zap.Int("pending", len(ln.pendingNodeConfigs)),
+				zap.Error(err),
+			)
+			ln.startWatchers(networkConfig)
+			return err
 		}
+		return err
 	}
 
+	for _, spec := range networkConfig.LinkConditions {
+		if err := ln.setLinkConditions(ctx, spec); err != nil {
+			return fmt.Errorf("couldn't set link conditions: %w", err)
+		}
+	}
+	ln.startWatchers(networkConfig)
 	return nil
 }
 
+// Starts the nodes with the given configs, in waves if [ln.startupWaves]
+// is set. Before each wave, waits for the nodes already started to become
+// healthy. Returns the number of nodes started, and an error wrapping
+// network.ErrPartialStart if not all of them could be started.
+func (ln *localNetwork) startNodes(ctx context.Context, nodeConfigs []node.Config) (int, error) {
+	for i, nodeConfig := range nodeConfigs {
+		if ln.startupWaves != nil && i%ln.startupWaves.WaveSize == 0 && len(ln.nodes) != 0 {
+			ln.log.Info("waiting for started nodes to become healthy before next startup wave",
+				zap.Int("started", len(ln.nodes)),
+				zap.Int("pending", len(nodeConfigs)-i),
+			)
+			waveCtx, cancel := context.WithTimeout(ctx, ln.startupWaves.HealthTimeout)
+			err := ln.healthy(waveCtx)
+			cancel()
+			if err != nil {
+				return i, fmt.Errorf("%w: started nodes not healthy before startup wave: %w", network.ErrPartialStart, err)
+			}
+		}
+		if _, err := ln.addNode(ctx, nodeConfig); err != nil {
+			return i, fmt.Errorf("%w: error adding node %s: %w", network.ErrPartialStart, nodeConfig.Name, err)
+		}
+	}
+	return len(nodeConfigs), nil
+}
+
+// See network.Network
+func (ln *localNetwork) ResumeCreate(ctx context.Context) error {
+	_, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+
+	// the failure may have happened after the node was added
+	if len(ln.pendingNodeConfigs) != 0 {
+		if _, ok := ln.nodes[ln.pendingNodeConfigs[0].Name]; ok {
+			ln.pendingNodeConfigs = ln.pendingNodeConfigs[1:]
+		}
+	}
+	started, err := ln.startNodes(ctx, ln.pendingNodeConfigs)
+	ln.pendingNodeConfigs = ln.pendingNodeConfigs[started:]
+	return err
+}
+
 // See network.Network
 func (ln *localNetwork) GetNetworkID() (uint32, error) {
 	ln.lock.Lock()
@@ -522,7 +838,13 @@ func (ln *localNetwork) GetNetworkID() (uint32, error) {
 }
 
 // See network.Network
-func (ln *localNetwork) AddNode(nodeConfig node.Config) (node.Node, error) {
+func (ln *localNetwork) AddNode(ctx context.Context, nodeConfig node.Config) (node.Node, error) {
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer endNodeOp()
+
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
 
@@ -530,11 +852,18 @@ func (ln *localNetwork) AddNode(nodeConfig node.Config) (node.Node, error) {
 		return nil, network.ErrStopped
 	}
 
-	return ln.addNode(nodeConfig)
+	if err := ln.setVersionBinaryPath(ctx, &nodeConfig); err != nil {
+		return nil, err
+	}
+	return ln.addNode(ctx, nodeConfig)
 }
 
+// Nothing is started if [ctx] is done before the node process is.
 // Assumes [ln.lock] is held and [ln.Stop] hasn't been called.
-func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
+func (ln *localNetwork) addNode(ctx context.Context, nodeConfig node.Config) (node.Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if nodeConfig.Flags == nil {
 		nodeConfig.Flags = map[string]interface{}{}
 	}
@@ -552,6 +881,11 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 	if nodeConfig.BinaryPath == "" {
 		nodeConfig.BinaryPath = ln.binaryPath
 	}
+	if validator, ok := ln.nodeProcessCreator.(nodeConfigValidator); ok {
+		if err := validator.ValidateNodeConfig(nodeConfig); err != nil {
+			return nil, err
+		}
+	}
 	for k, v := range ln.chainConfigFiles {
 		_, ok := nodeConfig.ChainConfigFiles[k]
 		if !ok {
@@ -570,14 +904,34 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 			nodeConfig.SubnetConfigFiles[k] = v
 		}
 	}
+	if err := addNetworkVMPlugins(ln.vmPlugins, &nodeConfig); err != nil {
+		return nil, err
+	}
+	// the node preset takes precedence over the network flags
+	if err := nodeConfig.ApplyResourcePreset(); err != nil {
+		return nil, err
+	}
+	if err := nodeConfig.ApplyObserverRole(); err != nil {
+		return nil, err
+	}
 	addNetworkFlags(ln.flags, nodeConfig.Flags)
+	if ln.beaconConnectionTimeout > 0 {
+		if _, ok := nodeConfig.Flags[config.BootstrapBeaconConnectionTimeoutKey]; !ok {
+			nodeConfig.Flags[config.BootstrapBeaconConnectionTimeoutKey] = ln.beaconConnectionTimeout.String()
+		}
+	}
+	if ln.nodeOpenFilesLimit > 0 {
+		if err := addOpenFilesLimitFlag(nodeConfig, ln.nodeOpenFilesLimit); err != nil {
+			return nil, err
+		}
+	}
 
 	// it shouldn't happen that just one is empty, most probably both,
 	// but in any case if just one is empty it's unusable so we just assign a new one.
 	if nodeConfig.StakingCert == "" || nodeConfig.StakingKey == "" {
-		stakingCert, stakingKey, err := staking.NewCertAndKeyBytes()
+		stakingKey, stakingCert, _, err := utils.NewStakingIdentity()
 		if err != nil {
-			return nil, fmt.Errorf("couldn't generate staking Cert/Key: %w", err)
+			return nil, err
 		}
 		nodeConfig.StakingCert = string(stakingCert)
 		nodeConfig.StakingKey = string(stakingKey)
@@ -598,6 +952,17 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 
 	isPausedNode := ln.isPausedNode(&nodeConfig)
 
+	// if the node isn't added, release its ports and close its output file,
+	// unless they are the ones of the paused node of the same name
+	success := false
+	defer func() {
+		if success || isPausedNode {
+			return
+		}
+		ln.closeNodeOutputFile(nodeConfig.Name)
+		ln.releaseNodePorts(nodeConfig.Name)
+	}()
+
 	nodeDir, err := makeNodeDir(ln.log, ln.rootDir, nodeConfig.Name)
 	if err != nil {
 		return nil, err
@@ -611,16 +976,30 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		}
 	}
 
+	// a new start of the node
+	ln.resetProgress(nodeConfig.Name)
+
+	// Check the binary before running it
+	if err := verifyNodeBinary(nodeConfig); err != nil {
+		return nil, err
+	}
 	// Get node version
 	nodeSemVer, err := ln.getNodeSemVer(nodeConfig)
 	if err != nil {
 		return nil, err
 	}
+	if ln.strictFlags {
+		if err := checkFlagNames(ln.nodeProcessCreator, nodeSemVer, nodeConfig, configFile); err != nil {
+			return nil, err
+		}
+	}
+	ln.reportProgress(nodeConfig.Name, network.PhaseBinaryChecked)
 
 	nodeData, err := ln.buildArgs(nodeSemVer, configFile, nodeDir, &nodeConfig)
 	if err != nil {
 		return nil, err
 	}
+	ln.reportProgress(nodeConfig.Name, network.PhaseFilesWritten)
 
 	// Parse this node's ID
 	nodeID, err := utils.ToNodeID([]byte(nodeConfig.StakingKey), []byte(nodeConfig.StakingCert))
@@ -628,16 +1007,46 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		return nil, fmt.Errorf("couldn't get node ID: %w", err)
 	}
 
+	nodeLog := ln.nodeLog(nodeConfig.Name)
+
+	proxy, err := ln.newNodeAPIProxy(nodeLog, nodeConfig.Name, nodeData)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseStartSlot, err := ln.acquireStartSlot(ctx)
+	if err != nil {
+		if proxy != nil {
+			_ = proxy.close()
+		}
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		releaseStartSlot()
+		if proxy != nil {
+			_ = proxy.close()
+		}
+		return nil, err
+	}
+
+	outputFilePath := ln.setNodeOutputFile(nodeConfig.Name, nodeDir, nodeConfig.OutputFile)
+
 	// Start the AvalancheGo node and pass it the flags defined above
-	nodeProcess, err := ln.nodeProcessCreator.NewNodeProcess(nodeConfig, nodeData.args...)
+	nodeProcess, err := ln.nodeProcessCreator.NewNodeProcess(ln.nodeProcessConfig(nodeConfig), nodeData.args...)
 	if err != nil {
+		releaseStartSlot()
+		if proxy != nil {
+			_ = proxy.close()
+		}
 		return nil, fmt.Errorf(
 			"couldn't create new node process with binary %q and args %v: %w",
 			nodeConfig.BinaryPath, nodeData.args, err,
 		)
 	}
+	ln.reportProgress(nodeConfig.Name, network.PhaseProcessStarted)
 
-	ln.log.Info(
+	nodeLog.Info(
 		"adding node",
 		zap.String("node-name", nodeConfig.Name),
 		zap.String("node-dir", nodeData.dataDir),
@@ -647,7 +1056,7 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		zap.Uint16("api-port", nodeData.apiPort),
 	)
 
-	ln.log.Debug(
+	nodeLog.Debug(
 		"starting node",
 		zap.String("name", nodeConfig.Name),
 		zap.String("binaryPath", nodeConfig.BinaryPath),
@@ -659,7 +1068,10 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		name:          nodeConfig.Name,
 		nodeID:        nodeID,
 		networkID:     ln.networkID,
-		client:        ln.newAPIClientF(nodeData.publicIP, nodeData.apiPort),
+		publicIP:      nodeData.publicIP,
+		apiProxy:      proxy,
+		log:           nodeLog,
+		flags:         nodeFlags(configFile, nodeData.flags),
 		process:       nodeProcess,
 		apiPort:       nodeData.apiPort,
 		p2pPort:       nodeData.p2pPort,
@@ -667,12 +1079,43 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		dataDir:       nodeData.dataDir,
 		dbDir:         nodeData.dbDir,
 		logsDir:       nodeData.logsDir,
+		outputFile:    outputFilePath,
+		ports:         nodeData.portAssignment,
 		config:        nodeConfig,
 		pluginDir:     nodeData.pluginDir,
+		binaryVersion: nodeSemVer,
 		httpHost:      nodeData.httpHost,
 		attachedPeers: map[string]peer.Peer{},
+		startTime:     time.Now(),
+
+		nodeProcessCreator: ln.nodeProcessCreator,
 	}
+	node.client = ln.nodeAPIClientF(nodeConfig)(node.apiClientAddr())
 	ln.nodes[node.name] = node
+	success = true
+	ln.nodePaths[node.name] = network.NodeArtifactPaths{
+		DataDir:   node.dataDir,
+		DbDir:     node.dbDir,
+		LogsDir:   node.logsDir,
+		PluginDir: node.pluginDir,
+	}
+	if watcher, ok := nodeProcess.(processExitWatcher); ok {
+		go ln.watchNodeProcess(node, watcher)
+	}
+	if ln.onProgress != nil {
+		go ln.watchNodeStart(node)
+	}
+	if nodeConfig.TTL > 0 || nodeConfig.RemoveWhen != nil {
+		go ln.removeEphemeralNode(node, nodeConfig.TTL, nodeConfig.RemoveWhen)
+	}
+	if ln.nodeOps != nil && ln.nodeOps.startSlots != nil {
+		go ln.releaseStartSlotWhenStarted(node, releaseStartSlot)
+	}
+	ln.publishEvent(network.Event{
+		Type:     network.EventNodeStarted,
+		NodeName: node.name,
+		Message:  fmt.Sprintf("started with API port %d and P2P port %d", node.apiPort, node.p2pPort),
+	})
 	// If this node is a beacon, add its IP/ID to the beacon lists.
 	// Note that we do this *after* we set this node's bootstrap IPs/IDs
 	// so this node won't try to use itself as a beacon.
@@ -685,16 +1128,150 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 	return node, err
 }
 
+// Returns the API client factory of the node of [nodeConfig]: its own one
+// if given, or else the network one
+func (ln *localNetwork) nodeAPIClientF(nodeConfig node.Config) api.NewAPIClientF {
+	if nodeConfig.NewAPIClient != nil {
+		return nodeConfig.NewAPIClient
+	}
+	return ln.newAPIClientF
+}
+
+// Returns a proxy for the node API, if needed for the API clients to
+// reach the node (eg to add auth tokens, to trust the TLS CA, or to use
+// a custom transport), or nil.
+func (ln *localNetwork) newNodeAPIProxy(log logging.Logger, nodeName string, nodeData buildArgsReturn) (*apiProxy, error) {
+	if nodeData.apiAuthPassword == "" && ln.apiCA == nil && ln.apiRetry == nil && ln.apiTransport == nil {
+		return nil, nil
+	}
+	target := &url.URL{
+		Scheme: "http",
+		Host:   net.JoinHostPort(nodeData.publicIP, strconv.Itoa(int(nodeData.apiPort))),
+	}
+	transport := http.DefaultTransport
+	if ln.apiTransport != nil {
+		transport = ln.apiTransport
+	}
+	if ln.apiCA != nil {
+		target.Scheme = "https"
+		transport = ln.apiCA.transport(transport)
+	}
+	if nodeData.apiAuthPassword != "" {
+		transport = newAPIAuthTransport(transport, target, nodeData.apiAuthPassword)
+	}
+	if ln.apiRetry != nil {
+		transport = newAPIRetryTransport(transport, *ln.apiRetry)
+	}
+	return newAPIProxy(log, nodeName, target, transport)
+}
+
+// Returns the logger for health check messages
+func (ln *localNetwork) healthLog() logging.Logger {
+	if ln.healthLogger != nil {
+		return ln.healthLogger
+	}
+	return ln.log
+}
+
+// Returns the logger for messages about [nodeName]
+func (ln *localNetwork) nodeLog(nodeName string) logging.Logger {
+	if ln.nodeLogger != nil {
+		if log := ln.nodeLogger(nodeName); log != nil {
+			return log
+		}
+	}
+	return ln.log
+}
+
 // See network.Network
 func (ln *localNetwork) Healthy(ctx context.Context) error {
 	ln.lock.RLock()
 	defer ln.lock.RUnlock()
 
-	return ln.healthy(ctx)
+	err := ln.healthyQuorum(ctx, ln.healthQuorum)
+	if err == nil && !ln.healthyReported.Swap(true) {
+		ln.publishEvent(network.Event{
+			Type:    network.EventNetworkHealthy,
+			Message: "network healthy",
+		})
+	}
+	return err
+}
+
+// See network.Network
+func (ln *localNetwork) NodeHealthy(ctx context.Context, nodeName string) error {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	node, ok := ln.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	if node.paused {
+		return fmt.Errorf("node %q is paused", nodeName)
+	}
+	ctx, cancel := ln.withStopCancel(ctx)
+	defer cancel()
+	return ln.nodeHealthy(ctx, node)
+}
+
+// See network.Network
+func (ln *localNetwork) Status(ctx context.Context) (map[string]network.NodeState, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+	type stateResult struct {
+		nodeName string
+		state    network.NodeState
+	}
+	resultsCh := make(chan stateResult, len(ln.nodes))
+	for nodeName, node := range ln.nodes {
+		nodeName, node := nodeName, node
+		go func() {
+			resultsCh <- stateResult{nodeName: nodeName, state: nodeState(ctx, node)}
+		}()
+	}
+	states := make(map[string]network.NodeState, len(ln.nodes))
+	for range ln.nodes {
+		result := <-resultsCh
+		states[result.nodeName] = result.state
+	}
+	return states, nil
 }
 
+// Returns the state of [node], asking it once for its health if running
+func nodeState(ctx context.Context, node *localNode) network.NodeState {
+	if node.paused || node.Status() != status.Running {
+		return network.NodeStopped
+	}
+	health, err := node.GetAPIClient().HealthAPI().Health(ctx, nil)
+	switch {
+	case err == nil && health.Healthy:
+		node.healthSeen.Store(true)
+		return network.NodeHealthy
+	case node.healthSeen.Load():
+		return network.NodeUnhealthy
+	default:
+		return network.NodeStarting
+	}
+}
+
+// Returns nil once all the nodes not paused are healthy
 func (ln *localNetwork) healthy(ctx context.Context) error {
-	ln.log.Info("checking local network healthiness", zap.Int("num-of-nodes", len(ln.nodes)))
+	return ln.healthyQuorum(ctx, 0)
+}
+
+// Returns nil once [quorum] of the nodes not paused are healthy, or all
+// of them if [quorum] is 0 or larger than their number.
+// The nodes not healthy by then are logged as stragglers.
+func (ln *localNetwork) healthyQuorum(ctx context.Context, quorum int) error {
+	ln.healthLog().Info("checking local network healthiness", zap.Int("num-of-nodes", len(ln.nodes)))
 
 	// Return unhealthy if the network is stopped
 	if ln.stopCalled() {
@@ -703,54 +1280,188 @@ func (ln *localNetwork) healthy(ctx context.Context) error {
 
 	// Derive a new context that's cancelled when Stop is called,
 	// so that calls to Healthy() below immediately return.
-	ctx, cancel := context.WithCancel(ctx)
+	ctx, cancel := ln.withStopCancel(ctx)
 	defer cancel()
-	go func(ctx context.Context) {
+
+	nodes := []*localNode{}
+	for _, node := range ln.nodes {
+		if node.paused {
+			// no health check for paused nodes
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	if quorum <= 0 || quorum > len(nodes) {
+		quorum = len(nodes)
+	}
+	type healthResult struct {
+		nodeName string
+		err      error
+	}
+	resultsCh := make(chan healthResult, len(nodes))
+	for _, node := range nodes {
+		node := node
+		go func() {
+			resultsCh <- healthResult{
+				nodeName: node.GetName(),
+				err:      ln.nodeHealthy(ctx, node),
+			}
+		}()
+	}
+	// Wait until enough nodes are ready, too many failed, or timeout.
+	// The remaining checks are then cancelled and waited for.
+	var err error
+	healthyNodes := set.NewSet[string](len(nodes))
+	pending, failed := len(nodes), 0
+	for pending > 0 && healthyNodes.Len() < quorum {
+		result := <-resultsCh
+		pending--
+		if result.err == nil {
+			healthyNodes.Add(result.nodeName)
+			continue
+		}
+		failed++
+		if failed > len(nodes)-quorum {
+			err = result.err
+			break
+		}
+	}
+	cancel()
+	for ; pending > 0; pending-- {
+		<-resultsCh
+	}
+	if err != nil {
+		return err
+	}
+	if healthyNodes.Len() < len(nodes) {
+		stragglers := []string{}
+		for _, node := range nodes {
+			if !healthyNodes.Contains(node.GetName()) {
+				stragglers = append(stragglers, node.GetName())
+			}
+		}
+		sort.Strings(stragglers)
+		ln.healthLog().Warn("network healthy with a quorum of nodes",
+			zap.Int("healthy", healthyNodes.Len()),
+			zap.Int("quorum", quorum),
+			zap.Strings("stragglers", stragglers),
+		)
+	}
+	return nil
+}
+
+// Every [healthCheckFreq] (or the configured health poll interval),
+// queries [node] for its health status, until it's healthy, [ctx] is
+// done, the configured node health timeout passes or the node stops
+func (ln *localNetwork) nodeHealthy(ctx context.Context, node *localNode) error {
+	nodeName := node.GetName()
+	pollInterval := healthCheckFreq
+	if ln.healthPollInterval > 0 {
+		pollInterval = ln.healthPollInterval
+	}
+	if ln.nodeHealthTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ln.nodeHealthTimeout)
+		defer cancel()
+	}
+	for {
+		if node.Status() != status.Running {
+			// If we had stopped this node ourselves, it wouldn't be in [ln.nodes].
+			// Since it is, it means the node stopped unexpectedly.
+			return &unhealthyNodeError{
+				nodeName: nodeName,
+				msg:      fmt.Sprintf("node %q stopped unexpectedly", nodeName),
+			}
+		}
+		health, err := node.GetAPIClient().HealthAPI().Health(ctx, nil)
+		if err == nil {
+			ln.reportProgress(nodeName, network.PhaseAPIReachable)
+		}
+		if err == nil && health.Healthy {
+			node.healthSeen.Store(true)
+			ln.healthLog().Debug("node became healthy", zap.String("name", nodeName))
+			ln.reportProgress(nodeName, network.PhaseBootstrapped)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return &unhealthyNodeError{
+				nodeName: nodeName,
+				msg:      fmt.Sprintf("node %q failed to become healthy within timeout, or network stopped", nodeName),
+			}
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Returns a context derived from [ctx] that is cancelled when Stop is called
+func (ln *localNetwork) withStopCancel(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
 		// This goroutine runs until [ln.Stop] is called
-		// or this function returns.
+		// or the context is cancelled.
 		select {
 		case <-ln.onStopCh:
 			cancel()
 		case <-ctx.Done():
 		}
-	}(ctx)
+	}()
+	return ctx, cancel
+}
+
+// See network.Network
+func (ln *localNetwork) AwaitValidatorSet(ctx context.Context, expected []ids.NodeID) error {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+
+	ln.healthLog().Info("waiting for primary network validator set", zap.Int("num-of-validators", len(expected)))
+
+	ctx, cancel := ln.withStopCancel(ctx)
+	defer cancel()
 
+	expectedSet := set.Of(expected...)
 	errGr, ctx := errgroup.WithContext(ctx)
 	for _, node := range ln.nodes {
 		if node.paused {
-			// no health check for paused nodes
 			continue
 		}
 		node := node
 		nodeName := node.GetName()
 		errGr.Go(func() error {
-			// Every [healthCheckFreq], query node for health status.
-			// Do this until ctx timeout or network closed.
+			// Every [validatorSetCheckFreq], query node for the current validators.
+			// Do this until they match, ctx timeout or network closed.
 			for {
-				if node.Status() != status.Running {
-					// If we had stopped this node ourselves, it wouldn't be in [ln.nodes].
-					// Since it is, it means the node stopped unexpectedly.
-					return fmt.Errorf("node %q stopped unexpectedly", nodeName)
-				}
-				health, err := node.client.HealthAPI().Health(ctx, nil)
-				if err == nil && health.Healthy {
-					ln.log.Debug("node became healthy", zap.String("name", nodeName))
-					return nil
+				vdrs, err := node.GetAPIClient().PChainAPI().GetCurrentValidators(ctx, avagoconstants.PrimaryNetworkID, nil)
+				if err == nil {
+					vdrsSet := set.NewSet[ids.NodeID](len(vdrs))
+					for _, vdr := range vdrs {
+						vdrsSet.Add(vdr.NodeID)
+					}
+					if vdrsSet.Equals(expectedSet) {
+						ln.healthLog().Debug("node validator set matches", zap.String("name", nodeName))
+						return nil
+					}
 				}
 				select {
 				case <-ctx.Done():
-					return fmt.Errorf("node %q failed to become healthy within timeout, or network stopped", nodeName)
-				case <-time.After(healthCheckFreq):
+					return fmt.Errorf("node %q validator set failed to match within timeout, or network stopped", nodeName)
+				case <-time.After(validatorSetCheckFreq):
 				}
 			}
 		})
 	}
-	// Wait until all nodes are ready or timeout
 	return errGr.Wait()
 }
 
 // See network.Network
-func (ln *localNetwork) GetNode(nodeName string) (node.Node, error) {
+func (ln *localNetwork) GetNode(ctx context.Context, nodeName string) (node.Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	ln.lock.RLock()
 	defer ln.lock.RUnlock()
 
@@ -802,29 +1513,154 @@ func (ln *localNetwork) Stop(ctx context.Context) error {
 			ln.lock.Lock()
 			defer ln.lock.Unlock()
 
+			var resources leakCheckResources
+			if ln.leakCheck != nil {
+				resources = ln.collectLeakCheckResources()
+			}
 			err = ln.stop(ctx)
+			if ln.leakCheck != nil {
+				if leakErr := ln.checkLeaks(resources); leakErr != nil {
+					ln.log.Error("leak check failed", zap.Error(leakErr))
+					if err == nil {
+						err = leakErr
+					}
+				}
+			}
+			ln.endChaos()
+			message := "network stopped"
+			if err != nil {
+				message = fmt.Sprintf("network stopped with errors: %s", err)
+			}
+			ln.publishEvent(network.Event{
+				Type:    network.EventNetworkStopped,
+				Message: message,
+			})
+			ln.events.close()
+			ln.logs.close()
+			for nodeName := range ln.outputFiles {
+				ln.closeNodeOutputFile(nodeName)
+			}
+			for nodeName := range ln.nodePorts {
+				ln.releaseNodePorts(nodeName)
+			}
 		},
 	)
 	return err
 }
 
+// See network.Network
+func (ln *localNetwork) GetMetadata() network.Metadata {
+	metadata := ln.metadata
+	metadata.Tags = maps.Clone(metadata.Tags)
+	return metadata
+}
+
+// See network.Network
+func (ln *localNetwork) GetArtifactPaths() network.ArtifactPaths {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	paths := network.ArtifactPaths{
+		RootDir:      ln.rootDir,
+		SnapshotsDir: ln.snapshotsDir,
+		Nodes:        maps.Clone(ln.nodePaths),
+	}
+	if ln.apiCA != nil {
+		paths.APICACert = filepath.Join(ln.rootDir, apiCACertFileName)
+	}
+	paths.SharedDir = ln.sharedDir
+	return paths
+}
+
+// See network.Network
+func (ln *localNetwork) Events() <-chan network.Event {
+	return ln.events.subscribe()
+}
+
+// Publishes [event] to the network event subscribers
+func (ln *localNetwork) publishEvent(event network.Event) {
+	if !ln.events.publish(event) {
+		ln.log.Debug("event not delivered to some subscriber", zap.String("type", string(event.Type)))
+	}
+}
+
 // Assumes [ln.lock] is held.
 func (ln *localNetwork) stop(ctx context.Context) error {
+	start := time.Now()
+	result := network.StopResult{Nodes: []network.NodeStopResult{}}
 	errs := wrappers.Errs{}
-	for nodeName := range ln.nodes {
+	for nodeName, node := range ln.nodes {
+		paused := node.paused
 		stopCtx, stopCtxCancel := context.WithTimeout(ctx, stopTimeout)
 		if err := ln.removeNode(stopCtx, nodeName); err != nil {
 			ln.log.Error("error stopping node", zap.String("name", nodeName), zap.Error(err))
 			errs.Add(err)
 		}
 		stopCtxCancel()
+		if !paused && node.stopResult != nil {
+			result.Nodes = append(result.Nodes, *node.stopResult)
+		}
+	}
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].Name < result.Nodes[j].Name
+	})
+	result.Duration = time.Since(start)
+	ln.stopResult = &result
+	if ln.linkShaper != nil {
+		if err := ln.linkShaper.close(); err != nil {
+			errs.Add(fmt.Errorf("couldn't restore node links: %w", err))
+		}
+		ln.linkConditions = map[link]network.LinkConditions{}
+	}
+	if ln.sharedDir != "" {
+		if err := os.RemoveAll(ln.sharedDir); err != nil {
+			errs.Add(fmt.Errorf("couldn't remove shared dir: %w", err))
+		}
 	}
 	ln.log.Info("done stopping network")
 	return errs.Err
 }
 
-// Sends a SIGTERM to the given node and removes it from this network.
+// Returns the config the process of node [nodeConfig] is created with,
+// which also gives the network settings for the node process
+func (ln *localNetwork) nodeProcessConfig(nodeConfig node.Config) node.Config {
+	if ln.sharedDir == "" {
+		return nodeConfig
+	}
+	env := maps.Clone(nodeConfig.Env)
+	if env == nil {
+		env = map[string]string{}
+	}
+	env[constants.SharedDirEnvVar] = ln.sharedDir
+	nodeConfig.Env = env
+	return nodeConfig
+}
+
+// Sends a SIGTERM to the given node and removes it from this network,
+// unless the network would lose its validator quorum.
 func (ln *localNetwork) RemoveNode(ctx context.Context, nodeName string) error {
+	return ln.removeNodeOp(ctx, nodeName, false)
+}
+
+// Sends a SIGTERM to the given node and removes it from this network.
+func (ln *localNetwork) ForceRemoveNode(ctx context.Context, nodeName string) error {
+	return ln.removeNodeOp(ctx, nodeName, true)
+}
+
+func (ln *localNetwork) removeNodeOp(ctx context.Context, nodeName string, force bool) error {
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
+	// the nodes are asked before taking the lock
+	if !force {
+		if err := ln.checkQuorum(ctx, nodeName); err != nil {
+			return err
+		}
+	}
+
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
 
@@ -836,31 +1672,106 @@ func (ln *localNetwork) RemoveNode(ctx context.Context, nodeName string) error {
 
 // Assumes [ln.lock] is held.
 func (ln *localNetwork) removeNode(ctx context.Context, nodeName string) error {
-	ln.log.Debug("removing node", zap.String("name", nodeName))
 	node, ok := ln.nodes[nodeName]
 	if !ok {
 		return fmt.Errorf("node %q not found", nodeName)
 	}
+	node.log.Debug("removing node", zap.String("name", nodeName))
 
 	paused := node.paused
 
 	// If the node wasn't a beacon, we don't care
 	_ = ln.bootstraps.RemoveByID(node.nodeID)
 	delete(ln.nodes, nodeName)
+	// remove the node extra files once its process is stopped
+	defer func() {
+		if err := removeExtraFiles(ln.networkID, node.dataDir, node.config); err != nil {
+			node.log.Warn("couldn't remove node extra files", zap.String("name", nodeName), zap.Error(err))
+		}
+		ln.closeNodeOutputFile(nodeName)
+		ln.releaseNodePorts(nodeName)
+	}()
 
 	if !paused {
-		// cchain eth api uses a websocket connection and must be closed before stopping the node,
-		// to avoid errors logs at client
-		node.client.CChainEthAPI().Close()
-		if exitCode := node.process.Stop(ctx); exitCode != 0 {
+		if exitCode := ln.stopNodeProcess(ctx, node); exitCode != 0 {
 			return fmt.Errorf("node %q exited with exit code: %d", nodeName, exitCode)
 		}
 	}
 	return nil
 }
 
+// Kills the process of [nodeName], as in a crash, and removes the node
+// from the network. The node dir is kept as the process left it.
+func (ln *localNetwork) KillNode(ctx context.Context, nodeName string) error {
+	_, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	node, ok := ln.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	node.log.Debug("killing node", zap.String("name", nodeName))
+	_ = ln.bootstraps.RemoveByID(node.nodeID)
+	delete(ln.nodes, nodeName)
+	if !node.paused {
+		// processes are killed right away given a done context
+		killCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ln.stopNodeProcess(killCtx, node)
+	}
+	ln.closeNodeOutputFile(nodeName)
+	ln.releaseNodePorts(nodeName)
+	return nil
+}
+
+// Stops the process of [node], records it in the node history and
+// publishes how it was stopped. Returns the process exit code.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) stopNodeProcess(ctx context.Context, node *localNode) int {
+	// cchain eth api uses a websocket connection and must be closed before stopping the node,
+	// to avoid errors logs at client
+	node.GetAPIClient().CChainEthAPI().Close()
+	node.closeAPIProxy()
+	start := time.Now()
+	exitCode := node.process.Stop(ctx)
+	result := network.NodeStopResult{
+		Name: node.name,
+		// the process is killed once [ctx] is done
+		Killed:   ctx.Err() != nil,
+		ExitCode: exitCode,
+		Duration: time.Since(start),
+	}
+	node.stopResult = &result
+	ln.recordNodeHistory(node, false, exitCode)
+	how := "gracefully"
+	if result.Killed {
+		how = "by killing it"
+	}
+	ln.publishEvent(network.Event{
+		Type:     network.EventNodeStopped,
+		NodeName: node.name,
+		Message:  fmt.Sprintf("stopped %s in %s, exit code %d", how, result.Duration.Round(time.Millisecond), exitCode),
+	})
+	return exitCode
+}
+
 // Sends a SIGTERM to the given node and keeps it in the network with paused state
 func (ln *localNetwork) PauseNode(ctx context.Context, nodeName string) error {
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
 	if ln.stopCalled() {
@@ -871,29 +1782,75 @@ func (ln *localNetwork) PauseNode(ctx context.Context, nodeName string) error {
 
 // Assumes [ln.lock] is held.
 func (ln *localNetwork) pauseNode(ctx context.Context, nodeName string) error {
-	ln.log.Debug("pausing node", zap.String("name", nodeName))
 	node, ok := ln.nodes[nodeName]
 	if !ok {
 		return fmt.Errorf("node %q not found", nodeName)
 	}
+	node.log.Debug("pausing node", zap.String("name", nodeName))
 	if node.paused {
 		return fmt.Errorf("node has been paused already")
 	}
-	// cchain eth api uses a websocket connection and must be closed before stopping the node,
-	// to avoid errors logs at client
-	node.client.CChainEthAPI().Close()
-	if exitCode := node.process.Stop(ctx); exitCode != 0 {
+	exitCode := ln.stopNodeProcess(ctx, node)
+	if exitCode != 0 {
 		return fmt.Errorf("node %q exited with exit code: %d", nodeName, exitCode)
 	}
 	node.paused = true
 	return nil
 }
 
+// Freezes the process of [nodeName] with SIGSTOP, along with its
+// descendants (eg plugins), simulating a stalled node
+func (ln *localNetwork) FreezeNode(ctx context.Context, nodeName string) error {
+	return ln.freezeNode(ctx, nodeName, true)
+}
+
+// Resumes the process of [nodeName] frozen by FreezeNode, with SIGCONT
+func (ln *localNetwork) UnfreezeNode(ctx context.Context, nodeName string) error {
+	return ln.freezeNode(ctx, nodeName, false)
+}
+
+func (ln *localNetwork) freezeNode(ctx context.Context, nodeName string, freeze bool) error {
+	_, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	node, ok := ln.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	if node.paused {
+		return fmt.Errorf("node %q is paused", nodeName)
+	}
+	process, ok := node.process.(processFreezer)
+	if !ok {
+		return errFreezeUnsupported
+	}
+	if freeze {
+		node.log.Debug("freezing node", zap.String("name", nodeName))
+		return process.freeze()
+	}
+	node.log.Debug("unfreezing node", zap.String("name", nodeName))
+	return process.unfreeze()
+}
+
 // Resume previously paused [nodeName] using the same config.
 func (ln *localNetwork) ResumeNode(
 	ctx context.Context,
 	nodeName string,
 ) error {
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
 
@@ -905,7 +1862,7 @@ func (ln *localNetwork) ResumeNode(
 
 // Assumes [ln.lock] is held.
 func (ln *localNetwork) resumeNode(
-	_ context.Context,
+	ctx context.Context,
 	nodeName string,
 ) error {
 	node, ok := ln.nodes[nodeName]
@@ -921,9 +1878,10 @@ func (ln *localNetwork) resumeNode(
 	nodeConfig.Flags[config.LogsDirKey] = node.GetLogsDir()
 	nodeConfig.Flags[config.HTTPPortKey] = int(node.GetAPIPort())
 	nodeConfig.Flags[config.StakingPortKey] = int(node.GetP2PPort())
-	if _, err := ln.addNode(nodeConfig); err != nil {
+	if _, err := ln.addNode(ctx, nodeConfig); err != nil {
 		return err
 	}
+	node.setReplacedBy(ln.nodes[nodeName])
 	return nil
 }
 
@@ -939,9 +1897,18 @@ func (ln *localNetwork) RestartNode(
 	upgradeConfigs map[string]string,
 	subnetConfigs map[string]string,
 ) error {
+	ctx, endNodeOp, err := ln.beginNodeOp(ctx)
+	if err != nil {
+		return err
+	}
+	defer endNodeOp()
+
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
 
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
 	return ln.restartNode(
 		ctx,
 		nodeName,
@@ -954,6 +1921,17 @@ func (ln *localNetwork) RestartNode(
 	)
 }
 
+// See network.Network
+func (ln *localNetwork) UpgradeNode(ctx context.Context, nodeName string, binaryPath string) error {
+	if binaryPath == "" {
+		return errors.New("binary path of the upgrade not given")
+	}
+	if err := ln.RestartNode(ctx, nodeName, binaryPath, "", "", nil, nil, nil); err != nil {
+		return err
+	}
+	return ln.NodeHealthy(ctx, nodeName)
+}
+
 func (ln *localNetwork) restartNode(
 	ctx context.Context,
 	nodeName string,
@@ -973,6 +1951,9 @@ func (ln *localNetwork) restartNode(
 
 	if binaryPath != "" {
 		nodeConfig.BinaryPath = binaryPath
+		nodeConfig.BinaryVersion = ""
+		// the sum was of the previous binary
+		nodeConfig.BinarySHA256 = ""
 	}
 	if pluginDir != "" {
 		nodeConfig.Flags[config.PluginDirKey] = pluginDir
@@ -1007,10 +1988,10 @@ func (ln *localNetwork) restartNode(
 		}
 	}
 
-	if _, err := ln.addNode(nodeConfig); err != nil {
+	if _, err := ln.addNode(ctx, nodeConfig); err != nil {
 		return err
 	}
-
+	node.setReplacedBy(ln.nodes[nodeName])
 	return nil
 }
 
@@ -1037,12 +2018,14 @@ func (ln *localNetwork) setNodeName(nodeConfig *node.Config) error {
 	if len(nodeConfig.Name) == 0 {
 		for {
 			nodeConfig.Name = fmt.Sprintf("%s%d", defaultNodeNamePrefix, ln.nextNodeSuffix)
-			_, ok := ln.nodes[nodeConfig.Name]
-			if !ok {
+			ln.nextNodeSuffix++
+			if _, ok := ln.nodes[nodeConfig.Name]; !ok {
 				break
 			}
-			ln.nextNodeSuffix++
 		}
+	} else if suffix, ok := defaultNodeNameSuffix(nodeConfig.Name); ok && suffix >= ln.nextNodeSuffix {
+		// so the given name is not generated later
+		ln.nextNodeSuffix = suffix + 1
 	}
 	// Enforce name uniqueness
 	// Only paused nodes are enabled to be started with repeated name
@@ -1052,8 +2035,22 @@ func (ln *localNetwork) setNodeName(nodeConfig *node.Config) error {
 	return nil
 }
 
+// Returns the suffix of [nodeName] if it follows the default name pattern
+func defaultNodeNameSuffix(nodeName string) (uint64, bool) {
+	suffixStr, ok := strings.CutPrefix(nodeName, defaultNodeNamePrefix)
+	if !ok {
+		return 0, false
+	}
+	suffix, err := strconv.ParseUint(suffixStr, 10, 64)
+	if err != nil || strconv.FormatUint(suffix, 10) != suffixStr {
+		return 0, false
+	}
+	return suffix, true
+}
+
 type buildArgsReturn struct {
 	args      []string
+	flags     map[string]string
 	publicIP  string
 	apiPort   uint16
 	p2pPort   uint16
@@ -1062,6 +2059,10 @@ type buildArgsReturn struct {
 	logsDir   string
 	pluginDir string
 	httpHost  string
+	// how [apiPort] and [p2pPort] were assigned
+	portAssignment node.PortAssignment
+	// not empty if api auth is required
+	apiAuthPassword string
 }
 
 // buildArgs returns the:
@@ -1107,18 +2108,12 @@ func (ln *localNetwork) buildArgs(
 		return buildArgsReturn{}, err
 	}
 
-	// Use random free API port unless given in config file
-	apiPort, err := getPort(nodeConfig.Flags, configFile, config.HTTPPortKey)
-	if err != nil {
-		return buildArgsReturn{}, err
-	}
-
-	// Use a random free P2P (staking) port unless given in config file
-	// Use random free API port unless given in config file
-	p2pPort, err := getPort(nodeConfig.Flags, configFile, config.StakingPortKey)
+	// Use random free API and P2P (staking) ports unless given in config
+	portAssignment, err := ln.assignNodePorts(nodeConfig, configFile)
 	if err != nil {
 		return buildArgsReturn{}, err
 	}
+	apiPort, p2pPort := portAssignment.APIPort, portAssignment.P2PPort
 
 	// publicIP from all configs for node
 	publicIP, err := getConfigEntry(nodeConfig.Flags, configFile, config.PublicIPKey, constants.IPv4Lookback)
@@ -1161,6 +2156,44 @@ func (ln *localNetwork) buildArgs(
 		flags[k] = fileFlags[k]
 	}
 
+	if len(nodeConfig.VMPlugins) > 0 {
+		if pluginDir == "" {
+			pluginDir = filepath.Join(dataDir, defaultPluginsSubdir)
+			flags[config.PluginDirKey] = pluginDir
+		}
+		if err := installVMPlugins(pluginDir, nodeConfig.VMPlugins); err != nil {
+			return buildArgsReturn{}, err
+		}
+	}
+	vmAliasesFlags, err := writeVMAliases(dataDir, nodeConfig, configFile)
+	if err != nil {
+		return buildArgsReturn{}, err
+	}
+	for k := range vmAliasesFlags {
+		flags[k] = vmAliasesFlags[k]
+	}
+
+	apiAuthPassword := ""
+	if nodeConfig.APIAuth {
+		var apiAuthPasswordPath string
+		apiAuthPassword, apiAuthPasswordPath, err = writeAPIAuthPassword(dataDir)
+		if err != nil {
+			return buildArgsReturn{}, err
+		}
+		flags[config.APIAuthRequiredKey] = "true"
+		flags[config.APIAuthPasswordFileKey] = apiAuthPasswordPath
+	}
+
+	if ln.apiCA != nil {
+		certPath, keyPath, err := ln.apiCA.writeNodeCert(dataDir, publicIP)
+		if err != nil {
+			return buildArgsReturn{}, err
+		}
+		flags[config.HTTPSEnabledKey] = "true"
+		flags[config.HTTPSCertFileKey] = certPath
+		flags[config.HTTPSKeyFileKey] = keyPath
+	}
+
 	// avoid given these again, as apiPort/p2pPort can be dynamic even if given in nodeConfig
 	portFlags := set.Set[string]{
 		config.HTTPPortKey:    {},
@@ -1190,15 +2223,18 @@ func (ln *localNetwork) buildArgs(
 	}
 
 	return buildArgsReturn{
-		args:      args,
-		publicIP:  publicIP,
-		apiPort:   apiPort,
-		p2pPort:   p2pPort,
-		dataDir:   dataDir,
-		dbDir:     dbDir,
-		logsDir:   logsDir,
-		pluginDir: pluginDir,
-		httpHost:  httpHost,
+		args:            args,
+		flags:           flagsForAvagoVersion,
+		publicIP:        publicIP,
+		apiPort:         apiPort,
+		p2pPort:         p2pPort,
+		portAssignment:  portAssignment,
+		dataDir:         dataDir,
+		dbDir:           dbDir,
+		logsDir:         logsDir,
+		pluginDir:       pluginDir,
+		httpHost:        httpHost,
+		apiAuthPassword: apiAuthPassword,
 	}, nil
 }
 
@@ -1223,6 +2259,89 @@ func (ln *localNetwork) getNodeSemVer(nodeConfig node.Config) (string, error) {
 	return nodeSemVer, nil
 }
 
+// If [nodeConfig] gives a binary version but no binary path, sets the
+// binary path to the release of that version, downloading it if not cached
+func (ln *localNetwork) setVersionBinaryPath(ctx context.Context, nodeConfig *node.Config) error {
+	if nodeConfig.BinaryPath != "" || nodeConfig.BinaryVersion == "" {
+		return nil
+	}
+	binaryPath, err := ln.binaries.VerifiedBinaryPath(ctx, nodeConfig.BinaryVersion, nodeConfig.BinarySHA256)
+	if err != nil {
+		return err
+	}
+	nodeConfig.BinaryPath = binaryPath
+	return nil
+}
+
+// binary path --> binarySum
+var binarySumCache sync.Map
+
+// SHA256 of a binary, valid while the binary has the same size and
+// modification time
+type binarySum struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// Returns an error if the binary of [nodeConfig] doesn't have the SHA256
+// given on the config, if any. Sums are cached, so unchanged binaries
+// are not read again on each node start.
+func verifyNodeBinary(nodeConfig node.Config) error {
+	if nodeConfig.BinarySHA256 == "" {
+		return nil
+	}
+	info, err := os.Stat(nodeConfig.BinaryPath)
+	if err != nil {
+		return fmt.Errorf("couldn't verify binary: %w", err)
+	}
+	cached, ok := binarySumCache.Load(nodeConfig.BinaryPath)
+	if !ok || cached.(binarySum).size != info.Size() || !cached.(binarySum).modTime.Equal(info.ModTime()) {
+		sum, err := binaries.FileSHA256(nodeConfig.BinaryPath)
+		if err != nil {
+			return fmt.Errorf("couldn't verify binary: %w", err)
+		}
+		cached = binarySum{size: info.Size(), modTime: info.ModTime(), sum: sum}
+		binarySumCache.Store(nodeConfig.BinaryPath, cached)
+	}
+	if sum := cached.(binarySum).sum; !strings.EqualFold(sum, nodeConfig.BinarySHA256) {
+		return fmt.Errorf("%w for node %q: binary %s has sha256 %s, expected %s",
+			binaries.ErrBinaryMismatch, nodeConfig.Name, nodeConfig.BinaryPath, sum, nodeConfig.BinarySHA256)
+	}
+	return nil
+}
+
+// Gets the version of each binary of [nodeConfigs] before any node is
+// started, so unusable binaries fail the network creation upfront.
+// Networks whose nodes run different avalanchego versions are allowed
+// (eg for version skew tests), and logged.
+func (ln *localNetwork) checkBinaryVersions(nodeConfigs []node.Config) error {
+	// binary path --> version
+	binaryVersions := map[string]string{}
+	versions := set.Set[string]{}
+	for _, nodeConfig := range nodeConfigs {
+		if nodeConfig.BinaryPath == "" {
+			nodeConfig.BinaryPath = ln.binaryPath
+		}
+		if err := verifyNodeBinary(nodeConfig); err != nil {
+			return err
+		}
+		if _, ok := binaryVersions[nodeConfig.BinaryPath]; ok {
+			continue
+		}
+		nodeSemVer, err := ln.getNodeSemVer(nodeConfig)
+		if err != nil {
+			return err
+		}
+		binaryVersions[nodeConfig.BinaryPath] = nodeSemVer
+		versions.Add(nodeSemVer)
+	}
+	if versions.Len() > 1 {
+		ln.log.Info("nodes run different avalanchego versions", zap.Any("versions", binaryVersions))
+	}
+	return nil
+}
+
 // ensure flags are compatible with the running avalanchego version
 func getFlagsForAvagoVersion(avagoVersion string, givenFlags map[string]string) map[string]string {
 	flags := maps.Clone(givenFlags)
@@ -1,40 +1,82 @@
 package local
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/api"
+	"github.com/ava-labs/avalanche-network-runner/api/apitest"
 	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/binaries"
 	"github.com/ava-labs/avalanche-network-runner/local/mocks"
 	healthmocks "github.com/ava-labs/avalanche-network-runner/local/mocks/health"
 	"github.com/ava-labs/avalanche-network-runner/network"
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/network/node/status"
 	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanchego/api/admin"
 	"github.com/ava-labs/avalanchego/api/health"
+	"github.com/ava-labs/avalanchego/api/info"
 	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
+	"github.com/ava-labs/avalanchego/staking"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/process"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slog"
 )
 
 const (
 	defaultHealthyTimeout = 5 * time.Second
 	nodeVersion           = "avalanche/1.9.5 extra"
+	nodeFlagsUsage        = `      --api-admin-enabled                        If true, this node exposes the Admin API
+      --health-check-frequency duration          Time between health checks (default 30s)
+      --http-port uint                           Port of the HTTP server (default 9650)
+      --index-enabled                            If true, index all accepted containers and transactions
+      --log-display-level string                 The log display level
+      --log-level string                         The log level (default "info")
+      --network-max-reconnect-delay duration     Maximum delay between reconnects (default 1m0s)
+      --network-peer-list-gossip-frequency duration
+                                                 Frequency to gossip peers (default 1m0s)
+      --public-ip string                         Public IP of this node for P2P communication
+      --staking-port uint                        Port of the consensus server (default 9651)
+`
 )
 
 var (
@@ -42,6 +84,10 @@ var (
 	_ NodeProcessCreator    = &localTestFailedStartProcessCreator{}
 	_ NodeProcessCreator    = &localTestProcessUndefNodeProcessCreator{}
 	_ NodeProcessCreator    = &localTestFlagCheckProcessCreator{}
+	_ NodeProcessCreator    = &localTestVersionsProcessCreator{}
+	_ NodeProcessCreator    = &localTestEnvProcessCreator{}
+	_ NodeProcessCreator    = &localTestHangingProcessCreator{}
+	_ NodeProcessCreator    = &localTestPIDProcessCreator{}
 	_ api.NewAPIClientF     = newMockAPISuccessful
 	_ api.NewAPIClientF     = newMockAPIUnhealthy
 	_ router.InboundHandler = &noOpInboundHandler{}
@@ -57,6 +103,10 @@ func (*localTestSuccessfulNodeProcessCreator) GetNodeVersion(_ node.Config) (str
 	return nodeVersion, nil
 }
 
+func (*localTestSuccessfulNodeProcessCreator) GetNodeFlagsUsage(_ node.Config) (string, error) {
+	return nodeFlagsUsage, nil
+}
+
 type localTestFailedStartProcessCreator struct{}
 
 func (*localTestFailedStartProcessCreator) NewNodeProcess(node.Config, ...string) (NodeProcess, error) {
@@ -67,6 +117,10 @@ func (*localTestFailedStartProcessCreator) GetNodeVersion(_ node.Config) (string
 	return nodeVersion, nil
 }
 
+func (*localTestFailedStartProcessCreator) GetNodeFlagsUsage(_ node.Config) (string, error) {
+	return nodeFlagsUsage, nil
+}
+
 type localTestProcessUndefNodeProcessCreator struct{}
 
 func (*localTestProcessUndefNodeProcessCreator) NewNodeProcess(config node.Config, flags ...string) (NodeProcess, error) {
@@ -77,6 +131,10 @@ func (*localTestProcessUndefNodeProcessCreator) GetNodeVersion(_ node.Config) (s
 	return nodeVersion, nil
 }
 
+func (*localTestProcessUndefNodeProcessCreator) GetNodeFlagsUsage(_ node.Config) (string, error) {
+	return nodeFlagsUsage, nil
+}
+
 type localTestFlagCheckProcessCreator struct {
 	expectedFlags map[string]interface{}
 	require       *require.Assertions
@@ -91,6 +149,10 @@ func (*localTestFlagCheckProcessCreator) GetNodeVersion(_ node.Config) (string,
 	return nodeVersion, nil
 }
 
+func (*localTestFlagCheckProcessCreator) GetNodeFlagsUsage(_ node.Config) (string, error) {
+	return nodeFlagsUsage, nil
+}
+
 // Returns an API client where:
 // * The Health API's Health method always returns healthy
 // * The CChainEthAPI's Close method may be called
@@ -107,16 +169,24 @@ func newMockAPISuccessful(string, uint16) api.Client {
 	client := &apimocks.Client{}
 	client.On("HealthAPI").Return(healthClient)
 	client.On("CChainEthAPI").Return(ethClient)
+	// P-chain client used when removing nodes, to check the validator quorum
+	client.On("PChainAPI").Return(&snapshotPChainClient{})
 	return client
 }
 
-// Returns an API client where the Health API's Health method always returns unhealthy
+// Returns an API client where the Health API's Health method always returns unhealthy,
+// and the CChainEthAPI's Close method may be called
 func newMockAPIUnhealthy(string, uint16) api.Client {
 	healthReply := &health.APIReply{Healthy: false}
 	healthClient := &healthmocks.Client{}
 	healthClient.On("Health", mock.Anything, mock.Anything).Return(healthReply, nil)
+	ethClient := &apimocks.EthClient{}
+	ethClient.On("Close").Return()
 	client := &apimocks.Client{}
 	client.On("HealthAPI").Return(healthClient)
+	client.On("CChainEthAPI").Return(ethClient)
+	// P-chain client used when removing nodes, to check the validator quorum
+	client.On("PChainAPI").Return(&snapshotPChainClient{})
 	return client
 }
 
@@ -201,6 +271,10 @@ func (*localTestOneNodeCreator) GetNodeVersion(_ node.Config) (string, error) {
 	return nodeVersion, nil
 }
 
+func (*localTestOneNodeCreator) GetNodeFlagsUsage(_ node.Config) (string, error) {
+	return nodeFlagsUsage, nil
+}
+
 // Start a network with one node.
 func TestNewNetworkOneNode(t *testing.T) {
 	t.Parallel()
@@ -497,11 +571,19 @@ func TestUnhealthyNetwork(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
 	networkConfig := testNetworkConfig(t)
-	net, err := newNetwork(logging.NoLog{}, newMockAPIUnhealthy, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	nodeAPI := apitest.NewServer()
+	defer nodeAPI.Close()
+	nodeAPI.SetHealthy(false)
+	net, err := newNetwork(logging.NoLog{}, nodeAPI.NewAPIClient, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
 	err = net.loadConfig(context.Background(), networkConfig)
 	require.NoError(err)
 	require.Error(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	require.Positive(nodeAPI.Calls("health.health"))
+
+	nodeAPI.SetHealthy(true)
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	require.NoError(net.Stop(context.Background()))
 }
 
 // Create a network without giving names to nodes.
@@ -525,832 +607,3834 @@ func TestGeneratedNodesNames(t *testing.T) {
 	require.EqualValues(len(nodeNameMap), len(networkConfig.NodeConfigs))
 }
 
-// TestGenerateDefaultNetwork create a default network with config from NewDefaultConfig and
-// check expected number of nodes, node names, and avalanchego node ids
-func TestGenerateDefaultNetwork(t *testing.T) {
-	t.Parallel()
+// TestGeneratedNodesNamesNotReused checks that generated names are unique
+// under concurrent node additions, and not reused after node removals
+func TestGeneratedNodesNamesNotReused(t *testing.T) {
 	require := require.New(t)
-	binaryPath := "pepito"
-	networkConfig := NewDefaultConfig(binaryPath)
+	networkConfig := testNetworkConfig(t)
 	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	err = net.loadConfig(context.Background(), networkConfig)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	const numNodes = 5
+	names := make(chan string, numNodes)
+	wg := sync.WaitGroup{}
+	for i := 0; i < numNodes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			added, err := net.AddNode(context.Background(), node.Config{})
+			require.NoError(err)
+			names <- added.GetName()
+		}()
+	}
+	wg.Wait()
+	close(names)
+	generated := set.Set[string]{}
+	for name := range names {
+		generated.Add(name)
+	}
+	require.Len(generated, numNodes)
+	for _, nodeConfig := range networkConfig.NodeConfigs {
+		require.False(generated.Contains(nodeConfig.Name))
+	}
+
+	// the name of a removed node is not generated again
+	removed := generated.List()[0]
+	require.NoError(net.RemoveNode(context.Background(), removed))
+	added, err := net.AddNode(context.Background(), node.Config{})
+	require.NoError(err)
+	require.NotEqual(removed, added.GetName())
+	require.False(generated.Contains(added.GetName()))
+
+	// nor a given name following the same pattern
+	_, err = net.AddNode(context.Background(), node.Config{Name: "node100"})
 	require.NoError(err)
+	added, err = net.AddNode(context.Background(), node.Config{})
+	require.NoError(err)
+	require.Equal("node101", added.GetName())
+}
+
+// A node with its own API client factory gets its clients from it,
+// and the other nodes from the network one
+func TestNodeAPIClientFactory(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	nodeClient := newMockAPISuccessful("", 0)
+	nodeClientCalls := 0
+	networkConfig.NodeConfigs[1].NewAPIClient = func(string, uint16) api.Client {
+		nodeClientCalls++
+		return nodeClient
+	}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
 	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
-	names, err := net.GetNodeNames()
+
+	instrumented, err := net.GetNode(context.Background(), "node1")
 	require.NoError(err)
-	require.Len(names, 5)
-	for _, nodeInfo := range []struct {
-		name string
-		ID   string
-	}{
-		{
-			"node1",
-			"NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg",
-		},
-		{
-			"node2",
-			"NodeID-MFrZFVCXPv5iCn6M9K6XduxGTYp891xXZ",
-		},
-		{
-			"node3",
-			"NodeID-NFBbbJ4qCmNaCzeW7sxErhvWqvEQMnYcN",
-		},
-		{
-			"node4",
-			"NodeID-GWPcbFJZFfZreETSoWjPimr846mXEKCtu",
-		},
-		{
-			"node5",
-			"NodeID-P7oB2McjBGgW2NXXWVYjV8JEDFoW9xDE5",
-		},
-	} {
-		require.Contains(names, nodeInfo.name)
-		node, err := net.GetNode(nodeInfo.name)
-		require.NoError(err)
-		require.EqualValues(nodeInfo.name, node.GetName())
-		expectedID, err := ids.NodeIDFromString(nodeInfo.ID)
-		require.NoError(err)
-		require.EqualValues(expectedID, node.GetNodeID())
+	require.Same(nodeClient, instrumented.GetAPIClient())
+	require.Equal(1, nodeClientCalls)
+	plain, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	require.NotSame(nodeClient, plain.GetAPIClient())
+
+	// the client is reset with the node factory too
+	net.onClockJump(time.Hour)
+	require.Equal(2, nodeClientCalls)
+	require.NoError(net.Stop(context.Background()))
+}
+
+// Returns the version of each binary path in [versions]
+type localTestVersionsProcessCreator struct {
+	versions map[string]string
+}
+
+func (*localTestVersionsProcessCreator) NewNodeProcess(config node.Config, flags ...string) (NodeProcess, error) {
+	return newMockProcessSuccessful(config, flags...)
+}
+
+func (lt *localTestVersionsProcessCreator) GetNodeVersion(config node.Config) (string, error) {
+	version, ok := lt.versions[config.BinaryPath]
+	if !ok {
+		return "", errors.New("error on purpose for test")
 	}
+	return version, nil
 }
 
-// TODO add byzantine node to conf
-// TestNetworkFromConfig creates/waits/checks/stops a network from config file
-// the check verify that all the nodes can be accessed
-func TestNetworkFromConfig(t *testing.T) {
-	t.Parallel()
+func (*localTestVersionsProcessCreator) GetNodeFlagsUsage(_ node.Config) (string, error) {
+	return nodeFlagsUsage, nil
+}
+
+// Nodes of a network may run different avalanchego binaries, and each
+// reports its own version
+func TestMixedBinaryVersions(t *testing.T) {
 	require := require.New(t)
 	networkConfig := testNetworkConfig(t)
-	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	networkConfig.BinaryPath = "/old/avalanchego"
+	networkConfig.NodeConfigs[1].BinaryPath = "/new/avalanchego"
+	processCreator := &localTestVersionsProcessCreator{versions: map[string]string{
+		"/old/avalanchego": "avalanche/1.9.5",
+		"/new/avalanchego": "avalanche/1.10.0",
+	}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
 	require.NoError(err)
-	err = net.loadConfig(context.Background(), networkConfig)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	nodes, err := net.GetAllNodes()
 	require.NoError(err)
-	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
-	runningNodes := make(map[string]struct{})
-	for _, nodeConfig := range networkConfig.NodeConfigs {
-		runningNodes[nodeConfig.Name] = struct{}{}
+	for nodeName, node := range nodes {
+		if nodeName == "node1" {
+			require.Equal("v1.10.0", node.GetBinaryVersion())
+		} else {
+			require.Equal("v1.9.5", node.GetBinaryVersion())
+		}
 	}
-	checkNetwork(t, net, runningNodes, nil)
+	require.NoError(net.Stop(context.Background()))
+
+	// a binary without version fails the network before any node starts
+	networkConfig.NodeConfigs[2].BinaryPath = "/broken/avalanchego"
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	require.Error(net.loadConfig(context.Background(), networkConfig))
+	require.Empty(net.nodes)
 }
 
-// TestNetworkNodeOps creates an empty network,
-// adds nodes one by one, then removes nodes one by one.
-// Setween all operations, a network check is performed
-// to verify that all the running nodes are in the network,
-// and all removed nodes are not.
-func TestNetworkNodeOps(t *testing.T) {
-	t.Parallel()
+// Nodes configured by binary version run the cached release of it
+func TestBinaryVersion(t *testing.T) {
 	require := require.New(t)
+	cacheDir := t.TempDir()
+	cachedBinaryPath := filepath.Join(cacheDir, "v1.10.15", "avalanchego")
+	require.NoError(os.MkdirAll(filepath.Dir(cachedBinaryPath), 0o750))
+	require.NoError(os.WriteFile(cachedBinaryPath, nil, 0o600))
+	networkConfig := testNetworkConfig(t)
+	networkConfig.BinaryPath = "/old/avalanchego"
+	networkConfig.NodeConfigs[1].BinaryVersion = "v1.10.15"
+	processCreator := &localTestVersionsProcessCreator{versions: map[string]string{
+		"/old/avalanchego": "avalanche/1.9.5",
+		cachedBinaryPath:   "avalanche/1.10.15",
+	}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	net.binaries, err = binaries.NewDownloader(logging.NoLog{}, cacheDir, "")
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
 
-	// Start a new, empty network
-	emptyNetworkConfig, err := emptyNetworkConfig()
+	node, err := net.GetNode(context.Background(), "node1")
 	require.NoError(err)
+	require.Equal(cachedBinaryPath, node.GetBinaryPath())
+	require.Equal("v1.10.15", node.GetBinaryVersion())
+	node, err = net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	require.Equal("/old/avalanchego", node.GetBinaryPath())
+	require.NoError(net.Stop(context.Background()))
+}
+
+// Nodes whose binary doesn't have the given checksum fail to start
+func TestBinarySHA256(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	binaryPath := filepath.Join(t.TempDir(), "avalanchego")
+	require.NoError(os.WriteFile(binaryPath, []byte("avalanchego"), 0o600))
+	sum := sha256.Sum256([]byte("avalanchego"))
+	networkConfig := testNetworkConfig(t)
+	networkConfig.BinaryPath = binaryPath
+	networkConfig.NodeConfigs[0].BinarySHA256 = hex.EncodeToString(sum[:])
 	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	err = net.loadConfig(context.Background(), emptyNetworkConfig)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+
+	// the binary is checked again on restart
+	require.NoError(os.WriteFile(binaryPath, []byte("stale avalanchego"), 0o600))
+	require.NoError(os.Chtimes(binaryPath, time.Now(), time.Now().Add(time.Minute)))
+	err = net.RestartNode(ctx, "node0", "", "", "", nil, nil, nil)
+	require.ErrorIs(err, binaries.ErrBinaryMismatch)
+	require.ErrorContains(err, "node0")
+	// nodes without checksum are not checked
+	require.NoError(net.RestartNode(ctx, "node1", "", "", "", nil, nil, nil))
+	require.NoError(net.Stop(ctx))
+
+	// and before any node starts
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	runningNodes := make(map[string]struct{})
+	require.ErrorIs(net.loadConfig(ctx, networkConfig), binaries.ErrBinaryMismatch)
+	require.Empty(net.nodes)
+}
 
-	// Add nodes to the network one by one
+// In strict mode, nodes given flags unknown to their binary fail to start
+func TestStrictFlags(t *testing.T) {
+	require := require.New(t)
 	networkConfig := testNetworkConfig(t)
-	for _, nodeConfig := range networkConfig.NodeConfigs {
-		_, err := net.AddNode(nodeConfig)
-		require.NoError(err)
-		runningNodes[nodeConfig.Name] = struct{}{}
-		checkNetwork(t, net, runningNodes, nil)
+	networkConfig.StrictFlags = true
+	// fake binary whose help lists the flags of the config
+	flagNames := set.Set[string]{}
+	for name := range networkConfig.Flags {
+		flagNames.Add(name)
 	}
-	// Wait for all nodes to be healthy
-	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
-
-	// Remove nodes one by one
-	removedNodes := make(map[string]struct{})
 	for _, nodeConfig := range networkConfig.NodeConfigs {
-		_, err := net.GetNode(nodeConfig.Name)
-		require.NoError(err)
-		err = net.RemoveNode(context.Background(), nodeConfig.Name)
-		require.NoError(err)
-		removedNodes[nodeConfig.Name] = struct{}{}
-		delete(runningNodes, nodeConfig.Name)
-		checkNetwork(t, net, runningNodes, removedNodes)
+		for name := range nodeConfig.Flags {
+			flagNames.Add(name)
+		}
+		if nodeConfig.ConfigFile != "" {
+			var configFile map[string]interface{}
+			require.NoError(json.Unmarshal([]byte(nodeConfig.ConfigFile), &configFile))
+			for name := range configFile {
+				flagNames.Add(name)
+			}
+		}
 	}
+	usage := ""
+	for _, name := range flagNames.List() {
+		usage += fmt.Sprintf("      --%s string   usage\n", name)
+	}
+	networkConfig.BinaryPath = filepath.Join(t.TempDir(), "avalanchego")
+	script := "#!/bin/sh\ncat <<'EOF'\n" + usage + "EOF\n"
+	require.NoError(os.WriteFile(networkConfig.BinaryPath, []byte(script), 0o700)) //nolint:gosec
+
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.NoError(net.Stop(context.Background()))
+
+	networkConfig.NodeConfigs[1].Flags[config.LogLevelKey+"l"] = "debug"
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), networkConfig)
+	require.ErrorContains(err, config.LogLevelKey+"l")
+	require.Empty(net.nodes)
 }
 
-// TestNodeNotFound checks all operations fail for an unknown node,
-// being it either not created, or created and removed thereafter
-func TestNodeNotFound(t *testing.T) {
-	t.Parallel()
+// With a health quorum, a straggler doesn't keep the network unhealthy
+func TestHealthQuorum(t *testing.T) {
 	require := require.New(t)
-	emptyNetworkConfig, err := emptyNetworkConfig()
-	require.NoError(err)
 	networkConfig := testNetworkConfig(t)
+	networkConfig.HealthQuorum = 2
+	networkConfig.BeaconConnectionTimeout = time.Minute
+	networkConfig.NodeConfigs[2].NewAPIClient = newMockAPIUnhealthy
 	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	err = net.loadConfig(context.Background(), emptyNetworkConfig)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	// operations needing all the nodes still wait for the straggler
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(net.healthy(ctx))
+
+	node, err := net.GetNode(context.Background(), "node0")
 	require.NoError(err)
-	_, err = net.AddNode(networkConfig.NodeConfigs[0])
+	timeout, err := node.GetFlag(config.BootstrapBeaconConnectionTimeoutKey)
 	require.NoError(err)
-	// get node
-	_, err = net.GetNode(networkConfig.NodeConfigs[0].Name)
+	require.Equal("1m0s", timeout)
+	require.NoError(net.Stop(context.Background()))
+
+	networkConfig.HealthQuorum = 0
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	// get non-existent node
-	_, err = net.GetNode(networkConfig.NodeConfigs[1].Name)
-	require.Error(err)
-	// remove non-existent node
-	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[1].Name)
-	require.Error(err)
-	// remove node
-	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[0].Name)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(net.Healthy(ctx))
+	require.NoError(net.Stop(context.Background()))
+}
+
+// Nodes are polled at the configured interval, and reported unhealthy once
+// the node health timeout passes
+func TestHealthPolling(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.HealthPollInterval = 10 * time.Millisecond
+	networkConfig.NodeHealthTimeout = 200 * time.Millisecond
+	healthCalls := atomic.Int64{}
+	networkConfig.NodeConfigs[2].NewAPIClient = func(string, uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).
+			Run(func(mock.Arguments) { healthCalls.Add(1) }).
+			Return(&health.APIReply{Healthy: false}, nil)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(healthClient)
+		client.On("CChainEthAPI").Return(ethClient)
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	// get removed node
-	_, err = net.GetNode(networkConfig.NodeConfigs[0].Name)
-	require.Error(err)
-	// remove already-removed node
-	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[0].Name)
-	require.Error(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	start := time.Now()
+	err = net.Healthy(context.Background())
+	require.ErrorContains(err, `node "node2" failed to become healthy`)
+	require.Less(time.Since(start), 5*time.Second)
+	require.Greater(healthCalls.Load(), int64(5))
+	require.NoError(net.Stop(context.Background()))
+
+	networkConfig.HealthPollInterval = -time.Second
+	require.ErrorContains(networkConfig.Validate(), "negative health poll interval")
 }
 
-// TestStoppedNetwork checks that operations fail for an already stopped network
-func TestStoppedNetwork(t *testing.T) {
-	t.Parallel()
+// switchHealthClient is a health client that only implements Health,
+// reporting the health held by [healthy]
+type switchHealthClient struct {
+	health.Client
+
+	healthy *atomic.Bool
+}
+
+func (c *switchHealthClient) Health(context.Context, []string, ...rpc.Option) (*health.APIReply, error) {
+	return &health.APIReply{Healthy: c.healthy.Load()}, nil
+}
+
+// The state of each node is reported, and the health of single nodes
+// can be awaited
+// quorumPChainClient is a P-chain client that returns [vdrs] as the
+// current validators
+type quorumPChainClient struct {
+	platformvm.Client
+	vdrs *[]platformvm.ClientPermissionlessValidator
+}
+
+func (c *quorumPChainClient) GetCurrentValidators(context.Context, ids.ID, []ids.NodeID, ...rpc.Option) ([]platformvm.ClientPermissionlessValidator, error) {
+	return *c.vdrs, nil
+}
+
+// TestRemoveNodeQuorum tests that a validator is not removed if the
+// network would lose its validator quorum, unless forced
+func TestRemoveNodeQuorum(t *testing.T) {
 	require := require.New(t)
-	emptyNetworkConfig, err := emptyNetworkConfig()
+	ctx := context.Background()
+	vdrs := []platformvm.ClientPermissionlessValidator{}
+	node2Unhealthy := &atomic.Bool{}
+	newAPIClientF := func(unhealthy *atomic.Bool) api.NewAPIClientF {
+		return func(string, uint16) api.Client {
+			healthClient := &healthmocks.Client{}
+			healthClient.On("Health", mock.Anything, mock.Anything).Return(
+				func(context.Context, []string, ...rpc.Option) *health.APIReply {
+					return &health.APIReply{Healthy: !unhealthy.Load()}
+				},
+				nil,
+			)
+			ethClient := &apimocks.EthClient{}
+			ethClient.On("Close").Return()
+			client := &apimocks.Client{}
+			client.On("HealthAPI").Return(healthClient)
+			client.On("CChainEthAPI").Return(ethClient)
+			client.On("PChainAPI").Return(&quorumPChainClient{vdrs: &vdrs})
+			return client
+		}
+	}
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[2].NewAPIClient = newAPIClientF(node2Unhealthy)
+	net, err := newNetwork(logging.NoLog{}, newAPIClientF(&atomic.Bool{}), &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+	for nodeName, weight := range map[string]uint64{"node0": 1, "node1": 10, "node2": 10} {
+		vdr := platformvm.ClientPermissionlessValidator{}
+		vdr.NodeID = net.nodes[nodeName].nodeID
+		vdr.Weight = weight
+		vdrs = append(vdrs, vdr)
+	}
+	_, err = net.AddNode(context.Background(), node.Config{Name: "node3"})
 	require.NoError(err)
+
+	// not a validator
+	require.NoError(net.RemoveNode(ctx, "node3"))
+	// only the weight of healthy nodes counts: 10 of 21 left
+	node2Unhealthy.Store(true)
+	err = net.RemoveNode(ctx, "node0")
+	require.ErrorIs(err, network.ErrValidatorQuorum)
+	node2Unhealthy.Store(false)
+	// 20 of 21 left
+	require.NoError(net.RemoveNode(ctx, "node0"))
+	// 10 of 21 left
+	err = net.RemoveNode(ctx, "node1")
+	require.ErrorIs(err, network.ErrValidatorQuorum)
+	var quorumErr *network.QuorumError
+	require.ErrorAs(err, &quorumErr)
+	require.Equal(&network.QuorumError{NodeName: "node1", HealthyWeight: 10, TotalWeight: 21}, quorumErr)
+	require.Contains(net.nodes, "node1")
+	require.NoError(net.ForceRemoveNode(ctx, "node1"))
+	require.NotContains(net.nodes, "node1")
+
+	require.NoError(net.Stop(ctx))
+	require.ErrorIs(net.ForceRemoveNode(ctx, "node2"), network.ErrStopped)
+}
+
+func TestNodeStatus(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
 	networkConfig := testNetworkConfig(t)
+	networkConfig.HealthPollInterval = 10 * time.Millisecond
+	node0Healthy := &atomic.Bool{}
+	node0Healthy.Store(true)
+	networkConfig.NodeConfigs[0].NewAPIClient = func(string, uint16) api.Client {
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(&switchHealthClient{healthy: node0Healthy})
+		client.On("CChainEthAPI").Return(ethClient)
+		return client
+	}
+	networkConfig.NodeConfigs[2].NewAPIClient = newMockAPIUnhealthy
 	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	err = net.loadConfig(context.Background(), emptyNetworkConfig)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+	require.NoError(net.PauseNode(ctx, "node1"))
+
+	states, err := net.Status(ctx)
 	require.NoError(err)
-	_, err = net.AddNode(networkConfig.NodeConfigs[0])
+	require.Equal(map[string]network.NodeState{
+		"node0": network.NodeHealthy,
+		"node1": network.NodeStopped,
+		"node2": network.NodeStarting,
+	}, states)
+	// nodes found healthy before are unhealthy, not starting
+	node0Healthy.Store(false)
+	states, err = net.Status(ctx)
 	require.NoError(err)
-	// first GetNodeNames should return some nodes
-	_, err = net.GetNodeNames()
+	require.Equal(network.NodeUnhealthy, states["node0"])
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		node0Healthy.Store(true)
+	}()
+	require.NoError(net.NodeHealthy(ctx, "node0"))
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	require.ErrorContains(net.NodeHealthy(timeoutCtx, "node2"), `node "node2" failed to become healthy`)
+	require.ErrorContains(net.NodeHealthy(ctx, "node1"), `node "node1" is paused`)
+	require.ErrorContains(net.NodeHealthy(ctx, "node3"), `node "node3" not found`)
+
+	require.NoError(net.Stop(ctx))
+	_, err = net.Status(ctx)
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+// TestMetricsTargets tests that the metrics endpoints of the running nodes
+// are returned, sorted by node name
+func TestMetricsTargets(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	err = net.Stop(context.Background())
+	require.NoError(net.loadConfig(ctx, testNetworkConfig(t)))
+	require.NoError(net.PauseNode(ctx, "node1"))
+
+	targets, err := net.MetricsTargets(ctx)
 	require.NoError(err)
-	// Stop failure
-	require.EqualValues(net.Stop(context.Background()), network.ErrStopped)
-	// AddNode failure
-	_, err = net.AddNode(networkConfig.NodeConfigs[1])
-	require.EqualValues(network.ErrStopped, err)
-	// GetNode failure
-	_, err = net.GetNode(networkConfig.NodeConfigs[0].Name)
-	require.EqualValues(err, network.ErrStopped)
-	// second GetNodeNames should return no nodes
-	_, err = net.GetNodeNames()
-	require.EqualValues(network.ErrStopped, err)
-	// RemoveNode failure
-	require.EqualValues(network.ErrStopped, net.RemoveNode(context.Background(), networkConfig.NodeConfigs[0].Name))
-	// Healthy failure
-	require.EqualValues(awaitNetworkHealthy(net, defaultHealthyTimeout), network.ErrStopped)
-	_, err = net.GetAllNodes()
-	require.EqualValues(err, network.ErrStopped)
+	require.Equal([]network.ScrapeTarget{
+		{
+			NodeName: "node0",
+			Address:  fmt.Sprintf("%s:%d", net.nodes["node0"].publicIP, net.nodes["node0"].GetAPIPort()),
+			URI:      net.nodes["node0"].GetURI(),
+		},
+		{
+			NodeName: "node2",
+			Address:  fmt.Sprintf("%s:%d", net.nodes["node2"].publicIP, net.nodes["node2"].GetAPIPort()),
+			URI:      net.nodes["node2"].GetURI(),
+		},
+	}, targets)
+
+	require.NoError(net.Stop(ctx))
+	_, err = net.MetricsTargets(ctx)
+	require.ErrorIs(err, network.ErrStopped)
 }
 
-func TestGetAllNodes(t *testing.T) {
+// TestNodeURIsWithAPITLS tests that, when the node APIs use TLS, the node
+// URIs given to users point to the API proxies
+func TestNodeURIsWithAPITLS(t *testing.T) {
 	require := require.New(t)
+	ctx := context.Background()
 	networkConfig := testNetworkConfig(t)
+	networkConfig.APITLS = true
 	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	err = net.loadConfig(context.Background(), networkConfig)
-	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, networkConfig))
 
-	nodes, err := net.GetAllNodes()
+	targets, err := net.MetricsTargets(ctx)
 	require.NoError(err)
-	require.Len(nodes, len(net.nodes))
-	for name, node := range net.nodes {
-		require.EqualValues(node, nodes[name])
+	require.Len(targets, 3)
+	for _, target := range targets {
+		node := net.nodes[target.NodeName]
+		require.NotNil(node.apiProxy)
+		proxyAddr := fmt.Sprintf("%s:%d", apiProxyHost, node.apiProxy.port)
+		require.Equal("http://"+proxyAddr, node.GetURI())
+		require.Equal(node.GetURI(), target.URI)
+		require.Equal(proxyAddr, target.Address)
 	}
+	require.NoError(net.Stop(ctx))
 }
 
-// TestFlags tests that we can pass flags through the network.Config
-// but also via node.Config and that the latter overrides the former
-// if same keys exist.
-func TestFlags(t *testing.T) {
-	t.Parallel()
+// VM plugins are installed into the node plugin dir, named after their VM IDs
+func TestVMPlugins(t *testing.T) {
 	require := require.New(t)
+	pluginPath := filepath.Join(t.TempDir(), "subnetevm")
+	require.NoError(os.WriteFile(pluginPath, []byte("vm"), 0o600))
+	vmID, err := utils.VMID("subnetevm")
+	require.NoError(err)
 	networkConfig := testNetworkConfig(t)
-
-	// submit both network.Config flags and node.Config
-	networkConfig.Flags = map[string]interface{}{
-		"test-network-config-flag": "something",
-		"common-config-flag":       "should not be added",
-	}
-	for i := range networkConfig.NodeConfigs {
-		v := &networkConfig.NodeConfigs[i]
-		v.Flags = map[string]interface{}{
-			"test-node-config-flag":  "node",
-			"test2-node-config-flag": "config",
-			"common-config-flag":     "this should be added",
-		}
-	}
-	expectedFlags := map[string]interface{}{
-		"test-network-config-flag": "something",
-		"common-config-flag":       "this should be added",
-		"test-node-config-flag":    "node",
-		"test2-node-config-flag":   "config",
+	networkConfig.NodeConfigs[1].VMPlugins = []node.VMPlugin{
+		{Path: pluginPath, VM: "subnetevm"},
 	}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
 
-	nw, err := newNetwork(
-		logging.NoLog{},
-		newMockAPISuccessful,
-		&localTestFlagCheckProcessCreator{
-			// after creating the network, one flag should have been overridden by the node configs
-			expectedFlags: expectedFlags,
-			require:       require,
-		},
-		"",
-		"",
-		false,
-		false,
-		false,
-	)
+	withPlugins, err := net.GetNode(context.Background(), "node1")
 	require.NoError(err)
-	err = nw.loadConfig(context.Background(), networkConfig)
+	pluginDir := withPlugins.GetPluginDir()
+	require.Equal(filepath.Join(withPlugins.GetDataDir(), defaultPluginsSubdir), pluginDir)
+	contents, err := os.ReadFile(filepath.Join(pluginDir, vmID.String()))
 	require.NoError(err)
-	err = nw.Stop(context.Background())
+	require.Equal("vm", string(contents))
+	withoutPlugins, err := net.GetNode(context.Background(), "node0")
 	require.NoError(err)
+	require.Empty(withoutPlugins.GetPluginDir())
+	require.NoError(net.Stop(context.Background()))
 
-	// submit only node.Config flags
-	networkConfig.Flags = nil
-	flags := map[string]interface{}{
-		"test-node-config-flag":  "node",
-		"test2-node-config-flag": "config",
-		"common-config-flag":     "this should be added",
+	networkConfig.NodeConfigs[1].VMPlugins = append(networkConfig.NodeConfigs[1].VMPlugins, node.VMPlugin{
+		Path: pluginPath, VM: vmID.String(),
+	})
+	require.Error(networkConfig.Validate())
+}
+
+// Network VM plugins are installed on all nodes, including the ones added
+// later, and VM aliases are written to the node VM aliases files
+func TestVMPluginAliases(t *testing.T) {
+	require := require.New(t)
+	pluginPath := filepath.Join(t.TempDir(), "subnetevm")
+	require.NoError(os.WriteFile(pluginPath, []byte("vm"), 0o600))
+	otherPluginPath := filepath.Join(t.TempDir(), "othervm")
+	require.NoError(os.WriteFile(otherPluginPath, []byte("other vm"), 0o600))
+	vmID, err := utils.VMID("subnetevm")
+	require.NoError(err)
+	otherVMID := ids.GenerateTestID()
+	networkConfig := testNetworkConfig(t)
+	networkConfig.VMPlugins = []node.VMPlugin{
+		{Path: pluginPath, VM: "subnetevm", Aliases: []string{"evm"}},
 	}
-	for i := range networkConfig.NodeConfigs {
-		v := &networkConfig.NodeConfigs[i]
-		v.Flags = flags
+	// the node plugin of the same VM takes precedence
+	networkConfig.NodeConfigs[1].VMPlugins = []node.VMPlugin{
+		{Path: otherPluginPath, VM: vmID.String(), Aliases: []string{"evm", "subnet-evm"}},
+		{Path: otherPluginPath, VM: otherVMID.String()},
 	}
-	nw, err = newNetwork(
-		logging.NoLog{},
-		newMockAPISuccessful,
-		&localTestFlagCheckProcessCreator{
-			// after creating the network, only node configs should exist
-			expectedFlags: flags,
-			require:       require,
-		},
-		"",
-		"",
-		false,
-		false,
-		false,
-	)
-	require.NoError(err)
-	err = nw.loadConfig(context.Background(), networkConfig)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	err = nw.Stop(context.Background())
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	_, err = net.AddNode(context.Background(), node.Config{Name: "node3"})
 	require.NoError(err)
 
-	// submit only network.Config flags
-	flags = map[string]interface{}{
-		"test-network-config-flag": "something",
-		"common-config-flag":       "else",
-	}
-	networkConfig.Flags = flags
-	for i := range networkConfig.NodeConfigs {
-		v := &networkConfig.NodeConfigs[i]
-		v.Flags = nil
-	}
-	nw, err = newNetwork(
-		logging.NoLog{},
-		newMockAPISuccessful,
-		&localTestFlagCheckProcessCreator{
-			// after creating the network, only flags from the network config should exist
-			expectedFlags: flags,
-			require:       require,
+	for nodeName, expected := range map[string]struct {
+		aliases map[string][]string
+		plugins map[ids.ID]string
+	}{
+		"node0": {
+			aliases: map[string][]string{vmID.String(): {"evm"}},
+			plugins: map[ids.ID]string{vmID: "vm"},
 		},
-		"",
-		"",
-		false,
-		false,
-		false,
-	)
-	require.NoError(err)
-	err = nw.loadConfig(context.Background(), networkConfig)
-	require.NoError(err)
-	err = nw.Stop(context.Background())
-	require.NoError(err)
+		"node1": {
+			aliases: map[string][]string{vmID.String(): {"evm", "subnet-evm"}},
+			plugins: map[ids.ID]string{vmID: "other vm", otherVMID: "other vm"},
+		},
+		"node3": {
+			aliases: map[string][]string{vmID.String(): {"evm"}},
+			plugins: map[ids.ID]string{vmID: "vm"},
+		},
+	} {
+		node := net.nodes[nodeName]
+		vmAliasesPath := node.flags[config.VMAliasesFileKey]
+		require.Equal(filepath.Join(node.GetDataDir(), vmAliasesFileName), vmAliasesPath)
+		vmAliasesBytes, err := os.ReadFile(vmAliasesPath)
+		require.NoError(err)
+		vmAliases := map[string][]string{}
+		require.NoError(json.Unmarshal(vmAliasesBytes, &vmAliases))
+		require.Equal(expected.aliases, vmAliases, nodeName)
+		for pluginVMID, contents := range expected.plugins {
+			pluginContents, err := os.ReadFile(filepath.Join(node.GetPluginDir(), pluginVMID.String()))
+			require.NoError(err)
+			require.Equal(contents, string(pluginContents))
+		}
+	}
+
+	// aliases can't be given both on plugins and flags
+	_, err = net.AddNode(context.Background(), node.Config{
+		Name:  "node4",
+		Flags: map[string]interface{}{config.VMAliasesFileKey: "/tmp/aliases.json"},
+	})
+	require.ErrorContains(err, "VM aliases given both")
+	// nor twice
+	_, err = net.AddNode(context.Background(), node.Config{
+		Name:      "node5",
+		VMPlugins: []node.VMPlugin{{Path: otherPluginPath, VM: otherVMID.String(), Aliases: []string{"evm"}}},
+	})
+	require.ErrorContains(err, "VM alias \"evm\" given twice")
+	require.NoError(net.Stop(context.Background()))
 }
 
-// for the TestChildCmdRedirection we need to be able to wait
-// until the buffer is written to or else there is a race condition
-type lockedBuffer struct {
-	bytes.Buffer
-	// [writtenCh] is closed after Write is called
-	writtenCh chan struct{}
+// Records the env of the node processes it creates
+type localTestEnvProcessCreator struct {
+	localTestSuccessfulNodeProcessCreator
+	lock sync.Mutex
+	envs map[string]map[string]string
 }
 
-// Write is locked for the lockedBuffer
-func (m *lockedBuffer) Write(b []byte) (int, error) {
-	defer close(m.writtenCh)
-	return m.Buffer.Write(b)
+func (lt *localTestEnvProcessCreator) NewNodeProcess(config node.Config, flags ...string) (NodeProcess, error) {
+	lt.lock.Lock()
+	lt.envs[config.Name] = config.Env
+	lt.lock.Unlock()
+	return newMockProcessSuccessful(config, flags...)
 }
 
-// TestChildCmdRedirection checks that RedirectStdout set to true on a NodeConfig
-// results indeed in the output being prepended and colored.
-// For the color check we just measure the length of the required terminal escape values
-func TestChildCmdRedirection(t *testing.T) {
-	t.Parallel()
-	// we need this to create the actual process we test
-	buf := &lockedBuffer{
-		writtenCh: make(chan struct{}),
-	}
-	npc := &nodeProcessCreator{
-		log:         logging.NoLog{},
-		stdout:      buf,
-		stderr:      buf,
-		colorPicker: utils.NewColorPicker(),
-	}
+// The shared dir is given to all the node processes, and removed on stop
+func TestSharedDir(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.SharedDir = true
+	processCreator := &localTestEnvProcessCreator{envs: map[string]map[string]string{}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
 
-	// define a bogus output
-	testOutput := "this is the output"
-	// we will use `echo` with the testOutput as we will get a measurable result
-	ctrlCmd := exec.Command("echo", testOutput)
-	// we would not really need to execute the command, just the output would be enough
-	// nevertheless let's do it to simulate the actual case
-	expectedResult, err := ctrlCmd.Output()
-	if err != nil {
-		t.Fatal(err)
+	sharedDir := net.GetArtifactPaths().SharedDir
+	require.Equal(filepath.Join(net.rootDir, sharedDirName), sharedDir)
+	require.DirExists(sharedDir)
+	require.Len(processCreator.envs, 3)
+	for _, env := range processCreator.envs {
+		require.Equal(sharedDir, env[constants.SharedDirEnvVar])
 	}
+	// not kept on the node configs
+	node, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	require.NotContains(node.GetConfig().Env, constants.SharedDirEnvVar)
 
-	// this is the "mock" node name we want to see prepended to the output
-	mockNodeName := "redirect-test-node"
+	require.NoError(net.Stop(context.Background()))
+	require.NoDirExists(sharedDir)
+}
 
-	// now create the node process and check it will be prepended and colored
-	testConfig := node.Config{
-		BinaryPath:     "sh",
-		RedirectStdout: true,
-		RedirectStderr: true,
-		Name:           mockNodeName,
-	}
-	// Sleep for a second after echoing so that we have a chance to read from the stdout pipe
-	// before it closes when the process exits and Wait() returns.
-	// See https://pkg.go.dev/os/exec#Cmd.StdoutPipe
-	proc, err := npc.NewNodeProcess(testConfig, "-c", fmt.Sprintf("echo %s && sleep 1", testOutput))
-	if err != nil {
-		t.Fatal(err)
+// Creates processes that exit on stop, except for the one of node
+// [hanging], which has to be killed
+type localTestHangingProcessCreator struct {
+	localTestSuccessfulNodeProcessCreator
+	hanging string
+}
+
+func (lt *localTestHangingProcessCreator) NewNodeProcess(config node.Config, flags ...string) (NodeProcess, error) {
+	if config.Name != lt.hanging {
+		return newMockProcessSuccessful(config, flags...)
 	}
+	process := &mocks.NodeProcess{}
+	process.On("Stop", mock.Anything).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(-1)
+	process.On("Status").Return(status.Running)
+	return process, nil
+}
 
-	// lock read access to the buffer
-	<-buf.writtenCh
-	result := buf.String()
+// Stop results tell which nodes were killed, and stop events are
+// published for each stopped node
+func TestStopResult(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	processCreator := &localTestHangingProcessCreator{hanging: "node2"}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	events := net.Events()
 
-	// wait for the process to finish.
-	_ = proc.Stop(context.Background())
+	_, err = net.GetStopResult()
+	require.ErrorIs(err, network.ErrNotStopped)
 
-	// now do the checks:
-	// the new string should contain the node name
-	if !strings.Contains(result, mockNodeName) {
-		t.Fatalf("expected subcommand to contain node name %s, but it didn't", mockNodeName)
-	}
+	require.NoError(net.RemoveNode(context.Background(), "node0"))
+	event := <-events
+	require.Equal(network.EventNodeStopped, event.Type)
+	require.Equal("node0", event.NodeName)
+	require.Contains(event.Message, "gracefully")
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	event = <-events
+	require.Equal(network.EventNodeStopped, event.Type)
+	require.Equal("node1", event.NodeName)
 
-	// and it should have a specific length:
-	//             the actual output   + the color terminal escape sequence      + node name    + []<space> + color terminal reset escape sequence
-	expectedLen := len(expectedResult) + len(utils.NewColorPicker().NextColor()) + len(mockNodeName) + 3 + len(logging.Reset)
-	if len(result) != expectedLen {
-		t.Fatalf("expected string length to be %d, but it was %d", expectedLen, len(result))
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err := network.StopWithResult(ctx, net)
+	require.Error(err)
+	// paused nodes are not stopped again
+	require.Len(result.Nodes, 1)
+	require.Equal("node2", result.Nodes[0].Name)
+	require.True(result.Nodes[0].Killed)
+	require.Equal(-1, result.Nodes[0].ExitCode)
+	require.NotZero(result.Nodes[0].Duration)
+	require.GreaterOrEqual(result.Duration, result.Nodes[0].Duration)
+	require.Equal([]string{"node2"}, result.Killed())
+	require.Empty(result.Graceful())
+	event = <-events
+	require.Equal(network.EventNodeStopped, event.Type)
+	require.Equal("node2", event.NodeName)
+	require.Contains(event.Message, "killing")
+
+	// the result of the first stop is kept
+	stoppedAgain, err := network.StopWithResult(context.Background(), net)
+	require.ErrorIs(err, network.ErrStopped)
+	require.Equal(result, stoppedAgain)
 }
 
-// checkNetwork receives a network, a set of running nodes (started and not removed yet), and
-// a set of removed nodes, checking:
-// - GetNodeNames retrieves the correct number of running nodes
-// - GetNode does not fail for given running nodes
-// - GetNode does fail for given stopped nodes
-func checkNetwork(t *testing.T, net network.Network, runningNodes map[string]struct{}, removedNodes map[string]struct{}) {
+// References to restarted or resumed nodes reach the new node process
+func TestRestartedNodeReferences(t *testing.T) {
 	require := require.New(t)
-	nodeNames, err := net.GetNodeNames()
+	ctx := context.Background()
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	require.EqualValues(len(nodeNames), len(runningNodes))
-	for nodeName := range runningNodes {
-		_, err := net.GetNode(nodeName)
-		require.NoError(err)
-	}
-	for nodeName := range removedNodes {
-		_, err := net.GetNode(nodeName)
-		require.Error(err)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+
+	node0, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	client := node0.GetAPIClient()
+	require.NoError(net.RestartNode(ctx, "node0", "", "", "", nil, nil, nil))
+	restarted, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	require.NotSame(node0, restarted)
+	require.NotSame(client, node0.GetAPIClient())
+	require.Same(restarted.GetAPIClient(), node0.GetAPIClient())
+	require.Equal(restarted.GetEndpoints(), node0.GetEndpoints())
+
+	// and so are references to nodes restarted more than once
+	require.NoError(net.PauseNode(ctx, "node0"))
+	require.NoError(net.ResumeNode(ctx, "node0"))
+	resumed, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	require.NotSame(restarted, resumed)
+	require.Same(resumed.GetAPIClient(), node0.GetAPIClient())
+	require.Same(resumed.GetAPIClient(), restarted.GetAPIClient())
+	require.Equal(status.Running, node0.Status())
+
+	// node info is the one of the current node
+	require.NoError(net.PauseNode(ctx, "node0"))
+	require.True(node0.GetPaused())
+	require.NoError(net.ResumeNode(ctx, "node0"))
+	require.False(node0.GetPaused())
+	binaryPath := filepath.Join(t.TempDir(), "avalanchego")
+	require.NoError(net.RestartNode(ctx, "node0", binaryPath, "", "", nil, nil, nil))
+	current, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	for _, handle := range []node.Node{node0, restarted, resumed} {
+		require.Equal(binaryPath, handle.GetBinaryPath())
+		require.Equal(current.GetBinaryVersion(), handle.GetBinaryVersion())
+		require.Equal(current.GetConfig(), handle.GetConfig())
+		require.Equal(current.GetConfigFile(), handle.GetConfigFile())
+		require.Equal(current.GetDataDir(), handle.GetDataDir())
+		require.Equal(current.GetPluginDir(), handle.GetPluginDir())
+		require.Equal(current.GetOutputFilePaths(), handle.GetOutputFilePaths())
+		require.False(handle.GetPaused())
 	}
 }
 
-// Return a network config that has no nodes
-func emptyNetworkConfig() (network.Config, error) {
-	networkID := uint32(1337)
-	// Use a dummy genesis
-	genesis, err := network.NewAvalancheGoGenesis(
-		networkID,
-		[]network.AddrAndBalance{
-			{
-				Addr:    ids.GenerateTestShortID(),
-				Balance: big.NewInt(1),
-			},
-		},
-		nil,
-		[]ids.NodeID{ids.GenerateTestNodeID()},
-	)
-	if err != nil {
-		return network.Config{}, err
+// Listing of the sockets of nodes with pids 100 (port 9651), 200 (port
+// 9653) and 300, where 100 dialed 200 and 300 dialed 100
+const testProcNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:25B3 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 10 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:9C40 0100007F:25B5 01 00000000:00000000 00:00000000 00000000  1000        0 11 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:25B5 0100007F:9C40 01 00000000:00000000 00:00000000 00000000  1000        0 21 1 0000000000000000 20 4 30 10 -1
+   3: 0100007F:9C41 0100007F:25B3 01 00000000:00000000 00:00000000 00000000  1000        0 31 1 0000000000000000 20 4 30 10 -1
+   4: 0100007F:25B3 0100007F:9C41 01 00000000:00000000 00:00000000 00000000  1000        0 12 1 0000000000000000 20 4 30 10 -1
+`
+
+// Returns a proc dir listing [testProcNetTCP]
+func testProcDir(t *testing.T) string {
+	require := require.New(t)
+	dir := t.TempDir()
+	require.NoError(os.MkdirAll(filepath.Join(dir, "net"), 0o750))
+	require.NoError(os.WriteFile(filepath.Join(dir, "net", "tcp"), []byte(testProcNetTCP), 0o600))
+	for pid, inodes := range map[int][]int{100: {10, 11, 12}, 200: {21}, 300: {31}} {
+		fdDir := filepath.Join(dir, strconv.Itoa(pid), "fd")
+		require.NoError(os.MkdirAll(fdDir, 0o750))
+		require.NoError(os.Symlink("/dev/null", filepath.Join(fdDir, "0")))
+		for i, inode := range inodes {
+			require.NoError(os.Symlink(fmt.Sprintf("socket:[%d]", inode), filepath.Join(fdDir, strconv.Itoa(i+1))))
+		}
 	}
-	return network.Config{
-		Genesis: string(genesis),
-	}, nil
+	return dir
 }
 
-// Returns a config for a three node network,
-// where the nodes have randomly generated staking
-// keys and certificates.
-func testNetworkConfig(t *testing.T) network.Config {
+func TestLinkFlows(t *testing.T) {
 	require := require.New(t)
-	networkConfig, err := NewDefaultConfigNNodes("pepito", 3)
+	procDir := testProcDir(t)
+
+	sockets, err := hostTCPSockets(procDir)
 	require.NoError(err)
-	for i := 0; i < 3; i++ {
-		networkConfig.NodeConfigs[i].Name = fmt.Sprintf("node%d", i)
-		delete(networkConfig.NodeConfigs[i].Flags, config.HTTPPortKey)
-		delete(networkConfig.NodeConfigs[i].Flags, config.StakingPortKey)
+	require.Len(sockets, 4)
+	inodes := map[int]set.Set[uint64]{}
+	for _, pid := range []int{100, 200, 300} {
+		inodes[pid], err = processSocketInodes(procDir, pid)
+		require.NoError(err)
 	}
-	return networkConfig
-}
+	require.Equal(set.Of[uint64](10, 11, 12), inodes[100])
 
-// Returns nil when all the nodes in [net] are healthy,
-// or an error if one doesn't become healthy within
-// the timeout.
-func awaitNetworkHealthy(net network.Network, timeout time.Duration) error { //nolint
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	return net.Healthy(ctx)
+	// the flows of each direction are told apart whoever dialed
+	require.Equal([]flow{{srcPort: 0x9C40, dstPort: 9653}}, linkFlows(sockets, inodes[100], inodes[200]))
+	require.Equal([]flow{{srcPort: 9653, dstPort: 0x9C40}}, linkFlows(sockets, inodes[200], inodes[100]))
+	require.Equal([]flow{{srcPort: 9651, dstPort: 0x9C41}}, linkFlows(sockets, inodes[100], inodes[300]))
+	require.Empty(linkFlows(sockets, inodes[200], inodes[300]))
 }
 
-func TestAddNetworkFlags(t *testing.T) {
-	t.Parallel()
-	type test struct {
-		name            string
-		netFlags        map[string]interface{}
-		beforeNodeFlags map[string]interface{}
-		afterNodeFlags  map[string]interface{}
+func TestTCShaper(t *testing.T) {
+	require := require.New(t)
+	commands := []string{}
+	shaper := newTCShaper(func(_ context.Context, args ...string) error {
+		commands = append(commands, strings.Join(args, " "))
+		return nil
+	})
+	link01 := link{from: "node0", to: "node1"}
+	link10 := link{from: "node1", to: "node0"}
+	conditions := map[link]network.LinkConditions{
+		link01: {Latency: 100 * time.Millisecond, Jitter: 10 * time.Millisecond},
+		link10: {Loss: 2.5},
 	}
-	tests := []test{
-		{
-			name:            "all empty",
-			netFlags:        map[string]interface{}{},
-			beforeNodeFlags: map[string]interface{}{},
-			afterNodeFlags:  map[string]interface{}{},
-		},
-		{
-			name:            "net flags not empty; node flags empty",
-			netFlags:        map[string]interface{}{"1": 1},
-			beforeNodeFlags: map[string]interface{}{},
-			afterNodeFlags:  map[string]interface{}{"1": 1},
-		},
-		{
-			name:            "net flags not empty; node flags not empty",
-			netFlags:        map[string]interface{}{"1": 1},
-			beforeNodeFlags: map[string]interface{}{"2": 2},
-			afterNodeFlags:  map[string]interface{}{"1": 1, "2": 2},
-		},
-		{
-			name:            "net flags empty; node flags not empty",
-			netFlags:        map[string]interface{}{},
-			beforeNodeFlags: map[string]interface{}{"2": 2},
-			afterNodeFlags:  map[string]interface{}{"2": 2},
-		},
+	flows := map[link][]flow{
+		link01: {{srcPort: 40000, dstPort: 9653}},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			require := require.New(t)
-			addNetworkFlags(tt.netFlags, tt.beforeNodeFlags)
-			require.Equal(tt.afterNodeFlags, tt.beforeNodeFlags)
-		})
+	require.NoError(shaper.apply(context.Background(), conditions, flows))
+	require.Equal([]string{
+		"qdisc add dev lo root handle 1: htb",
+		"class add dev lo parent 1: classid 1:10 htb rate 100gbit",
+		"qdisc add dev lo parent 1:10 handle 10: netem delay 100000us 10000us",
+		"class add dev lo parent 1: classid 1:11 htb rate 100gbit",
+		"qdisc add dev lo parent 1:11 handle 11: netem loss 2.5%",
+		"filter add dev lo parent 1: protocol ip prio 1 u32 match ip sport 40000 0xffff match ip dport 9653 0xffff flowid 1:10",
+	}, commands)
+
+	// nothing is done if nothing changed
+	commands = commands[:0]
+	require.NoError(shaper.apply(context.Background(), conditions, flows))
+	require.Empty(commands)
+
+	// filters are replaced when the flows change, and classes are kept
+	conditions[link10] = network.LinkConditions{Loss: 5}
+	flows[link10] = []flow{{srcPort: 9653, dstPort: 40000}}
+	require.NoError(shaper.apply(context.Background(), conditions, flows))
+	require.Equal([]string{
+		"qdisc change dev lo parent 1:11 handle 11: netem loss 5%",
+		"filter del dev lo parent 1: prio 1",
+		"filter add dev lo parent 1: protocol ip prio 1 u32 match ip sport 40000 0xffff match ip dport 9653 0xffff flowid 1:10",
+		"filter add dev lo parent 1: protocol ip prio 1 u32 match ip sport 9653 0xffff match ip dport 40000 0xffff flowid 1:11",
+	}, commands)
+
+	commands = commands[:0]
+	require.NoError(shaper.close())
+	require.Equal([]string{"qdisc del dev lo root"}, commands)
+}
+
+// Creates processes with the given pids
+type localTestPIDProcessCreator struct {
+	localTestSuccessfulNodeProcessCreator
+	pids map[string]int
+}
+
+type localTestPIDProcess struct {
+	NodeProcess
+	processPID int
+}
+
+func (p *localTestPIDProcess) pid() int {
+	return p.processPID
+}
+
+func (lt *localTestPIDProcessCreator) NewNodeProcess(config node.Config, flags ...string) (NodeProcess, error) {
+	process, err := newMockProcessSuccessful(config, flags...)
+	if err != nil {
+		return nil, err
 	}
+	return &localTestPIDProcess{NodeProcess: process, processPID: lt.pids[config.Name]}, nil
 }
 
-func TestSetNodeName(t *testing.T) {
-	t.Parallel()
-	require := require.New(t)
+// Records the link conditions and flows it is given
+type localTestLinkShaper struct {
+	conditions map[link]network.LinkConditions
+	flows      map[link][]flow
+	closed     bool
+}
 
-	ln := &localNetwork{
-		nodes:          make(map[string]*localNode),
-		nextNodeSuffix: 1,
+func (s *localTestLinkShaper) apply(_ context.Context, conditions map[link]network.LinkConditions, flows map[link][]flow) error {
+	s.conditions = maps.Clone(conditions)
+	s.flows = flows
+	return nil
+}
+
+func (s *localTestLinkShaper) close() error {
+	s.closed = true
+	return nil
+}
+
+// Link conditions given on the config and at runtime are applied to
+// the connections between the nodes
+func TestLinkConditions(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.LinkConditions = []network.LinkSpec{{
+		From:       "node0",
+		To:         "node1",
+		Conditions: network.LinkConditions{Latency: 100 * time.Millisecond},
+	}}
+	processCreator := &localTestPIDProcessCreator{pids: map[string]int{"node0": 100, "node1": 200, "node2": 300}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	shaper := &localTestLinkShaper{}
+	net.newLinkShaper = func(logging.Logger) (linkShaper, error) {
+		return shaper, nil
 	}
+	net.procDir = testProcDir(t)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
 
-	// Case: No name given
-	config := &node.Config{Name: ""}
-	err := ln.setNodeName(config)
+	link01 := link{from: "node0", to: "node1"}
+	link20 := link{from: "node2", to: "node0"}
+	net.lock.RLock()
+	require.Equal(map[link]network.LinkConditions{link01: {Latency: 100 * time.Millisecond}}, shaper.conditions)
+	require.Equal([]flow{{srcPort: 0x9C40, dstPort: 9653}}, shaper.flows[link01])
+	net.lock.RUnlock()
+
+	require.NoError(net.SetLinkConditions(context.Background(), "node2", "node0", network.LinkConditions{Loss: 10}))
+	require.NoError(net.SetLinkConditions(context.Background(), "node0", "node1", network.LinkConditions{}))
+	net.lock.RLock()
+	require.Equal(map[link]network.LinkConditions{link20: {Loss: 10}}, shaper.conditions)
+	require.Equal([]flow{{srcPort: 0x9C41, dstPort: 9651}}, shaper.flows[link20])
+	net.lock.RUnlock()
+
+	require.Error(net.SetLinkConditions(context.Background(), "node0", "node3", network.LinkConditions{Loss: 10}))
+	require.Error(net.SetLinkConditions(context.Background(), "node0", "node1", network.LinkConditions{Loss: 101}))
+
+	require.NoError(net.Stop(context.Background()))
+	require.True(shaper.closed)
+	require.ErrorIs(net.SetLinkConditions(context.Background(), "node0", "node1", network.LinkConditions{}), network.ErrStopped)
+}
+
+// Runs a shell for each node, with a sleeping child, that exits
+// gracefully on SIGINT
+type localTestSleepProcessCreator struct {
+	localTestSuccessfulNodeProcessCreator
+}
+
+func (*localTestSleepProcessCreator) NewNodeProcess(config node.Config, _ ...string) (NodeProcess, error) {
+	cmd := exec.Command("sh", "-c", "trap 'kill $!; exit 0' INT; sleep 100 & wait")
+	return newNodeProcess(config.Name, logging.NoLog{}, cmd)
+}
+
+// Waits for the shell of [n] to start its child, as from then it
+// handles SIGINT
+func waitSleepChild(t *testing.T, n *localNode) {
+	proc, err := process.NewProcess(int32(n.process.(processWithPID).pid()))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		children, err := proc.Children()
+		return err == nil && len(children) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// Returns true if the process of [n] is stopped by a signal
+func isProcessStopped(t *testing.T, n *localNode) bool {
+	proc, err := process.NewProcess(int32(n.process.(processWithPID).pid()))
+	require.NoError(t, err)
+	processStatus, err := proc.Status()
+	require.NoError(t, err)
+	return processStatus == "T"
+}
+
+func TestFreezeNode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("processes can't be frozen on windows")
+	}
+	require := require.New(t)
+	ctx := context.Background()
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSleepProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	require.Equal("node1", config.Name)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+	for _, node := range net.nodes {
+		waitSleepChild(t, node)
+	}
 
-	// Case: No name given again
-	config.Name = ""
-	err = ln.setNodeName(config)
+	require.NoError(net.FreezeNode(ctx, "node0"))
+	node0 := net.nodes["node0"]
+	require.True(isProcessStopped(t, node0))
+	require.Equal(status.Running, node0.Status())
+	require.Error(net.FreezeNode(ctx, "node0"))
+	require.NoError(net.UnfreezeNode(ctx, "node0"))
+	require.Eventually(func() bool {
+		return !isProcessStopped(t, node0)
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Error(net.UnfreezeNode(ctx, "node0"))
+	require.Error(net.FreezeNode(ctx, "node3"))
+
+	// paused nodes have no process to freeze
+	require.NoError(net.PauseNode(ctx, "node2"))
+	require.Error(net.FreezeNode(ctx, "node2"))
+
+	// frozen nodes are stopped gracefully
+	require.NoError(net.FreezeNode(ctx, "node1"))
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	result, err := network.StopWithResult(stopCtx, net)
 	require.NoError(err)
-	require.Equal("node1", config.Name)
+	require.Empty(result.Killed())
+	require.ErrorIs(net.FreezeNode(ctx, "node0"), network.ErrStopped)
 
-	// Case: name given
-	config.Name = "hi"
-	err = ln.setNodeName(config)
+	// processes that can't be frozen are reported
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	require.Equal("hi", config.Name)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+	require.ErrorIs(net.FreezeNode(ctx, "node0"), errFreezeUnsupported)
+	require.NoError(net.Stop(ctx))
+}
 
-	// Case: name already present
-	config.Name = "hi"
-	ln.nodes = map[string]*localNode{
-		"hi": {},
+// Killed nodes don't get to handle the interrupt, and can be added back
+// on their data dir
+func TestKillNode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a unix shell")
 	}
-	err = ln.setNodeName(config)
+	require := require.New(t)
+	ctx := context.Background()
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSleepProcessCreator{}, t.TempDir(), "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+
+	node0, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	nodeConfig := node0.GetConfig()
+	dataDir := node0.GetDataDir()
+	require.NoError(net.KillNode(ctx, "node0"))
+	_, err = net.GetNode(context.Background(), "node0")
 	require.Error(err)
+	history, err := net.GetNodeHistory("node0")
+	require.NoError(err)
+	require.Len(history, 1)
+	require.False(history[0].Crashed)
+	// the shell trap exits with 0 on interrupt
+	require.Equal(-1, history[0].ExitCode)
+	require.Equal("killed", history[0].Signal)
+	require.Error(net.KillNode(ctx, "node0"))
+
+	node0, err = net.AddNode(context.Background(), nodeConfig)
+	require.NoError(err)
+	require.Equal(dataDir, node0.GetDataDir())
+	for _, node := range net.nodes {
+		waitSleepChild(t, node)
+	}
+	require.NoError(net.Stop(ctx))
+	require.ErrorIs(net.KillNode(ctx, "node1"), network.ErrStopped)
 }
 
-func TestGetConfigEntry(t *testing.T) {
-	t.Parallel()
+// Killed nodes have their output file closed, and can be added back
+// under the same name
+func TestKillNodeOutputFile(t *testing.T) {
 	require := require.New(t)
+	ctx := context.Background()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, testNetworkConfig(t)))
+	defer net.Stop(ctx) //nolint:errcheck
 
-	// case: key not present
-	val, err := getConfigEntry(
-		map[string]interface{}{},
-		map[string]interface{}{"2": "2"},
-		"1",
-		"1",
-	)
+	nodeConfig := node.Config{Name: "node3", OutputFile: &node.OutputFileConfig{}}
+	node3, err := net.AddNode(ctx, nodeConfig)
 	require.NoError(err)
-	require.Equal("1", val)
+	outputFile := node3.GetOutputFilePaths()
+	require.NotEmpty(outputFile)
+	require.Contains(net.outputFiles, "node3")
 
-	// case: key present
-	val, err = getConfigEntry(
-		map[string]interface{}{},
-		map[string]interface{}{"1": "hi", "2": "2"},
-		"1",
-		"1",
-	)
+	require.NoError(net.KillNode(ctx, "node3"))
+	require.NotContains(net.outputFiles, "node3")
+
+	node3, err = net.AddNode(ctx, nodeConfig)
 	require.NoError(err)
-	require.Equal("hi", val)
+	require.Equal(outputFile, node3.GetOutputFilePaths())
+	require.Contains(net.outputFiles, "node3")
+	require.NoError(net.KillNode(ctx, "node3"))
+	require.Empty(net.outputFiles)
+}
 
-	// case: key present wrong type
-	_, err = getConfigEntry(
-		map[string]interface{}{},
-		map[string]interface{}{"1": 1, "2": "2"},
-		"1",
-		"1",
-	)
-	require.Error(err)
+// Runs node processes that exit with code 3 the given number of times
+// per node, and then shells that sleep
+type localTestCrashingProcessCreator struct {
+	localTestSleepProcessCreator
+	crashes map[string]int
 }
 
-func TestGetPort(t *testing.T) {
-	t.Parallel()
+func (lt *localTestCrashingProcessCreator) NewNodeProcess(config node.Config, flags ...string) (NodeProcess, error) {
+	if lt.crashes[config.Name] == 0 {
+		return lt.localTestSleepProcessCreator.NewNodeProcess(config, flags...)
+	}
+	lt.crashes[config.Name]--
+	return newNodeProcess(config.Name, logging.NoLog{}, exec.Command("sh", "-c", "exit 3"))
+}
+
+// Nodes whose process exits are restarted as given by their restart policy
+func TestRestartPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a unix shell")
+	}
 	require := require.New(t)
+	ctx := context.Background()
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[0].RestartPolicy = &node.RestartPolicy{Mode: node.RestartOnFailure, Backoff: 10 * time.Millisecond}
+	networkConfig.NodeConfigs[1].RestartPolicy = &node.RestartPolicy{Mode: node.RestartAlways, MaxRetries: 1, Backoff: 10 * time.Millisecond}
+	processCreator := &localTestCrashingProcessCreator{crashes: map[string]int{"node0": 2, "node1": 5, "node2": 1}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	events := net.Events()
+	require.NoError(net.loadConfig(ctx, networkConfig))
 
-	// Case: port key present in config file
-	port, err := getPort(
-		map[string]interface{}{},
-		map[string]interface{}{"flag": float64(10013)},
-		"flag",
-	)
+	restarted := map[string]int{}
+	for restarted["node0"] < 2 || restarted["node1"] < 1 {
+		select {
+		case event := <-events:
+			if event.Type == network.EventNodeRestarted {
+				restarted[event.NodeName]++
+			}
+		case <-time.After(5 * time.Second):
+			require.FailNow("nodes not restarted", restarted)
+		}
+	}
+	// node1 is not restarted once it runs out of retries
+	require.Eventually(func() bool {
+		history, err := net.GetNodeHistory("node1")
+		require.NoError(err)
+		return len(history) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	net.lock.RLock()
+	node0, node1, node2 := net.nodes["node0"], net.nodes["node1"], net.nodes["node2"]
+	require.Equal(2, node0.restarts)
+	require.Equal(1, node1.restarts)
+	require.Equal(0, node2.restarts)
+	net.lock.RUnlock()
+	require.Equal(status.Running, node0.Status())
+	require.Equal(status.Stopped, node1.Status())
+	require.Equal(status.Stopped, node2.Status())
+	history, err := net.GetNodeHistory("node0")
 	require.NoError(err)
-	require.Equal(uint16(10013), port)
+	require.Len(history, 2)
+	require.True(history[1].Crashed)
+	require.Equal(3, history[1].ExitCode)
+	require.Equal(map[string]int{"node0": 2, "node1": 1}, restarted)
 
-	// Case: port key present in flags
-	port, err = getPort(
-		map[string]interface{}{"flag": 10013},
-		map[string]interface{}{},
-		"flag",
-	)
+	waitSleepChild(t, node0)
+	_ = net.Stop(ctx)
+}
+
+// TestRefreshBootstraps tests that nodes started with beacons other than
+// the current ones are restarted with the current ones
+func TestRefreshBootstraps(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	require.Equal(uint16(10013), port)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+	bootstrapIDs := func(nodeName string) string {
+		net.lock.RLock()
+		defer net.lock.RUnlock()
+		return net.nodes[nodeName].flags[config.BootstrapIDsKey]
+	}
 
-	// Case: port key present in config file and flags
-	port, err = getPort(
-		map[string]interface{}{"flag": 10013},
-		map[string]interface{}{"flag": float64(14)},
-		"flag",
-	)
+	require.ErrorIs(net.SetBeacons(ctx, nil), errNoBeacons)
+	require.ErrorContains(net.SetBeacons(ctx, []string{"node5"}), `node "node5" not found`)
+
+	// all the nodes are beacons, so node2 bootstraps from node0 and node1
+	require.NoError(net.SetBeacons(ctx, []string{"node0"}))
+	restarted, err := net.RefreshBootstraps(ctx)
 	require.NoError(err)
-	require.Equal(uint16(10013), port)
+	require.Equal([]string{"node2"}, restarted)
+	node0ID := net.nodes["node0"].GetNodeID().String()
+	require.Equal(node0ID, bootstrapIDs("node2"))
+	require.False(net.nodes["node2"].GetConfig().IsBeacon)
 
-	// Case: port key not present
-	_, err = getPort(
-		map[string]interface{}{},
-		map[string]interface{}{},
-		"flag",
-	)
+	// nodes added later don't depend on the removed beacon once refreshed
+	_, err = net.AddNode(context.Background(), node.Config{Name: "node3"})
+	require.NoError(err)
+	require.Equal(node0ID, bootstrapIDs("node3"))
+	require.NoError(net.SetBeacons(ctx, []string{"node1"}))
+	require.NoError(net.RemoveNode(ctx, "node0"))
+	restarted, err = net.RefreshBootstraps(ctx)
+	require.NoError(err)
+	require.Equal([]string{"node1", "node2", "node3"}, restarted)
+	require.Empty(bootstrapIDs("node1"))
+	require.Equal(net.nodes["node1"].GetNodeID().String(), bootstrapIDs("node3"))
+	require.True(net.nodes["node1"].GetConfig().IsBeacon)
+	restarted, err = net.RefreshBootstraps(ctx)
 	require.NoError(err)
+	require.Empty(restarted)
+
+	require.NoError(net.Stop(ctx))
+	_, err = net.RefreshBootstraps(ctx)
+	require.ErrorIs(err, network.ErrStopped)
 }
 
-func TestCreateFileAndWrite(t *testing.T) {
-	t.Parallel()
+// Nodes without staking identity, or with just half of it, get a new one
+func TestGeneratedStakingIdentity(t *testing.T) {
 	require := require.New(t)
-	dir, err := os.MkdirTemp("", "network-runner-test-*")
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[1].StakingKey = ""
+	networkConfig.NodeConfigs[1].StakingCert = ""
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	path := filepath.Join(dir, "path")
-	contents := []byte("hi")
-	err = createFileAndWrite(path, contents)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	generated, err := net.GetNode(context.Background(), "node1")
 	require.NoError(err)
-	gotBytes, err := os.ReadFile(path)
+	nodeConfig := generated.GetConfig()
+	require.NotEmpty(nodeConfig.StakingKey)
+	nodeID, err := utils.ToNodeID([]byte(nodeConfig.StakingKey), []byte(nodeConfig.StakingCert))
 	require.NoError(err)
-	require.Equal(contents, gotBytes)
-}
+	require.Equal(nodeID, generated.GetNodeID())
 
-func TestWriteFiles(t *testing.T) {
-	t.Parallel()
-	stakingKey := "stakingKey"
-	stakingCert := "stakingCert"
-	genesis := []byte("genesis")
-	configFile := "config file"
-	chainConfigFiles := map[string]string{
-		"C": "c-chain config file",
-	}
-	tmpDir, err := os.MkdirTemp("", "avalanche-network-runner-tests-*")
-	if err != nil {
-		t.Fatal(err)
-	}
-	stakingKeyPath := filepath.Join(tmpDir, stakingKeyFileName)
-	stakingCertPath := filepath.Join(tmpDir, stakingCertFileName)
-	stakingSigningKeyPath := filepath.Join(tmpDir, stakingSigningKeyFileName)
-	genesisPath := filepath.Join(tmpDir, genesisFileName)
-	configFilePath := filepath.Join(tmpDir, configFileName)
-	chainConfigDir := filepath.Join(tmpDir, chainConfigSubDir)
-	subnetConfigDir := filepath.Join(tmpDir, subnetConfigSubDir)
-	cChainConfigPath := filepath.Join(tmpDir, chainConfigSubDir, "C", configFileName)
+	// node IDs can be known before adding the nodes
+	stakingKey, stakingCert, nodeID, err := utils.NewStakingIdentity()
+	require.NoError(err)
+	added, err := net.AddNode(context.Background(), node.Config{StakingKey: string(stakingKey), StakingCert: string(stakingCert)})
+	require.NoError(err)
+	require.Equal(nodeID, added.GetNodeID())
 
-	type test struct {
-		name          string
-		shouldErr     bool
-		genesis       []byte
-		nodeConfig    node.Config
-		expectedFlags map[string]string
-	}
+	added, err = net.AddNode(context.Background(), node.Config{StakingKey: string(stakingKey)})
+	require.NoError(err)
+	require.NotEqual(nodeID, added.GetNodeID())
+	require.NotEqual(string(stakingKey), added.GetConfig().StakingKey)
+}
 
-	tests := []test{
+// TestGenerateDefaultNetwork create a default network with config from NewDefaultConfig and
+// check expected number of nodes, node names, and avalanchego node ids
+func TestGenerateDefaultNetwork(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	binaryPath := "pepito"
+	networkConfig := NewDefaultConfig(binaryPath)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), networkConfig)
+	require.NoError(err)
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Len(names, 5)
+	for _, nodeInfo := range []struct {
+		name string
+		ID   string
+	}{
 		{
-			name:      "no config files given",
-			shouldErr: false,
-			genesis:   genesis,
-			nodeConfig: node.Config{
-				StakingKey:  stakingKey,
-				StakingCert: stakingCert,
-			},
-			expectedFlags: map[string]string{
-				config.StakingTLSKeyPathKey:    stakingKeyPath,
-				config.StakingCertPathKey:      stakingCertPath,
-				config.StakingSignerKeyPathKey: stakingSigningKeyPath,
-				config.GenesisFileKey:          genesisPath,
-				config.ChainConfigDirKey:       chainConfigDir,
-				config.SubnetConfigDirKey:      subnetConfigDir,
-			},
+			"node1",
+			"NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg",
 		},
 		{
-			name:      "config file given but not c-chain config file",
-			shouldErr: false,
-			genesis:   genesis,
-			nodeConfig: node.Config{
-				StakingKey:  stakingKey,
-				StakingCert: stakingCert,
-				ConfigFile:  configFile,
-			},
-			expectedFlags: map[string]string{
-				config.StakingTLSKeyPathKey:    stakingKeyPath,
-				config.StakingCertPathKey:      stakingCertPath,
-				config.StakingSignerKeyPathKey: stakingSigningKeyPath,
-				config.GenesisFileKey:          genesisPath,
-				config.ChainConfigDirKey:       chainConfigDir,
-				config.SubnetConfigDirKey:      subnetConfigDir,
-				config.ConfigFileKey:           configFilePath,
-			},
+			"node2",
+			"NodeID-MFrZFVCXPv5iCn6M9K6XduxGTYp891xXZ",
 		},
 		{
-			name:      "config file and c-chain config file given",
-			shouldErr: false,
-			genesis:   genesis,
-			nodeConfig: node.Config{
-				StakingKey:       stakingKey,
-				StakingCert:      stakingCert,
-				ConfigFile:       configFile,
-				ChainConfigFiles: chainConfigFiles,
-			},
-			expectedFlags: map[string]string{
-				config.StakingTLSKeyPathKey:    stakingKeyPath,
-				config.StakingCertPathKey:      stakingCertPath,
-				config.StakingSignerKeyPathKey: stakingSigningKeyPath,
-				config.GenesisFileKey:          genesisPath,
-				config.ChainConfigDirKey:       chainConfigDir,
-				config.SubnetConfigDirKey:      subnetConfigDir,
-				config.ConfigFileKey:           configFilePath,
-			},
+			"node3",
+			"NodeID-NFBbbJ4qCmNaCzeW7sxErhvWqvEQMnYcN",
+		},
+		{
+			"node4",
+			"NodeID-GWPcbFJZFfZreETSoWjPimr846mXEKCtu",
+		},
+		{
+			"node5",
+			"NodeID-P7oB2McjBGgW2NXXWVYjV8JEDFoW9xDE5",
 		},
+	} {
+		require.Contains(names, nodeInfo.name)
+		node, err := net.GetNode(context.Background(), nodeInfo.name)
+		require.NoError(err)
+		require.EqualValues(nodeInfo.name, node.GetName())
+		expectedID, err := ids.NodeIDFromString(nodeInfo.ID)
+		require.NoError(err)
+		require.EqualValues(expectedID, node.GetNodeID())
 	}
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			require := require.New(t)
-			flags, err := writeFiles(0, tt.genesis, tmpDir, &tt.nodeConfig)
-			if tt.shouldErr {
-				require.Error(err)
-				return
-			}
-			require.NoError(err)
-			// Make sure returned flags are right
-			require.Len(tt.expectedFlags, len(flags))
-			for k := range flags {
-				require.Equal(tt.expectedFlags[k], flags[k])
-			}
-			// Assert files created correctly
-			gotStakingKey, err := os.ReadFile(stakingKeyPath)
-			require.NoError(err)
-			require.Equal([]byte(tt.nodeConfig.StakingKey), gotStakingKey)
-			gotStakingCert, err := os.ReadFile(stakingCertPath)
-			require.NoError(err)
-			require.Equal([]byte(tt.nodeConfig.StakingCert), gotStakingCert)
-			gotGenesis, err := os.ReadFile(genesisPath)
-			require.NoError(err)
-			require.Equal(tt.genesis, gotGenesis)
-			if len(tt.nodeConfig.ConfigFile) > 0 {
-				gotConfigFile, err := os.ReadFile(configFilePath)
-				require.NoError(err)
-				require.Equal([]byte(configFile), gotConfigFile)
-			}
-			if tt.nodeConfig.ChainConfigFiles != nil {
-				gotCChainConfigFile, err := os.ReadFile(cChainConfigPath)
-				require.NoError(err)
-				require.Equal([]byte(chainConfigFiles["C"]), gotCChainConfigFile)
-			}
-		})
+// TODO add byzantine node to conf
+// TestNetworkFromConfig creates/waits/checks/stops a network from config file
+// the check verify that all the nodes can be accessed
+func TestNetworkFromConfig(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), networkConfig)
+	require.NoError(err)
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	runningNodes := make(map[string]struct{})
+	for _, nodeConfig := range networkConfig.NodeConfigs {
+		runningNodes[nodeConfig.Name] = struct{}{}
 	}
+	checkNetwork(t, net, runningNodes, nil)
 }
 
-func TestRemoveBeacon(t *testing.T) {
+// TestNetworkNodeOps creates an empty network,
+// adds nodes one by one, then removes nodes one by one.
+// Setween all operations, a network check is performed
+// to verify that all the running nodes are in the network,
+// and all removed nodes are not.
+func TestNetworkNodeOps(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
 
-	// create a network with no nodes in it
+	// Start a new, empty network
 	emptyNetworkConfig, err := emptyNetworkConfig()
 	require.NoError(err)
 	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
 	err = net.loadConfig(context.Background(), emptyNetworkConfig)
 	require.NoError(err)
+	runningNodes := make(map[string]struct{})
 
-	// a network config for a 3 node staking network, and add the bootstrapper
-	// to the exesting network
+	// Add nodes to the network one by one
 	networkConfig := testNetworkConfig(t)
-	_, err = net.AddNode(networkConfig.NodeConfigs[0])
-	require.NoError(err)
+	for _, nodeConfig := range networkConfig.NodeConfigs {
+		_, err := net.AddNode(context.Background(), nodeConfig)
+		require.NoError(err)
+		runningNodes[nodeConfig.Name] = struct{}{}
+		checkNetwork(t, net, runningNodes, nil)
+	}
+	// Wait for all nodes to be healthy
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
 
-	// remove the beacon node from the network
+	// Remove nodes one by one
+	removedNodes := make(map[string]struct{})
+	for _, nodeConfig := range networkConfig.NodeConfigs {
+		_, err := net.GetNode(context.Background(), nodeConfig.Name)
+		require.NoError(err)
+		err = net.RemoveNode(context.Background(), nodeConfig.Name)
+		require.NoError(err)
+		removedNodes[nodeConfig.Name] = struct{}{}
+		delete(runningNodes, nodeConfig.Name)
+		checkNetwork(t, net, runningNodes, removedNodes)
+	}
+}
+
+// TestNodeNotFound checks all operations fail for an unknown node,
+// being it either not created, or created and removed thereafter
+func TestNodeNotFound(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	emptyNetworkConfig, err := emptyNetworkConfig()
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), emptyNetworkConfig)
+	require.NoError(err)
+	_, err = net.AddNode(context.Background(), networkConfig.NodeConfigs[0])
+	require.NoError(err)
+	// get node
+	_, err = net.GetNode(context.Background(), networkConfig.NodeConfigs[0].Name)
+	require.NoError(err)
+	// get non-existent node
+	_, err = net.GetNode(context.Background(), networkConfig.NodeConfigs[1].Name)
+	require.Error(err)
+	// remove non-existent node
+	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[1].Name)
+	require.Error(err)
+	// remove node
 	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[0].Name)
 	require.NoError(err)
-	require.Equal(0, net.bootstraps.Len())
+	// get removed node
+	_, err = net.GetNode(context.Background(), networkConfig.NodeConfigs[0].Name)
+	require.Error(err)
+	// remove already-removed node
+	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[0].Name)
+	require.Error(err)
 }
 
-// Returns an API client where:
-//   - The Health API's Health method always returns an error after the
-//     given context is cancelled.
-//   - The CChainEthAPI's Close method may be called
-//   - Only the above 2 methods may be called
-func newMockAPIHealthyBlocks(string, uint16) api.Client {
-	healthClient := &healthmocks.Client{}
-	healthClient.On("Health", mock.MatchedBy(func(_ context.Context) bool {
-		return true
-	}), mock.Anything, mock.Anything).Return(
-		func(ctx context.Context, _ []string, _ ...rpc.Option) *health.APIReply {
-			<-ctx.Done()
-			return nil
-		},
-		func(ctx context.Context, _ []string, _ ...rpc.Option) error {
-			<-ctx.Done()
-			return ctx.Err()
-		},
-	)
-	// ethClient used when removing nodes, to close websocket connection
-	ethClient := &apimocks.EthClient{}
-	ethClient.On("Close").Return()
-	client := &apimocks.Client{}
-	client.On("HealthAPI").Return(healthClient)
-	client.On("CChainEthAPI").Return(ethClient)
-	return client
+// TestNodeStartContext checks nodes are not started once the context given
+// to create the network or add the node is done
+func TestNodeStartContext(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(canceledCtx, testNetworkConfig(t))
+	require.ErrorIs(err, context.Canceled)
+	require.ErrorIs(err, network.ErrPartialStart)
+	// stopped on failure
+	_, err = net.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+	require.ErrorIs(net.Stop(context.Background()), network.ErrStopped)
+
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	_, err = net.AddNode(canceledCtx, node.Config{Name: "node3"})
+	require.ErrorIs(err, context.Canceled)
+	_, err = net.GetNode(context.Background(), "node3")
+	require.ErrorIs(err, network.ErrNodeNotFound)
+	_, err = net.GetNode(canceledCtx, "node0")
+	require.ErrorIs(err, context.Canceled)
+	_, err = net.AddNode(context.Background(), node.Config{Name: "node3"})
+	require.NoError(err)
+	require.NoError(net.Stop(context.Background()))
 }
 
-// Assert that if the network's Stop method is called while
-// a call to Healthy is ongoing, Healthy returns immediately.
-func TestHealthyDuringNetworkStop(t *testing.T) {
+// TestStoppedNetwork checks that operations fail for an already stopped network
+func TestStoppedNetwork(t *testing.T) {
+	t.Parallel()
 	require := require.New(t)
+	emptyNetworkConfig, err := emptyNetworkConfig()
+	require.NoError(err)
 	networkConfig := testNetworkConfig(t)
-	// Calls to a node's Healthy() function blocks until context cancelled
-	net, err := newNetwork(logging.NoLog{}, newMockAPIHealthyBlocks, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
 	require.NoError(err)
-	err = net.loadConfig(context.Background(), networkConfig)
+	err = net.loadConfig(context.Background(), emptyNetworkConfig)
+	require.NoError(err)
+	_, err = net.AddNode(context.Background(), networkConfig.NodeConfigs[0])
+	require.NoError(err)
+	// first GetNodeNames should return some nodes
+	_, err = net.GetNodeNames()
 	require.NoError(err)
-
-	healthyChan := make(chan error)
-	go func() {
-		healthyChan <- net.Healthy(context.Background())
-	}()
-	// Wait to make sure we're actually blocking on Health API call
-	time.Sleep(500 * time.Millisecond)
 	err = net.Stop(context.Background())
 	require.NoError(err)
-	select {
-	case err := <-healthyChan:
-		require.Error(err)
-	case <-time.After(1 * time.Second):
-		// Since [net.Stop] was called, [net.Healthy] should immediately return.
-		// We assume that it will do so within 1 second.
-		require.Fail("Healthy should've returned immediately because network closed")
-	}
+	// Stop failure
+	require.EqualValues(net.Stop(context.Background()), network.ErrStopped)
+	// AddNode failure
+	_, err = net.AddNode(context.Background(), networkConfig.NodeConfigs[1])
+	require.EqualValues(network.ErrStopped, err)
+	// GetNode failure
+	_, err = net.GetNode(context.Background(), networkConfig.NodeConfigs[0].Name)
+	require.EqualValues(err, network.ErrStopped)
+	// second GetNodeNames should return no nodes
+	_, err = net.GetNodeNames()
+	require.EqualValues(network.ErrStopped, err)
+	// RemoveNode failure
+	require.EqualValues(network.ErrStopped, net.RemoveNode(context.Background(), networkConfig.NodeConfigs[0].Name))
+	// Healthy failure
+	require.EqualValues(awaitNetworkHealthy(net, defaultHealthyTimeout), network.ErrStopped)
+	_, err = net.GetAllNodes()
+	require.EqualValues(err, network.ErrStopped)
+}
+
+func TestGetAllNodes(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), networkConfig)
+	require.NoError(err)
+
+	nodes, err := net.GetAllNodes()
+	require.NoError(err)
+	require.Len(nodes, len(net.nodes))
+	for name, node := range net.nodes {
+		require.EqualValues(node, nodes[name])
+	}
+}
+
+// TestFlags tests that we can pass flags through the network.Config
+// but also via node.Config and that the latter overrides the former
+// if same keys exist.
+func TestFlags(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+
+	// submit both network.Config flags and node.Config
+	networkConfig.Flags = map[string]interface{}{
+		"test-network-config-flag": "something",
+		"common-config-flag":       "should not be added",
+	}
+	for i := range networkConfig.NodeConfigs {
+		v := &networkConfig.NodeConfigs[i]
+		v.Flags = map[string]interface{}{
+			"test-node-config-flag":  "node",
+			"test2-node-config-flag": "config",
+			"common-config-flag":     "this should be added",
+		}
+	}
+	expectedFlags := map[string]interface{}{
+		"test-network-config-flag": "something",
+		"common-config-flag":       "this should be added",
+		"test-node-config-flag":    "node",
+		"test2-node-config-flag":   "config",
+	}
+
+	nw, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestFlagCheckProcessCreator{
+			// after creating the network, one flag should have been overridden by the node configs
+			expectedFlags: expectedFlags,
+			require:       require,
+		},
+		"",
+		"",
+		false,
+		false,
+		false,
+	)
+	require.NoError(err)
+	err = nw.loadConfig(context.Background(), networkConfig)
+	require.NoError(err)
+	err = nw.Stop(context.Background())
+	require.NoError(err)
+
+	// submit only node.Config flags
+	networkConfig.Flags = nil
+	flags := map[string]interface{}{
+		"test-node-config-flag":  "node",
+		"test2-node-config-flag": "config",
+		"common-config-flag":     "this should be added",
+	}
+	for i := range networkConfig.NodeConfigs {
+		v := &networkConfig.NodeConfigs[i]
+		v.Flags = flags
+	}
+	nw, err = newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestFlagCheckProcessCreator{
+			// after creating the network, only node configs should exist
+			expectedFlags: flags,
+			require:       require,
+		},
+		"",
+		"",
+		false,
+		false,
+		false,
+	)
+	require.NoError(err)
+	err = nw.loadConfig(context.Background(), networkConfig)
+	require.NoError(err)
+	err = nw.Stop(context.Background())
+	require.NoError(err)
+
+	// submit only network.Config flags
+	flags = map[string]interface{}{
+		"test-network-config-flag": "something",
+		"common-config-flag":       "else",
+	}
+	networkConfig.Flags = flags
+	for i := range networkConfig.NodeConfigs {
+		v := &networkConfig.NodeConfigs[i]
+		v.Flags = nil
+	}
+	nw, err = newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestFlagCheckProcessCreator{
+			// after creating the network, only flags from the network config should exist
+			expectedFlags: flags,
+			require:       require,
+		},
+		"",
+		"",
+		false,
+		false,
+		false,
+	)
+	require.NoError(err)
+	err = nw.loadConfig(context.Background(), networkConfig)
+	require.NoError(err)
+	err = nw.Stop(context.Background())
+	require.NoError(err)
+}
+
+// for the TestChildCmdRedirection we need to be able to wait
+// until the buffer is written to or else there is a race condition
+type lockedBuffer struct {
+	bytes.Buffer
+	// [writtenCh] is closed after Write is called
+	writtenCh chan struct{}
+}
+
+// Write is locked for the lockedBuffer
+func (m *lockedBuffer) Write(b []byte) (int, error) {
+	defer close(m.writtenCh)
+	return m.Buffer.Write(b)
+}
+
+// TestChildCmdRedirection checks that RedirectStdout set to true on a NodeConfig
+// results indeed in the output being prepended and colored.
+// For the color check we just measure the length of the required terminal escape values
+func TestChildCmdRedirection(t *testing.T) {
+	t.Parallel()
+	// we need this to create the actual process we test
+	buf := &lockedBuffer{
+		writtenCh: make(chan struct{}),
+	}
+	npc := &nodeProcessCreator{
+		log:         logging.NoLog{},
+		stdout:      buf,
+		stderr:      buf,
+		colorPicker: utils.NewColorPicker(),
+	}
+
+	// define a bogus output
+	testOutput := "this is the output"
+	// we will use `echo` with the testOutput as we will get a measurable result
+	ctrlCmd := exec.Command("echo", testOutput)
+	// we would not really need to execute the command, just the output would be enough
+	// nevertheless let's do it to simulate the actual case
+	expectedResult, err := ctrlCmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// this is the "mock" node name we want to see prepended to the output
+	mockNodeName := "redirect-test-node"
+
+	// now create the node process and check it will be prepended and colored
+	testConfig := node.Config{
+		BinaryPath:     "sh",
+		RedirectStdout: true,
+		RedirectStderr: true,
+		Name:           mockNodeName,
+	}
+	// Sleep for a second after echoing so that we have a chance to read from the stdout pipe
+	// before it closes when the process exits and Wait() returns.
+	// See https://pkg.go.dev/os/exec#Cmd.StdoutPipe
+	proc, err := npc.NewNodeProcess(testConfig, "-c", fmt.Sprintf("echo %s && sleep 1", testOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lock read access to the buffer
+	<-buf.writtenCh
+	result := buf.String()
+
+	// wait for the process to finish.
+	_ = proc.Stop(context.Background())
+
+	// now do the checks:
+	// the new string should contain the node name
+	if !strings.Contains(result, mockNodeName) {
+		t.Fatalf("expected subcommand to contain node name %s, but it didn't", mockNodeName)
+	}
+
+	// and it should have a specific length:
+	//             the actual output   + the color terminal escape sequence      + node name    + []<space> + color terminal reset escape sequence
+	expectedLen := len(expectedResult) + len(utils.NewColorPicker().NextColor()) + len(mockNodeName) + 3 + len(logging.Reset)
+	if len(result) != expectedLen {
+		t.Fatalf("expected string length to be %d, but it was %d", expectedLen, len(result))
+	}
+}
+
+// checkNetwork receives a network, a set of running nodes (started and not removed yet), and
+// a set of removed nodes, checking:
+// - GetNodeNames retrieves the correct number of running nodes
+// - GetNode does not fail for given running nodes
+// - GetNode does fail for given stopped nodes
+func checkNetwork(t *testing.T, net network.Network, runningNodes map[string]struct{}, removedNodes map[string]struct{}) {
+	require := require.New(t)
+	nodeNames, err := net.GetNodeNames()
+	require.NoError(err)
+	require.EqualValues(len(nodeNames), len(runningNodes))
+	for nodeName := range runningNodes {
+		_, err := net.GetNode(context.Background(), nodeName)
+		require.NoError(err)
+	}
+	for nodeName := range removedNodes {
+		_, err := net.GetNode(context.Background(), nodeName)
+		require.Error(err)
+	}
+}
+
+// Return a network config that has no nodes
+func emptyNetworkConfig() (network.Config, error) {
+	networkID := uint32(1337)
+	// Use a dummy genesis
+	genesis, err := network.NewAvalancheGoGenesis(
+		networkID,
+		[]network.AddrAndBalance{
+			{
+				Addr:    ids.GenerateTestShortID(),
+				Balance: big.NewInt(1),
+			},
+		},
+		nil,
+		[]ids.NodeID{ids.GenerateTestNodeID()},
+	)
+	if err != nil {
+		return network.Config{}, err
+	}
+	return network.Config{
+		Genesis: string(genesis),
+	}, nil
+}
+
+// Returns a config for a three node network,
+// where the nodes have randomly generated staking
+// keys and certificates.
+func testNetworkConfig(t *testing.T) network.Config {
+	require := require.New(t)
+	networkConfig, err := NewDefaultConfigNNodes("pepito", 3)
+	require.NoError(err)
+	for i := 0; i < 3; i++ {
+		networkConfig.NodeConfigs[i].Name = fmt.Sprintf("node%d", i)
+		delete(networkConfig.NodeConfigs[i].Flags, config.HTTPPortKey)
+		delete(networkConfig.NodeConfigs[i].Flags, config.StakingPortKey)
+	}
+	return networkConfig
+}
+
+// Returns nil when all the nodes in [net] are healthy,
+// or an error if one doesn't become healthy within
+// the timeout.
+func awaitNetworkHealthy(net network.Network, timeout time.Duration) error { //nolint
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return net.Healthy(ctx)
+}
+
+func TestAddNetworkFlags(t *testing.T) {
+	t.Parallel()
+	type test struct {
+		name            string
+		netFlags        map[string]interface{}
+		beforeNodeFlags map[string]interface{}
+		afterNodeFlags  map[string]interface{}
+	}
+	tests := []test{
+		{
+			name:            "all empty",
+			netFlags:        map[string]interface{}{},
+			beforeNodeFlags: map[string]interface{}{},
+			afterNodeFlags:  map[string]interface{}{},
+		},
+		{
+			name:            "net flags not empty; node flags empty",
+			netFlags:        map[string]interface{}{"1": 1},
+			beforeNodeFlags: map[string]interface{}{},
+			afterNodeFlags:  map[string]interface{}{"1": 1},
+		},
+		{
+			name:            "net flags not empty; node flags not empty",
+			netFlags:        map[string]interface{}{"1": 1},
+			beforeNodeFlags: map[string]interface{}{"2": 2},
+			afterNodeFlags:  map[string]interface{}{"1": 1, "2": 2},
+		},
+		{
+			name:            "net flags empty; node flags not empty",
+			netFlags:        map[string]interface{}{},
+			beforeNodeFlags: map[string]interface{}{"2": 2},
+			afterNodeFlags:  map[string]interface{}{"2": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			addNetworkFlags(tt.netFlags, tt.beforeNodeFlags)
+			require.Equal(tt.afterNodeFlags, tt.beforeNodeFlags)
+		})
+	}
+}
+
+func TestSetNodeName(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	ln := &localNetwork{
+		nodes:          make(map[string]*localNode),
+		nextNodeSuffix: 1,
+	}
+
+	// Case: No name given
+	config := &node.Config{Name: ""}
+	err := ln.setNodeName(config)
+	require.NoError(err)
+	require.Equal("node1", config.Name)
+
+	// Case: No name given again. Generated names are not reused.
+	config.Name = ""
+	err = ln.setNodeName(config)
+	require.NoError(err)
+	require.Equal("node2", config.Name)
+
+	// Case: name given
+	config.Name = "hi"
+	err = ln.setNodeName(config)
+	require.NoError(err)
+	require.Equal("hi", config.Name)
+
+	// Case: name already present
+	config.Name = "hi"
+	ln.nodes = map[string]*localNode{
+		"hi": {},
+	}
+	err = ln.setNodeName(config)
+	require.Error(err)
+}
+
+func TestGetConfigEntry(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// case: key not present
+	val, err := getConfigEntry(
+		map[string]interface{}{},
+		map[string]interface{}{"2": "2"},
+		"1",
+		"1",
+	)
+	require.NoError(err)
+	require.Equal("1", val)
+
+	// case: key present
+	val, err = getConfigEntry(
+		map[string]interface{}{},
+		map[string]interface{}{"1": "hi", "2": "2"},
+		"1",
+		"1",
+	)
+	require.NoError(err)
+	require.Equal("hi", val)
+
+	// case: key present wrong type
+	_, err = getConfigEntry(
+		map[string]interface{}{},
+		map[string]interface{}{"1": 1, "2": "2"},
+		"1",
+		"1",
+	)
+	require.Error(err)
+}
+
+func TestGetPort(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Case: port key present in config file
+	port, err := getPort(
+		map[string]interface{}{},
+		map[string]interface{}{"flag": float64(10013)},
+		"flag",
+	)
+	require.NoError(err)
+	require.Equal(uint16(10013), port)
+
+	// Case: port key present in flags
+	port, err = getPort(
+		map[string]interface{}{"flag": 10013},
+		map[string]interface{}{},
+		"flag",
+	)
+	require.NoError(err)
+	require.Equal(uint16(10013), port)
+
+	// Case: port key present in config file and flags
+	port, err = getPort(
+		map[string]interface{}{"flag": 10013},
+		map[string]interface{}{"flag": float64(14)},
+		"flag",
+	)
+	require.NoError(err)
+	require.Equal(uint16(10013), port)
+
+	// Case: port key not present
+	_, err = getPort(
+		map[string]interface{}{},
+		map[string]interface{}{},
+		"flag",
+	)
+	require.NoError(err)
+}
+
+func TestCreateFileAndWrite(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	dir, err := os.MkdirTemp("", "network-runner-test-*")
+	require.NoError(err)
+	path := filepath.Join(dir, "path")
+	contents := []byte("hi")
+	err = createFileAndWrite(path, contents)
+	require.NoError(err)
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(err)
+	require.Equal(contents, gotBytes)
+}
+
+func TestWriteFiles(t *testing.T) {
+	t.Parallel()
+	stakingKey := "stakingKey"
+	stakingCert := "stakingCert"
+	genesis := []byte("genesis")
+	configFile := "config file"
+	chainConfigFiles := map[string]string{
+		"C": "c-chain config file",
+	}
+	tmpDir, err := os.MkdirTemp("", "avalanche-network-runner-tests-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stakingKeyPath := filepath.Join(tmpDir, stakingKeyFileName)
+	stakingCertPath := filepath.Join(tmpDir, stakingCertFileName)
+	stakingSigningKeyPath := filepath.Join(tmpDir, stakingSigningKeyFileName)
+	genesisPath := filepath.Join(tmpDir, genesisFileName)
+	configFilePath := filepath.Join(tmpDir, configFileName)
+	chainConfigDir := filepath.Join(tmpDir, chainConfigSubDir)
+	subnetConfigDir := filepath.Join(tmpDir, subnetConfigSubDir)
+	cChainConfigPath := filepath.Join(tmpDir, chainConfigSubDir, "C", configFileName)
+
+	type test struct {
+		name          string
+		shouldErr     bool
+		genesis       []byte
+		nodeConfig    node.Config
+		expectedFlags map[string]string
+	}
+
+	tests := []test{
+		{
+			name:      "no config files given",
+			shouldErr: false,
+			genesis:   genesis,
+			nodeConfig: node.Config{
+				StakingKey:  stakingKey,
+				StakingCert: stakingCert,
+			},
+			expectedFlags: map[string]string{
+				config.StakingTLSKeyPathKey:    stakingKeyPath,
+				config.StakingCertPathKey:      stakingCertPath,
+				config.StakingSignerKeyPathKey: stakingSigningKeyPath,
+				config.GenesisFileKey:          genesisPath,
+				config.ChainConfigDirKey:       chainConfigDir,
+				config.SubnetConfigDirKey:      subnetConfigDir,
+			},
+		},
+		{
+			name:      "config file given but not c-chain config file",
+			shouldErr: false,
+			genesis:   genesis,
+			nodeConfig: node.Config{
+				StakingKey:  stakingKey,
+				StakingCert: stakingCert,
+				ConfigFile:  configFile,
+			},
+			expectedFlags: map[string]string{
+				config.StakingTLSKeyPathKey:    stakingKeyPath,
+				config.StakingCertPathKey:      stakingCertPath,
+				config.StakingSignerKeyPathKey: stakingSigningKeyPath,
+				config.GenesisFileKey:          genesisPath,
+				config.ChainConfigDirKey:       chainConfigDir,
+				config.SubnetConfigDirKey:      subnetConfigDir,
+				config.ConfigFileKey:           configFilePath,
+			},
+		},
+		{
+			name:      "config file and c-chain config file given",
+			shouldErr: false,
+			genesis:   genesis,
+			nodeConfig: node.Config{
+				StakingKey:       stakingKey,
+				StakingCert:      stakingCert,
+				ConfigFile:       configFile,
+				ChainConfigFiles: chainConfigFiles,
+			},
+			expectedFlags: map[string]string{
+				config.StakingTLSKeyPathKey:    stakingKeyPath,
+				config.StakingCertPathKey:      stakingCertPath,
+				config.StakingSignerKeyPathKey: stakingSigningKeyPath,
+				config.GenesisFileKey:          genesisPath,
+				config.ChainConfigDirKey:       chainConfigDir,
+				config.SubnetConfigDirKey:      subnetConfigDir,
+				config.ConfigFileKey:           configFilePath,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			flags, err := writeFiles(0, tt.genesis, tmpDir, &tt.nodeConfig)
+			if tt.shouldErr {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			// Make sure returned flags are right
+			require.Len(tt.expectedFlags, len(flags))
+			for k := range flags {
+				require.Equal(tt.expectedFlags[k], flags[k])
+			}
+			// Assert files created correctly
+			gotStakingKey, err := os.ReadFile(stakingKeyPath)
+			require.NoError(err)
+			require.Equal([]byte(tt.nodeConfig.StakingKey), gotStakingKey)
+			gotStakingCert, err := os.ReadFile(stakingCertPath)
+			require.NoError(err)
+			require.Equal([]byte(tt.nodeConfig.StakingCert), gotStakingCert)
+			gotGenesis, err := os.ReadFile(genesisPath)
+			require.NoError(err)
+			require.Equal(tt.genesis, gotGenesis)
+			if len(tt.nodeConfig.ConfigFile) > 0 {
+				gotConfigFile, err := os.ReadFile(configFilePath)
+				require.NoError(err)
+				require.Equal([]byte(configFile), gotConfigFile)
+			}
+			if tt.nodeConfig.ChainConfigFiles != nil {
+				gotCChainConfigFile, err := os.ReadFile(cChainConfigPath)
+				require.NoError(err)
+				require.Equal([]byte(chainConfigFiles["C"]), gotCChainConfigFile)
+			}
+		})
+	}
+}
+
+func TestRemoveBeacon(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// create a network with no nodes in it
+	emptyNetworkConfig, err := emptyNetworkConfig()
+	require.NoError(err)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), emptyNetworkConfig)
+	require.NoError(err)
+
+	// a network config for a 3 node staking network, and add the bootstrapper
+	// to the exesting network
+	networkConfig := testNetworkConfig(t)
+	_, err = net.AddNode(context.Background(), networkConfig.NodeConfigs[0])
+	require.NoError(err)
+
+	// remove the beacon node from the network
+	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[0].Name)
+	require.NoError(err)
+	require.Equal(0, net.bootstraps.Len())
+}
+
+// Returns an API client where:
+//   - The Health API's Health method always returns an error after the
+//     given context is cancelled.
+//   - The CChainEthAPI's Close method may be called
+//   - Only the above 2 methods may be called
+func newMockAPIHealthyBlocks(string, uint16) api.Client {
+	healthClient := &healthmocks.Client{}
+	healthClient.On("Health", mock.MatchedBy(func(_ context.Context) bool {
+		return true
+	}), mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, _ []string, _ ...rpc.Option) *health.APIReply {
+			<-ctx.Done()
+			return nil
+		},
+		func(ctx context.Context, _ []string, _ ...rpc.Option) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+	// ethClient used when removing nodes, to close websocket connection
+	ethClient := &apimocks.EthClient{}
+	ethClient.On("Close").Return()
+	client := &apimocks.Client{}
+	client.On("HealthAPI").Return(healthClient)
+	client.On("CChainEthAPI").Return(ethClient)
+	return client
+}
+
+// Assert that if the network's Stop method is called while
+// a call to Healthy is ongoing, Healthy returns immediately.
+func TestHealthyDuringNetworkStop(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	// Calls to a node's Healthy() function blocks until context cancelled
+	net, err := newNetwork(logging.NoLog{}, newMockAPIHealthyBlocks, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), networkConfig)
+	require.NoError(err)
+
+	healthyChan := make(chan error)
+	go func() {
+		healthyChan <- net.Healthy(context.Background())
+	}()
+	// Wait to make sure we're actually blocking on Health API call
+	time.Sleep(500 * time.Millisecond)
+	err = net.Stop(context.Background())
+	require.NoError(err)
+	select {
+	case err := <-healthyChan:
+		require.Error(err)
+	case <-time.After(1 * time.Second):
+		// Since [net.Stop] was called, [net.Healthy] should immediately return.
+		// We assume that it will do so within 1 second.
+		require.Fail("Healthy should've returned immediately because network closed")
+	}
+}
+
+// TestClockJumpEvents tests that a clock jump reconnects the nodes and
+// is notified, together with the network health, on the event stream
+func TestClockJumpEvents(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	events := net.Events()
+	clients := map[string]api.Client{}
+	for name, node := range net.nodes {
+		clients[name] = node.GetAPIClient()
+	}
+	net.onClockJump(time.Minute)
+	for name, node := range net.nodes {
+		require.NotSame(clients[name], node.GetAPIClient())
+	}
+	require.Equal(network.EventClockJump, (<-events).Type)
+	require.Equal(network.EventNetworkHealthy, (<-events).Type)
+
+	require.NoError(net.Stop(context.Background()))
+	// the channel is closed after the stop events of the nodes and
+	// the network
+	for range networkConfig.NodeConfigs {
+		require.Equal(network.EventNodeStopped, (<-events).Type)
+	}
+	require.Equal(network.EventNetworkStopped, (<-events).Type)
+	_, ok := <-events
+	require.False(ok)
+	// subscriptions after stop are already closed
+	_, ok = <-net.Events()
+	require.False(ok)
+}
+
+// TestLifecycleEvents tests that node starts and stops, and the network
+// stop, are published
+func TestLifecycleEvents(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	events := net.Events()
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	started := []string{}
+	for range networkConfig.NodeConfigs {
+		event := <-events
+		require.Equal(network.EventNodeStarted, event.Type)
+		started = append(started, event.NodeName)
+	}
+	require.ElementsMatch([]string{"node0", "node1", "node2"}, started)
+
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	event := <-events
+	require.Equal(network.EventNodeStopped, event.Type)
+	require.Equal("node1", event.NodeName)
+	require.NoError(net.ResumeNode(context.Background(), "node1"))
+	event = <-events
+	require.Equal(network.EventNodeStarted, event.Type)
+	require.Equal("node1", event.NodeName)
+
+	require.NoError(net.Stop(context.Background()))
+	for range networkConfig.NodeConfigs {
+		require.Equal(network.EventNodeStopped, (<-events).Type)
+	}
+	event = <-events
+	require.Equal(network.EventNetworkStopped, event.Type)
+	require.Empty(event.NodeName)
+	_, ok := <-events
+	require.False(ok)
+}
+
+// TestChaosWindow tests that health degradation and crashes during a chaos
+// window are reported as expected
+func TestChaosWindow(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	processCreator := &crashableProcessCreator{processes: map[string]*crashableProcess{}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	events := net.Events()
+
+	require.Error(net.BeginChaos(context.Background(), network.ChaosWindow{NodeNames: []string{"node3"}}))
+	require.Error(net.BeginChaos(context.Background(), network.ChaosWindow{Duration: -time.Second}))
+
+	// degradation outside the window is a regression
+	node0Err := &unhealthyNodeError{nodeName: "node0", msg: "node0 unhealthy"}
+	net.publishHealthCheck(node0Err, "test")
+	event := <-events
+	require.Equal(network.EventNetworkUnhealthy, event.Type)
+	require.Equal("node0", event.NodeName)
+	require.False(event.Expected)
+
+	require.NoError(net.BeginChaos(context.Background(), network.ChaosWindow{Reason: "partition", NodeNames: []string{"node0"}}))
+	event = <-events
+	require.Equal(network.EventChaosStarted, event.Type)
+	require.Contains(event.Message, "partition")
+
+	net.publishHealthCheck(node0Err, "test")
+	event = <-events
+	require.Equal(network.EventNetworkUnhealthy, event.Type)
+	require.True(event.Expected)
+	// nodes outside the window are not expected to be unhealthy
+	net.publishHealthCheck(&unhealthyNodeError{nodeName: "node1", msg: "node1 unhealthy"}, "test")
+	require.False((<-events).Expected)
+	net.publishHealthCheck(errors.New("unknown"), "test")
+	require.False((<-events).Expected)
+
+	// crashes of affected nodes are expected
+	processCreator.processes["node0"].crash()
+	require.Eventually(func() bool {
+		_, err := net.GetNodeHistory("node0")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	history, err := net.GetNodeHistory("node0")
+	require.NoError(err)
+	require.True(history[0].Crashed)
+	require.True(history[0].Expected)
+	event = <-events
+	require.Equal(network.EventNodeCrashed, event.Type)
+	require.Equal("node0", event.NodeName)
+	require.True(event.Expected)
+
+	require.NoError(net.EndChaos(context.Background()))
+	require.Equal(network.EventChaosEnded, (<-events).Type)
+	net.publishHealthCheck(node0Err, "test")
+	require.False((<-events).Expected)
+
+	// windows with a duration end by themselves
+	require.NoError(net.BeginChaos(context.Background(), network.ChaosWindow{Reason: "restart", Duration: 100 * time.Millisecond}))
+	require.Equal(network.EventChaosStarted, (<-events).Type)
+	select {
+	case event := <-events:
+		require.Equal(network.EventChaosEnded, event.Type)
+	case <-time.After(5 * time.Second):
+		require.FailNow("chaos window didn't end")
+	}
+
+	require.Error(net.Stop(context.Background()))
+	require.ErrorIs(net.BeginChaos(context.Background(), network.ChaosWindow{}), network.ErrStopped)
+	require.ErrorIs(net.EndChaos(context.Background()), network.ErrStopped)
+}
+
+// TestLeakCheck tests that Stop verifies the released resources
+// when leak checking is enabled
+func TestLeakCheck(t *testing.T) {
+	require := require.New(t)
+
+	networkConfig := testNetworkConfig(t)
+	networkConfig.LeakCheck = &network.LeakCheckConfig{}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.NoError(net.Stop(context.Background()))
+
+	// the root dir is larger than the limit
+	rootDir := t.TempDir()
+	require.NoError(os.WriteFile(filepath.Join(rootDir, "leaked"), make([]byte, 1024), 0o600))
+	networkConfig.LeakCheck = &network.LeakCheckConfig{MaxRootDirSize: 512}
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, rootDir, "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.ErrorIs(net.Stop(context.Background()), network.ErrLeakDetected)
+}
+
+// TestDefaultConfigWithoutFiles tests that the default config doesn't
+// depend on files on disk
+func TestDefaultConfigWithoutFiles(t *testing.T) {
+	require := require.New(t)
+	wd, err := os.Getwd()
+	require.NoError(err)
+	require.NoError(os.Chdir(t.TempDir()))
+	defer func() {
+		require.NoError(os.Chdir(wd))
+	}()
+
+	networkConfig := NewDefaultConfig("pepito")
+	require.NoError(networkConfig.Validate())
+	require.NotEmpty(networkConfig.ChainConfigFiles["C"])
+	networkID, err := utils.NetworkIDFromGenesis([]byte(networkConfig.Genesis))
+	require.NoError(err)
+	require.EqualValues(1337, networkID)
+}
+
+// TestStartProgress tests that each node start phase is reported once,
+// without waiting for the network to be healthy, and that the progress
+// callback can call back into the network
+func TestStartProgress(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	progressLock := sync.Mutex{}
+	phases := map[string][]network.StartPhase{}
+	var net *localNetwork
+	networkConfig.OnProgress = func(progress network.Progress) {
+		// not recorded, so failing the test, if the node is not found
+		if _, err := net.GetNode(context.Background(), progress.NodeName); err != nil {
+			return
+		}
+		progressLock.Lock()
+		defer progressLock.Unlock()
+		phases[progress.NodeName] = append(phases[progress.NodeName], progress.Phase)
+	}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	defer net.Stop(context.Background()) //nolint:errcheck
+
+	expectedPhases := []network.StartPhase{
+		network.PhaseBinaryChecked,
+		network.PhaseFilesWritten,
+		network.PhaseProcessStarted,
+		network.PhaseAPIReachable,
+		network.PhaseBootstrapped,
+	}
+	require.Eventually(func() bool {
+		progressLock.Lock()
+		defer progressLock.Unlock()
+		for _, nodeConfig := range networkConfig.NodeConfigs {
+			if len(phases[nodeConfig.Name]) < len(expectedPhases) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	// reported once, even when the health is checked again
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	progressLock.Lock()
+	defer progressLock.Unlock()
+	require.Len(phases, len(networkConfig.NodeConfigs))
+	for _, nodePhases := range phases {
+		require.Equal(expectedPhases, nodePhases)
+	}
+}
+
+// snapshotInfoClient is an info client that only implements GetNodeVersion
+type snapshotInfoClient struct {
+	info.Client
+}
+
+func (*snapshotInfoClient) GetNodeVersion(context.Context, ...rpc.Option) (*info.GetNodeVersionReply, error) {
+	return &info.GetNodeVersionReply{Version: "avalanche/1.10.15"}, nil
+}
+
+// snapshotPChainClient is a P-chain client that only implements the
+// calls made to save snapshots
+type snapshotPChainClient struct {
+	platformvm.Client
+}
+
+func (*snapshotPChainClient) GetHeight(context.Context, ...rpc.Option) (uint64, error) {
+	return 5, nil
+}
+
+func (*snapshotPChainClient) GetCurrentValidators(context.Context, ids.ID, []ids.NodeID, ...rpc.Option) ([]platformvm.ClientPermissionlessValidator, error) {
+	return nil, nil
+}
+
+// Returns an API client of a healthy node that answers the calls made to
+// save snapshots
+func newMockAPISnapshot(string, uint16) api.Client {
+	healthClient := &healthmocks.Client{}
+	healthClient.On("Health", mock.Anything, mock.Anything).Return(&health.APIReply{Healthy: true}, nil)
+	ethClient := &apimocks.EthClient{}
+	ethClient.On("Close").Return()
+	ethClient.On("BlockNumber", mock.Anything).Return(uint64(7), nil)
+	client := &apimocks.Client{}
+	client.On("HealthAPI").Return(healthClient)
+	client.On("CChainEthAPI").Return(ethClient)
+	client.On("InfoAPI").Return(&snapshotInfoClient{})
+	client.On("PChainAPI").Return(&snapshotPChainClient{})
+	return client
+}
+
+// TestRestartWithSettings tests that a network is saved to a snapshot and
+// started again from it with the changed settings
+func TestRestartWithSettings(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	networkConfig := testNetworkConfig(t)
+	// settings not saved to snapshots
+	transport := &http.Transport{}
+	networkConfig.APITransport = transport
+	healthLogger := logging.NoLog{}
+	networkConfig.HealthLogger = healthLogger
+	networkConfig.OnProgress = func(network.Progress) {}
+	var node1Clients atomic.Int32
+	networkConfig.NodeConfigs[1].NewAPIClient = func(ipAddr string, port uint16) api.Client {
+		node1Clients.Add(1)
+		return newMockAPISnapshot(ipAddr, port)
+	}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISnapshot, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), t.TempDir(), false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, networkConfig))
+	apiPorts := map[string]uint16{}
+	for nodeName, node := range net.nodes {
+		dbDir := filepath.Join(node.GetDbDir(), avagoconstants.NetworkName(net.networkID))
+		require.NoError(os.MkdirAll(dbDir, os.ModePerm))
+		require.NoError(os.WriteFile(filepath.Join(dbDir, "data"), []byte(nodeName), 0o600))
+		apiPorts[nodeName] = node.GetAPIPort()
+	}
+
+	restarted, err := RestartWithSettings(ctx, net, SettingsChange{
+		SnapshotName:   "rehearsal",
+		Flags:          map[string]interface{}{config.LogLevelKey: "debug"},
+		NodeFlags:      map[string]map[string]interface{}{"node1": {config.LogLevelKey: "info"}},
+		UpgradeConfigs: map[string]string{"C": `{"networkUpgradeOverrides": {}}`},
+		RootDir:        t.TempDir(),
+	})
+	require.NoError(err)
+	defer restarted.Stop(ctx) //nolint:errcheck
+	_, err = net.GetAllNodes()
+	require.ErrorIs(err, network.ErrStopped)
+
+	// nodes keep their ports and database, with the changed settings
+	nodes, err := restarted.GetAllNodes()
+	require.NoError(err)
+	require.Len(nodes, 3)
+	for nodeName, node := range nodes {
+		require.Equal(apiPorts[nodeName], node.GetAPIPort())
+		data, err := os.ReadFile(filepath.Join(node.GetDbDir(), avagoconstants.NetworkName(net.networkID), "data"))
+		require.NoError(err)
+		require.Equal(nodeName, string(data))
+		nodeConfig := node.GetConfig()
+		require.Equal(`{"networkUpgradeOverrides": {}}`, nodeConfig.UpgradeConfigFiles["C"])
+		logLevel := "debug"
+		if nodeName == "node1" {
+			logLevel = "info"
+		}
+		require.Equal(logLevel, nodeConfig.Flags[config.LogLevelKey])
+	}
+	// and with the settings not saved to the snapshot
+	restartedNet := restarted.(*localNetwork)
+	require.Same(transport, restartedNet.apiTransport)
+	require.Equal(healthLogger, restartedNet.healthLogger)
+	require.NotNil(restartedNet.onProgress)
+	require.NotNil(nodes["node1"].GetConfig().NewAPIClient)
+	require.Nil(nodes["node0"].GetConfig().NewAPIClient)
+	require.Equal(int32(2), node1Clients.Load())
+
+	// flags of nodes not on the snapshot fail the restart
+	_, err = RestartWithSettings(ctx, restarted, SettingsChange{
+		SnapshotName: "rehearsal2",
+		NodeFlags:    map[string]map[string]interface{}{"node3": {config.LogLevelKey: "info"}},
+	})
+	require.ErrorContains(err, `node "node3" not found on snapshot "rehearsal2"`)
+}
+
+// TestPruneSnapshots tests snapshot listing and retention based removal
+func TestPruneSnapshots(t *testing.T) {
+	require := require.New(t)
+	snapshotsDir := t.TempDir()
+	now := time.Now()
+	// snapshot name --> age
+	ages := map[string]time.Duration{
+		"new":    time.Minute,
+		"middle": time.Hour,
+		"old":    48 * time.Hour,
+	}
+	for name, age := range ages {
+		snapshotDir := filepath.Join(snapshotsDir, snapshotPrefix+name)
+		require.NoError(os.MkdirAll(snapshotDir, os.ModePerm))
+		networkConfigPath := filepath.Join(snapshotDir, "network.json")
+		require.NoError(os.WriteFile(networkConfigPath, []byte("{}"), 0o600))
+		require.NoError(os.Chtimes(networkConfigPath, now.Add(-age), now.Add(-age)))
+	}
+
+	snapshotsInfo, err := GetSnapshotsInfo(snapshotsDir)
+	require.NoError(err)
+	require.Len(snapshotsInfo, 3)
+	require.Equal("new", snapshotsInfo[0].Name)
+	require.Equal("middle", snapshotsInfo[1].Name)
+	require.Equal("old", snapshotsInfo[2].Name)
+	require.EqualValues(2, snapshotsInfo[0].Size)
+
+	removed, err := PruneSnapshots(logging.NoLog{}, snapshotsDir, network.SnapshotRetentionPolicy{MaxAge: 24 * time.Hour})
+	require.NoError(err)
+	require.Equal([]string{"old"}, removed)
+
+	removed, err = PruneSnapshots(logging.NoLog{}, snapshotsDir, network.SnapshotRetentionPolicy{MaxCount: 1})
+	require.NoError(err)
+	require.Equal([]string{"middle"}, removed)
+
+	snapshotNames, err := GetSnapshotNames(snapshotsDir)
+	require.NoError(err)
+	require.Equal([]string{"new"}, snapshotNames)
+}
+
+// A network with a root dir doesn't write out of it (eg into the home
+// or temp dirs), as long as no snapshot is saved
+func TestNoWritesOutOfRootDir(t *testing.T) {
+	require := require.New(t)
+	homeDir := t.TempDir()
+	tmpDir := t.TempDir()
+	rootDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("TMPDIR", tmpDir)
+	prevSnapshotsDir := defaultSnapshotsDir
+	defaultSnapshotsDir = filepath.Join(homeDir, snapshotsRelPath)
+	t.Cleanup(func() {
+		defaultSnapshotsDir = prevSnapshotsDir
+	})
+
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, rootDir, "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	_, err = net.AddNode(context.Background(), node.Config{})
+	require.NoError(err)
+	require.NoError(net.RemoveNode(context.Background(), "node0"))
+	snapshotNames, err := net.GetSnapshotNames(context.Background())
+	require.NoError(err)
+	require.Empty(snapshotNames)
+	require.NoError(net.Stop(context.Background()))
+
+	for _, dir := range []string{homeDir, tmpDir} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(err)
+		require.Empty(entries)
+	}
+	entries, err := os.ReadDir(rootDir)
+	require.NoError(err)
+	require.NotEmpty(entries)
+}
+
+// TestCustomLoggers tests that health and node messages go to the given loggers
+func TestCustomLoggers(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	healthBuf := &bytes.Buffer{}
+	networkConfig.HealthLogger = utils.NewSlogLogger(slog.NewTextHandler(healthBuf, nil))
+	nodeBufsLock := sync.Mutex{}
+	nodeBufs := map[string]*bytes.Buffer{}
+	networkConfig.NodeLogger = func(nodeName string) logging.Logger {
+		nodeBufsLock.Lock()
+		defer nodeBufsLock.Unlock()
+		nodeBufs[nodeName] = &bytes.Buffer{}
+		return utils.NewSlogLogger(slog.NewTextHandler(nodeBufs[nodeName], nil))
+	}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+
+	require.Contains(healthBuf.String(), "checking local network healthiness")
+	require.Len(nodeBufs, len(networkConfig.NodeConfigs))
+	for nodeName, nodeBuf := range nodeBufs {
+		require.Contains(nodeBuf.String(), "adding node")
+		require.Contains(nodeBuf.String(), "node-name="+nodeName)
+	}
+}
+
+// crashableProcess is a node process that can be made to exit unexpectedly
+type crashableProcess struct {
+	lock      sync.Mutex
+	exitedCh  chan struct{}
+	hasExited bool
+}
+
+func (p *crashableProcess) crash() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.hasExited = true
+	close(p.exitedCh)
+}
+
+func (p *crashableProcess) Stop(context.Context) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.hasExited {
+		return 2
+	}
+	p.hasExited = true
+	close(p.exitedCh)
+	return 0
+}
+
+func (p *crashableProcess) Status() status.Status {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.hasExited {
+		return status.Stopped
+	}
+	return status.Running
+}
+
+func (p *crashableProcess) exited() <-chan struct{} {
+	return p.exitedCh
+}
+
+func (p *crashableProcess) exitInfo() processExitInfo {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return processExitInfo{exitCode: 2, signal: "killed"}
+}
+
+type crashableProcessCreator struct {
+	lock      sync.Mutex
+	processes map[string]*crashableProcess
+}
+
+func (c *crashableProcessCreator) NewNodeProcess(config node.Config, _ ...string) (NodeProcess, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	process := &crashableProcess{exitedCh: make(chan struct{})}
+	c.processes[config.Name] = process
+	return process, nil
+}
+
+func (*crashableProcessCreator) GetNodeVersion(node.Config) (string, error) {
+	return nodeVersion, nil
+}
+
+func (*crashableProcessCreator) GetNodeFlagsUsage(node.Config) (string, error) {
+	return nodeFlagsUsage, nil
+}
+
+// TestNodeHistory tests that crashed and removed nodes are recorded
+func TestNodeHistory(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	processCreator := &crashableProcessCreator{processes: map[string]*crashableProcess{}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	_, err = net.GetNodeHistory("node0")
+	require.Error(err)
+
+	// crash node0 with some log lines
+	node0, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	require.NoError(os.MkdirAll(node0.GetLogsDir(), os.ModePerm))
+	logLines := []string{}
+	for i := 0; i < nodeHistoryLogLines+5; i++ {
+		logLines = append(logLines, fmt.Sprintf("log line %d", i))
+	}
+	require.NoError(os.WriteFile(filepath.Join(node0.GetLogsDir(), nodeMainLogFileName), []byte(strings.Join(logLines, "\n")+"\n"), 0o600))
+	processCreator.processes["node0"].crash()
+	require.Eventually(func() bool {
+		_, err := net.GetNodeHistory("node0")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	history, err := net.GetNodeHistory("node0")
+	require.NoError(err)
+	require.Len(history, 1)
+	require.True(history[0].Crashed)
+	require.Equal(2, history[0].ExitCode)
+	require.Equal("killed", history[0].Signal)
+	require.Equal(node0.GetNodeID(), history[0].NodeID)
+	require.Equal(logLines[5:], history[0].LastLogLines)
+	// the crashed node is kept in the network
+	require.Error(awaitNetworkHealthy(net, time.Second))
+
+	// removed nodes are recorded
+	require.NoError(net.RemoveNode(context.Background(), "node1"))
+	history, err = net.GetNodeHistory("node1")
+	require.NoError(err)
+	require.Len(history, 1)
+	require.False(history[0].Crashed)
+
+	// the crashed node is recorded once, and history survives stop
+	require.Error(net.Stop(context.Background()))
+	history, err = net.GetNodeHistory("node0")
+	require.NoError(err)
+	require.Len(history, 1)
+	history, err = net.GetNodeHistory("node2")
+	require.NoError(err)
+	require.Len(history, 1)
+}
+
+// TestNodeOpsLimits tests the limits on concurrent node starts and the
+// node operations timeout
+func TestNodeOpsLimits(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs = nil
+	networkConfig.NodeOps = &network.NodeOpsConfig{
+		MaxConcurrentStarts: 1,
+		Timeout:             10 * time.Second,
+	}
+	reachableLock := sync.Mutex{}
+	reachable := false
+	newAPIClientF := func(string, uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(
+			func(context.Context, []string, ...rpc.Option) (*health.APIReply, error) {
+				reachableLock.Lock()
+				defer reachableLock.Unlock()
+				if !reachable {
+					return nil, errors.New("unreachable")
+				}
+				return &health.APIReply{Healthy: true}, nil
+			},
+		)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(healthClient)
+		client.On("CChainEthAPI").Return(ethClient)
+		client.On("PChainAPI").Return(&snapshotPChainClient{})
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClientF, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	_, err = net.AddNode(context.Background(), node.Config{Name: "node0"})
+	require.NoError(err)
+	// node1 can't start until node0 API is reachable
+	addedCh := make(chan error)
+	go func() {
+		_, err := net.AddNode(context.Background(), node.Config{Name: "node1"})
+		addedCh <- err
+	}()
+	select {
+	case <-addedCh:
+		require.FailNow("node started while another one is starting")
+	case <-time.After(100 * time.Millisecond):
+	}
+	reachableLock.Lock()
+	reachable = true
+	reachableLock.Unlock()
+	select {
+	case err := <-addedCh:
+		require.NoError(err)
+	case <-time.After(5 * time.Second):
+		require.FailNow("node didn't start after the previous one did")
+	}
+
+	// operations time out waiting for previous ones
+	_, endNodeOp, err := net.beginNodeOp(context.Background())
+	require.NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(net.RemoveNode(ctx, "node0"), network.ErrNodeOpTimeout)
+	endNodeOp()
+	require.NoError(net.RemoveNode(context.Background(), "node0"))
+}
+
+func TestNetworkTTL(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.TTL = 2 * time.Second
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	events := net.Events()
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+
+	eventTypes := []network.EventType{}
+	timeout := time.After(10 * time.Second)
+	for done := false; !done; {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				done = true
+				break
+			}
+			if event.Type == network.EventNetworkExpiring || event.Type == network.EventNetworkExpired {
+				eventTypes = append(eventTypes, event.Type)
+			}
+		case <-timeout:
+			require.FailNow("network didn't expire")
+		}
+	}
+	require.Equal([]network.EventType{network.EventNetworkExpiring, network.EventNetworkExpired}, eventTypes)
+	_, err = net.GetAllNodes()
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestEphemeralNodes(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[1].TTL = 500 * time.Millisecond
+	removeNode2 := make(chan struct{})
+	networkConfig.NodeConfigs[2].RemoveWhen = func(context.Context, node.Node) (bool, error) {
+		select {
+		case <-removeNode2:
+			return true, nil
+		default:
+			return false, nil
+		}
+	}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	nodeGone := func(nodeName string) func() bool {
+		return func() bool {
+			_, err := net.GetNode(context.Background(), nodeName)
+			return err != nil
+		}
+	}
+	require.Eventually(nodeGone("node1"), 5*time.Second, 10*time.Millisecond)
+	_, err = net.GetNode(context.Background(), "node2")
+	require.NoError(err)
+	close(removeNode2)
+	require.Eventually(nodeGone("node2"), 2*ephemeralNodeCheckFreq, 10*time.Millisecond)
+	nodeNames, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Equal([]string{"node0"}, nodeNames)
+}
+
+func TestImportNodeIdentity(t *testing.T) {
+	require := require.New(t)
+	stakingDir := t.TempDir()
+	stakingCert, stakingKey, err := staking.NewCertAndKeyBytes()
+	require.NoError(err)
+	signingKey, err := bls.NewSecretKey()
+	require.NoError(err)
+	paths := NodeIdentityPaths{
+		StakingKey:  filepath.Join(stakingDir, "staker.key"),
+		StakingCert: filepath.Join(stakingDir, "staker.crt"),
+		SigningKey:  filepath.Join(stakingDir, "signer.key"),
+	}
+	require.NoError(os.WriteFile(paths.StakingKey, stakingKey, 0o600))
+	require.NoError(os.WriteFile(paths.StakingCert, stakingCert, 0o600))
+	require.NoError(os.WriteFile(paths.SigningKey, bls.SecretKeyToBytes(signingKey), 0o600))
+
+	nodeConfig := node.Config{}
+	nodeID, err := ImportNodeIdentity(&nodeConfig, paths)
+	require.NoError(err)
+	expectedNodeID, err := utils.ToNodeID(stakingKey, stakingCert)
+	require.NoError(err)
+	require.Equal(expectedNodeID, nodeID)
+	require.Equal(string(stakingKey), nodeConfig.StakingKey)
+	require.Equal(string(stakingCert), nodeConfig.StakingCert)
+	require.Equal(base64.StdEncoding.EncodeToString(bls.SecretKeyToBytes(signingKey)), nodeConfig.StakingSigningKey)
+
+	// invalid signing keys are rejected
+	require.NoError(os.WriteFile(paths.SigningKey, []byte("invalid"), 0o600))
+	_, err = ImportNodeIdentity(&node.Config{}, paths)
+	require.Error(err)
+}
+
+func TestNodeExtraFiles(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[0].Files = map[string]string{
+		"keystore/{{.NodeName}}-{{.NetworkID}}.json": "keystore",
+	}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	node0, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	filePath := filepath.Join(node0.GetDataDir(), "keystore", fmt.Sprintf("node0-%d.json", net.networkID))
+	contents, err := os.ReadFile(filePath)
+	require.NoError(err)
+	require.Equal("keystore", string(contents))
+
+	// files are removed with the node
+	require.NoError(net.RemoveNode(context.Background(), "node0"))
+	_, err = os.Stat(filePath)
+	require.ErrorIs(err, os.ErrNotExist)
+
+	// files can't be written outside the data dir
+	nodeConfig := networkConfig.NodeConfigs[1]
+	nodeConfig.Name = "node3"
+	nodeConfig.Files = map[string]string{"../outside": "contents"}
+	_, err = net.AddNode(context.Background(), nodeConfig)
+	require.ErrorContains(err, "not inside the node data dir")
+}
+
+func TestGetArtifactPaths(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	rootDir := t.TempDir()
+	snapshotsDir := t.TempDir()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, rootDir, snapshotsDir, false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	paths := net.GetArtifactPaths()
+	require.Equal(rootDir, paths.RootDir)
+	require.Equal(snapshotsDir, paths.SnapshotsDir)
+	require.Empty(paths.APICACert)
+	require.Len(paths.Nodes, 3)
+	node0, err := net.GetNode(context.Background(), "node0")
+	require.NoError(err)
+	require.Equal(network.NodeArtifactPaths{
+		DataDir:   node0.GetDataDir(),
+		DbDir:     node0.GetDbDir(),
+		LogsDir:   node0.GetLogsDir(),
+		PluginDir: node0.GetPluginDir(),
+	}, paths.Nodes["node0"])
+	require.Equal(filepath.Join(rootDir, "node0"), paths.Nodes["node0"].DataDir)
+
+	// removed nodes and stopped networks keep their paths
+	require.NoError(net.RemoveNode(context.Background(), "node0"))
+	require.Len(net.GetArtifactPaths().Nodes, 3)
+	require.NoError(net.Stop(context.Background()))
+	require.Equal(paths, net.GetArtifactPaths())
+}
+
+// failingNodeProcessCreator fails to start the nodes in [failing]
+type failingNodeProcessCreator struct {
+	lock    sync.Mutex
+	failing map[string]bool
+}
+
+func (c *failingNodeProcessCreator) NewNodeProcess(config node.Config, flags ...string) (NodeProcess, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.failing[config.Name] {
+		return nil, errors.New("error on purpose for test")
+	}
+	return newMockProcessSuccessful(config, flags...)
+}
+
+func (*failingNodeProcessCreator) GetNodeVersion(node.Config) (string, error) {
+	return nodeVersion, nil
+}
+
+func (*failingNodeProcessCreator) GetNodeFlagsUsage(node.Config) (string, error) {
+	return nodeFlagsUsage, nil
+}
+
+func (c *failingNodeProcessCreator) setFailing(nodeNames ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.failing = map[string]bool{}
+	for _, nodeName := range nodeNames {
+		c.failing[nodeName] = true
+	}
+}
+
+// TestAddNodeFailureCleanup tests that a node that fails to start
+// releases its ports and closes its output file
+func TestAddNodeFailureCleanup(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	processCreator := &failingNodeProcessCreator{}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", true, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, testNetworkConfig(t)))
+	defer net.Stop(ctx) //nolint:errcheck
+
+	apiPort, err := ports.reserveFree()
+	require.NoError(err)
+	p2pPort, err := ports.reserveFree()
+	require.NoError(err)
+	ports.release(apiPort, p2pPort)
+	nodeConfig := node.Config{
+		Name: "node3",
+		Flags: map[string]interface{}{
+			config.HTTPPortKey:    int(apiPort),
+			config.StakingPortKey: int(p2pPort),
+		},
+		OutputFile: &node.OutputFileConfig{},
+	}
+	processCreator.setFailing("node3")
+	_, err = net.AddNode(ctx, nodeConfig)
+	require.ErrorContains(err, "error on purpose for test")
+	require.NotContains(net.nodePorts, "node3")
+	require.NotContains(net.outputFiles, "node3")
+
+	// the ports are not reassigned, as they are not reserved anymore
+	processCreator.setFailing()
+	node3, err := net.AddNode(ctx, nodeConfig)
+	require.NoError(err)
+	assignment := node3.GetPortAssignment()
+	require.Equal(apiPort, assignment.APIPort)
+	require.Equal(p2pPort, assignment.P2PPort)
+	require.False(assignment.Reassigned())
+	require.Contains(net.outputFiles, "node3")
+}
+
+// TestPartialStart tests the rollback of a failed network creation,
+// and the resumption of a partially started one
+func TestPartialStart(t *testing.T) {
+	require := require.New(t)
+	processCreator := &failingNodeProcessCreator{}
+	processCreator.setFailing("node1")
+
+	// started nodes are stopped by default
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), testNetworkConfig(t))
+	require.ErrorIs(err, network.ErrPartialStart)
+	_, err = net.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+	_, err = net.AddNode(context.Background(), node.Config{Name: "node3"})
+	require.ErrorIs(err, network.ErrStopped)
+
+	// or kept, and the network creation resumed
+	networkConfig := testNetworkConfig(t)
+	networkConfig.KeepPartialStart = true
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	require.ErrorIs(net.loadConfig(context.Background(), networkConfig), network.ErrPartialStart)
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Equal([]string{"node0"}, names)
+
+	require.ErrorIs(net.ResumeCreate(context.Background()), network.ErrPartialStart)
+	processCreator.setFailing()
+	require.NoError(net.ResumeCreate(context.Background()))
+	names, err = net.GetNodeNames()
+	require.NoError(err)
+	require.ElementsMatch([]string{"node0", "node1", "node2"}, names)
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	// nothing left to resume
+	require.NoError(net.ResumeCreate(context.Background()))
+
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(net.ResumeCreate(context.Background()), network.ErrStopped)
+}
+
+// TestStartupWaves tests that nodes are started in waves, once the
+// nodes of the previous waves are healthy
+func TestStartupWaves(t *testing.T) {
+	require := require.New(t)
+
+	// large networks must be started in waves
+	networkConfig := testNetworkConfig(t)
+	for len(networkConfig.NodeConfigs) <= network.MaxNodesWithoutStartupWaves {
+		networkConfig.NodeConfigs = append(networkConfig.NodeConfigs, networkConfig.NodeConfigs[0])
+	}
+	require.Error(networkConfig.Validate())
+	networkConfig.StartupWaves = &network.StartupWavesConfig{}
+	require.Error(networkConfig.Validate())
+	networkConfig.StartupWaves.WaveSize = 10
+	require.NoError(networkConfig.Validate())
+
+	networkConfig = testNetworkConfig(t)
+	networkConfig.StartupWaves = &network.StartupWavesConfig{WaveSize: 2}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Len(names, 3)
+	require.NoError(net.Stop(context.Background()))
+
+	// the next wave is not started while the previous one is unhealthy
+	networkConfig = testNetworkConfig(t)
+	networkConfig.StartupWaves = &network.StartupWavesConfig{WaveSize: 2, HealthTimeout: 100 * time.Millisecond}
+	networkConfig.KeepPartialStart = true
+	net, err = newNetwork(logging.NoLog{}, newMockAPIUnhealthy, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.ErrorIs(net.loadConfig(context.Background(), networkConfig), network.ErrPartialStart)
+	names, err = net.GetNodeNames()
+	require.NoError(err)
+	require.Len(names, 2)
+}
+
+func TestResourcePreset(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.Flags = map[string]interface{}{config.ConsensusAppConcurrencyKey: 4}
+	networkConfig.NodeConfigs[0].ResourcePreset = node.ResourcePresetSmall
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	// the preset takes precedence over the network flags
+	node0 := net.nodes["node0"]
+	require.Equal("512MiB", node0.config.Env["GOMEMLIMIT"])
+	require.Equal(1, node0.config.Flags[config.ConsensusAppConcurrencyKey])
+	require.Equal(4, net.nodes["node1"].config.Flags[config.ConsensusAppConcurrencyKey])
+
+	networkConfig = testNetworkConfig(t)
+	networkConfig.NodeConfigs[0].ResourcePreset = "huge"
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.ErrorContains(net.loadConfig(context.Background(), networkConfig), "unknown resource preset")
+}
+
+func TestObserverNode(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.Flags = map[string]interface{}{config.IndexEnabledKey: false}
+	networkConfig.ChainConfigFiles = map[string]string{"C": `{"log-level": "debug"}`}
+	networkConfig.NodeConfigs[0].Observer = true
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	// the observer role takes precedence over the network flags and
+	// chain configs, and is kept on the node config
+	node0 := net.nodes["node0"]
+	require.True(node0.GetConfig().Observer)
+	require.Equal(true, node0.config.Flags[config.IndexEnabledKey])
+	require.JSONEq(`{"log-level": "debug", "pruning-enabled": false}`, node0.config.ChainConfigFiles["C"])
+	node1 := net.nodes["node1"]
+	require.Equal(false, node1.config.Flags[config.IndexEnabledKey])
+	require.JSONEq(`{"log-level": "debug"}`, node1.config.ChainConfigFiles["C"])
+}
+
+// TestNotifications tests that healthy, crash and disk usage events are
+// posted to the webhook
+func TestNotifications(t *testing.T) {
+	require := require.New(t)
+	messages := make(chan network.WebhookMessage, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		msg := network.WebhookMessage{}
+		if err := json.NewDecoder(r.Body).Decode(&msg); err == nil {
+			messages <- msg
+		}
+	}))
+	defer server.Close()
+	receive := func() network.WebhookMessage {
+		select {
+		case msg := <-messages:
+			return msg
+		case <-time.After(10 * time.Second):
+			require.FailNow("no notification posted")
+			return network.WebhookMessage{}
+		}
+	}
+
+	networkConfig := testNetworkConfig(t)
+	networkConfig.Name = "devnet"
+	networkConfig.DiskUsageThreshold = 1
+	networkConfig.Notifications = &network.NotificationsConfig{WebhookURL: server.URL}
+	processCreator := &crashableProcessCreator{processes: map[string]*crashableProcess{}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, processCreator, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	// healthy is notified once
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	require.NoError(net.Healthy(context.Background()))
+	msg := receive()
+	require.Equal(network.EventNetworkHealthy, msg.Event.Type)
+	require.Equal("devnet", msg.Network)
+	require.Equal("[devnet] network-healthy: network healthy", msg.Text)
+
+	require.True(net.checkDiskUsage(1, false))
+	require.True(net.checkDiskUsage(1, true))
+	require.Equal(network.EventDiskThresholdExceeded, receive().Event.Type)
+
+	// clock jumps are not notified by default, the health check after them is
+	net.onClockJump(time.Minute)
+	msg = receive()
+	require.Equal(network.EventNetworkHealthy, msg.Event.Type)
+	require.Contains(msg.Text, "after clock jump")
+
+	processCreator.processes["node0"].crash()
+	msg = receive()
+	require.Equal(network.EventNodeCrashed, msg.Event.Type)
+	require.Equal("node0", msg.Event.NodeName)
+	require.Contains(msg.Text, "node-crashed (node node0)")
+	require.Empty(messages)
+
+	networkConfig = testNetworkConfig(t)
+	networkConfig.Notifications = &network.NotificationsConfig{WebhookURL: "localhost:1234"}
+	require.Error(networkConfig.Validate())
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	require := require.New(t)
+	snapshotsDir := t.TempDir()
+	// writes a snapshot of the test network with the given nodes and db sizes
+	writeSnapshot := func(name string, dbSizes map[string]int, chainState *network.SnapshotChainState) {
+		snapshotDir := filepath.Join(snapshotsDir, snapshotPrefix+name)
+		networkConfig := testNetworkConfig(t)
+		nodeConfigs := []node.Config{}
+		for _, nodeConfig := range networkConfig.NodeConfigs {
+			size, ok := dbSizes[nodeConfig.Name]
+			if !ok {
+				continue
+			}
+			nodeConfigs = append(nodeConfigs, nodeConfig)
+			dbDir := filepath.Join(snapshotDir, defaultDBSubdir, nodeConfig.Name)
+			require.NoError(os.MkdirAll(dbDir, os.ModePerm))
+			require.NoError(os.WriteFile(filepath.Join(dbDir, "data"), make([]byte, size), 0o600))
+		}
+		networkConfig.NodeConfigs = nodeConfigs
+		networkConfigJSON, err := json.Marshal(networkConfig)
+		require.NoError(err)
+		require.NoError(os.WriteFile(filepath.Join(snapshotDir, "network.json"), networkConfigJSON, 0o600))
+		networkStateJSON, err := json.Marshal(NetworkState{ChainState: chainState})
+		require.NoError(err)
+		require.NoError(os.WriteFile(filepath.Join(snapshotDir, "state.json"), networkStateJSON, 0o600))
+	}
+	writeSnapshot("before", map[string]int{"node0": 10, "node1": 10}, &network.SnapshotChainState{
+		Nodes: map[string]network.SnapshotNodeState{
+			"node0": {NodeID: "NodeID-A", Version: "avalanche/1.10.14", PChainHeight: 5, CChainHeight: 7},
+			"node1": {NodeID: "NodeID-B", Version: "avalanche/1.10.14", PChainHeight: 5, CChainHeight: 7},
+		},
+		Validators: []string{"NodeID-A", "NodeID-B"},
+	})
+	writeSnapshot("after", map[string]int{"node0": 30, "node1": 10, "node2": 10}, &network.SnapshotChainState{
+		Nodes: map[string]network.SnapshotNodeState{
+			"node0": {NodeID: "NodeID-A", Version: "avalanche/1.10.15", PChainHeight: 8, CChainHeight: 7},
+			"node1": {NodeID: "NodeID-B", Version: "avalanche/1.10.14", PChainHeight: 5, CChainHeight: 7},
+			"node2": {NodeID: "NodeID-C", Version: "avalanche/1.10.15", PChainHeight: 8, CChainHeight: 7},
+		},
+		Validators: []string{"NodeID-A", "NodeID-C"},
+	})
+	// snapshots saved without chain state only compare db sizes
+	writeSnapshot("old", map[string]int{"node0": 5}, nil)
+
+	diff, err := DiffSnapshots(snapshotsDir, "before", "after")
+	require.NoError(err)
+	require.False(diff.GenesisChanged)
+	require.Equal([]string{"node2"}, diff.AddedNodes)
+	require.Empty(diff.RemovedNodes)
+	require.Equal([]string{"NodeID-C"}, diff.AddedValidators)
+	require.Equal([]string{"NodeID-B"}, diff.RemovedValidators)
+	require.Len(diff.Nodes, 2)
+	require.EqualValues(10, diff.Nodes["node0"].From.DBSize)
+	require.EqualValues(30, diff.Nodes["node0"].To.DBSize)
+	require.Equal(uint64(8), diff.Nodes["node0"].To.PChainHeight)
+	summary := diff.String()
+	require.Contains(summary, "node0: version avalanche/1.10.14 -> avalanche/1.10.15, P-chain height 5 -> 8, db size 10 -> 30 (+20)")
+	require.Contains(summary, "node1: unchanged")
+	require.Contains(summary, "added nodes: node2")
+
+	diff, err = DiffSnapshots(snapshotsDir, "old", "before")
+	require.NoError(err)
+	require.Equal([]string{"node1"}, diff.AddedNodes)
+	require.EqualValues(5, diff.Nodes["node0"].From.DBSize)
+	require.Empty(diff.Nodes["node0"].From.Version)
+
+	_, err = DiffSnapshots(snapshotsDir, "before", "missing")
+	require.ErrorIs(err, ErrSnapshotNotFound)
+}
+
+// TestCompactDatabases tests that node databases are compacted offline,
+// and the nodes are running again afterwards
+func TestCompactDatabases(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[2].Flags[config.DBTypeKey] = memdb.Name
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.NoError(awaitNetworkHealthy(net, defaultHealthyTimeout))
+
+	// fill node0 db with deleted entries
+	node0 := net.nodes["node0"]
+	dbPath := filepath.Join(node0.GetDbDir(), avagoconstants.NetworkName(net.networkID), version.CurrentDatabase.String())
+	db, err := leveldb.New(dbPath, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+	value := make([]byte, 1024)
+	for i := 0; i < 1000; i++ {
+		require.NoError(db.Put([]byte(fmt.Sprintf("key%d", i)), value))
+	}
+	for i := 0; i < 1000; i++ {
+		require.NoError(db.Delete([]byte(fmt.Sprintf("key%d", i))))
+	}
+	require.NoError(db.Close())
+
+	compactions, err := net.CompactDatabases(context.Background())
+	require.NoError(err)
+	require.Len(compactions, 3)
+	require.Equal("node0", compactions[0].NodeName)
+	require.Less(compactions[0].SizeAfter, compactions[0].SizeBefore)
+	// node1 has no db yet, node2 db is in memory
+	require.Zero(compactions[1].SizeBefore)
+	require.Zero(compactions[2].SizeAfter)
+
+	// nodes were resumed
+	for _, node := range net.nodes {
+		require.False(node.paused)
+	}
+	require.NotSame(node0, net.nodes["node0"])
+	require.NoError(net.Healthy(context.Background()))
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.CompactDatabases(context.Background())
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestNodeOpenFilesLimit(t *testing.T) {
+	require := require.New(t)
+	hostLimit, err := hostOpenFilesLimit()
+	require.NoError(err)
+
+	limit := uint64(1024)
+	if limit > hostLimit {
+		limit = hostLimit
+	}
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeOpenFilesLimit = limit
+	networkConfig.NodeConfigs[1].Flags[config.FdLimitKey] = 512
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.Equal(limit, net.nodes["node0"].config.Flags[config.FdLimitKey])
+	// node flags take precedence
+	require.Equal(512, net.nodes["node1"].config.Flags[config.FdLimitKey])
+
+	if hostLimit == math.MaxUint64 {
+		return
+	}
+	networkConfig = testNetworkConfig(t)
+	networkConfig.NodeOpenFilesLimit = hostLimit + 1
+	net, err = newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	err = net.loadConfig(context.Background(), networkConfig)
+	require.ErrorContains(err, "larger than the host hard limit")
+	require.Empty(net.nodes)
+}
+
+// bootstrapInfoClient is an info client that only implements IsBootstrapped
+type bootstrapInfoClient struct {
+	info.Client
+
+	lock sync.Mutex
+	// chains bootstrapped. Chains not in the map are unknown to the node.
+	bootstrapped map[string]bool
+}
+
+func (c *bootstrapInfoClient) setBootstrapped(chain string, bootstrapped bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.bootstrapped[chain] = bootstrapped
+}
+
+func (c *bootstrapInfoClient) IsBootstrapped(_ context.Context, chain string, _ ...rpc.Option) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	bootstrapped, ok := c.bootstrapped[chain]
+	if !ok {
+		return false, fmt.Errorf("unknown chain %s", chain)
+	}
+	return bootstrapped, nil
+}
+
+// blockchainsPClient is a P-chain client that only implements GetBlockchains
+type blockchainsPClient struct {
+	platformvm.Client
+
+	blockchains []platformvm.APIBlockchain
+}
+
+func (c *blockchainsPClient) GetBlockchains(context.Context, ...rpc.Option) ([]platformvm.APIBlockchain, error) {
+	return c.blockchains, nil
+}
+
+// TestChainBootstrapEvents tests that an event is published the first time
+// each chain is found bootstrapped on each node
+func TestChainBootstrapEvents(t *testing.T) {
+	require := require.New(t)
+	subnetChainID := ids.GenerateTestID()
+	pClient := &blockchainsPClient{blockchains: []platformvm.APIBlockchain{
+		{ID: ids.GenerateTestID(), Name: "X", SubnetID: avagoconstants.PrimaryNetworkID},
+		{ID: subnetChainID, Name: "subnetevm", SubnetID: ids.GenerateTestID()},
+	}}
+	infoClients := map[uint16]*bootstrapInfoClient{}
+	infoClientsLock := sync.Mutex{}
+	newAPIClient := func(_ string, port uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(&health.APIReply{Healthy: true}, nil)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(healthClient)
+		client.On("CChainEthAPI").Return(ethClient)
+		infoClient := &bootstrapInfoClient{bootstrapped: map[string]bool{"P": true, "X": false, "C": false}}
+		infoClientsLock.Lock()
+		infoClients[port] = infoClient
+		infoClientsLock.Unlock()
+		client.On("InfoAPI").Return(infoClient)
+		client.On("PChainAPI").Return(pClient)
+		return client
+	}
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(logging.NoLog{}, newAPIClient, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	events := net.Events()
+	node0 := net.nodes["node0"]
+	node0Info := infoClients[node0.GetAPIPort()]
+
+	bootstrapped := map[*localNode]set.Set[string]{}
+	net.checkChainBootstraps(bootstrapped)
+	nodeNames := []string{}
+	for range net.nodes {
+		event := <-events
+		require.Equal(network.EventChainBootstrapped, event.Type)
+		require.Equal("P", event.Chain)
+		require.Equal("P-chain bootstrapped", event.Message)
+		nodeNames = append(nodeNames, event.NodeName)
+	}
+	require.ElementsMatch([]string{"node0", "node1", "node2"}, nodeNames)
+
+	// only new bootstraps are reported
+	node0Info.setBootstrapped("C", true)
+	node0Info.setBootstrapped(subnetChainID.String(), true)
+	net.checkChainBootstraps(bootstrapped)
+	event := <-events
+	require.Equal("node0", event.NodeName)
+	require.Equal("C", event.Chain)
+	event = <-events
+	require.Equal("node0", event.NodeName)
+	require.Equal(subnetChainID.String(), event.Chain)
+	require.Contains(event.Message, "subnetevm")
+	net.checkChainBootstraps(bootstrapped)
+	require.Empty(events)
+}
+
+// TestStreamLogs tests that the lines output by the nodes are streamed,
+// prefixed with the node names, until the network is stopped
+func TestStreamLogs(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	reader, writer := io.Pipe()
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- net.StreamLogs(context.Background(), writer)
+		_ = writer.Close()
+	}()
+	require.Eventually(func() bool {
+		net.logs.lock.Lock()
+		defer net.logs.lock.Unlock()
+		return len(net.logs.subscribers) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	node0Stdout := net.logs.writer("node0")
+	node1Stderr := net.logs.writer("node1")
+	_, err = node0Stdout.Write([]byte("started\nbootstr"))
+	require.NoError(err)
+	_, err = node1Stderr.Write([]byte("warning\r\n"))
+	require.NoError(err)
+	_, err = node0Stdout.Write([]byte("apped\n"))
+	require.NoError(err)
+
+	scanner := bufio.NewScanner(reader)
+	lines := []string{}
+	for len(lines) < 3 && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Equal([]string{"[node0] started", "[node1] warning", "[node0] bootstrapped"}, lines)
+
+	require.NoError(net.Stop(context.Background()))
+	require.NoError(<-streamErr)
+	require.ErrorIs(net.StreamLogs(context.Background(), io.Discard), network.ErrStopped)
+}
+
+// TestLogRecords tests that the JSON log records output by the nodes are
+// parsed and delivered to the subscriptions selecting them
+func TestLogRecords(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	_, err = net.LogRecords(context.Background(), network.LogFilter{MinLevel: "loud"})
+	require.ErrorContains(err, "invalid min level")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	all, err := net.LogRecords(ctx, network.LogFilter{})
+	require.NoError(err)
+	warnings, err := net.LogRecords(ctx, network.LogFilter{
+		NodeNames: []string{"node1"},
+		MinLevel:  "warn",
+		Modules:   []string{"C Chain"},
+	})
+	require.NoError(err)
+
+	node0Stdout := net.logs.writer("node0")
+	node1Stdout := net.logs.writer("node1")
+	_, err = node0Stdout.Write([]byte("not json\n"))
+	require.NoError(err)
+	_, err = node1Stdout.Write([]byte(`{"level":"info","timestamp":"2023-10-30T10:00:00.000Z","logger":"C Chain","msg":"started"}` + "\n"))
+	require.NoError(err)
+	_, err = node0Stdout.Write([]byte(`{"level":"warn","timestamp":"2023-10-30T10:00:01.000Z","logger":"C Chain","msg":"slow"}` + "\n"))
+	require.NoError(err)
+	_, err = node1Stdout.Write([]byte(`{"level":"error","timestamp":"2023-10-30T10:00:02.000Z","logger":"C Chain","msg":"failed","height":10}` + "\n"))
+	require.NoError(err)
+
+	messages := []string{}
+	for len(messages) < 3 {
+		record := <-all
+		messages = append(messages, record.NodeName+" "+record.Message)
+	}
+	require.Equal([]string{"node1 started", "node0 slow", "node1 failed"}, messages)
+	record := <-warnings
+	require.Equal("node1", record.NodeName)
+	require.Equal(logging.Error, record.Level)
+	require.Equal("failed", record.Message)
+	require.Equal(map[string]interface{}{"height": float64(10)}, record.Fields)
+
+	cancel()
+	for range all {
+	}
+	for range warnings {
+	}
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.LogRecords(context.Background(), network.LogFilter{})
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+// TestNodeOutputFile tests that the output of nodes asked to is written to
+// their node dir, rotated by size
+func TestNodeOutputFile(t *testing.T) {
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[0].OutputFile = &node.OutputFileConfig{MaxSizeMB: 1, MaxBackups: 1}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	node0 := net.nodes["node0"]
+	outputFilePath := filepath.Join(net.rootDir, "node0", node.OutputFileName)
+	require.Equal([]string{outputFilePath}, node0.GetOutputFilePaths())
+	require.Empty(net.nodes["node1"].GetOutputFilePaths())
+
+	net.lock.Lock()
+	output := net.nodeOutput("node0")
+	net.lock.Unlock()
+	line := []byte(strings.Repeat("x", 1023) + "\n")
+	for i := 0; i < 1024; i++ {
+		_, err := output.Write(line)
+		require.NoError(err)
+	}
+	_, err = output.Write([]byte("rotated\n"))
+	require.NoError(err)
+	paths := node0.GetOutputFilePaths()
+	require.Len(paths, 2)
+	require.Equal(outputFilePath, paths[0])
+	require.Equal(filepath.Dir(outputFilePath), filepath.Dir(paths[1]))
+	content, err := os.ReadFile(outputFilePath)
+	require.NoError(err)
+	require.Equal("rotated\n", string(content))
+	info, err := os.Stat(paths[1])
+	require.NoError(err)
+	require.Equal(int64(1024*1024), info.Size())
+
+	require.NoError(net.Stop(context.Background()))
+	require.Empty(net.outputFiles)
+}
+
+// logLevelAdminClient is an admin client that only implements
+// SetLoggerLevel, recording the levels set
+type logLevelAdminClient struct {
+	admin.Client
+
+	lock   sync.Mutex
+	levels []string
+}
+
+func (c *logLevelAdminClient) SetLoggerLevel(_ context.Context, loggerName, logLevel, displayLevel string, _ ...rpc.Option) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.levels = append(c.levels, fmt.Sprintf("%q %s %s", loggerName, logLevel, displayLevel))
+	return nil
+}
+
+// TestSetLogLevel tests that log levels are set through the admin API of
+// the nodes that enable it, and by restarting the others
+func TestSetLogLevel(t *testing.T) {
+	require := require.New(t)
+	adminClient := &logLevelAdminClient{}
+	newAPIClient := func(ipAddr string, port uint16) api.Client {
+		client := newMockAPISuccessful(ipAddr, port).(*apimocks.Client)
+		client.On("AdminAPI").Return(adminClient)
+		return client
+	}
+	networkConfig := testNetworkConfig(t)
+	// the test network enables the admin API, and sets the display level
+	networkConfig.NodeConfigs[0].Flags = map[string]interface{}{config.AdminAPIEnabledKey: false}
+	net, err := newNetwork(logging.NoLog{}, newAPIClient, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	ctx := context.Background()
+
+	node0 := net.nodes["node0"]
+	require.NoError(net.SetLogLevel(ctx, "node0", "debug"))
+	require.NotSame(node0, net.nodes["node0"])
+	require.Equal("debug", net.nodes["node0"].flags[config.LogLevelKey])
+
+	node1 := net.nodes["node1"]
+	require.NoError(net.SetLogLevel(ctx, "node1", "debug"))
+	require.Same(node1, net.nodes["node1"])
+	require.Equal("debug", node1.flags[config.LogLevelKey])
+	require.Equal([]string{`"" debug `}, adminClient.levels)
+	// kept on restart
+	require.NoError(net.RestartNode(ctx, "node1", "", "", "", nil, nil, nil))
+	require.Equal("debug", net.nodes["node1"].flags[config.LogLevelKey])
+
+	require.ErrorContains(net.SetLogLevel(ctx, "node0", "loud"), "invalid log level")
+	require.ErrorContains(net.SetLogLevel(ctx, "node9", "debug"), "node \"node9\" not found")
+
+	// also given to the nodes added later
+	require.NoError(net.PauseNode(ctx, "node2"))
+	require.NoError(net.SetNetworkLogLevel(ctx, "info"))
+	require.Equal("info", net.nodes["node0"].flags[config.LogLevelKey])
+	require.Equal("info", net.nodes["node1"].flags[config.LogLevelKey])
+	require.NoError(net.ResumeNode(ctx, "node2"))
+	require.Equal("info", net.nodes["node2"].flags[config.LogLevelKey])
+	_, err = net.AddNode(context.Background(), node.Config{Name: "node3"})
+	require.NoError(err)
+	require.Equal("info", net.nodes["node3"].flags[config.LogLevelKey])
+
+	require.NoError(net.Stop(ctx))
+	require.ErrorIs(net.SetLogLevel(ctx, "node0", "debug"), network.ErrStopped)
+}
+
+func TestNodePortAssignment(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := context.Background()
+	// holds a port in use
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	usedPort, err := strconv.Atoi(strings.Split(server.Listener.Addr().String(), ":")[1])
+	require.NoError(err)
+
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", true, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, testNetworkConfig(t)))
+
+	// ports not given are assigned free ones
+	node0, err := net.GetNode(ctx, "node0")
+	require.NoError(err)
+	assignment := node0.GetPortAssignment()
+	require.NotZero(assignment.APIPort)
+	require.NotZero(assignment.P2PPort)
+	require.Zero(assignment.RequestedAPIPort)
+	require.False(assignment.Reassigned())
+
+	// a port in use is reassigned, and written to the flags
+	node3, err := net.AddNode(ctx, node.Config{
+		Name:  "node3",
+		Flags: map[string]interface{}{config.HTTPPortKey: usedPort},
+	})
+	require.NoError(err)
+	assignment = node3.GetPortAssignment()
+	require.Equal(uint16(usedPort), assignment.RequestedAPIPort)
+	require.NotEqual(uint16(usedPort), assignment.APIPort)
+	require.True(assignment.Reassigned())
+	require.Equal(assignment.APIPort, node3.GetAPIPort())
+	require.Equal(int(assignment.APIPort), node3.GetConfig().Flags[config.HTTPPortKey])
+
+	// a port reserved by another node is reassigned, and written to the
+	// config file
+	node4, err := net.AddNode(ctx, node.Config{
+		Name:       "node4",
+		ConfigFile: fmt.Sprintf(`{"%s":%d}`, config.StakingPortKey, node3.GetP2PPort()),
+	})
+	require.NoError(err)
+	assignment = node4.GetPortAssignment()
+	require.Equal(node3.GetP2PPort(), assignment.RequestedP2PPort)
+	require.NotEqual(node3.GetP2PPort(), assignment.P2PPort)
+	configFile := map[string]interface{}{}
+	require.NoError(json.Unmarshal([]byte(node4.GetConfigFile()), &configFile))
+	require.Equal(float64(assignment.P2PPort), configFile[config.StakingPortKey])
+
+	// released on removal
+	node4P2PPort := assignment.P2PPort
+	require.NoError(net.RemoveNode(ctx, "node4"))
+	require.True(ports.reserve(node4P2PPort, false))
+	ports.release(node4P2PPort)
+
+	// used as given without reassignment
+	net2, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net2.loadConfig(ctx, testNetworkConfig(t)))
+	node5, err := net2.AddNode(ctx, node.Config{
+		Name:  "node5",
+		Flags: map[string]interface{}{config.HTTPPortKey: usedPort},
+	})
+	require.NoError(err)
+	require.Equal(uint16(usedPort), node5.GetAPIPort())
+	require.False(node5.GetPortAssignment().Reassigned())
+
+	require.NoError(net.Stop(ctx))
+	require.NoError(net2.Stop(ctx))
+}
+
+// TestUpgradeNode tests that a node is restarted on a new binary, keeping
+// its state
+func TestUpgradeNode(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, testNetworkConfig(t)))
+
+	node0, err := net.GetNode(ctx, "node0")
+	require.NoError(err)
+	dataDir, apiPort := node0.GetDataDir(), node0.GetAPIPort()
+	binaryPath := filepath.Join(t.TempDir(), "avalanchego")
+	require.NoError(net.UpgradeNode(ctx, "node0", binaryPath))
+	require.Equal(binaryPath, node0.GetBinaryPath())
+	require.Equal(dataDir, node0.GetDataDir())
+	require.Equal(apiPort, node0.GetAPIPort())
+
+	require.ErrorContains(net.UpgradeNode(ctx, "node0", ""), "binary path of the upgrade not given")
+	require.ErrorContains(net.UpgradeNode(ctx, "node9", binaryPath), `node "node9" not found`)
+	require.NoError(net.Stop(ctx))
+	require.ErrorIs(net.UpgradeNode(ctx, "node0", binaryPath), network.ErrStopped)
+}
+
+func TestUpgradeNetwork(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISnapshot, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), t.TempDir(), false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, testNetworkConfig(t)))
+	for _, node := range net.nodes {
+		require.NoError(os.MkdirAll(filepath.Join(node.GetDbDir(), avagoconstants.NetworkName(net.networkID)), os.ModePerm))
+	}
+
+	verified := []string{}
+	upgraded, err := UpgradeNetwork(ctx, net, "upgrade", network.UpgradeSpec{
+		UpgradeConfigs: map[string]string{"C": `{"precompileUpgrades": []}`},
+		Verify: func(_ context.Context, n node.Node) error {
+			require.Equal(`{"precompileUpgrades": []}`, n.GetConfig().UpgradeConfigFiles["C"])
+			verified = append(verified, n.GetName())
+			return nil
+		},
+	})
+	require.NoError(err)
+	defer upgraded.Stop(ctx) //nolint:errcheck
+	_, err = net.GetAllNodes()
+	require.ErrorIs(err, network.ErrStopped)
+	require.Equal([]string{"node0", "node1", "node2"}, verified)
+}
+
+// Only the default keys funded by the genesis are given
+func TestGenesisFundedKeys(t *testing.T) {
+	require := require.New(t)
+	networkID := uint32(1000)
+	genesisMap, err := network.LoadLocalGenesis()
+	require.NoError(err)
+	genesisBytes, err := json.Marshal(genesisMap)
+	require.NoError(err)
+	fundedKeys, err := genesisFundedKeys(networkID, genesisBytes)
+	require.NoError(err)
+	require.Equal(DefaultFundedKeys(networkID), fundedKeys)
+
+	// custom genesis funding other addresses on the X and C chains
+	genesisMap["allocations"] = []interface{}{
+		map[string]interface{}{
+			"ethAddr":       "0x0000000000000000000000000000000000000000",
+			"avaxAddr":      "X-custom1g65uqn6t77p656w64023nh8nd9updzmxwd59gh",
+			"initialAmount": 1000,
+		},
+	}
+	genesisMap["cChainGenesis"] = `{"alloc": {"0x0000000000000000000000000000000000000001": {"balance": "0x10"}}}`
+	genesisBytes, err = json.Marshal(genesisMap)
+	require.NoError(err)
+	fundedKeys, err = genesisFundedKeys(networkID, genesisBytes)
+	require.NoError(err)
+	require.Empty(fundedKeys)
+}
+
+// TestDoneContext tests that the disk and process methods return the
+// context error once it's done
+func TestDoneContext(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", t.TempDir(), false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	defer net.Stop(context.Background()) //nolint:errcheck
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(net.RemoveSnapshot(ctx, "snapshot"), context.Canceled)
+	_, err = net.GetSnapshotNames(ctx)
+	require.ErrorIs(err, context.Canceled)
+	_, err = net.GetSnapshotsInfo(ctx)
+	require.ErrorIs(err, context.Canceled)
+	_, err = net.PruneSnapshots(ctx, network.SnapshotRetentionPolicy{MaxCount: 1})
+	require.ErrorIs(err, context.Canceled)
+	_, err = net.DiffSnapshots(ctx, "before", "after")
+	require.ErrorIs(err, context.Canceled)
+	_, err = net.MetricsTargets(ctx)
+	require.ErrorIs(err, context.Canceled)
+	require.ErrorIs(net.BeginChaos(ctx, network.ChaosWindow{}), context.Canceled)
+	require.ErrorIs(net.EndChaos(ctx), context.Canceled)
+	require.ErrorIs(net.SetLinkConditions(ctx, "node0", "node1", network.LinkConditions{}), context.Canceled)
+}
+
+// TestNodeFlagsUsage tests that the flags of the nodes are checked, and
+// diffed, against the flags usage given by the node process creator
+func TestNodeFlagsUsage(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, testNetworkConfig(t)))
+	defer net.Stop(ctx) //nolint:errcheck
+	net.strictFlags = true
+
+	_, err = net.AddNode(ctx, node.Config{
+		Name:  "node3",
+		Flags: map[string]interface{}{"unknown-flag": true},
+	})
+	require.ErrorContains(err, "unknown-flag")
+
+	node3, err := net.AddNode(ctx, node.Config{
+		Name:  "node3",
+		Flags: map[string]interface{}{config.LogLevelKey: "debug"},
+	})
+	require.NoError(err)
+	diffs, err := DiffNodeFlags(node3)
+	require.NoError(err)
+	require.Contains(diffs, utils.FlagDiff{Name: config.LogLevelKey, Value: "debug", Default: "info"})
+}
+
+// localTestValidatingProcessCreator doesn't support node process priorities
+type localTestValidatingProcessCreator struct {
+	localTestSuccessfulNodeProcessCreator
+}
+
+func (*localTestValidatingProcessCreator) ValidateNodeConfig(config node.Config) error {
+	if config.Priority != nil {
+		return errors.New("priority not supported")
+	}
+	return nil
+}
+
+// TestNodeConfigValidator tests that the nodes added are validated by
+// the node process creator, if it implements nodeConfigValidator
+func TestNodeConfigValidator(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestValidatingProcessCreator{}, "", "", false, false, false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(ctx, testNetworkConfig(t)))
+	defer net.Stop(ctx) //nolint:errcheck
+
+	_, err = net.AddNode(ctx, node.Config{
+		Name:     "node3",
+		Priority: &node.ProcessPriority{Nice: 10},
+	})
+	require.ErrorContains(err, "priority not supported")
+	_, err = net.GetNode(ctx, "node3")
+	require.ErrorIs(err, network.ErrNodeNotFound)
+	_, err = net.AddNode(ctx, node.Config{Name: "node3"})
+	require.NoError(err)
 }
@@ -6,9 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/api"
+	"github.com/ava-labs/avalanche-network-runner/network"
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/network/node/status"
 	"github.com/ava-labs/avalanchego/ids"
@@ -53,13 +57,24 @@ type localNode struct {
 	// The ID of the network this node exists in
 	networkID uint32
 	// Allows user to make API calls to this node.
-	client api.Client
+	// Guarded by [clientLock], as it may be recreated while
+	// the node is running.
+	client     api.Client
+	clientLock sync.RWMutex
+	// The IP used by [client] to reach the node
+	publicIP string
+	// If not nil, [client] reaches the node through this proxy
+	apiProxy *apiProxy
+	// Logger for messages about this node
+	log logging.Logger
 	// The process running this node.
 	process NodeProcess
 	// The API port
 	apiPort uint16
 	// The P2P (staking) port
 	p2pPort uint16
+	// How the API and P2P ports were assigned
+	ports node.PortAssignment
 	// Returns a connection to this node
 	getConnFunc getConnFunc
 	// The data dir of the node
@@ -68,10 +83,20 @@ type localNode struct {
 	dbDir string
 	// The logs dir of the node
 	logsDir string
+	// The file the node output is written to, if any
+	outputFile string
 	// The plugin dir of the node
 	pluginDir string
+	// The avalanchego version of the node binary
+	binaryVersion string
+	// Runs the node binary, as for its flags usage
+	nodeProcessCreator NodeProcessCreator
 	// The node config
 	config node.Config
+	// The flags the node process was started with, including
+	// the ones on the config file.
+	// Flag name --> value.
+	flags map[string]string
 	// The node httpHost
 	httpHost string
 	// maps from peer ID to peer object
@@ -79,6 +104,21 @@ type localNode struct {
 	// signals that the process is stopped but the information is valid
 	// and can be resumed
 	paused bool
+	// Time the node process was started
+	startTime time.Time
+	// True if the stopped process was already added to the node history
+	recorded bool
+	// How the process was stopped, if stopped on request
+	stopResult *network.NodeStopResult
+	// Number of restarts of the node by its restart policy, since
+	// it was last started on request
+	restarts int
+	// The node started in place of this one when it was restarted or
+	// resumed, to which the API client and addresses are resolved.
+	// Guarded by [clientLock].
+	replacedBy *localNode
+	// True once the node process was found healthy
+	healthSeen atomic.Bool
 }
 
 func defaultGetConnFunc(ctx context.Context, node node.Node) (net.Conn, error) {
@@ -185,21 +225,77 @@ func (node *localNode) SendOutboundMessage(ctx context.Context, peerID string, c
 
 // See node.Node
 func (node *localNode) GetName() string {
+	node = node.current()
 	return node.name
 }
 
 // See node.Node
 func (node *localNode) GetNodeID() ids.NodeID {
+	node = node.current()
 	return node.nodeID
 }
 
+// Returns the node that currently runs in place of this one, following
+// restarts, or this node if it was not restarted
+func (node *localNode) current() *localNode {
+	node.clientLock.RLock()
+	replacedBy := node.replacedBy
+	node.clientLock.RUnlock()
+
+	if replacedBy == nil {
+		return node
+	}
+	return replacedBy.current()
+}
+
+// Records that [replacement] was started in place of the node, so
+// references to the node keep reaching the running process
+func (node *localNode) setReplacedBy(replacement *localNode) {
+	node.clientLock.Lock()
+	defer node.clientLock.Unlock()
+
+	node.replacedBy = replacement
+}
+
 // See node.Node
 func (node *localNode) GetAPIClient() api.Client {
+	node = node.current()
+	node.clientLock.RLock()
+	defer node.clientLock.RUnlock()
+
 	return node.client
 }
 
+// Replaces the API client of the node with a new one
+// created with [newAPIClientF], closing the previous one
+func (node *localNode) resetAPIClient(newAPIClientF api.NewAPIClientF) {
+	node.clientLock.Lock()
+	defer node.clientLock.Unlock()
+
+	// cchain eth api uses a websocket connection that must be closed
+	node.client.CChainEthAPI().Close()
+	node.client = newAPIClientF(node.apiClientAddr())
+}
+
+// Returns the address used by API clients to reach the node:
+// the API proxy if any, or else the node API
+func (node *localNode) apiClientAddr() (string, uint16) {
+	if node.apiProxy != nil {
+		return apiProxyHost, node.apiProxy.port
+	}
+	return node.publicIP, node.apiPort
+}
+
+// Stops the API proxy of the node, if any
+func (node *localNode) closeAPIProxy() {
+	if node.apiProxy != nil {
+		_ = node.apiProxy.close()
+	}
+}
+
 // See node.Node
 func (node *localNode) GetURL() string {
+	node = node.current()
 	if node.httpHost == "0.0.0.0" || node.httpHost == "." {
 		return "0.0.0.0"
 	}
@@ -208,56 +304,97 @@ func (node *localNode) GetURL() string {
 
 // See node.Node
 func (node *localNode) GetP2PPort() uint16 {
+	node = node.current()
 	return node.p2pPort
 }
 
 // See node.Node
 func (node *localNode) GetAPIPort() uint16 {
+	node = node.current()
 	return node.apiPort
 }
 
+// See node.Node
+func (node *localNode) GetURI() string {
+	node = node.current()
+	host, port := node.apiClientAddr()
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// See node.Node
+func (node *localNode) GetPortAssignment() node.PortAssignment {
+	node = node.current()
+	return node.ports
+}
+
+// See node.Node
+func (node *localNode) GetEndpoints() (endpoints node.Endpoints) {
+	node = node.current()
+	endpoints.Internal.Host = node.publicIP
+	endpoints.Internal.APIPort = node.apiPort
+	endpoints.Internal.P2PPort = node.p2pPort
+	// local nodes are reachable at the same address from everywhere
+	endpoints.External = endpoints.Internal
+	return endpoints
+}
+
 func (node *localNode) Status() status.Status {
+	node = node.current()
 	return node.process.Status()
 }
 
 // See node.Node
 func (node *localNode) GetBinaryPath() string {
+	node = node.current()
 	return node.config.BinaryPath
 }
 
+// See node.Node
+func (node *localNode) GetBinaryVersion() string {
+	node = node.current()
+	return node.binaryVersion
+}
+
 // See node.Node
 func (node *localNode) GetPluginDir() string {
+	node = node.current()
 	return node.pluginDir
 }
 
 // See node.Node
 func (node *localNode) GetDataDir() string {
+	node = node.current()
 	return node.dataDir
 }
 
 // See node.Node
 // TODO rename method so linter doesn't complain.
 func (node *localNode) GetDbDir() string { //nolint
+	node = node.current()
 	return node.dbDir
 }
 
 // See node.Node
 func (node *localNode) GetLogsDir() string {
+	node = node.current()
 	return node.logsDir
 }
 
 // See node.Node
 func (node *localNode) GetConfigFile() string {
+	node = node.current()
 	return node.config.ConfigFile
 }
 
 // See node.Node
 func (node *localNode) GetConfig() node.Config {
+	node = node.current()
 	return node.config
 }
 
 // See node.Node
 func (node *localNode) GetFlag(k string) (string, error) {
+	node = node.current()
 	var v string
 	if node.config.ConfigFile != "" {
 		var configFileMap map[string]interface{}
@@ -285,5 +422,12 @@ func (node *localNode) GetFlag(k string) (string, error) {
 
 // See node.Node
 func (node *localNode) GetPaused() bool {
+	node = node.current()
 	return node.paused
 }
+
+// See node.Node
+func (node *localNode) GetOutputFilePaths() []string {
+	node = node.current()
+	return outputFilePaths(node.outputFile)
+}
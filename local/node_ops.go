@@ -0,0 +1,100 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+)
+
+const nodeStartCheckFreq = time.Second
+
+// nodeOpsLimiter serializes node operations and limits the number of
+// node processes starting at the same time
+type nodeOpsLimiter struct {
+	timeout time.Duration
+	// holds a token while an operation runs
+	queue chan struct{}
+	// holds a token for each starting node. nil if not limited.
+	startSlots chan struct{}
+}
+
+func newNodeOpsLimiter(config *network.NodeOpsConfig) *nodeOpsLimiter {
+	limiter := &nodeOpsLimiter{
+		timeout: config.Timeout,
+		queue:   make(chan struct{}, 1),
+	}
+	if limiter.timeout == 0 {
+		limiter.timeout = network.DefaultNodeOpTimeout
+	}
+	if config.MaxConcurrentStarts > 0 {
+		limiter.startSlots = make(chan struct{}, config.MaxConcurrentStarts)
+	}
+	return limiter
+}
+
+// Waits for the previous node operations to finish.
+// Returns the context for the operation, with the operation timeout, and
+// a function to be called when the operation ends.
+// Doesn't wait if node operations are not limited.
+func (ln *localNetwork) beginNodeOp(ctx context.Context) (context.Context, func(), error) {
+	if ln.nodeOps == nil {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, ln.nodeOps.timeout)
+	select {
+	case ln.nodeOps.queue <- struct{}{}:
+	case <-ctx.Done():
+		cancel()
+		return nil, nil, fmt.Errorf("%w: waiting for previous node operations", network.ErrNodeOpTimeout)
+	}
+	return ctx, func() {
+		<-ln.nodeOps.queue
+		cancel()
+	}, nil
+}
+
+// Waits until less than the max number of nodes are starting, or [ctx]
+// is done.
+// Returns a function that releases the start slot.
+func (ln *localNetwork) acquireStartSlot(ctx context.Context) (func(), error) {
+	if ln.nodeOps == nil || ln.nodeOps.startSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case ln.nodeOps.startSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(ln.nodeOps.timeout):
+		return nil, fmt.Errorf("%w: waiting for starting nodes", network.ErrNodeOpTimeout)
+	}
+	return func() {
+		<-ln.nodeOps.startSlots
+	}, nil
+}
+
+// Calls [release] once the API of [node] is reachable, the node process
+// stops, the network is stopped, or the node op timeout passes
+func (ln *localNetwork) releaseStartSlotWhenStarted(node *localNode, release func()) {
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ln.nodeOps.timeout)
+	defer cancel()
+	ctx, cancel = ln.withStopCancel(ctx)
+	defer cancel()
+	for {
+		if node.Status() != status.Running {
+			return
+		}
+		if _, err := node.GetAPIClient().HealthAPI().Health(ctx, nil); err == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(nodeStartCheckFreq):
+		}
+	}
+}
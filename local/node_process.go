@@ -2,29 +2,47 @@ package local
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/network/node/status"
 	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
 	"github.com/ava-labs/avalanchego/config"
 	"github.com/ava-labs/avalanchego/utils/logging"
-	"github.com/shirou/gopsutil/process"
 	"go.uber.org/zap"
 )
 
-var _ NodeProcess = (*nodeProcess)(nil)
+var (
+	_ NodeProcess        = (*nodeProcess)(nil)
+	_ processExitWatcher = (*nodeProcess)(nil)
+	_ processWithPID     = (*nodeProcess)(nil)
+	_ processFreezer     = (*nodeProcess)(nil)
+
+	errFreezeUnsupported = errors.New("node process can't be frozen")
+)
+
+// processFreezer is implemented by node processes that can be frozen,
+// stalling the node while keeping its state and connections
+type processFreezer interface {
+	freeze() error
+	unfreeze() error
+}
 
 // NodeProcess as an interface so we can mock running
 // AvalancheGo binaries in tests
 type NodeProcess interface {
 	// Sends a SIGINT to this process and returns the process's
 	// exit code.
-	// If [ctx] is cancelled, sends a SIGKILL to this process and descendants.
+	// If [ctx] is cancelled, kills this process and descendants.
+	// If [ctx] is already cancelled, kills them right away, as in a crash.
 	// We assume sending a SIGKILL to a process will always successfully kill it.
 	// Subsequent calls to [Stop] have no effect.
 	Stop(ctx context.Context) int
@@ -35,11 +53,22 @@ type NodeProcess interface {
 // NodeProcessCreator is an interface for new node process creation
 type NodeProcessCreator interface {
 	GetNodeVersion(config node.Config) (string, error)
+	// Returns the flags usage of the node binary, as per --help
+	GetNodeFlagsUsage(config node.Config) (string, error)
 	NewNodeProcess(config node.Config, args ...string) (NodeProcess, error)
 }
 
+// nodeConfigValidator is implemented by node process creators that don't
+// support every node config, i.e. the ones not running the nodes on the host
+type nodeConfigValidator interface {
+	// Returns an error if [config] can't be run by the creator
+	ValidateNodeConfig(config node.Config) error
+}
+
 type nodeProcessCreator struct {
 	log logging.Logger
+	// If not nil, gives the logger of each node process, instead of [log]
+	nodeLog func(nodeName string) logging.Logger
 	// If this node's stdout or stderr are redirected, [colorPicker] determines
 	// the color of logs printed to stdout and/or stderr
 	colorPicker utils.ColorPicker
@@ -49,34 +78,92 @@ type nodeProcessCreator struct {
 	// If this node's stderr is redirected, it will be to here.
 	// In practice this is usually os.Stderr, but for testing can be replaced.
 	stderr io.Writer
+	// If not nil, gives the writer each node process stream (stdout, stderr)
+	// is also written to, whether redirected or not
+	output func(nodeName string) io.Writer
 }
 
 // NewNodeProcess creates a new process of the passed binary
 // If the config has redirection set to `true` for either StdErr or StdOut,
-// the output will be redirected and colored
+// the output will be redirected and colored.
+// The argv[0] of the process is not the binary path, but the binary path
+// followed by the node name in brackets, as shown by `ps`, and the node
+// name is also given in the ANR_NODE_NAME env var.
 func (npc *nodeProcessCreator) NewNodeProcess(config node.Config, args ...string) (NodeProcess, error) {
 	// Start the AvalancheGo node and pass it the flags defined above
 	cmd := exec.Command(config.BinaryPath, args...) //nolint
+	// label the process with the node name, so it can be identified at
+	// `ps` output, and let the node (and its plugins) know its name
+	cmd.Args[0] = nodeProcessLabel(config.BinaryPath, config.Name)
+	cmd.Env = append(os.Environ(), constants.NodeNameEnvVar+"="+config.Name)
+	for key, value := range config.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
 	// assign a new color to this process (might not be used if the config isn't set for it)
 	color := npc.colorPicker.NextColor()
 	// Optionally redirect stdout and stderr
-	if config.RedirectStdout {
+	if config.RedirectStdout || npc.output != nil {
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
 			return nil, fmt.Errorf("couldn't create stdout pipe: %w", err)
 		}
-		// redirect stdout and assign a color to the text
-		utils.ColorAndPrepend(stdout, npc.stdout, config.Name, color)
+		npc.copyOutput(config.Name, stdout, config.RedirectStdout, npc.stdout, color)
 	}
-	if config.RedirectStderr {
+	if config.RedirectStderr || npc.output != nil {
 		stderr, err := cmd.StderrPipe()
 		if err != nil {
 			return nil, fmt.Errorf("couldn't create stderr pipe: %w", err)
 		}
-		// redirect stderr and assign a color to the text
-		utils.ColorAndPrepend(stderr, npc.stderr, config.Name, color)
+		npc.copyOutput(config.Name, stderr, config.RedirectStderr, npc.stderr, color)
 	}
-	return newNodeProcess(config.Name, npc.log, cmd)
+	log := npc.log
+	if npc.nodeLog != nil {
+		log = npc.nodeLog(config.Name)
+	}
+	np, err := newNodeProcess(config.Name, log, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if config.Priority != nil {
+		if err := setProcessPriority(np.pid(), *config.Priority); err != nil {
+			// kill it right away, it's just started
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			np.Stop(ctx)
+			return nil, fmt.Errorf("couldn't set node process priority: %w", err)
+		}
+	}
+	return np, nil
+}
+
+// Copies the stream [reader] of node [nodeName] to [npc.output], if given,
+// and if [redirect], to [writer] with [color] and the node name prepended
+func (npc *nodeProcessCreator) copyOutput(
+	nodeName string,
+	reader io.Reader,
+	redirect bool,
+	writer io.Writer,
+	color logging.Color,
+) {
+	if npc.output != nil {
+		output := npc.output(nodeName)
+		if !redirect {
+			go func() {
+				// ends when the process exits
+				_, _ = io.Copy(output, reader)
+			}()
+			return
+		}
+		reader = io.TeeReader(reader, output)
+	}
+	// redirect and assign a color to the text
+	utils.ColorAndPrepend(reader, writer, nodeName, color)
+}
+
+// Returns the argv[0] used for a node process, which
+// includes the runner-assigned node name
+func nodeProcessLabel(binaryPath string, nodeName string) string {
+	return fmt.Sprintf("%s [%s]", binaryPath, nodeName)
 }
 
 type nodeProcess struct {
@@ -88,6 +175,13 @@ type nodeProcess struct {
 	state status.Status
 	// Closed when the process exits.
 	closedOnStop chan struct{}
+	// Time the process exited
+	exitTime time.Time
+	// Used to kill the process with its descendants.
+	// nil if the tree couldn't be tracked.
+	tree *processTree
+	// True if the process tree is frozen
+	frozen bool
 }
 
 func newNodeProcess(name string, log logging.Logger, cmd *exec.Cmd) (*nodeProcess, error) {
@@ -112,6 +206,11 @@ func (p *nodeProcess) start() error {
 		close(p.closedOnStop)
 		return fmt.Errorf("couldn't start process: %w", err)
 	}
+	tree, err := newProcessTree(p.cmd.Process)
+	if err != nil {
+		p.log.Warn("couldn't track node process descendants", zap.String("node", p.name), zap.Error(err))
+	}
+	p.tree = tree
 
 	go p.awaitExit()
 	return nil
@@ -129,10 +228,81 @@ func (p *nodeProcess) awaitExit() {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	if p.tree != nil {
+		p.tree.close()
+	}
+
 	p.state = status.Stopped
+	p.exitTime = time.Now()
 	close(p.closedOnStop)
 }
 
+// See processWithPID
+func (p *nodeProcess) pid() int {
+	return p.cmd.Process.Pid
+}
+
+// See processFreezer
+func (p *nodeProcess) freeze() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	switch {
+	case p.state != status.Running:
+		return fmt.Errorf("node process is %s", p.state)
+	case p.frozen:
+		return errors.New("node process is already frozen")
+	case p.tree == nil:
+		return errFreezeUnsupported
+	}
+	if err := p.tree.freeze(); err != nil {
+		// don't leave part of the tree frozen
+		_ = p.tree.unfreeze()
+		return fmt.Errorf("couldn't freeze node process: %w", err)
+	}
+	p.frozen = true
+	return nil
+}
+
+// See processFreezer
+func (p *nodeProcess) unfreeze() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.frozen {
+		return errors.New("node process is not frozen")
+	}
+	if err := p.tree.unfreeze(); err != nil {
+		return fmt.Errorf("couldn't unfreeze node process: %w", err)
+	}
+	p.frozen = false
+	return nil
+}
+
+// See processExitWatcher
+func (p *nodeProcess) exited() <-chan struct{} {
+	return p.closedOnStop
+}
+
+// See processExitWatcher
+func (p *nodeProcess) exitInfo() processExitInfo {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	info := processExitInfo{
+		exitCode: -1,
+		time:     p.exitTime,
+	}
+	if p.cmd.ProcessState == nil {
+		return info
+	}
+	info.exitCode = p.cmd.ProcessState.ExitCode()
+	if waitStatus, ok := p.cmd.ProcessState.Sys().(syscall.WaitStatus); ok && waitStatus.Signaled() {
+		info.signal = waitStatus.Signal().String()
+	}
+	return info
+}
+
 func (p *nodeProcess) Stop(ctx context.Context) int {
 	p.lock.Lock()
 
@@ -156,19 +326,30 @@ func (p *nodeProcess) Stop(ctx context.Context) int {
 
 	p.state = status.Stopping
 	proc := p.cmd.Process
+	frozen := p.frozen
+	p.frozen = false
 	// We have to unlock here so that [p.awaitExit] can grab the lock
 	// and close [p.closedOnStop].
 	p.lock.Unlock()
 
-	if err := proc.Signal(os.Interrupt); err != nil {
-		p.log.Warn("sending SIGINT errored", zap.Error(err))
+	if ctx.Err() == nil {
+		if err := proc.Signal(os.Interrupt); err != nil {
+			p.log.Warn("sending SIGINT errored", zap.Error(err))
+		}
+		// a frozen process only handles the SIGINT once resumed
+		if frozen {
+			if err := p.tree.unfreeze(); err != nil {
+				p.log.Warn("couldn't unfreeze node process", zap.Error(err))
+			}
+		}
 	}
 
 	select {
 	case <-ctx.Done():
 		p.log.Warn("context cancelled while waiting for node to stop", zap.String("node", p.name))
-		killDescendants(int32(proc.Pid), p.log)
-		if err := proc.Signal(os.Kill); err != nil {
+		if p.tree != nil {
+			p.tree.kill(p.log)
+		} else if err := proc.Signal(os.Kill); err != nil {
 			p.log.Warn("sending SIGKILL errored", zap.Error(err))
 		}
 	case <-p.closedOnStop:
@@ -188,28 +369,6 @@ func (p *nodeProcess) Status() status.Status {
 	return p.state
 }
 
-func killDescendants(pid int32, log logging.Logger) {
-	procs, err := process.Processes()
-	if err != nil {
-		log.Warn("couldn't get processes", zap.Error(err))
-		return
-	}
-	for _, proc := range procs {
-		ppid, err := proc.Ppid()
-		if err != nil {
-			log.Warn("couldn't get process ID", zap.Error(err))
-			continue
-		}
-		if ppid != pid {
-			continue
-		}
-		killDescendants(proc.Pid, log)
-		if err := proc.Kill(); err != nil {
-			log.Warn("error killing process", zap.Int32("pid", proc.Pid), zap.Error(err))
-		}
-	}
-}
-
 // GetNodeVersion gets the version of the executable as per --version flag
 func (*nodeProcessCreator) GetNodeVersion(c node.Config) (string, error) {
 	// Start the AvalancheGo node and pass it the --version flag
@@ -219,3 +378,13 @@ func (*nodeProcessCreator) GetNodeVersion(c node.Config) (string, error) {
 	}
 	return string(out), nil
 }
+
+// GetNodeFlagsUsage gets the flags usage of the executable as per --help flag
+func (*nodeProcessCreator) GetNodeFlagsUsage(c node.Config) (string, error) {
+	// the exit code for help may be non zero, so just check the output
+	out, err := exec.Command(c.BinaryPath, "--help").CombinedOutput() //nolint
+	if len(out) == 0 && err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
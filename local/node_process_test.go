@@ -0,0 +1,49 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodeProcessLabel checks that node processes are labelled with the
+// node name at argv[0], and are given the node name in their environment
+func TestNodeProcessLabel(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reads /proc")
+	}
+	require := require.New(t)
+	binaryPath, err := exec.LookPath("sleep")
+	require.NoError(err)
+	npc := &nodeProcessCreator{
+		log:         logging.NoLog{},
+		colorPicker: utils.NewColorPicker(),
+	}
+	proc, err := npc.NewNodeProcess(node.Config{Name: "node1", BinaryPath: binaryPath}, "100")
+	require.NoError(err)
+	defer func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		proc.Stop(ctx)
+	}()
+	pid := proc.(processWithPID).pid()
+
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	require.NoError(err)
+	argv := strings.Split(strings.TrimSuffix(string(cmdline), "\x00"), "\x00")
+	require.Equal([]string{binaryPath + " [node1]", "100"}, argv)
+
+	environ, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+	require.NoError(err)
+	require.Contains(strings.Split(string(environ), "\x00"), constants.NodeNameEnvVar+"=node1")
+}
@@ -6,12 +6,26 @@ import (
 	"crypto"
 	"crypto/tls"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ava-labs/avalanche-network-runner/network"
 	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
 	"github.com/ava-labs/avalanchego/network/peer"
@@ -22,6 +36,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/version"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/process"
 	"github.com/stretchr/testify/require"
 )
 
@@ -241,3 +256,316 @@ func TestAttachPeer(t *testing.T) {
 	// also ensures that [require] calls will be reflected in test results if failed
 	require.NoError(<-errCh)
 }
+
+// TestAPIAuthProxy tests that requests made through the api proxy
+// of an auth enabled node carry a valid token
+func TestAPIAuthProxy(t *testing.T) {
+	require := require.New(t)
+	password := "secret"
+	tokensGiven := 0
+	nodeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == apiAuthEndpoint {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(err)
+			if !strings.Contains(string(body), password) {
+				_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"incorrect password"}}`))
+				return
+			}
+			tokensGiven++
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"token":"token` + strconv.Itoa(tokensGiven) + `"}}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer nodeAPI.Close()
+	target, err := url.Parse(nodeAPI.URL)
+	require.NoError(err)
+
+	proxy, err := newAPIProxy(logging.NoLog{}, "node1", target, newAPIAuthTransport(http.DefaultTransport, target, password))
+	require.NoError(err)
+	defer proxy.close()
+	proxyURI := "http://" + net.JoinHostPort(apiProxyHost, strconv.Itoa(int(proxy.port)))
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(proxyURI + "/ext/health")
+		require.NoError(err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(err)
+		require.NoError(resp.Body.Close())
+		require.Equal(http.StatusOK, resp.StatusCode)
+		require.Equal("ok", string(body))
+	}
+	// token is reused
+	require.Equal(1, tokensGiven)
+
+	// wrong password
+	badProxy, err := newAPIProxy(logging.NoLog{}, "node1", target, newAPIAuthTransport(http.DefaultTransport, target, "wrong"))
+	require.NoError(err)
+	defer badProxy.close()
+	resp, err := http.Get("http://" + net.JoinHostPort(apiProxyHost, strconv.Itoa(int(badProxy.port))) + "/ext/health")
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal(http.StatusBadGateway, resp.StatusCode)
+}
+
+// TestAPITLSProxy tests that the api proxy reaches a node API served with
+// a cert signed by the runner CA
+func TestAPITLSProxy(t *testing.T) {
+	require := require.New(t)
+	ca, err := newAPICA()
+	require.NoError(err)
+	certPEM, keyPEM, err := ca.newNodeCert("127.0.0.1")
+	require.NoError(err)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(err)
+
+	nodeAPI := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	nodeAPI.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	nodeAPI.StartTLS()
+	defer nodeAPI.Close()
+	target, err := url.Parse(nodeAPI.URL)
+	require.NoError(err)
+	require.Equal("https", target.Scheme)
+
+	// untrusted by default
+	_, err = http.Get(nodeAPI.URL)
+	require.Error(err)
+
+	proxy, err := newAPIProxy(logging.NoLog{}, "node1", target, ca.transport(http.DefaultTransport))
+	require.NoError(err)
+	defer proxy.close()
+	resp, err := http.Get("http://" + net.JoinHostPort(apiProxyHost, strconv.Itoa(int(proxy.port))) + "/ext/health")
+	require.NoError(err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Equal("ok", string(body))
+}
+
+// tunnelTransport sends all the requests to [addr], counting them
+type tunnelTransport struct {
+	addr     string
+	requests atomic.Int32
+}
+
+func (t *tunnelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	req = req.Clone(req.Context())
+	req.URL.Host = t.addr
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestAPITransportProxy tests that the node API requests go through
+// the custom API transport
+func TestAPITransportProxy(t *testing.T) {
+	require := require.New(t)
+	nodeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer nodeAPI.Close()
+	target, err := url.Parse(nodeAPI.URL)
+	require.NoError(err)
+
+	tunnel := &tunnelTransport{addr: target.Host}
+	ln := &localNetwork{apiTransport: tunnel}
+	// not reachable without the tunnel
+	proxy, err := ln.newNodeAPIProxy(logging.NoLog{}, "node1", buildArgsReturn{publicIP: "192.0.2.1", apiPort: 9650})
+	require.NoError(err)
+	require.NotNil(proxy)
+	defer proxy.close()
+	resp, err := http.Get("http://" + net.JoinHostPort(apiProxyHost, strconv.Itoa(int(proxy.port))) + "/ext/health")
+	require.NoError(err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Equal("ok", string(body))
+	require.Equal(int32(1), tunnel.requests.Load())
+}
+
+func TestGetEndpoints(t *testing.T) {
+	require := require.New(t)
+	n := &localNode{
+		publicIP: "127.0.0.1",
+		apiPort:  9650,
+		p2pPort:  9651,
+	}
+	endpoints := n.GetEndpoints()
+	require.Equal("127.0.0.1", endpoints.Internal.Host)
+	require.Equal(uint16(9650), endpoints.Internal.APIPort)
+	require.Equal(uint16(9651), endpoints.Internal.P2PPort)
+	require.Equal(endpoints.Internal, endpoints.External)
+}
+
+func TestGetURI(t *testing.T) {
+	require := require.New(t)
+	n := &localNode{
+		publicIP: "127.0.0.1",
+		apiPort:  9650,
+	}
+	require.Equal("http://127.0.0.1:9650", n.GetURI())
+	// through the API proxy
+	n.apiProxy = &apiProxy{port: 40000}
+	require.Equal("http://"+apiProxyHost+":40000", n.GetURI())
+	// of the node running in its place
+	n.setReplacedBy(&localNode{publicIP: "127.0.0.1", apiPort: 9652})
+	require.Equal("http://127.0.0.1:9652", n.GetURI())
+}
+
+// TestAPIRetryProxy tests that the api proxy retries requests failing with
+// transient errors while the node is starting
+func TestAPIRetryProxy(t *testing.T) {
+	require := require.New(t)
+	requestsLock := sync.Mutex{}
+	requests := map[string]int{}
+	getRequests := func(path string) int {
+		requestsLock.Lock()
+		defer requestsLock.Unlock()
+		return requests[path]
+	}
+	nodeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(err)
+		requestsLock.Lock()
+		requests[r.URL.Path]++
+		attempt := requests[r.URL.Path]
+		requestsLock.Unlock()
+		if attempt < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer nodeAPI.Close()
+	target, err := url.Parse(nodeAPI.URL)
+	require.NoError(err)
+
+	retryConfig := withAPIRetryDefaults(network.APIRetryConfig{InitialBackoff: time.Millisecond})
+	proxy, err := newAPIProxy(logging.NoLog{}, "node1", target, newAPIRetryTransport(http.DefaultTransport, *retryConfig))
+	require.NoError(err)
+	defer proxy.close()
+	proxyURI := "http://" + net.JoinHostPort(apiProxyHost, strconv.Itoa(int(proxy.port)))
+
+	// retried until success, with the same body
+	resp, err := http.Post(proxyURI+"/ext/info", "application/json", strings.NewReader("request"))
+	require.NoError(err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Equal("request", string(body))
+	require.Equal(3, getRequests("/ext/info"))
+
+	// unhealthy answers are not retried
+	resp, err = http.Get(proxyURI + "/ext/health")
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+	require.Equal(1, getRequests("/ext/health"))
+}
+
+// TestProcessTreeKill tests that killing a process tree kills the
+// descendants of the process, including the ones in their own group
+func TestProcessTreeKill(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a unix shell")
+	}
+	require := require.New(t)
+	pidFile := filepath.Join(t.TempDir(), "pids")
+	// a child, and a child leading its own process group
+	script := fmt.Sprintf("sleep 100 & echo $! >> %[1]s; set -m; sleep 100 & echo $! >> %[1]s; wait", pidFile)
+	cmd := exec.Command("sh", "-c", script)
+	require.NoError(cmd.Start())
+	tree, err := newProcessTree(cmd.Process)
+	require.NoError(err)
+	defer tree.close()
+	waitErrCh := make(chan error, 1)
+	go func() {
+		waitErrCh <- cmd.Wait()
+	}()
+
+	var pids []int
+	require.Eventually(func() bool {
+		pidsBytes, err := os.ReadFile(pidFile)
+		if err != nil {
+			return false
+		}
+		pids = nil
+		for _, pidStr := range strings.Fields(string(pidsBytes)) {
+			pid, err := strconv.Atoi(pidStr)
+			require.NoError(err)
+			pids = append(pids, pid)
+		}
+		return len(pids) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	tree.kill(logging.NoLog{})
+	select {
+	case <-waitErrCh:
+	case <-time.After(5 * time.Second):
+		require.FailNow("process not killed")
+	}
+	for _, pid := range pids {
+		require.Eventually(func() bool {
+			exists, err := process.PidExists(int32(pid))
+			require.NoError(err)
+			if !exists {
+				return true
+			}
+			// killed children of the shell are zombies until reaped by init
+			proc, err := process.NewProcess(int32(pid))
+			if err != nil {
+				return true
+			}
+			status, err := proc.Status()
+			return err == nil && status == "Z"
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+}
+
+// TestNodeProcessPriority tests that node processes run with the
+// priority given on their configs
+func TestNodeProcessPriority(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("IO priorities are only supported on linux")
+	}
+	require := require.New(t)
+	sleepPath, err := exec.LookPath("sleep")
+	require.NoError(err)
+	npc := &nodeProcessCreator{log: logging.NoLog{}, colorPicker: utils.NewColorPicker()}
+	nodeProcess, err := npc.NewNodeProcess(node.Config{
+		Name:       "node1",
+		BinaryPath: sleepPath,
+		Priority:   &node.ProcessPriority{Nice: 10, IOClass: node.IOClassIdle},
+	}, "100")
+	require.NoError(err)
+	defer nodeProcess.Stop(context.Background())
+	pid := nodeProcess.(processWithPID).pid()
+
+	proc, err := process.NewProcess(int32(pid))
+	require.NoError(err)
+	nice, err := proc.Nice()
+	require.NoError(err)
+	require.Equal(int32(10), nice)
+	if ionicePath, err := exec.LookPath("ionice"); err == nil {
+		out, err := exec.Command(ionicePath, "-p", strconv.Itoa(pid)).Output()
+		require.NoError(err)
+		require.Equal("idle", strings.TrimSpace(string(out)))
+	}
+
+	// priorities that can't be set fail the start
+	if os.Geteuid() != 0 {
+		_, err = npc.NewNodeProcess(node.Config{
+			Name:       "node2",
+			BinaryPath: sleepPath,
+			Priority:   &node.ProcessPriority{Nice: -10},
+		}, "100")
+		require.ErrorContains(err, "couldn't set node process priority")
+	}
+}
@@ -0,0 +1,69 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"go.uber.org/zap"
+)
+
+const diskUsageCheckFreq = time.Minute
+
+// Every [diskUsageCheckFreq], compares the size of the network root dir
+// with [threshold]. Publishes an EventDiskThresholdExceeded event each time
+// it grows larger.
+// Runs until the network is stopped.
+func (ln *localNetwork) watchDiskUsage(threshold int64) {
+	ticker := time.NewTicker(diskUsageCheckFreq)
+	defer ticker.Stop()
+
+	exceeded := false
+	for {
+		select {
+		case <-ln.onStopCh:
+			return
+		case <-ticker.C:
+		}
+		exceeded = ln.checkDiskUsage(threshold, exceeded)
+	}
+}
+
+// Publishes an EventDiskThresholdExceeded event if the size of the network
+// root dir is larger than [threshold], and it was not already [exceeded].
+// Returns whether the threshold is exceeded.
+func (ln *localNetwork) checkDiskUsage(threshold int64, exceeded bool) bool {
+	size, err := dirSize(ln.rootDir)
+	if err != nil {
+		ln.log.Debug("couldn't get root dir size", zap.String("root-dir", ln.rootDir), zap.Error(err))
+		return exceeded
+	}
+	if size <= threshold {
+		return false
+	}
+	if exceeded {
+		return true
+	}
+	ln.log.Warn("network disk usage threshold exceeded",
+		zap.String("root-dir", ln.rootDir),
+		zap.Int64("size", size),
+		zap.Int64("threshold", threshold),
+	)
+	ln.publishEvent(network.Event{
+		Type:    network.EventDiskThresholdExceeded,
+		Message: fmt.Sprintf("root dir %s size %d larger than %d", ln.rootDir, size, threshold),
+	})
+	return true
+}
+
+// Posts the network events selected by [config] into its webhook,
+// until the network is stopped
+func (ln *localNetwork) startNotifier(config network.NotificationsConfig) {
+	notifier := network.NewWebhookNotifier(ln, config)
+	ctx, cancel := ln.withStopCancel(context.Background())
+	go func() {
+		defer cancel()
+		notifier.Run(ctx, ln.log)
+	}()
+}
@@ -0,0 +1,45 @@
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+)
+
+// Returns an error if node processes can't raise their open files
+// limit (RLIMIT_NOFILE) to [limit] on this host
+func checkOpenFilesLimit(limit uint64) error {
+	hostLimit, err := hostOpenFilesLimit()
+	if err != nil {
+		return fmt.Errorf("couldn't get host open files limit: %w", err)
+	}
+	if limit > hostLimit {
+		return fmt.Errorf(
+			"node open files limit %d is larger than the host hard limit %d: raise the host limit (eg ulimit -Hn, or nofile at /etc/security/limits.conf), or lower the node open files limit",
+			limit,
+			hostLimit,
+		)
+	}
+	return nil
+}
+
+// Sets the open files limit of the node, unless it's already set
+// by its flags or config file
+func addOpenFilesLimitFlag(nodeConfig node.Config, limit uint64) error {
+	if _, ok := nodeConfig.Flags[config.FdLimitKey]; ok {
+		return nil
+	}
+	if nodeConfig.ConfigFile != "" {
+		configFile := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(nodeConfig.ConfigFile), &configFile); err != nil {
+			return fmt.Errorf("couldn't unmarshal config file: %w", err)
+		}
+		if _, ok := configFile[config.FdLimitKey]; ok {
+			return nil
+		}
+	}
+	nodeConfig.Flags[config.FdLimitKey] = limit
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package local
+
+import "syscall"
+
+// Returns the hard limit of open files of the processes started by the runner
+func hostOpenFilesLimit() (uint64, error) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0, err
+	}
+	return uint64(rlimit.Max), nil
+}
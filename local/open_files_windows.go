@@ -0,0 +1,10 @@
+//go:build windows
+
+package local
+
+import "math"
+
+// There is no open files limit for windows processes
+func hostOpenFilesLimit() (uint64, error) {
+	return math.MaxUint64, nil
+}
@@ -0,0 +1,90 @@
+package local
+
+import (
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"go.uber.org/zap"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Returns the writer each output stream (stdout, stderr) of node
+// [nodeName] is written to: the log streams, and its output file if any.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) nodeOutput(nodeName string) io.Writer {
+	logsWriter := ln.logs.writer(nodeName)
+	outputFile, ok := ln.outputFiles[nodeName]
+	if !ok {
+		return logsWriter
+	}
+	return io.MultiWriter(logsWriter, ignoreErrorsWriter{w: outputFile})
+}
+
+// Sets the output file of node [nodeName], at node dir [nodeDir], as given
+// by [config], closing the previous one of the node if any.
+// Returns the output file path, or an empty string if [config] is nil.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) setNodeOutputFile(nodeName string, nodeDir string, config *node.OutputFileConfig) string {
+	ln.closeNodeOutputFile(nodeName)
+	if config == nil {
+		return ""
+	}
+	maxSizeMB := config.MaxSizeMB
+	if maxSizeMB == 0 {
+		maxSizeMB = node.DefaultOutputFileMaxSizeMB
+	}
+	outputFile := &lumberjack.Logger{
+		Filename:   filepath.Join(nodeDir, node.OutputFileName),
+		MaxSize:    maxSizeMB,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     config.MaxAgeDays,
+		Compress:   config.Compress,
+	}
+	if ln.outputFiles == nil {
+		ln.outputFiles = map[string]*lumberjack.Logger{}
+	}
+	ln.outputFiles[nodeName] = outputFile
+	return outputFile.Filename
+}
+
+// Closes the output file of node [nodeName], if any.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) closeNodeOutputFile(nodeName string) {
+	outputFile, ok := ln.outputFiles[nodeName]
+	if !ok {
+		return
+	}
+	delete(ln.outputFiles, nodeName)
+	if err := outputFile.Close(); err != nil {
+		ln.log.Warn("couldn't close node output file", zap.String("name", nodeName), zap.Error(err))
+	}
+}
+
+// Returns the paths of output file [outputFilePath] and its rotated files,
+// newest first
+func outputFilePaths(outputFilePath string) []string {
+	if outputFilePath == "" {
+		return nil
+	}
+	ext := filepath.Ext(outputFilePath)
+	prefix := strings.TrimSuffix(outputFilePath, ext) + "-"
+	// rotated files are named after their UTC rotation time, and may be
+	// gzipped
+	rotated, _ := filepath.Glob(prefix + "*" + ext + "*")
+	sort.Sort(sort.Reverse(sort.StringSlice(rotated)))
+	return append([]string{outputFilePath}, rotated...)
+}
+
+// Writes to [w] ignoring its errors, so a failing output file
+// doesn't stop the copy of the node output
+type ignoreErrorsWriter struct {
+	w io.Writer
+}
+
+func (w ignoreErrorsWriter) Write(p []byte) (int, error) {
+	_, _ = w.w.Write(p)
+	return len(p), nil
+}
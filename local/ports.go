@@ -0,0 +1,170 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"go.uber.org/zap"
+)
+
+// Number of free ports asked to the OS before giving up on finding
+// one not reserved
+const maxFreePortAttempts = 100
+
+var errNoFreePort = errors.New("couldn't find a free port not reserved by other nodes")
+
+// Reserves the ports of the nodes of all the networks run by this process,
+// so nodes starting at the same time, in the same network or not, don't
+// get the same free port before binding it.
+var ports portManager
+
+type portManager struct {
+	lock     sync.Mutex
+	reserved set.Set[uint16]
+}
+
+// Reserves and returns a port that is free on the host
+func (m *portManager) reserveFree() (uint16, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for i := 0; i < maxFreePortAttempts; i++ {
+		port, err := getFreePort()
+		if err != nil {
+			return 0, fmt.Errorf("couldn't get free port: %w", err)
+		}
+		if !m.reserved.Contains(port) {
+			m.reserved.Add(port)
+			return port, nil
+		}
+	}
+	return 0, errNoFreePort
+}
+
+// Reserves [port]. Returns false if it is reserved by another node, or
+// [checkFree] and it is not free on the host.
+func (m *portManager) reserve(port uint16, checkFree bool) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.reserved.Contains(port) || (checkFree && !isFreePort(port)) {
+		return false
+	}
+	m.reserved.Add(port)
+	return true
+}
+
+// Releases the reservation of [ports]
+func (m *portManager) release(ports ...uint16) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.reserved.Remove(ports...)
+}
+
+// Returns true if [port] can be listened on, on all the interfaces
+func isFreePort(port uint16) bool {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	_ = l.Close()
+	return true
+}
+
+// Assigns the API and P2P ports of the node of [nodeConfig], as given by
+// its flags or [configFile]. Ports not given are assigned free ones, and
+// so are given ports in use if [ln.reassignPortsIfUsed].
+// Assigned ports are written where the port was given, or if not given,
+// to [configFile] if the node has one, and are reserved until the node is
+// removed.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) assignNodePorts(
+	nodeConfig *node.Config,
+	configFile map[string]interface{},
+) (node.PortAssignment, error) {
+	// reservations of a previous node with the same name (eg a paused one)
+	ln.releaseNodePorts(nodeConfig.Name)
+
+	assignment := node.PortAssignment{}
+	var err error
+	assignment.APIPort, assignment.RequestedAPIPort, err = ln.assignNodePort(nodeConfig, configFile, config.HTTPPortKey)
+	if err != nil {
+		return node.PortAssignment{}, err
+	}
+	assignment.P2PPort, assignment.RequestedP2PPort, err = ln.assignNodePort(nodeConfig, configFile, config.StakingPortKey)
+	if err != nil {
+		ln.releaseNodePorts(nodeConfig.Name)
+		return node.PortAssignment{}, err
+	}
+	if len(nodeConfig.ConfigFile) != 0 {
+		configFileBytes, err := json.Marshal(configFile)
+		if err != nil {
+			ln.releaseNodePorts(nodeConfig.Name)
+			return node.PortAssignment{}, fmt.Errorf("couldn't marshal config file: %w", err)
+		}
+		nodeConfig.ConfigFile = string(configFileBytes)
+	}
+	return assignment, nil
+}
+
+// Returns the port assigned to flag [portKey] of the node of [nodeConfig],
+// and the port requested for it, or 0 if not given.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) assignNodePort(
+	nodeConfig *node.Config,
+	configFile map[string]interface{},
+	portKey string,
+) (uint16, uint16, error) {
+	requested, err := getPort(nodeConfig.Flags, configFile, portKey)
+	if err != nil {
+		return 0, 0, err
+	}
+	port := requested
+	switch {
+	case requested != 0 && ports.reserve(requested, ln.reassignPortsIfUsed):
+	case requested != 0 && !ln.reassignPortsIfUsed:
+		// used as given, without reservation, as it is reserved by
+		// another node
+		return requested, requested, nil
+	default:
+		port, err = ports.reserveFree()
+		if err != nil {
+			return 0, 0, err
+		}
+		if requested != 0 {
+			ln.log.Info("reassigning port in use",
+				zap.String("node-name", nodeConfig.Name),
+				zap.String("flag", portKey),
+				zap.Uint16("requested", requested),
+				zap.Uint16("assigned", port),
+			)
+		}
+	}
+	if ln.nodePorts == nil {
+		ln.nodePorts = map[string][]uint16{}
+	}
+	ln.nodePorts[nodeConfig.Name] = append(ln.nodePorts[nodeConfig.Name], port)
+
+	// written where given, so restarts and snapshots keep the port
+	switch _, inFlags := nodeConfig.Flags[portKey]; {
+	case inFlags:
+		nodeConfig.Flags[portKey] = int(port)
+	case len(nodeConfig.ConfigFile) != 0:
+		configFile[portKey] = port
+	}
+	return port, requested, nil
+}
+
+// Releases the ports reserved for node [nodeName].
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) releaseNodePorts(nodeName string) {
+	ports.release(ln.nodePorts[nodeName]...)
+	delete(ln.nodePorts, nodeName)
+}
@@ -0,0 +1,74 @@
+//go:build linux
+
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"golang.org/x/sys/unix"
+)
+
+// ioprio_set(2) arguments
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+)
+
+var ioprioClasses = map[node.IOClass]int{
+	node.IOClassRealtime:   1,
+	node.IOClassBestEffort: 2,
+	node.IOClassIdle:       3,
+}
+
+// Sets [priority] on all the threads of process [pid]. On linux both the
+// niceness and the IO priority are per thread, and new threads inherit
+// them from the thread creating them, so the threads are listed again
+// until no new ones show up.
+func setProcessPriority(pid int, priority node.ProcessPriority) error {
+	done := set.Set[int]{}
+	for {
+		entries, err := os.ReadDir(filepath.Join(procDir, strconv.Itoa(pid), "task"))
+		if err != nil {
+			return fmt.Errorf("couldn't list process threads: %w", err)
+		}
+		newThreads := false
+		for _, entry := range entries {
+			tid, err := strconv.Atoi(entry.Name())
+			if err != nil || done.Contains(tid) {
+				continue
+			}
+			newThreads = true
+			done.Add(tid)
+			err = setThreadPriority(tid, priority)
+			// the thread may have exited
+			if err != nil && !errors.Is(err, syscall.ESRCH) {
+				return err
+			}
+		}
+		if !newThreads {
+			return nil
+		}
+	}
+}
+
+func setThreadPriority(tid int, priority node.ProcessPriority) error {
+	if priority.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, priority.Nice); err != nil {
+			return fmt.Errorf("couldn't set niceness: %w", err)
+		}
+	}
+	if priority.IOClass != "" {
+		ioprio := ioprioClasses[priority.IOClass]<<ioprioClassShift | priority.IOLevel
+		if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(ioprio)); errno != 0 {
+			return fmt.Errorf("couldn't set IO priority: %w", errno)
+		}
+	}
+	return nil
+}
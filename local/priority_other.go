@@ -0,0 +1,25 @@
+//go:build !linux && !windows
+
+package local
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+)
+
+// Sets [priority] on process [pid]. The niceness is per process, while
+// IO priorities are not supported.
+func setProcessPriority(pid int, priority node.ProcessPriority) error {
+	if priority.IOClass != "" {
+		return errors.New("node process IO priorities are only supported on linux")
+	}
+	if priority.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, priority.Nice); err != nil {
+			return fmt.Errorf("couldn't set niceness: %w", err)
+		}
+	}
+	return nil
+}
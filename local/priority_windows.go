@@ -0,0 +1,17 @@
+//go:build windows
+
+package local
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+)
+
+// Process priorities are not supported on windows
+func setProcessPriority(_ int, priority node.ProcessPriority) error {
+	if priority != (node.ProcessPriority{}) {
+		return errors.New("node process priorities are not supported on windows")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+//go:build !windows
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/shirou/gopsutil/process"
+	"go.uber.org/zap"
+)
+
+// processTree is a node process and its descendants (eg plugins).
+// On unix systems (linux, macOS) descendants are found by walking the
+// process parent links. Process groups started by descendants are
+// killed as a whole, so their members are killed even after leaving
+// the tree (eg daemonized by their parent).
+type processTree struct {
+	proc *os.Process
+}
+
+func newProcessTree(proc *os.Process) (*processTree, error) {
+	return &processTree{proc: proc}, nil
+}
+
+// Kills the process and all its descendants.
+// The whole tree is collected before killing any process, so descendants
+// aren't missed by being reparented after their parent dies. The process
+// is killed first, so it doesn't get to see its descendants die.
+func (t *processTree) kill(log logging.Logger) {
+	descendants := []int32{}
+	if err := collectDescendants(int32(t.proc.Pid), &descendants); err != nil {
+		log.Warn("couldn't get process descendants", zap.Int("pid", t.proc.Pid), zap.Error(err))
+	}
+	if err := t.proc.Signal(os.Kill); err != nil {
+		log.Warn("sending SIGKILL errored", zap.Error(err))
+	}
+	for _, pid := range descendants {
+		// kill the group led by the descendant, if any. Don't kill
+		// the group of the runner, that node processes belong to.
+		if pgid, err := syscall.Getpgid(int(pid)); err == nil && pgid == int(pid) {
+			if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil {
+				log.Warn("error killing process group", zap.Int("pgid", pgid), zap.Error(err))
+			}
+			continue
+		}
+		if err := syscall.Kill(int(pid), syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+			log.Warn("error killing process", zap.Int32("pid", pid), zap.Error(err))
+		}
+	}
+}
+
+// Freezes the process and its descendants with SIGSTOP, parents first,
+// so the tree stalls without losing its state or connections
+func (t *processTree) freeze() error {
+	return t.signal(syscall.SIGSTOP)
+}
+
+// Resumes the processes stopped by freeze
+func (t *processTree) unfreeze() error {
+	return t.signal(syscall.SIGCONT)
+}
+
+// Sends [sig] to the process and its descendants, parents first
+func (t *processTree) signal(sig syscall.Signal) error {
+	if err := syscall.Kill(t.proc.Pid, sig); err != nil {
+		return err
+	}
+	descendants := []int32{}
+	if err := collectDescendants(int32(t.proc.Pid), &descendants); err != nil {
+		return fmt.Errorf("couldn't get process descendants: %w", err)
+	}
+	for _, pid := range descendants {
+		if err := syscall.Kill(int(pid), sig); err != nil && err != syscall.ESRCH {
+			return err
+		}
+	}
+	return nil
+}
+
+// Releases the resources used to track the tree
+func (*processTree) close() {}
+
+// Appends the descendants of [pid] to [descendants], parents first
+func collectDescendants(pid int32, descendants *[]int32) error {
+	procs, err := process.Processes()
+	if err != nil {
+		return err
+	}
+	children := map[int32][]int32{}
+	for _, proc := range procs {
+		ppid, err := proc.Ppid()
+		if err != nil {
+			// the process may have exited
+			continue
+		}
+		children[ppid] = append(children[ppid], proc.Pid)
+	}
+	pending := []int32{pid}
+	for len(pending) > 0 {
+		parent := pending[0]
+		pending = pending[1:]
+		for _, child := range children[parent] {
+			*descendants = append(*descendants, child)
+			pending = append(pending, child)
+		}
+	}
+	return nil
+}
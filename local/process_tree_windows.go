@@ -0,0 +1,80 @@
+//go:build windows
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows"
+)
+
+// processTree is a node process and its descendants (eg plugins).
+// On windows the process is assigned to a job object, that its
+// descendants inherit, so the job can be terminated as a whole.
+// The job is set to kill its remaining processes when closed.
+type processTree struct {
+	proc *os.Process
+	job  windows.Handle
+}
+
+func newProcessTree(proc *os.Process) (*processTree, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create job object: %w", err)
+	}
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(
+		job,
+		windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)),
+		uint32(unsafe.Sizeof(info)),
+	); err != nil {
+		_ = windows.CloseHandle(job)
+		return nil, fmt.Errorf("couldn't set job object limits: %w", err)
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(proc.Pid))
+	if err != nil {
+		_ = windows.CloseHandle(job)
+		return nil, fmt.Errorf("couldn't open process: %w", err)
+	}
+	defer func() {
+		_ = windows.CloseHandle(handle)
+	}()
+	if err := windows.AssignProcessToJobObject(job, handle); err != nil {
+		_ = windows.CloseHandle(job)
+		return nil, fmt.Errorf("couldn't assign process to job object: %w", err)
+	}
+	return &processTree{proc: proc, job: job}, nil
+}
+
+// Kills the process and all its descendants
+func (t *processTree) kill(log logging.Logger) {
+	if err := windows.TerminateJobObject(t.job, 1); err != nil {
+		log.Warn("couldn't terminate job object", zap.Error(err))
+		if err := t.proc.Kill(); err != nil {
+			log.Warn("error killing process", zap.Error(err))
+		}
+	}
+}
+
+// Processes can't be frozen on windows
+func (*processTree) freeze() error {
+	return errFreezeUnsupported
+}
+
+func (*processTree) unfreeze() error {
+	return errFreezeUnsupported
+}
+
+// Releases the job object, killing any remaining descendant
+func (t *processTree) close() {
+	_ = windows.CloseHandle(t.job)
+}
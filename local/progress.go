@@ -0,0 +1,82 @@
+package local
+
+import (
+	"context"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+// Forgets the start phases reported for node [nodeName],
+// as it is being started again
+func (ln *localNetwork) resetProgress(nodeName string) {
+	ln.progressLock.Lock()
+	defer ln.progressLock.Unlock()
+
+	delete(ln.reportedPhases, nodeName)
+}
+
+// Queues for the progress callback, if any, that node [nodeName]
+// reached [phase]. Each phase is reported once per node start.
+func (ln *localNetwork) reportProgress(nodeName string, phase network.StartPhase) {
+	if ln.onProgress == nil {
+		return
+	}
+	ln.progressLock.Lock()
+	defer ln.progressLock.Unlock()
+
+	phases, ok := ln.reportedPhases[nodeName]
+	if !ok {
+		phases = set.Set[network.StartPhase]{}
+		ln.reportedPhases[nodeName] = phases
+	}
+	if phases.Contains(phase) {
+		return
+	}
+	phases.Add(phase)
+	ln.pendingProgress = append(ln.pendingProgress, network.Progress{
+		NodeName: nodeName,
+		Phase:    phase,
+		Time:     time.Now(),
+	})
+	select {
+	case ln.progressCh <- struct{}{}:
+	default:
+	}
+}
+
+// Gives the queued progress to the progress callback, in order.
+// Calls it without holding any lock, so it can call back into the network.
+// Runs until the network is stopped.
+func (ln *localNetwork) deliverProgress() {
+	for {
+		stopped := false
+		select {
+		case <-ln.progressCh:
+		case <-ln.onStopCh:
+			stopped = true
+		}
+		ln.progressLock.Lock()
+		pending := ln.pendingProgress
+		ln.pendingProgress = nil
+		ln.progressLock.Unlock()
+		for _, progress := range pending {
+			ln.onProgress(progress)
+		}
+		if stopped {
+			return
+		}
+	}
+}
+
+// Follows the start of [node] until it's healthy, so its API reachable
+// and bootstrapped phases are reported even if Healthy isn't called.
+// Gives up if the node stops, the network is stopped or the node health
+// timeout passes.
+func (ln *localNetwork) watchNodeStart(node *localNode) {
+	ctx, cancel := ln.withStopCancel(context.Background())
+	defer cancel()
+
+	_ = ln.nodeHealthy(ctx, node)
+}
@@ -0,0 +1,109 @@
+package local
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"go.uber.org/zap"
+)
+
+// Returns a *network.QuorumError if removing the running validator
+// [nodeName] leaves less than network.MinHealthyValidatorWeight of the
+// primary network validator weight on healthy nodes.
+// The validators are asked to another healthy node. If there is none, or
+// it can't give them, the removal is not prevented.
+// The nodes are queried without holding [ln.lock], so other network
+// operations are not blocked by slow or frozen nodes.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) checkQuorum(ctx context.Context, nodeName string) error {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return network.ErrStopped
+	}
+	removed, ok := ln.nodes[nodeName]
+	if !ok || removed.paused {
+		// not found is reported on removal, and paused nodes don't validate
+		ln.lock.RUnlock()
+		return nil
+	}
+	nodeNames := make([]string, 0, len(ln.nodes))
+	for name, node := range ln.nodes {
+		if name != nodeName && !node.paused {
+			nodeNames = append(nodeNames, name)
+		}
+	}
+	sort.Strings(nodeNames)
+	others := make([]*localNode, 0, len(nodeNames))
+	for _, name := range nodeNames {
+		others = append(others, ln.nodes[name])
+	}
+	ln.lock.RUnlock()
+
+	healthy := healthyNodes(ctx, others)
+	if len(healthy) == 0 {
+		return nil
+	}
+	vdrs, err := healthy[0].GetAPIClient().PChainAPI().GetCurrentValidators(ctx, avagoconstants.PrimaryNetworkID, nil)
+	if err != nil {
+		ln.log.Warn("couldn't get validators to check the quorum, removing node anyway",
+			zap.String("name", nodeName),
+			zap.Error(err),
+		)
+		return nil
+	}
+	healthyIDs := set.Set[ids.NodeID]{}
+	for _, node := range healthy {
+		healthyIDs.Add(node.nodeID)
+	}
+	var (
+		isValidator   bool
+		healthyWeight uint64
+		totalWeight   uint64
+	)
+	for _, vdr := range vdrs {
+		totalWeight += vdr.Weight
+		switch {
+		case vdr.NodeID == removed.nodeID:
+			isValidator = true
+		case healthyIDs.Contains(vdr.NodeID):
+			healthyWeight += vdr.Weight
+		}
+	}
+	if !isValidator || float64(healthyWeight) >= network.MinHealthyValidatorWeight*float64(totalWeight) {
+		return nil
+	}
+	return &network.QuorumError{
+		NodeName:      nodeName,
+		HealthyWeight: healthyWeight,
+		TotalWeight:   totalWeight,
+	}
+}
+
+// Returns the nodes of [nodes] whose health check passes, in the same
+// order. The nodes are asked once, concurrently.
+func healthyNodes(ctx context.Context, nodes []*localNode) []*localNode {
+	isHealthy := make([]bool, len(nodes))
+	wg := sync.WaitGroup{}
+	for i, node := range nodes {
+		i, node := i, node
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			isHealthy[i] = nodeState(ctx, node) == network.NodeHealthy
+		}()
+	}
+	wg.Wait()
+	healthy := []*localNode{}
+	for i, node := range nodes {
+		if isHealthy[i] {
+			healthy = append(healthy, node)
+		}
+	}
+	return healthy
+}
@@ -0,0 +1,87 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/config"
+	"go.uber.org/zap"
+)
+
+// Schedules the restart of [node], whose process exited with [exitCode]
+// without being asked to, if its restart policy says so.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) scheduleRestart(node *localNode, exitCode int) {
+	policy := node.config.RestartPolicy
+	if policy == nil || !policy.Restarts(exitCode) {
+		return
+	}
+	if policy.MaxRetries > 0 && node.restarts >= policy.MaxRetries {
+		node.log.Warn("not restarting node, max restart retries reached",
+			zap.String("node-name", node.name),
+			zap.Int("restarts", node.restarts),
+		)
+		return
+	}
+	go ln.restartExitedNode(node, exitCode, policy.Delay(node.restarts))
+}
+
+// Restarts [node] once [delay] passes, unless it was removed, paused or
+// replaced (eg restarted on request) meanwhile, or the network is stopped.
+// If the node fails to start, a new restart is scheduled.
+func (ln *localNetwork) restartExitedNode(node *localNode, exitCode int, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ln.onStopCh:
+		return
+	case <-timer.C:
+	}
+
+	ctx, endNodeOp, err := ln.beginNodeOp(context.Background())
+	if err != nil {
+		node.log.Warn("couldn't restart node", zap.String("node-name", node.name), zap.Error(err))
+		return
+	}
+	defer endNodeOp()
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return
+	}
+	if currentNode, ok := ln.nodes[node.name]; !ok || currentNode != node || node.paused {
+		return
+	}
+	node.restarts++
+	node.log.Info("restarting node", zap.String("node-name", node.name), zap.Int("restart", node.restarts))
+	node.GetAPIClient().CChainEthAPI().Close()
+	node.closeAPIProxy()
+	// same dirs and ports, as for a resumed node
+	nodeConfig := node.GetConfig()
+	nodeConfig.Flags[config.DataDirKey] = node.GetDataDir()
+	nodeConfig.Flags[config.DBPathKey] = node.GetDbDir()
+	nodeConfig.Flags[config.LogsDirKey] = node.GetLogsDir()
+	nodeConfig.Flags[config.HTTPPortKey] = int(node.GetAPIPort())
+	nodeConfig.Flags[config.StakingPortKey] = int(node.GetP2PPort())
+	// a node without process is started as a paused one, keeping its
+	// bootstrap beacon
+	node.paused = true
+	if _, err := ln.addNode(ctx, nodeConfig); err != nil {
+		node.paused = false
+		node.log.Warn("couldn't restart node", zap.String("node-name", node.name), zap.Error(err))
+		ln.scheduleRestart(node, exitCode)
+		return
+	}
+	restarted := ln.nodes[node.name]
+	restarted.restarts = node.restarts
+	node.setReplacedBy(restarted)
+	ln.publishEvent(network.Event{
+		Type:     network.EventNodeRestarted,
+		NodeName: node.name,
+		Message:  fmt.Sprintf("restart %d, after exit code %d", node.restarts, exitCode),
+	})
+}
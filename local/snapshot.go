@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/api"
 	"github.com/ava-labs/avalanche-network-runner/network"
@@ -18,47 +20,26 @@ import (
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	dircopy "github.com/otiai10/copy"
+	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 )
 
-const (
-	deprecatedBuildDirKey           = "build-dir"
-	deprecatedWhitelistedSubnetsKey = "whitelisted-subnets"
-)
-
 // NetworkState defines dynamic network information not available on blockchain db
 type NetworkState struct {
 	// Map from subnet id to elastic subnet tx id
 	SubnetID2ElasticSubnetID map[string]string `json:"subnetID2ElasticSubnetID"`
+	// Heights, versions and validators when the snapshot was saved.
+	// Only used to compare snapshots.
+	ChainState *network.SnapshotChainState `json:"chainState,omitempty"`
+	// Suffix of the next generated node name, so names of nodes removed
+	// before the snapshot are not reused
+	NextNodeSuffix uint64 `json:"nextNodeSuffix,omitempty"`
 }
 
-// snapshots generated using older ANR versions may contain deprecated avago flags
-func fixDeprecatedAvagoFlags(flags map[string]interface{}) error {
-	if vIntf, ok := flags[deprecatedWhitelistedSubnetsKey]; ok {
-		v, ok := vIntf.(string)
-		if !ok {
-			return fmt.Errorf("expected %q to be of type string but got %T", deprecatedWhitelistedSubnetsKey, vIntf)
-		}
-		if v != "" {
-			flags[config.TrackSubnetsKey] = v
-		}
-		delete(flags, deprecatedWhitelistedSubnetsKey)
-	}
-	if vIntf, ok := flags[deprecatedBuildDirKey]; ok {
-		v, ok := vIntf.(string)
-		if !ok {
-			return fmt.Errorf("expected %q to be of type string but got %T", deprecatedBuildDirKey, vIntf)
-		}
-		if v != "" {
-			flags[config.PluginDirKey] = filepath.Join(v, "plugins")
-		}
-		delete(flags, deprecatedBuildDirKey)
-	}
-	return nil
-}
-
-// NewNetwork returns a new network from the given snapshot
+// NewNetworkFromSnapshot returns a new network from the given snapshot.
+// [ctx] bounds the start of the network nodes, but not the network once started.
 func NewNetworkFromSnapshot(
+	ctx context.Context,
 	log logging.Logger,
 	snapshotName string,
 	rootDir string,
@@ -73,15 +54,16 @@ func NewNetworkFromSnapshot(
 	redirectStdout bool,
 	redirectStderr bool,
 ) (network.Network, error) {
+	npc := &nodeProcessCreator{
+		colorPicker: utils.NewColorPicker(),
+		log:         log,
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+	}
 	net, err := newNetwork(
 		log,
 		api.NewAPIClient,
-		&nodeProcessCreator{
-			colorPicker: utils.NewColorPicker(),
-			log:         log,
-			stdout:      os.Stdout,
-			stderr:      os.Stderr,
-		},
+		npc,
 		rootDir,
 		snapshotsDir,
 		reassignPortsIfUsed,
@@ -91,8 +73,9 @@ func NewNetworkFromSnapshot(
 	if err != nil {
 		return net, err
 	}
+	npc.output = net.nodeOutput
 	err = net.loadSnapshot(
-		context.Background(),
+		ctx,
 		snapshotName,
 		binaryPath,
 		pluginDir,
@@ -100,6 +83,8 @@ func NewNetworkFromSnapshot(
 		upgradeConfigs,
 		subnetConfigs,
 		flags,
+		nil,
+		nil,
 	)
 	return net, err
 }
@@ -155,6 +140,8 @@ func (ln *localNetwork) SaveSnapshot(ctx context.Context, snapshotName string) (
 		nodesConfig[nodeName] = nodeConfig
 	}
 
+	// recorded to be able to compare snapshots
+	chainState := ln.snapshotChainState(ctx)
 	// stop network to safely save snapshot
 	if err := ln.stop(ctx); err != nil {
 		return "", err
@@ -178,6 +165,7 @@ func (ln *localNetwork) SaveSnapshot(ctx context.Context, snapshotName string) (
 	}
 	// save network conf
 	networkConfig := network.Config{
+		Version:            network.CurrentConfigVersion,
 		Genesis:            string(ln.genesis),
 		Flags:              networkConfigFlags,
 		NodeConfigs:        []node.Config{},
@@ -185,6 +173,11 @@ func (ln *localNetwork) SaveSnapshot(ctx context.Context, snapshotName string) (
 		ChainConfigFiles:   ln.chainConfigFiles,
 		UpgradeConfigFiles: ln.upgradeConfigFiles,
 		SubnetConfigFiles:  ln.subnetConfigFiles,
+		VMPlugins:          ln.vmPlugins,
+		APITLS:             ln.apiCA != nil,
+		APIRetry:           ln.apiRetry,
+		StartupWaves:       ln.startupWaves,
+		Metadata:           ln.metadata,
 	}
 
 	// no need to save this, will be generated automatically on snapshot load
@@ -203,6 +196,8 @@ func (ln *localNetwork) SaveSnapshot(ctx context.Context, snapshotName string) (
 	}
 	networkState := NetworkState{
 		SubnetID2ElasticSubnetID: subnetID2ElasticSubnetID,
+		ChainState:               &chainState,
+		NextNodeSuffix:           ln.nextNodeSuffix,
 	}
 	networkStateJSON, err := json.MarshalIndent(networkState, "", "    ")
 	if err != nil {
@@ -214,7 +209,9 @@ func (ln *localNetwork) SaveSnapshot(ctx context.Context, snapshotName string) (
 	return snapshotDir, nil
 }
 
-// start network from snapshot
+// start network from snapshot.
+// If not nil, the settings not saved to snapshots are taken from [unsaved].
+// If the load fails, the network is stopped.
 func (ln *localNetwork) loadSnapshot(
 	ctx context.Context,
 	snapshotName string,
@@ -224,6 +221,26 @@ func (ln *localNetwork) loadSnapshot(
 	upgradeConfigs map[string]string,
 	subnetConfigs map[string]string,
 	flags map[string]interface{},
+	nodeFlags map[string]map[string]interface{},
+	unsaved *network.Config,
+) error {
+	err := ln.applySnapshot(ctx, snapshotName, binaryPath, pluginDir, chainConfigs, upgradeConfigs, subnetConfigs, flags, nodeFlags, unsaved)
+	return ln.stopOnLoadError(ctx, err)
+}
+
+// Applies the network config of snapshot [snapshotName], with the given
+// changes, and starts the nodes of the network
+func (ln *localNetwork) applySnapshot(
+	ctx context.Context,
+	snapshotName string,
+	binaryPath string,
+	pluginDir string,
+	chainConfigs map[string]string,
+	upgradeConfigs map[string]string,
+	subnetConfigs map[string]string,
+	flags map[string]interface{},
+	nodeFlags map[string]map[string]interface{},
+	unsaved *network.Config,
 ) error {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
@@ -243,25 +260,35 @@ func (ln *localNetwork) loadSnapshot(
 	if err != nil {
 		return fmt.Errorf("failure reading network config file from snapshot: %w", err)
 	}
-	networkConfig := network.Config{}
-	if err := json.Unmarshal(networkConfigJSON, &networkConfig); err != nil {
-		return fmt.Errorf("failure unmarshaling network config from snapshot: %w", err)
-	}
-	// fix deprecated avago flags
-	if err := fixDeprecatedAvagoFlags(networkConfig.Flags); err != nil {
-		return err
-	}
-	for i := range networkConfig.NodeConfigs {
-		if err := fixDeprecatedAvagoFlags(networkConfig.NodeConfigs[i].Flags); err != nil {
-			return err
-		}
+	// older snapshots are upgraded to the current config format
+	networkConfig, err := network.LoadConfig(networkConfigJSON)
+	if err != nil {
+		return fmt.Errorf("failure loading network config from snapshot: %w", err)
 	}
-	// add flags
+	// add flags, the ones given per node taking precedence
 	for i := range networkConfig.NodeConfigs {
 		for k, v := range flags {
 			networkConfig.NodeConfigs[i].Flags[k] = v
 		}
 	}
+	for nodeName, overrides := range nodeFlags {
+		found := false
+		for i := range networkConfig.NodeConfigs {
+			if networkConfig.NodeConfigs[i].Name != nodeName {
+				continue
+			}
+			for k, v := range overrides {
+				networkConfig.NodeConfigs[i].Flags[k] = v
+			}
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("node %q not found on snapshot %q", nodeName, snapshotName)
+		}
+	}
+	if unsaved != nil {
+		copyUnsavedSettings(&networkConfig, *unsaved)
+	}
 	// load db
 	for _, nodeConfig := range networkConfig.NodeConfigs {
 		sourceDBDir := filepath.Join(snapshotDBDir, nodeConfig.Name)
@@ -328,12 +355,153 @@ func (ln *localNetwork) loadSnapshot(
 			}
 			ln.subnetID2ElasticSubnetID[subnetID] = elasticSubnetID
 		}
+		if networkState.NextNodeSuffix > ln.nextNodeSuffix {
+			ln.nextNodeSuffix = networkState.NextNodeSuffix
+		}
+	}
+	return ln.applyConfig(ctx, networkConfig)
+}
+
+// SettingsChange gives the settings changed by RestartWithSettings
+type SettingsChange struct {
+	// Name of the snapshot the network is saved to. Must not exist.
+	SnapshotName string
+	// If not empty, all the nodes run this binary
+	BinaryPath string
+	// If not empty, all the nodes use this plugin dir
+	PluginDir string
+	// Flags set on all the nodes
+	Flags map[string]interface{}
+	// Node name --> flags set on that node, taking precedence over [Flags]
+	NodeFlags map[string]map[string]interface{}
+	// Chain alias or ID --> chain config file contents, written for all nodes
+	ChainConfigs map[string]string
+	// Chain alias or ID --> upgrade file contents, written for all nodes
+	// (eg to move the activation of chain upgrades)
+	UpgradeConfigs map[string]string
+	// Subnet ID --> subnet config file contents, written for all nodes
+	SubnetConfigs map[string]string
+	// Root dir of the restarted network. If empty, a new temp dir is used.
+	RootDir string
+}
+
+// RestartWithSettings saves local network [net] to snapshot
+// [change.SnapshotName], which stops it, and starts a new network from
+// the snapshot with the settings changed by [change], eg to rehearse an
+// upgrade. Nodes keep their identity, ports and database, so genesis
+// can't be changed this way.
+// The snapshot is kept, so the network can be restored again if the new
+// settings don't work out.
+func RestartWithSettings(ctx context.Context, net network.Network, change SettingsChange) (network.Network, error) {
+	ln, ok := net.(*localNetwork)
+	if !ok {
+		return nil, fmt.Errorf("network of type %T can't be restarted from a snapshot", net)
+	}
+	// lost on the snapshot, and so given to the restarted network
+	unsaved := ln.unsavedSettings()
+	if _, err := ln.SaveSnapshot(ctx, change.SnapshotName); err != nil {
+		return nil, fmt.Errorf("couldn't save snapshot %q: %w", change.SnapshotName, err)
+	}
+	// the saved network is done with
+	if err := ln.Stop(ctx); err != nil {
+		ln.log.Warn("error stopping saved network", zap.String("snapshot", change.SnapshotName), zap.Error(err))
+	}
+	restarted, err := newNetwork(
+		ln.log,
+		ln.newAPIClientF,
+		ln.nodeProcessCreator,
+		change.RootDir,
+		ln.snapshotsDir,
+		ln.reassignPortsIfUsed,
+		ln.redirectStdout,
+		ln.redirectStderr,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := restarted.loadSnapshot(
+		ctx,
+		change.SnapshotName,
+		change.BinaryPath,
+		change.PluginDir,
+		change.ChainConfigs,
+		change.UpgradeConfigs,
+		change.SubnetConfigs,
+		change.Flags,
+		change.NodeFlags,
+		&unsaved,
+	); err != nil {
+		return nil, fmt.Errorf("couldn't restart network from snapshot %q: %w", change.SnapshotName, err)
+	}
+	return restarted, nil
+}
+
+// UpgradeNetwork stops local network [net], saving it to snapshot
+// [snapshotName], and starts it again with the binary and config files
+// given by [spec]. The restarted network is then checked with
+// network.VerifyUpgrade.
+// Unlike network.UpgradeNetwork, all the nodes go down at once. Genesis
+// can't be swapped either way, as nodes keep their database: rule changes
+// must be given as upgrade or chain config files.
+func UpgradeNetwork(ctx context.Context, net network.Network, snapshotName string, spec network.UpgradeSpec) (network.Network, error) {
+	upgraded, err := RestartWithSettings(ctx, net, SettingsChange{
+		SnapshotName:   snapshotName,
+		BinaryPath:     spec.BinaryPath,
+		PluginDir:      spec.PluginDir,
+		ChainConfigs:   spec.ChainConfigs,
+		UpgradeConfigs: spec.UpgradeConfigs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the upgraded network is given back even if not verified, to be stopped
+	return upgraded, network.VerifyUpgrade(ctx, upgraded, spec)
+}
+
+// Returns a network config with the settings of the network, and of its
+// nodes, that are not saved to snapshots (json:"-")
+func (ln *localNetwork) unsavedSettings() network.Config {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	unsaved := network.Config{
+		OnProgress:   ln.onProgress,
+		HealthLogger: ln.healthLogger,
+		NodeLogger:   ln.nodeLogger,
+		APITransport: ln.apiTransport,
+	}
+	for _, n := range ln.nodes {
+		unsaved.NodeConfigs = append(unsaved.NodeConfigs, node.Config{
+			Name:         n.config.Name,
+			RemoveWhen:   n.config.RemoveWhen,
+			NewAPIClient: n.config.NewAPIClient,
+		})
+	}
+	return unsaved
+}
+
+// Sets the settings not saved to snapshots of [networkConfig], and of its
+// nodes by name, to the ones of [unsaved]
+func copyUnsavedSettings(networkConfig *network.Config, unsaved network.Config) {
+	networkConfig.OnProgress = unsaved.OnProgress
+	networkConfig.HealthLogger = unsaved.HealthLogger
+	networkConfig.NodeLogger = unsaved.NodeLogger
+	networkConfig.APITransport = unsaved.APITransport
+	for _, unsavedNodeConfig := range unsaved.NodeConfigs {
+		for i := range networkConfig.NodeConfigs {
+			if networkConfig.NodeConfigs[i].Name == unsavedNodeConfig.Name {
+				networkConfig.NodeConfigs[i].RemoveWhen = unsavedNodeConfig.RemoveWhen
+				networkConfig.NodeConfigs[i].NewAPIClient = unsavedNodeConfig.NewAPIClient
+			}
+		}
 	}
-	return ln.loadConfig(ctx, networkConfig)
 }
 
 // Remove network snapshot
-func (ln *localNetwork) RemoveSnapshot(snapshotName string) error {
+func (ln *localNetwork) RemoveSnapshot(ctx context.Context, snapshotName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	snapshotDir := filepath.Join(ln.snapshotsDir, snapshotPrefix+snapshotName)
 	_, err := os.Stat(snapshotDir)
 	if err != nil {
@@ -350,16 +518,41 @@ func (ln *localNetwork) RemoveSnapshot(snapshotName string) error {
 }
 
 // Get network snapshots
-func (ln *localNetwork) GetSnapshotNames() ([]string, error) {
-	_, err := os.Stat(ln.snapshotsDir)
+func (ln *localNetwork) GetSnapshotNames(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return GetSnapshotNames(ln.snapshotsDir)
+}
+
+// See network.Network
+func (ln *localNetwork) GetSnapshotsInfo(ctx context.Context) ([]network.SnapshotInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return GetSnapshotsInfo(ln.snapshotsDir)
+}
+
+// See network.Network
+func (ln *localNetwork) PruneSnapshots(ctx context.Context, policy network.SnapshotRetentionPolicy) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return PruneSnapshots(ln.log, ln.snapshotsDir, policy)
+}
+
+// GetSnapshotNames returns the names of the snapshots saved at [snapshotsDir].
+// The dir is created on the first snapshot save, so if it doesn't exist
+// there are no snapshots.
+func GetSnapshotNames(snapshotsDir string) ([]string, error) {
+	_, err := os.Stat(snapshotsDir)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("snapshots dir %q does not exists", ln.snapshotsDir)
-		} else {
-			return nil, fmt.Errorf("failure accessing snapshots dir %q: %w", ln.snapshotsDir, err)
+			return []string{}, nil
 		}
+		return nil, fmt.Errorf("failure accessing snapshots dir %q: %w", snapshotsDir, err)
 	}
-	matches, err := filepath.Glob(filepath.Join(ln.snapshotsDir, snapshotPrefix+"*"))
+	matches, err := filepath.Glob(filepath.Join(snapshotsDir, snapshotPrefix+"*"))
 	if err != nil {
 		return nil, err
 	}
@@ -369,3 +562,65 @@ func (ln *localNetwork) GetSnapshotNames() ([]string, error) {
 	}
 	return snapshots, nil
 }
+
+// GetSnapshotsInfo returns info of the snapshots saved at [snapshotsDir],
+// newest first
+func GetSnapshotsInfo(snapshotsDir string) ([]network.SnapshotInfo, error) {
+	snapshotNames, err := GetSnapshotNames(snapshotsDir)
+	if err != nil {
+		return nil, err
+	}
+	snapshotsInfo := []network.SnapshotInfo{}
+	for _, snapshotName := range snapshotNames {
+		snapshotDir := filepath.Join(snapshotsDir, snapshotPrefix+snapshotName)
+		// network config is written last on save
+		fileInfo, err := os.Stat(filepath.Join(snapshotDir, "network.json"))
+		if errors.Is(err, os.ErrNotExist) {
+			fileInfo, err = os.Stat(snapshotDir)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failure accessing snapshot %q: %w", snapshotName, err)
+		}
+		size, err := dirSize(snapshotDir)
+		if err != nil {
+			return nil, fmt.Errorf("failure getting size of snapshot %q: %w", snapshotName, err)
+		}
+		snapshotsInfo = append(snapshotsInfo, network.SnapshotInfo{
+			Name:    snapshotName,
+			Path:    snapshotDir,
+			Size:    size,
+			SavedAt: fileInfo.ModTime(),
+		})
+	}
+	sort.Slice(snapshotsInfo, func(i, j int) bool {
+		return snapshotsInfo[i].SavedAt.After(snapshotsInfo[j].SavedAt)
+	})
+	return snapshotsInfo, nil
+}
+
+// PruneSnapshots removes the snapshots saved at [snapshotsDir] that don't
+// satisfy [policy]. Returns the names of the removed snapshots.
+func PruneSnapshots(
+	log logging.Logger,
+	snapshotsDir string,
+	policy network.SnapshotRetentionPolicy,
+) ([]string, error) {
+	snapshotsInfo, err := GetSnapshotsInfo(snapshotsDir)
+	if err != nil {
+		return nil, err
+	}
+	removed := []string{}
+	for i, snapshotInfo := range snapshotsInfo {
+		tooMany := policy.MaxCount > 0 && i >= policy.MaxCount
+		tooOld := policy.MaxAge > 0 && time.Since(snapshotInfo.SavedAt) > policy.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		log.Info("removing snapshot", zap.String("name", snapshotInfo.Name), zap.Int64("size", snapshotInfo.Size))
+		if err := os.RemoveAll(snapshotInfo.Path); err != nil {
+			return removed, fmt.Errorf("failure removing snapshot path %q: %w", snapshotInfo.Path, err)
+		}
+		removed = append(removed, snapshotInfo.Name)
+	}
+	return removed, nil
+}
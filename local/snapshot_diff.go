@@ -0,0 +1,154 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"go.uber.org/zap"
+)
+
+// max time to get the chain state of the nodes when saving a snapshot
+const snapshotChainStateTimeout = 30 * time.Second
+
+// Returns the heights, versions and validators of the running nodes.
+// Nodes failing to answer are recorded with the fields they answered.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) snapshotChainState(ctx context.Context) network.SnapshotChainState {
+	ctx, cancel := context.WithTimeout(ctx, snapshotChainStateTimeout)
+	defer cancel()
+
+	chainState := network.SnapshotChainState{
+		Nodes:      map[string]network.SnapshotNodeState{},
+		Validators: []string{},
+	}
+	gotValidators := false
+	for nodeName, node := range ln.nodes {
+		nodeState := network.SnapshotNodeState{NodeID: node.nodeID.String()}
+		chainState.Nodes[nodeName] = nodeState
+		if node.paused {
+			continue
+		}
+		client := node.GetAPIClient()
+		errs := wrappers.Errs{}
+		if reply, err := client.InfoAPI().GetNodeVersion(ctx); err != nil {
+			errs.Add(err)
+		} else {
+			nodeState.Version = reply.Version
+		}
+		if height, err := client.PChainAPI().GetHeight(ctx); err != nil {
+			errs.Add(err)
+		} else {
+			nodeState.PChainHeight = height
+		}
+		if height, err := client.CChainEthAPI().BlockNumber(ctx); err != nil {
+			errs.Add(err)
+		} else {
+			nodeState.CChainHeight = height
+		}
+		if !gotValidators {
+			if vdrs, err := client.PChainAPI().GetCurrentValidators(ctx, constants.PrimaryNetworkID, nil); err != nil {
+				errs.Add(err)
+			} else {
+				for _, vdr := range vdrs {
+					chainState.Validators = append(chainState.Validators, vdr.NodeID.String())
+				}
+				sort.Strings(chainState.Validators)
+				gotValidators = true
+			}
+		}
+		if errs.Errored() {
+			ln.log.Warn("couldn't get node chain state for snapshot", zap.String("node-name", nodeName), zap.Error(errs.Err))
+		}
+		chainState.Nodes[nodeName] = nodeState
+	}
+	return chainState
+}
+
+// See network.Network
+func (ln *localNetwork) DiffSnapshots(ctx context.Context, from string, to string) (network.SnapshotDiff, error) {
+	if err := ctx.Err(); err != nil {
+		return network.SnapshotDiff{}, err
+	}
+	return DiffSnapshots(ln.snapshotsDir, from, to)
+}
+
+// DiffSnapshots compares the snapshots [from] and [to] saved at [snapshotsDir].
+// Heights, versions and validators are only compared for snapshots saved with
+// the chain state, DB sizes are always compared.
+// [snapshotsDir] defaults to the default snapshots dir if empty.
+func DiffSnapshots(snapshotsDir string, from string, to string) (network.SnapshotDiff, error) {
+	if snapshotsDir == "" {
+		snapshotsDir = defaultSnapshotsDir
+	}
+	fromGenesis, fromState, err := readSnapshotChainState(snapshotsDir, from)
+	if err != nil {
+		return network.SnapshotDiff{}, err
+	}
+	toGenesis, toState, err := readSnapshotChainState(snapshotsDir, to)
+	if err != nil {
+		return network.SnapshotDiff{}, err
+	}
+	diff := network.SnapshotDiff{
+		From:           from,
+		To:             to,
+		GenesisChanged: fromGenesis != toGenesis,
+	}
+	network.DiffSnapshotChainStates(&diff, fromState, toState)
+	return diff, nil
+}
+
+// Returns the genesis and the chain state of the snapshot [snapshotName].
+// The node DB sizes are obtained from the snapshot files.
+func readSnapshotChainState(snapshotsDir string, snapshotName string) (string, network.SnapshotChainState, error) {
+	chainState := network.SnapshotChainState{}
+	snapshotDir := filepath.Join(snapshotsDir, snapshotPrefix+snapshotName)
+	if _, err := os.Stat(snapshotDir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", chainState, fmt.Errorf("%w: %q", ErrSnapshotNotFound, snapshotName)
+		}
+		return "", chainState, fmt.Errorf("failure accessing snapshot %q: %w", snapshotName, err)
+	}
+	networkConfigJSON, err := os.ReadFile(filepath.Join(snapshotDir, "network.json"))
+	if err != nil {
+		return "", chainState, fmt.Errorf("failure reading network config file from snapshot %q: %w", snapshotName, err)
+	}
+	networkConfig, err := network.LoadConfig(networkConfigJSON)
+	if err != nil {
+		return "", chainState, fmt.Errorf("failure loading network config from snapshot %q: %w", snapshotName, err)
+	}
+	networkStateJSON, err := os.ReadFile(filepath.Join(snapshotDir, "state.json"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", chainState, fmt.Errorf("failure reading network state file from snapshot %q: %w", snapshotName, err)
+	}
+	if err == nil {
+		networkState := NetworkState{}
+		if err := json.Unmarshal(networkStateJSON, &networkState); err != nil {
+			return "", chainState, fmt.Errorf("failure unmarshaling network state from snapshot %q: %w", snapshotName, err)
+		}
+		if networkState.ChainState != nil {
+			chainState = *networkState.ChainState
+		}
+	}
+	if chainState.Nodes == nil {
+		chainState.Nodes = map[string]network.SnapshotNodeState{}
+	}
+	for _, nodeConfig := range networkConfig.NodeConfigs {
+		size, err := dirSize(filepath.Join(snapshotDir, defaultDBSubdir, nodeConfig.Name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", chainState, fmt.Errorf("failure getting db size of node %q on snapshot %q: %w", nodeConfig.Name, snapshotName, err)
+		}
+		nodeState := chainState.Nodes[nodeConfig.Name]
+		nodeState.DBSize = size
+		chainState.Nodes[nodeConfig.Name] = nodeState
+	}
+	return networkConfig.Genesis, chainState, nil
+}
@@ -0,0 +1,25 @@
+package network
+
+// ArtifactPaths are the paths of the directories and files created
+// by the runner for a network, i.e. to be archived by CI jobs
+type ArtifactPaths struct {
+	// Directory under which the node directories are created
+	RootDir string `json:"rootDir"`
+	// Directory where the network snapshots are saved
+	SnapshotsDir string `json:"snapshotsDir"`
+	// CA cert of the node APIs. Empty if the node APIs don't use TLS.
+	APICACert string `json:"apiCACert,omitempty"`
+	// Dir shared by all the nodes. Empty if the network has none.
+	SharedDir string `json:"sharedDir,omitempty"`
+	// Node name --> paths of the node.
+	// Includes the nodes that were removed from the network.
+	Nodes map[string]NodeArtifactPaths `json:"nodes"`
+}
+
+// NodeArtifactPaths are the paths of the directories of a node
+type NodeArtifactPaths struct {
+	DataDir   string `json:"dataDir"`
+	DbDir     string `json:"dbDir"`
+	LogsDir   string `json:"logsDir"`
+	PluginDir string `json:"pluginDir,omitempty"`
+}
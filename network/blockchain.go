@@ -0,0 +1,36 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// CreateBlockchain creates a blockchain of VM [vmName] with genesis [genesis]
+// on subnet [subnetID] of [net], and returns its ID.
+// As with Network.CreateBlockchains, the VM plugin must be found in the plugin
+// dir of the nodes, named after the VM ID (see utils.VMID). The nodes reload
+// their plugins before the chain is created, and it returns once the chain
+// runs on all the subnet validators.
+func CreateBlockchain(
+	ctx context.Context,
+	net Network,
+	subnetID ids.ID,
+	vmName string,
+	genesis []byte,
+) (ids.ID, error) {
+	subnetIDStr := subnetID.String()
+	blockchainIDs, err := net.CreateBlockchains(ctx, []BlockchainSpec{{
+		VMName:   vmName,
+		Genesis:  genesis,
+		SubnetID: &subnetIDStr,
+	}})
+	if err != nil {
+		return ids.Empty, err
+	}
+	if len(blockchainIDs) != 1 {
+		return ids.Empty, fmt.Errorf("expected 1 blockchain to be created, got %d", len(blockchainIDs))
+	}
+	return blockchainIDs[0], nil
+}
@@ -0,0 +1,28 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBlockchain(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	subnetID, blockchainID := ids.GenerateTestID(), ids.GenerateTestID()
+	subnetIDStr := subnetID.String()
+	net := mocks.NewNetwork(t)
+	net.On("CreateBlockchains", ctx, []network.BlockchainSpec{{
+		VMName:   "subnetevm",
+		Genesis:  []byte("genesis"),
+		SubnetID: &subnetIDStr,
+	}}).Return([]ids.ID{blockchainID}, nil)
+	gotBlockchainID, err := network.CreateBlockchain(ctx, net, subnetID, "subnetevm", []byte("genesis"))
+	require.NoError(err)
+	require.Equal(blockchainID, gotBlockchainID)
+}
@@ -0,0 +1,193 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultBridgeLogPollInterval is the default time between reads of
+	// the node logs by the event bridge
+	DefaultBridgeLogPollInterval = time.Second
+	// main log file of a node, at its logs dir
+	bridgeNodeLogFileName = "main.log"
+)
+
+// EventPublisher publishes messages into a topic of a message broker,
+// eg a NATS subject or a Kafka topic
+type EventPublisher interface {
+	Publish(ctx context.Context, topic string, msg []byte) error
+	Close() error
+}
+
+// EventBridgeConfig defines what the event bridge forwards
+type EventBridgeConfig struct {
+	// Topic the network events are published into, JSON encoded
+	EventsTopic string
+	// Topic the matching node log lines are published into,
+	// JSON encoded as NodeLogLine
+	LogsTopic string
+	// Node log lines matching any of these are published.
+	// If empty, no log lines are published.
+	LogPatterns []*regexp.Regexp
+	// Time between reads of the node logs.
+	// Defaults to DefaultBridgeLogPollInterval if 0.
+	LogPollInterval time.Duration
+}
+
+// NodeLogLine is a node log line published by the event bridge
+type NodeLogLine struct {
+	NodeName string `json:"nodeName"`
+	Line     string `json:"line"`
+	// Pattern the line matched
+	Pattern string `json:"pattern"`
+	// Time the line was read
+	Time time.Time `json:"time"`
+}
+
+// nodeLogTail is the read position of a node log
+type nodeLogTail struct {
+	path   string
+	offset int64
+	// last line read, if not complete yet
+	partial []byte
+}
+
+// RunEventBridge publishes the events of [net], and the node log lines
+// matching [config.LogPatterns], into [publisher], so orchestration systems
+// can consume them without polling the runner.
+// Only the log lines written after the bridge starts are published.
+// Runs until [ctx] is done or the network is stopped. Failures to
+// publish are logged, and don't stop the bridge.
+func RunEventBridge(ctx context.Context, log logging.Logger, net Network, publisher EventPublisher, config EventBridgeConfig) {
+	events := net.Events()
+	pollInterval := config.LogPollInterval
+	if pollInterval == 0 {
+		pollInterval = DefaultBridgeLogPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	publish := func(topic string, v interface{}) {
+		msg, err := json.Marshal(v)
+		if err != nil {
+			log.Warn("couldn't marshal bridge message", zap.Error(err))
+			return
+		}
+		if err := publisher.Publish(ctx, topic, msg); err != nil && ctx.Err() == nil {
+			log.Warn("couldn't publish bridge message", zap.String("topic", topic), zap.Error(err))
+		}
+	}
+
+	// node name --> log read position
+	tails := map[string]*nodeLogTail{}
+	if len(config.LogPatterns) != 0 {
+		// skip what was logged before the bridge started
+		readNodeLogs(net, tails, true, func(string, string) {})
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			publish(config.EventsTopic, event)
+		case <-ticker.C:
+			if len(config.LogPatterns) == 0 {
+				continue
+			}
+			readNodeLogs(net, tails, false, func(nodeName string, line string) {
+				for _, pattern := range config.LogPatterns {
+					if pattern.MatchString(line) {
+						publish(config.LogsTopic, NodeLogLine{
+							NodeName: nodeName,
+							Line:     line,
+							Pattern:  pattern.String(),
+							Time:     time.Now(),
+						})
+						return
+					}
+				}
+			})
+		}
+	}
+}
+
+// Calls [onLine] with each complete line appended to the node logs since
+// the previous read. If [skip] is true, just moves the read positions to
+// the end of the logs.
+func readNodeLogs(net Network, tails map[string]*nodeLogTail, skip bool, onLine func(nodeName string, line string)) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return
+	}
+	for nodeName, node := range nodes {
+		path := filepath.Join(node.GetLogsDir(), bridgeNodeLogFileName)
+		tail, ok := tails[nodeName]
+		if !ok || tail.path != path {
+			// logs of a new node are read from the start
+			tail = &nodeLogTail{path: path}
+			tails[nodeName] = tail
+		}
+		lines, err := tail.read(skip)
+		if err != nil {
+			continue
+		}
+		for _, line := range lines {
+			onLine(nodeName, line)
+		}
+	}
+}
+
+// Returns the complete lines appended to the log since the previous read
+func (t *nodeLogTail) read(skip bool) ([]string, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.Size() < t.offset {
+		// truncated or rotated
+		t.offset = 0
+		t.partial = nil
+	}
+	if skip {
+		t.offset = fileInfo.Size()
+		return nil, nil
+	}
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	t.offset += int64(len(b))
+	b = append(t.partial, b...)
+	lines := []string{}
+	for {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, string(bytes.TrimSuffix(b[:i], []byte("\r"))))
+		b = b[i+1:]
+	}
+	t.partial = append([]byte{}, b...)
+	return lines, nil
+}
@@ -0,0 +1,163 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	natsDialTimeout  = 10 * time.Second
+	natsWriteTimeout = 10 * time.Second
+	// content type of the Kafka REST proxy v2 API for JSON records
+	kafkaRESTContentType = "application/vnd.kafka.json.v2+json"
+)
+
+// NewEventPublisher returns a publisher for the broker at [brokerURL]:
+// a NATS server for nats://host:port, or a Kafka REST proxy
+// for http(s)://host:port
+func NewEventPublisher(ctx context.Context, brokerURL string) (EventPublisher, error) {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker url %q: %w", brokerURL, err)
+	}
+	switch u.Scheme {
+	case "nats":
+		return NewNATSPublisher(ctx, u.Host)
+	case "http", "https":
+		return NewKafkaRESTPublisher(brokerURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported broker url scheme %q", u.Scheme)
+	}
+}
+
+// natsPublisher publishes into the subjects of a NATS server, using
+// the NATS core protocol
+type natsPublisher struct {
+	conn net.Conn
+	// serializes writes to [conn]
+	lock sync.Mutex
+}
+
+// NewNATSPublisher connects to the NATS server at [address] (host:port)
+// and returns a publisher into its subjects
+func NewNATSPublisher(ctx context.Context, address string) (EventPublisher, error) {
+	dialer := net.Dialer{Timeout: natsDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't connect to nats server: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	_ = conn.SetReadDeadline(time.Now().Add(natsDialTimeout))
+	info, err := reader.ReadString('\n')
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("couldn't read nats server info: %w", err)
+	}
+	if !strings.HasPrefix(info, "INFO ") {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unexpected nats server greeting %q", strings.TrimSpace(info))
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	p := &natsPublisher{conn: conn}
+	if err := p.write([]byte("CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"avalanche-network-runner\"}\r\n")); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("couldn't send nats connect: %w", err)
+	}
+	go p.handleServerMessages(reader)
+	return p, nil
+}
+
+// Answers the server pings, so the connection is kept open.
+// Returns once the connection is closed.
+func (p *natsPublisher) handleServerMessages(reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.TrimSpace(line) == "PING" {
+			_ = p.write([]byte("PONG\r\n"))
+		}
+	}
+}
+
+func (p *natsPublisher) write(b []byte) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	_ = p.conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout))
+	_, err := p.conn.Write(b)
+	return err
+}
+
+func (p *natsPublisher) Publish(_ context.Context, subject string, msg []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("invalid nats subject %q", subject)
+	}
+	buf := bytes.Buffer{}
+	fmt.Fprintf(&buf, "PUB %s %d\r\n", subject, len(msg))
+	buf.Write(msg)
+	buf.WriteString("\r\n")
+	return p.write(buf.Bytes())
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Close()
+}
+
+// kafkaRESTPublisher publishes into Kafka topics through a Kafka REST proxy
+type kafkaRESTPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewKafkaRESTPublisher returns a publisher into Kafka topics through the
+// Kafka REST proxy (v2 API) at [proxyURL]. Messages must be JSON.
+func NewKafkaRESTPublisher(proxyURL string) EventPublisher {
+	return &kafkaRESTPublisher{
+		url:    strings.TrimSuffix(proxyURL, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *kafkaRESTPublisher) Publish(ctx context.Context, topic string, msg []byte) error {
+	if !json.Valid(msg) {
+		return errors.New("kafka rest proxy messages must be JSON")
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"records": []map[string]interface{}{
+			{"value": json.RawMessage(msg)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaRESTContentType)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected kafka rest proxy status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (*kafkaRESTPublisher) Close() error {
+	return nil
+}
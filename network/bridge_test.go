@@ -0,0 +1,177 @@
+package network_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	nodemocks "github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+type publishedMessage struct {
+	topic string
+	msg   []byte
+}
+
+// chanPublisher sends the published messages into a channel
+type chanPublisher struct {
+	msgs chan publishedMessage
+}
+
+func (p *chanPublisher) Publish(_ context.Context, topic string, msg []byte) error {
+	p.msgs <- publishedMessage{topic: topic, msg: msg}
+	return nil
+}
+
+func (*chanPublisher) Close() error {
+	return nil
+}
+
+func receiveMessage(t *testing.T, msgs <-chan publishedMessage) publishedMessage {
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "no message published")
+		return publishedMessage{}
+	}
+}
+
+func TestRunEventBridge(t *testing.T) {
+	require := require.New(t)
+
+	logsDir := t.TempDir()
+	logPath := filepath.Join(logsDir, "main.log")
+	require.NoError(os.WriteFile(logPath, []byte("old chain bootstrapped\n"), 0o600))
+
+	events := make(chan network.Event)
+	net := mocks.NewNetwork(t)
+	net.On("Events").Return((<-chan network.Event)(events))
+	n := nodemocks.NewNode(t)
+	n.On("GetLogsDir").Return(logsDir)
+	net.On("GetAllNodes").Return(map[string]node.Node{"node1": n}, nil)
+
+	publisher := &chanPublisher{msgs: make(chan publishedMessage, 10)}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		network.RunEventBridge(ctx, logging.NoLog{}, net, publisher, network.EventBridgeConfig{
+			EventsTopic:     "anr.events",
+			LogsTopic:       "anr.logs",
+			LogPatterns:     []*regexp.Regexp{regexp.MustCompile("bootstrapped")},
+			LogPollInterval: 10 * time.Millisecond,
+		})
+		close(done)
+	}()
+
+	events <- network.Event{Type: network.EventNetworkHealthy, Time: time.Now()}
+	msg := receiveMessage(t, publisher.msgs)
+	require.Equal("anr.events", msg.topic)
+	event := network.Event{}
+	require.NoError(json.Unmarshal(msg.msg, &event))
+	require.Equal(network.EventNetworkHealthy, event.Type)
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(err)
+	_, err = f.WriteString("unrelated line\nchain bootstrapped\nnot complete bootstrapped")
+	require.NoError(err)
+	require.NoError(f.Close())
+
+	msg = receiveMessage(t, publisher.msgs)
+	require.Equal("anr.logs", msg.topic)
+	line := network.NodeLogLine{}
+	require.NoError(json.Unmarshal(msg.msg, &line))
+	require.Equal("node1", line.NodeName)
+	require.Equal("chain bootstrapped", line.Line)
+	require.Equal("bootstrapped", line.Pattern)
+
+	cancel()
+	<-done
+	require.Empty(publisher.msgs)
+}
+
+func TestNATSPublisher(t *testing.T) {
+	require := require.New(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer listener.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		lines := []string{}
+		for len(lines) < 3 {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines = append(lines, strings.TrimSpace(line))
+		}
+		received <- lines
+	}()
+
+	publisher, err := network.NewEventPublisher(context.Background(), "nats://"+listener.Addr().String())
+	require.NoError(err)
+	defer publisher.Close()
+	require.Error(publisher.Publish(context.Background(), "anr events", []byte("{}")))
+	require.NoError(publisher.Publish(context.Background(), "anr.events", []byte(`{"type":"network-healthy"}`)))
+
+	select {
+	case lines := <-received:
+		require.True(strings.HasPrefix(lines[0], "CONNECT "))
+		require.Equal([]string{"PUB anr.events 26", `{"type":"network-healthy"}`}, lines[1:])
+	case <-time.After(10 * time.Second):
+		require.FailNow("nats server didn't receive the message")
+	}
+}
+
+func TestKafkaRESTPublisher(t *testing.T) {
+	require := require.New(t)
+
+	type request struct {
+		path        string
+		contentType string
+		body        []byte
+	}
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{path: r.URL.Path, contentType: r.Header.Get("Content-Type"), body: body}
+	}))
+	defer server.Close()
+
+	publisher, err := network.NewEventPublisher(context.Background(), server.URL)
+	require.NoError(err)
+	defer publisher.Close()
+	require.Error(publisher.Publish(context.Background(), "anr.events", []byte("not json")))
+	require.NoError(publisher.Publish(context.Background(), "anr.events", []byte(`{"type":"network-healthy"}`)))
+
+	req := <-requests
+	require.Equal("/topics/anr.events", req.path)
+	require.Equal("application/vnd.kafka.json.v2+json", req.contentType)
+	require.JSONEq(`{"records":[{"value":{"type":"network-healthy"}}]}`, string(req.body))
+
+	_, err = network.NewEventPublisher(context.Background(), "amqp://localhost:5672")
+	require.Error(err)
+}
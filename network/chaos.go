@@ -0,0 +1,31 @@
+package network
+
+import "time"
+
+// ChaosWindow declares a period of intentional fault injection, during which
+// the network, or some of its nodes, are expected to be unhealthy.
+// Health degradation found by the runner during the window is reported
+// as expected, instead of as a regression.
+type ChaosWindow struct {
+	// Description of the injected fault, included in the reports
+	Reason string `json:"reason"`
+	// Names of the nodes affected by the fault.
+	// Empty if the whole network is affected.
+	NodeNames []string `json:"nodeNames,omitempty"`
+	// The window ends after this duration.
+	// Zero if it only ends with EndChaos.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Returns true if the window affects the node with this name
+func (w ChaosWindow) Affects(nodeName string) bool {
+	if len(w.NodeNames) == 0 {
+		return true
+	}
+	for _, name := range w.NodeNames {
+		if name == nodeName {
+			return true
+		}
+	}
+	return false
+}
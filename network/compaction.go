@@ -0,0 +1,9 @@
+package network
+
+// DBCompaction describes the compaction of the database of a node
+type DBCompaction struct {
+	NodeName string `json:"nodeName"`
+	// Size in bytes of the node DB dir before and after the compaction
+	SizeBefore int64 `json:"sizeBefore"`
+	SizeAfter  int64 `json:"sizeAfter"`
+}
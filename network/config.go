@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/units"
 	"golang.org/x/exp/maps"
 )
@@ -22,6 +25,23 @@ var cChainConfig map[string]interface{}
 
 const (
 	validatorStake = units.MegaAvax
+
+	// DefaultNodeOpTimeout is the default max time of a node operation
+	// when node operations are limited
+	DefaultNodeOpTimeout = 5 * time.Minute
+
+	// Defaults of APIRetryConfig
+	DefaultAPIRetryWindow         = time.Minute
+	DefaultAPIRetryMaxRetries     = 10
+	DefaultAPIRetryInitialBackoff = 100 * time.Millisecond
+	DefaultAPIRetryMaxBackoff     = 5 * time.Second
+
+	// MaxNodesWithoutStartupWaves is the max number of nodes of a network
+	// started without StartupWaves
+	MaxNodesWithoutStartupWaves = 100
+	// DefaultWaveHealthTimeout is the default max time to wait for the
+	// nodes of a startup wave to become healthy
+	DefaultWaveHealthTimeout = 5 * time.Minute
 )
 
 func init() {
@@ -49,6 +69,10 @@ type AddrAndBalance struct {
 
 // Config that defines a network when it is created.
 type Config struct {
+	// Version of the serialized config format. See [CurrentConfigVersion].
+	// If 0, the config is assumed to be generated by an older runner
+	// and is migrated on load.
+	Version uint32 `json:"version"`
 	// Must not be empty
 	Genesis string `json:"genesis"`
 	// If 0, will use default network ID
@@ -77,6 +101,144 @@ type Config struct {
 	UpgradeConfigFiles map[string]string `json:"upgradeConfigFiles"`
 	// Subnet config files to use per default, if not specified in node config
 	SubnetConfigFiles map[string]string `json:"subnetConfigFiles"`
+	// VM plugins installed on each node, including the ones added later,
+	// besides its own. A node plugin of the same VM takes precedence.
+	VMPlugins []node.VMPlugin `json:"vmPlugins,omitempty"`
+	// If not nil, Stop verifies that the network didn't leak resources
+	LeakCheck *LeakCheckConfig `json:"leakCheck,omitempty"`
+	// If true, node APIs are served over HTTPS, using certs signed by
+	// a CA generated by the runner. The API clients of the nodes trust the CA.
+	APITLS bool `json:"apiTLS,omitempty"`
+	// If not nil, limits the node operations
+	NodeOps *NodeOpsConfig `json:"nodeOps,omitempty"`
+	// If > 0, the network is automatically stopped once this time passes
+	// since its creation. An EventNetworkExpiring event is published a few
+	// minutes before (or at half of the TTL if shorter).
+	TTL time.Duration `json:"ttl,omitempty"`
+	// If not nil, the API clients of the nodes retry requests that fail
+	// because the node is still starting
+	APIRetry *APIRetryConfig `json:"apiRetry,omitempty"`
+	// If a node fails to start on network creation, the nodes already started
+	// are stopped, unless this is true. In such case, they are kept running, and
+	// the remaining nodes can be started with Network.ResumeCreate.
+	KeepPartialStart bool `json:"keepPartialStart,omitempty"`
+	// If not nil, the nodes are started in waves. Required for networks
+	// with more than MaxNodesWithoutStartupWaves nodes.
+	StartupWaves *StartupWavesConfig `json:"startupWaves,omitempty"`
+	// If > 0, the open files limit (RLIMIT_NOFILE) the node processes raise
+	// themselves to (avalanchego --fd-limit), unless set on the node flags.
+	// Network creation fails if the host hard limit is lower.
+	NodeOpenFilesLimit uint64 `json:"nodeOpenFilesLimit,omitempty"`
+	// If true, a node fails to start if given flags (on the network config,
+	// its node config or its config file) not supported by its avalanchego
+	// binary, as listed by its --help, instead of passing them through
+	StrictFlags bool `json:"strictFlags,omitempty"`
+	// If > 0, Healthy returns once this many of the nodes (not paused) are
+	// healthy, logging the ones that are not yet, instead of waiting for all
+	// of them. Network operations that need all the nodes (eg subnet
+	// creation) still wait for all of them.
+	HealthQuorum int `json:"healthQuorum,omitempty"`
+	// If > 0, how often a node is asked for its health while waiting for
+	// it to be healthy, instead of every 3 seconds
+	HealthPollInterval time.Duration `json:"healthPollInterval,omitempty"`
+	// If > 0, max time each node is given to become healthy on a health
+	// check, after which it is reported unhealthy even if the context of
+	// the check is not done yet
+	NodeHealthTimeout time.Duration `json:"nodeHealthTimeout,omitempty"`
+	// If > 0, max time a node waits to connect to the beacons on bootstrap
+	// (avalanchego --bootstrap-beacon-connection-timeout), unless set on
+	// the flags
+	BeaconConnectionTimeout time.Duration `json:"beaconConnectionTimeout,omitempty"`
+	// If true, a dir shared by all the nodes is created in the root dir, to
+	// exchange data with the VMs. Its path is given to the node processes
+	// (and so to their VM plugins) by the ANR_SHARED_DIR env var. It is
+	// removed when the network is stopped.
+	SharedDir bool `json:"sharedDir,omitempty"`
+	// If > 0, an EventDiskThresholdExceeded event is published when the size
+	// in bytes of the network root dir grows larger than this
+	DiskUsageThreshold int64 `json:"diskUsageThreshold,omitempty"`
+	// If true, an EventChainBootstrapped event is published each time a
+	// chain (P, X, C and subnet chains) finishes bootstrapping on a node,
+	// so chain specific workloads can start without waiting for the
+	// network to be healthy
+	ChainEvents bool `json:"chainEvents,omitempty"`
+	// If not nil, some network events are posted to a webhook
+	Notifications *NotificationsConfig `json:"notifications,omitempty"`
+	// Latency and packet loss of the traffic between given nodes, set
+	// once the nodes are started. See Network.SetLinkConditions.
+	LinkConditions []LinkSpec `json:"linkConditions,omitempty"`
+	// Optional name, description and tags that identify the network,
+	// eg when several networks share a host
+	Metadata
+	// If not nil, called each time a node reaches a new start phase
+	OnProgress ProgressFunc `json:"-"`
+	// If not nil, used instead of the network logger for health checks
+	HealthLogger logging.Logger `json:"-"`
+	// If not nil, called on each node start to get the logger used for
+	// messages about the node (including its process), instead of the
+	// network logger
+	NodeLogger func(nodeName string) logging.Logger `json:"-"`
+	// If not nil, used by the node API clients (including health and
+	// bootstrap checks) to send requests to the node APIs, eg through an
+	// SSH tunnel, a SOCKS proxy or a k8s port-forward, when the nodes are
+	// not directly reachable
+	APITransport http.RoundTripper `json:"-"`
+}
+
+// LeakCheckConfig defines the resource leak verifications done on network Stop.
+// Node ports still in use and node processes (or descendants) still alive
+// are always considered leaks.
+type LeakCheckConfig struct {
+	// If > 0, the network root dir is considered leaked if
+	// its size in bytes is larger than this
+	MaxRootDirSize int64 `json:"maxRootDirSize"`
+}
+
+// Metadata identifies a network
+type Metadata struct {
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// NodeOpsConfig limits node operations (add, remove, pause, resume, restart),
+// so bursts of them don't overwhelm small hosts.
+// Operations are run one at a time, in arrival order.
+type NodeOpsConfig struct {
+	// If > 0, max number of node processes starting at the same time.
+	// A node is starting until its API is reachable, or [Timeout] passes.
+	MaxConcurrentStarts int `json:"maxConcurrentStarts"`
+	// Max time a node operation can take, including the time waiting
+	// for previous operations. Defaults to DefaultNodeOpTimeout if 0.
+	Timeout time.Duration `json:"timeout"`
+}
+
+// APIRetryConfig defines the retries done by node API clients on transient
+// errors (connection refused and 5xx responses), during the window after
+// each node start. Zero fields take their defaults.
+type APIRetryConfig struct {
+	// Time after a node start during which failed requests are retried
+	Window time.Duration `json:"window"`
+	// Max retries per request
+	MaxRetries int `json:"maxRetries"`
+	// Wait before the first retry, doubled on each retry
+	// up to [MaxBackoff]
+	InitialBackoff time.Duration `json:"initialBackoff"`
+	MaxBackoff     time.Duration `json:"maxBackoff"`
+}
+
+// StartupWavesConfig shards the startup of large networks into waves, so
+// starting all the nodes at once doesn't exhaust the host file descriptors
+// and CPU. Each wave is started once the nodes of the previous ones are
+// healthy. Use NodeOpsConfig.MaxConcurrentStarts to also limit the nodes
+// starting at the same time within a wave.
+type StartupWavesConfig struct {
+	// Number of nodes started on each wave. Beacons are started first,
+	// so they should fit on the first wave.
+	WaveSize int `json:"waveSize"`
+	// Max time to wait for the nodes started to become healthy before
+	// starting the next wave. Defaults to DefaultWaveHealthTimeout if 0.
+	HealthTimeout time.Duration `json:"healthTimeout,omitempty"`
 }
 
 // Validate returns an error if this config is invalid
@@ -111,6 +273,49 @@ func (c *Config) Validate() error {
 	if len(c.NodeConfigs) > 0 && !someNodeIsBeacon {
 		return errors.New("beacon nodes not given")
 	}
+	if err := node.ValidateVMPlugins(c.VMPlugins); err != nil {
+		return fmt.Errorf("invalid network VM plugins: %w", err)
+	}
+	if c.TTL < 0 {
+		return errors.New("negative TTL")
+	}
+	if c.DiskUsageThreshold < 0 {
+		return errors.New("negative disk usage threshold")
+	}
+	if c.HealthQuorum < 0 {
+		return errors.New("negative health quorum")
+	}
+	if c.HealthPollInterval < 0 {
+		return errors.New("negative health poll interval")
+	}
+	if c.NodeHealthTimeout < 0 {
+		return errors.New("negative node health timeout")
+	}
+	if c.BeaconConnectionTimeout < 0 {
+		return errors.New("negative beacon connection timeout")
+	}
+	if c.Notifications != nil {
+		u, err := url.Parse(c.Notifications.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid notifications webhook url %q", c.Notifications.WebhookURL)
+		}
+	}
+	for _, link := range c.LinkConditions {
+		if err := link.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.StartupWaves == nil && len(c.NodeConfigs) > MaxNodesWithoutStartupWaves {
+		return fmt.Errorf("networks with more than %d nodes must be started in waves", MaxNodesWithoutStartupWaves)
+	}
+	if c.StartupWaves != nil {
+		if c.StartupWaves.WaveSize <= 0 {
+			return errors.New("startup wave size must be positive")
+		}
+		if c.StartupWaves.HealthTimeout < 0 {
+			return errors.New("negative startup wave health timeout")
+		}
+	}
 	return nil
 }
 
@@ -49,6 +49,9 @@ func TestConfigMarshalJSON(t *testing.T) {
 		Flags: map[string]interface{}{
 			"flag-three": "val-three",
 		},
+		Metadata: network.Metadata{
+			Name: "abcxyz",
+		},
 	}
 
 	var netcfg network.Config
@@ -58,3 +61,26 @@ func TestConfigMarshalJSON(t *testing.T) {
 
 	require.EqualValues(t, control, netcfg)
 }
+
+func TestMigrateConfig(t *testing.T) {
+	require := require.New(t)
+
+	v0JSON := "{\"genesis\":\"g\",\"flags\":{\"whitelisted-subnets\":\"subnet1\"},\"nodeConfigs\":[{\"name\":\"node1\",\"flags\":{\"build-dir\":\"/tmp/build\"}}]}"
+	netcfg, err := network.LoadConfig([]byte(v0JSON))
+	require.NoError(err)
+	require.Equal(network.CurrentConfigVersion, netcfg.Version)
+	require.Equal(map[string]interface{}{"track-subnets": "subnet1"}, netcfg.Flags)
+	require.Len(netcfg.NodeConfigs, 1)
+	require.Equal(map[string]interface{}{"plugin-dir": "/tmp/build/plugins"}, netcfg.NodeConfigs[0].Flags)
+
+	// current version configs are loaded as is
+	currentJSON, err := json.Marshal(netcfg)
+	require.NoError(err)
+	migratedJSON, err := network.MigrateConfig(currentJSON)
+	require.NoError(err)
+	require.Equal(currentJSON, migratedJSON)
+
+	// configs from newer runner versions are rejected
+	_, err = network.LoadConfig([]byte("{\"version\":1000,\"genesis\":\"g\"}"))
+	require.Error(err)
+}
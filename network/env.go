@@ -0,0 +1,161 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Prefix of the env vars overriding network config fields,
+	// eg AVALANCHE_NR_HEALTH_POLL_INTERVAL=1s
+	EnvPrefix = "AVALANCHE_NR_"
+	// Prefix, after EnvPrefix, of the env vars overriding the flags given
+	// to all the nodes, eg AVALANCHE_NR_NODE_DEFAULTS_LOG_LEVEL=debug
+	envNodeDefaultsPrefix = "NODE_DEFAULTS_"
+	// Prefix, after EnvPrefix, of the env vars overriding the fields or
+	// flags of a node, eg AVALANCHE_NR_NODE_NODE1_BINARY_PATH=/tmp/avalanchego
+	// or AVALANCHE_NR_NODE_NODE1_LOG_LEVEL=debug
+	envNodePrefix = "NODE_"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ApplyEnv overrides the fields of [c] with the env vars starting with
+// EnvPrefix found in [environ] (given as by os.Environ), in this order of
+// precedence:
+//   - AVALANCHE_NR_<FIELD> sets the network config field <FIELD>
+//   - AVALANCHE_NR_NODE_DEFAULTS_<FLAG> sets the flag <FLAG> of all the
+//     nodes, as a network flag
+//   - AVALANCHE_NR_NODE_<NAME>_<FIELD> sets the field <FIELD> of the node
+//     named <NAME>, or its flag <FIELD> if node configs have no such field
+//
+// Names are matched regardless of case, with underscores in place of
+// dashes for flags and node names, and between the words of fields.
+// Durations are given as "1m30s", strings as they are, flags and other
+// values as json, or as strings if they are not json.
+// Returns an error if a field or a node is not found.
+func (c *Config) ApplyEnv(environ []string) error {
+	environ = append([]string{}, environ...)
+	sort.Strings(environ)
+	for _, envVar := range environ {
+		key, value, ok := strings.Cut(envVar, "=")
+		if !ok || !strings.HasPrefix(key, EnvPrefix) {
+			continue
+		}
+		if err := c.applyEnvVar(strings.TrimPrefix(key, EnvPrefix), value); err != nil {
+			return fmt.Errorf("couldn't apply env var %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Sets the network config field, node default flag, or node field or flag
+// given by env var [key] (without EnvPrefix) to [value]
+func (c *Config) applyEnvVar(key string, value string) error {
+	// network fields first, as some start with NODE_
+	if field, ok := envField(reflect.ValueOf(c).Elem(), key); ok {
+		return setEnvField(field, value)
+	}
+	if flag, ok := strings.CutPrefix(key, envNodeDefaultsPrefix); ok && flag != "" {
+		if c.Flags == nil {
+			c.Flags = map[string]interface{}{}
+		}
+		c.Flags[envFlagName(flag)] = envFlagValue(value)
+		return nil
+	}
+	if nodeKey, ok := strings.CutPrefix(key, envNodePrefix); ok {
+		return c.applyNodeEnvVar(nodeKey, value)
+	}
+	return fmt.Errorf("no network config field %q", key)
+}
+
+// Sets the field or flag given by [nodeKey], starting with the node name,
+// of the node config with the longest name it starts with
+func (c *Config) applyNodeEnvVar(nodeKey string, value string) error {
+	nodeIndex := -1
+	fieldKey := ""
+	for i, nodeConfig := range c.NodeConfigs {
+		prefix := envNodeName(nodeConfig.Name) + "_"
+		if len(nodeKey) == len(prefix) || !strings.HasPrefix(strings.ToUpper(nodeKey), prefix) {
+			continue
+		}
+		if nodeIndex == -1 || len(nodeKey)-len(prefix) < len(fieldKey) {
+			nodeIndex = i
+			fieldKey = nodeKey[len(prefix):]
+		}
+	}
+	if nodeIndex == -1 {
+		return fmt.Errorf("no node config for %q", nodeKey)
+	}
+	nodeConfig := &c.NodeConfigs[nodeIndex]
+	if field, ok := envField(reflect.ValueOf(nodeConfig).Elem(), fieldKey); ok {
+		return setEnvField(field, value)
+	}
+	if nodeConfig.Flags == nil {
+		nodeConfig.Flags = map[string]interface{}{}
+	}
+	nodeConfig.Flags[envFlagName(fieldKey)] = envFlagValue(value)
+	return nil
+}
+
+// Returns the field of struct [v] whose json name is [key], regardless of
+// case and underscores
+func envField(v reflect.Value, key string) (reflect.Value, bool) {
+	key = strings.ToLower(strings.ReplaceAll(key, "_", ""))
+	for i := 0; i < v.NumField(); i++ {
+		jsonName, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+		if strings.ToLower(jsonName) == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// Sets [field] to env var value [value]
+func setEnvField(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	default:
+		fieldValue := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(value), fieldValue.Interface()); err != nil {
+			return err
+		}
+		field.Set(fieldValue.Elem())
+	}
+	return nil
+}
+
+// Returns the avalanchego flag named [key] in an env var, eg
+// LOG_LEVEL --> log-level
+func envFlagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// Returns the node name [name] as given in env vars, eg
+// node-1 --> NODE_1
+func envNodeName(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Returns flag value [value] decoded as json, or as it is if it is not json
+func envFlagValue(value string) interface{} {
+	var flagValue interface{}
+	if err := json.Unmarshal([]byte(value), &flagValue); err != nil {
+		return value
+	}
+	return flagValue
+}
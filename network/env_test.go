@@ -0,0 +1,50 @@
+package network_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigApplyEnv(t *testing.T) {
+	require := require.New(t)
+
+	config := network.Config{
+		Genesis:           "genesis",
+		Flags:             map[string]interface{}{"log-level": "info"},
+		NodeHealthTimeout: time.Minute,
+		NodeConfigs: []node.Config{
+			{Name: "node1", BinaryPath: "/bin/avalanchego"},
+			{Name: "node1-extra"},
+		},
+	}
+	require.NoError(config.ApplyEnv([]string{
+		"PATH=/bin",
+		"AVALANCHE_NR_NODE_DEFAULTS_LOG_LEVEL=debug",
+		"AVALANCHE_NR_NODE_DEFAULTS_INDEX_ENABLED=true",
+		// network fields are matched before node names
+		"AVALANCHE_NR_NODE_HEALTH_TIMEOUT=90s",
+		"AVALANCHE_NR_HEALTH_QUORUM=1",
+		"AVALANCHE_NR_NODE_OPS={\"maxConcurrentStarts\":2}",
+		"AVALANCHE_NR_NODE_NODE1_BINARY_PATH=/tmp/avalanchego",
+		"AVALANCHE_NR_NODE_NODE1_HTTP_PORT=9650",
+		// the longest node name is matched
+		"AVALANCHE_NR_NODE_NODE1_EXTRA_IS_BEACON=true",
+	}))
+	require.Equal(map[string]interface{}{"log-level": "debug", "index-enabled": true}, config.Flags)
+	require.Equal(90*time.Second, config.NodeHealthTimeout)
+	require.Equal(1, config.HealthQuorum)
+	require.Equal(&network.NodeOpsConfig{MaxConcurrentStarts: 2}, config.NodeOps)
+	require.Equal("/tmp/avalanchego", config.NodeConfigs[0].BinaryPath)
+	require.Equal(map[string]interface{}{"http-port": float64(9650)}, config.NodeConfigs[0].Flags)
+	require.False(config.NodeConfigs[0].IsBeacon)
+	require.True(config.NodeConfigs[1].IsBeacon)
+
+	require.ErrorContains(config.ApplyEnv([]string{"AVALANCHE_NR_NO_SUCH_FIELD=1"}), "AVALANCHE_NR_NO_SUCH_FIELD")
+	require.ErrorContains(config.ApplyEnv([]string{"AVALANCHE_NR_NODE_NODE2_LOG_LEVEL=debug"}), "no node config")
+	require.Error(config.ApplyEnv([]string{"AVALANCHE_NR_TTL=forever"}))
+	require.Error(config.ApplyEnv([]string{"AVALANCHE_NR_HEALTH_QUORUM=some"}))
+}
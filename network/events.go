@@ -0,0 +1,59 @@
+package network
+
+import "time"
+
+// EventType identifies the kind of an Event
+type EventType string
+
+const (
+	// A large jump of the host wall clock was detected,
+	// usually because the host was suspended and resumed.
+	EventClockJump EventType = "clock-jump"
+	// All the running nodes were found healthy
+	EventNetworkHealthy EventType = "network-healthy"
+	// Some running node was found unhealthy
+	EventNetworkUnhealthy EventType = "network-unhealthy"
+	// The network was stopped, and no more events are published
+	EventNetworkStopped EventType = "network-stopped"
+	// The network TTL is about to pass
+	EventNetworkExpiring EventType = "network-expiring"
+	// The network TTL passed, and the network is being stopped
+	EventNetworkExpired EventType = "network-expired"
+	// A chaos window was declared
+	EventChaosStarted EventType = "chaos-started"
+	// The chaos window ended
+	EventChaosEnded EventType = "chaos-ended"
+	// A node process was started, on network start or when the node
+	// is added, restarted or resumed
+	EventNodeStarted EventType = "node-started"
+	// A node process exited without being asked to stop
+	EventNodeCrashed EventType = "node-crashed"
+	// A node process was stopped on request, either gracefully or
+	// by killing it once the stop timeout passed
+	EventNodeStopped EventType = "node-stopped"
+	// A node whose process exited without being asked to was restarted,
+	// as given by its node.RestartPolicy
+	EventNodeRestarted EventType = "node-restarted"
+	// The network root dir grew larger than Config.DiskUsageThreshold
+	EventDiskThresholdExceeded EventType = "disk-threshold-exceeded"
+	// A chain finished bootstrapping on a node.
+	// Only published if Config.ChainEvents is set.
+	EventChainBootstrapped EventType = "chain-bootstrapped"
+)
+
+// Event is a notification of something that happened on the network
+type Event struct {
+	Type EventType `json:"type"`
+	// Name of the node the event refers to.
+	// Empty for network wide events.
+	NodeName string `json:"nodeName,omitempty"`
+	// Alias (P, X, C) or ID of the chain the event refers to.
+	// Empty for events not related to a chain.
+	Chain string    `json:"chain,omitempty"`
+	Time  time.Time `json:"time"`
+	// Human readable details about the event
+	Message string `json:"message,omitempty"`
+	// True if the event is the expected consequence of a declared
+	// chaos window, instead of a regression
+	Expected bool `json:"expected,omitempty"`
+}
@@ -0,0 +1,217 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/coreth/accounts/abi/bind"
+	"github.com/ava-labs/coreth/accounts/keystore"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// mnemonic entropy for 24 words
+	evmMnemonicEntropyBits = 256
+	// BIP44 purpose and coin type of EVM accounts, as in m/44'/60'/0'/0/i
+	evmBIP44Purpose  = 44
+	evmBIP44CoinType = 60
+)
+
+// EVMAccount is an EVM account derived from a mnemonic
+type EVMAccount struct {
+	// Index of the account in the derivation path m/44'/60'/0'/0/[Index]
+	Index      uint32
+	Address    common.Address
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// NewEVMMnemonic returns a new random 24 word BIP39 mnemonic
+func NewEVMMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(evmMnemonicEntropyBits)
+	if err != nil {
+		return "", fmt.Errorf("couldn't generate mnemonic entropy: %w", err)
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// DeriveEVMAccounts returns the first [n] accounts derived from [mnemonic],
+// following the derivation path m/44'/60'/0'/0/i used by EVM wallets
+func DeriveEVMAccounts(mnemonic string, n int) ([]EVMAccount, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("invalid mnemonic")
+	}
+	master, err := hdkeychain.NewMaster(bip39.NewSeed(mnemonic, ""), &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't derive master key: %w", err)
+	}
+	// m/44'/60'/0'/0
+	key := master
+	for _, i := range []uint32{
+		hdkeychain.HardenedKeyStart + evmBIP44Purpose,
+		hdkeychain.HardenedKeyStart + evmBIP44CoinType,
+		hdkeychain.HardenedKeyStart,
+		0,
+	} {
+		key, err = key.Derive(i)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't derive key: %w", err)
+		}
+	}
+	accounts := make([]EVMAccount, 0, n)
+	for i := uint32(0); i < uint32(n); i++ {
+		accountKey, err := key.Derive(i)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't derive account %d key: %w", i, err)
+		}
+		ecPrivKey, err := accountKey.ECPrivKey()
+		if err != nil {
+			return nil, err
+		}
+		privKey, err := crypto.ToECDSA(ecPrivKey.Serialize())
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, EVMAccount{
+			Index:      i,
+			Address:    crypto.PubkeyToAddress(privKey.PublicKey),
+			PrivateKey: privKey,
+		})
+	}
+	return accounts, nil
+}
+
+// EVMAccountsBalances returns the C-Chain balances that fund each of
+// [accounts] with [balance], to be given to NewAvalancheGoGenesis
+func EVMAccountsBalances(accounts []EVMAccount, balance *big.Int) []AddrAndBalance {
+	balances := make([]AddrAndBalance, 0, len(accounts))
+	for _, account := range accounts {
+		balances = append(balances, AddrAndBalance{
+			Addr:    ids.ShortID(account.Address),
+			Balance: balance,
+		})
+	}
+	return balances
+}
+
+// FundEVMAccounts returns a copy of the avalanchego [genesis] where
+// each of [accounts] has [balance] on the C-Chain
+func FundEVMAccounts(genesis []byte, accounts []EVMAccount, balance *big.Int) ([]byte, error) {
+	var genesisMap map[string]interface{}
+	if err := json.Unmarshal(genesis, &genesisMap); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal genesis: %w", err)
+	}
+	cChainGenesisStr, ok := genesisMap["cChainGenesis"].(string)
+	if !ok {
+		return nil, errors.New("genesis has no C-Chain genesis")
+	}
+	var cChainGenesis map[string]interface{}
+	if err := json.Unmarshal([]byte(cChainGenesisStr), &cChainGenesis); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal C-Chain genesis: %w", err)
+	}
+	alloc, ok := cChainGenesis["alloc"].(map[string]interface{})
+	if !ok {
+		alloc = map[string]interface{}{}
+	}
+	for _, account := range accounts {
+		// the address may be already allocated, with keys in other formats
+		for addrHex := range alloc {
+			if common.HexToAddress(addrHex) == account.Address {
+				delete(alloc, addrHex)
+			}
+		}
+		alloc["0x"+hex.EncodeToString(account.Address[:])] = map[string]interface{}{
+			"balance": fmt.Sprintf("0x%x", balance),
+		}
+	}
+	cChainGenesis["alloc"] = alloc
+	cChainGenesisBytes, err := json.Marshal(cChainGenesis)
+	if err != nil {
+		return nil, err
+	}
+	genesisMap["cChainGenesis"] = string(cChainGenesisBytes)
+	return json.MarshalIndent(genesisMap, "", "\t")
+}
+
+// WriteEVMKeystore writes an encrypted keystore file for each of [accounts]
+// into [dir], using [password]. Returns the paths of the files.
+// Light scrypt params are used, as the accounts are meant for tests.
+func WriteEVMKeystore(dir string, accounts []EVMAccount, password string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("couldn't create keystore dir: %w", err)
+	}
+	paths := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		keyJSON, err := keystore.EncryptKey(&keystore.Key{
+			Id:         uuid.New(),
+			Address:    account.Address,
+			PrivateKey: account.PrivateKey,
+		}, password, keystore.LightScryptN, keystore.LightScryptP)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't encrypt key of account %s: %w", account.Address, err)
+		}
+		// same file name as the ones written by geth
+		fileName := fmt.Sprintf("UTC--%s--%s",
+			time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"),
+			hex.EncodeToString(account.Address[:]),
+		)
+		path := filepath.Join(dir, fileName)
+		if err := os.WriteFile(path, keyJSON, 0o600); err != nil {
+			return nil, fmt.Errorf("couldn't write keystore file: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// evmAccountJSON is the format of the accounts written by WriteEVMAccounts
+type evmAccountJSON struct {
+	Index      uint32         `json:"index"`
+	Address    common.Address `json:"address"`
+	PrivateKey string         `json:"privateKey"`
+}
+
+// WriteEVMAccounts writes the addresses and hex encoded private keys of
+// [accounts] into the JSON file at [path], eg for scripts and dapp tests
+func WriteEVMAccounts(path string, accounts []EVMAccount) error {
+	accountsJSON := make([]evmAccountJSON, 0, len(accounts))
+	for _, account := range accounts {
+		accountsJSON = append(accountsJSON, evmAccountJSON{
+			Index:      account.Index,
+			Address:    account.Address,
+			PrivateKey: hex.EncodeToString(crypto.FromECDSA(account.PrivateKey)),
+		})
+	}
+	b, err := json.MarshalIndent(accountsJSON, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("couldn't write accounts file: %w", err)
+	}
+	return nil
+}
+
+// SignTx signs [tx] with the account key, for the chain [chainID]
+func (a EVMAccount) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), a.PrivateKey)
+}
+
+// Transactor returns the options to send contract transactions from the
+// account, on the chain [chainID]
+func (a EVMAccount) Transactor(chainID *big.Int) (*bind.TransactOpts, error) {
+	return bind.NewKeyedTransactorWithChainID(a.PrivateKey, chainID)
+}
@@ -0,0 +1,87 @@
+package network_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/coreth/accounts/keystore"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestDeriveEVMAccounts(t *testing.T) {
+	require := require.New(t)
+	accounts, err := network.DeriveEVMAccounts(testMnemonic, 2)
+	require.NoError(err)
+	require.Len(accounts, 2)
+	// well known accounts of the test mnemonic
+	require.Equal(common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), accounts[0].Address)
+	require.Equal(common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"), accounts[1].Address)
+
+	_, err = network.DeriveEVMAccounts("not a mnemonic", 1)
+	require.Error(err)
+
+	mnemonic, err := network.NewEVMMnemonic()
+	require.NoError(err)
+	_, err = network.DeriveEVMAccounts(mnemonic, 1)
+	require.NoError(err)
+}
+
+func TestEVMAccountFiles(t *testing.T) {
+	require := require.New(t)
+	accounts, err := network.DeriveEVMAccounts(testMnemonic, 2)
+	require.NoError(err)
+
+	// genesis funding
+	genesisMap, err := network.LoadLocalGenesis()
+	require.NoError(err)
+	genesis, err := json.Marshal(genesisMap)
+	require.NoError(err)
+	genesis, err = network.FundEVMAccounts(genesis, accounts, big.NewInt(1000))
+	require.NoError(err)
+	require.NoError(json.Unmarshal(genesis, &genesisMap))
+	var cChainGenesis struct {
+		Alloc map[string]struct {
+			Balance string `json:"balance"`
+		} `json:"alloc"`
+	}
+	require.NoError(json.Unmarshal([]byte(genesisMap["cChainGenesis"].(string)), &cChainGenesis))
+	require.Equal("0x3e8", cChainGenesis.Alloc["0x70997970c51812dc3a010c7d01b50e0d17dc79c8"].Balance)
+	// the existing allocations are kept
+	require.Greater(len(cChainGenesis.Alloc), 2)
+
+	// keystore
+	paths, err := network.WriteEVMKeystore(filepath.Join(t.TempDir(), "keystore"), accounts, "password")
+	require.NoError(err)
+	require.Len(paths, 2)
+	keyJSON, err := os.ReadFile(paths[0])
+	require.NoError(err)
+	key, err := keystore.DecryptKey(keyJSON, "password")
+	require.NoError(err)
+	require.Equal(accounts[0].Address, key.Address)
+
+	// accounts file
+	accountsPath := filepath.Join(t.TempDir(), "accounts.json")
+	require.NoError(network.WriteEVMAccounts(accountsPath, accounts))
+	accountsJSON, err := os.ReadFile(accountsPath)
+	require.NoError(err)
+	require.Contains(string(accountsJSON), "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+
+	// signing
+	chainID := big.NewInt(43112)
+	tx, err := accounts[0].SignTx(types.NewTransaction(0, accounts[1].Address, big.NewInt(1), 21000, big.NewInt(1), nil), chainID)
+	require.NoError(err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	require.NoError(err)
+	require.Equal(accounts[0].Address, sender)
+	opts, err := accounts[1].Transactor(chainID)
+	require.NoError(err)
+	require.Equal(accounts[1].Address, opts.From)
+}
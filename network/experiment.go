@@ -0,0 +1,182 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"golang.org/x/exp/maps"
+)
+
+// ExperimentSpec defines a flag experiment run by RunExperiment
+type ExperimentSpec struct {
+	// Config of the control network
+	Config Config
+	// Flag set to [FlagValue] on all the nodes of the treatment network.
+	// Otherwise, the treatment network has the same config as the
+	// control one.
+	FlagName  string
+	FlagValue interface{}
+	// Creates and starts a network with the given config, eg a closure
+	// over local.NewNetwork
+	NewNetwork func(context.Context, Config) (Network, error)
+	// Run on each network once healthy, eg issuing txs. Should do the same
+	// work on both networks, so their metrics can be compared.
+	Workload func(ctx context.Context, net Network) error
+}
+
+// ExperimentRun holds the results of the workload on one of the
+// networks of an experiment
+type ExperimentRun struct {
+	// Time the workload took
+	WorkloadDuration time.Duration `json:"workloadDuration"`
+	// Change of each metric while the workload ran, added up over all
+	// the nodes of the network
+	Metrics Metrics `json:"metrics"`
+}
+
+// MetricComparison compares a metric of the control and treatment runs
+type MetricComparison struct {
+	Name      string  `json:"name"`
+	Control   float64 `json:"control"`
+	Treatment float64 `json:"treatment"`
+}
+
+// ExperimentReport compares the control and treatment networks of an
+// experiment
+type ExperimentReport struct {
+	FlagName  string        `json:"flagName"`
+	FlagValue string        `json:"flagValue"`
+	Control   ExperimentRun `json:"control"`
+	Treatment ExperimentRun `json:"treatment"`
+	// The metrics that changed on any of the runs, sorted by name
+	Comparison []MetricComparison `json:"comparison"`
+}
+
+// RunExperiment evaluates a flag change, by running the workload of
+// [spec] on a control network and on a treatment network having the flag
+// changed, and comparing their metrics. The networks are run one after
+// the other, so they don't compete for the host resources, and each one
+// is stopped once its workload finishes.
+func RunExperiment(ctx context.Context, spec ExperimentSpec) (ExperimentReport, error) {
+	if spec.FlagName == "" {
+		return ExperimentReport{}, errors.New("no experiment flag given")
+	}
+	if spec.NewNetwork == nil || spec.Workload == nil {
+		return ExperimentReport{}, errors.New("experiment network constructor and workload must be given")
+	}
+	control, err := runExperimentNetwork(ctx, spec, spec.Config)
+	if err != nil {
+		return ExperimentReport{}, fmt.Errorf("control network: %w", err)
+	}
+	treatment, err := runExperimentNetwork(ctx, spec, treatmentConfig(spec.Config, spec.FlagName, spec.FlagValue))
+	if err != nil {
+		return ExperimentReport{}, fmt.Errorf("treatment network: %w", err)
+	}
+	return ExperimentReport{
+		FlagName:   spec.FlagName,
+		FlagValue:  fmt.Sprintf("%v", spec.FlagValue),
+		Control:    control,
+		Treatment:  treatment,
+		Comparison: compareMetrics(control.Metrics, treatment.Metrics),
+	}, nil
+}
+
+// Returns a copy of [config] with flag [flagName] set to [flagValue]
+// on the network and node flags
+func treatmentConfig(config Config, flagName string, flagValue interface{}) Config {
+	config.Flags = maps.Clone(config.Flags)
+	if config.Flags == nil {
+		config.Flags = map[string]interface{}{}
+	}
+	config.Flags[flagName] = flagValue
+	config.NodeConfigs = append([]node.Config{}, config.NodeConfigs...)
+	for i := range config.NodeConfigs {
+		// node flags take precedence over the network ones
+		if _, ok := config.NodeConfigs[i].Flags[flagName]; ok {
+			config.NodeConfigs[i].Flags = maps.Clone(config.NodeConfigs[i].Flags)
+			config.NodeConfigs[i].Flags[flagName] = flagValue
+		}
+	}
+	return config
+}
+
+// Creates a network with [config], runs the workload on it once healthy,
+// and stops it
+func runExperimentNetwork(ctx context.Context, spec ExperimentSpec, config Config) (ExperimentRun, error) {
+	net, err := spec.NewNetwork(ctx, config)
+	if err != nil {
+		return ExperimentRun{}, err
+	}
+	defer func() {
+		_ = net.Stop(context.Background())
+	}()
+	if err := net.Healthy(ctx); err != nil {
+		return ExperimentRun{}, fmt.Errorf("network not healthy: %w", err)
+	}
+	before, err := net.ScrapeMetrics(ctx)
+	if err != nil {
+		return ExperimentRun{}, err
+	}
+	start := time.Now()
+	if err := spec.Workload(ctx, net); err != nil {
+		return ExperimentRun{}, fmt.Errorf("workload failed: %w", err)
+	}
+	duration := time.Since(start)
+	after, err := net.ScrapeMetrics(ctx)
+	if err != nil {
+		return ExperimentRun{}, err
+	}
+	metrics := Metrics{}
+	for _, nodeDiff := range DiffMetrics(before, after) {
+		for key, delta := range nodeDiff {
+			metrics[key] += delta
+		}
+	}
+	return ExperimentRun{
+		WorkloadDuration: duration,
+		Metrics:          metrics,
+	}, nil
+}
+
+// Returns the comparison of the metrics that changed on any of the runs
+func compareMetrics(control, treatment Metrics) []MetricComparison {
+	names := map[string]struct{}{}
+	for name := range control {
+		names[name] = struct{}{}
+	}
+	for name := range treatment {
+		names[name] = struct{}{}
+	}
+	comparison := make([]MetricComparison, 0, len(names))
+	for name := range names {
+		comparison = append(comparison, MetricComparison{
+			Name:      name,
+			Control:   control[name],
+			Treatment: treatment[name],
+		})
+	}
+	sort.Slice(comparison, func(i, j int) bool {
+		return comparison[i].Name < comparison[j].Name
+	})
+	return comparison
+}
+
+// String returns a human readable table of the report
+func (r ExperimentReport) String() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "flag %s=%s\n", r.FlagName, r.FlagValue)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tcontrol\ttreatment")
+	fmt.Fprintf(w, "workload duration\t%s\t%s\n", r.Control.WorkloadDuration, r.Treatment.WorkloadDuration)
+	for _, c := range r.Comparison {
+		fmt.Fprintf(w, "%s\t%g\t%g\n", c.Name, c.Control, c.Treatment)
+	}
+	_ = w.Flush()
+	return buf.String()
+}
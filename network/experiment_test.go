@@ -0,0 +1,93 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a mock network whose metrics grow by [accepted] accepted
+// blocks on each node between scrapes
+func newExperimentNetwork(t *testing.T, accepted float64) *mocks.Network {
+	net := mocks.NewNetwork(t)
+	net.On("Healthy", mock.Anything).Return(nil)
+	net.On("Stop", mock.Anything).Return(nil)
+	net.On("ScrapeMetrics", mock.Anything).Return(map[string]network.Metrics{
+		"node1": {"accepted": 10, "peers": 4},
+		"node2": {"accepted": 10, "peers": 4},
+	}, nil).Once()
+	net.On("ScrapeMetrics", mock.Anything).Return(map[string]network.Metrics{
+		"node1": {"accepted": 10 + accepted, "peers": 4},
+		"node2": {"accepted": 10 + accepted, "peers": 4},
+	}, nil).Once()
+	return net
+}
+
+func TestRunExperiment(t *testing.T) {
+	require := require.New(t)
+
+	config := network.Config{
+		Flags: map[string]interface{}{"log-level": "info"},
+		NodeConfigs: []node.Config{
+			{Name: "node1", Flags: map[string]interface{}{"snow-sample-size": 10}},
+			{Name: "node2"},
+		},
+	}
+	configs := []network.Config{}
+	nets := []*mocks.Network{newExperimentNetwork(t, 2), newExperimentNetwork(t, 5)}
+	workloads := 0
+	report, err := network.RunExperiment(context.Background(), network.ExperimentSpec{
+		Config:    config,
+		FlagName:  "snow-sample-size",
+		FlagValue: 20,
+		NewNetwork: func(_ context.Context, config network.Config) (network.Network, error) {
+			configs = append(configs, config)
+			return nets[len(configs)-1], nil
+		},
+		Workload: func(context.Context, network.Network) error {
+			workloads++
+			return nil
+		},
+	})
+	require.NoError(err)
+	require.Equal(2, workloads)
+
+	// control config is unchanged
+	require.Equal(config, configs[0])
+	require.Nil(config.Flags["snow-sample-size"])
+	require.Equal(10, config.NodeConfigs[0].Flags["snow-sample-size"])
+	// treatment config has the flag changed on all nodes
+	require.Equal(20, configs[1].Flags["snow-sample-size"])
+	require.Equal(20, configs[1].NodeConfigs[0].Flags["snow-sample-size"])
+	require.Nil(configs[1].NodeConfigs[1].Flags)
+
+	require.Equal("snow-sample-size", report.FlagName)
+	require.Equal("20", report.FlagValue)
+	require.Equal([]network.MetricComparison{{Name: "accepted", Control: 4, Treatment: 10}}, report.Comparison)
+	require.True(strings.HasPrefix(report.String(), "flag snow-sample-size=20\n"))
+
+	// workload failure
+	_, err = network.RunExperiment(context.Background(), network.ExperimentSpec{
+		Config:    config,
+		FlagName:  "snow-sample-size",
+		FlagValue: 20,
+		NewNetwork: func(context.Context, network.Config) (network.Network, error) {
+			net := mocks.NewNetwork(t)
+			net.On("Healthy", mock.Anything).Return(nil)
+			net.On("Stop", mock.Anything).Return(nil)
+			net.On("ScrapeMetrics", mock.Anything).Return(map[string]network.Metrics{}, nil)
+			return net, nil
+		},
+		Workload: func(context.Context, network.Network) error {
+			return errors.New("workload error")
+		},
+	})
+	require.ErrorContains(err, "control network: workload failed")
+}
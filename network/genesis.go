@@ -2,16 +2,27 @@ package network
 
 import (
 	_ "embed"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
 	coreth_params "github.com/ava-labs/coreth/params"
 )
 
+// time from the genesis start time at which the locked genesis
+// allocations are unlocked
+const genesisLocktimeStartTimeDelta = 2836800 * time.Second
+
 //go:embed default/genesis.json
 var genesisBytes []byte
 
-// LoadLocalGenesis loads the local network genesis from disk
+// LoadLocalGenesis loads the local network genesis, embedded in the binary,
 // and returns it as a map[string]interface{}
 func LoadLocalGenesis() (map[string]interface{}, error) {
 	var (
@@ -45,3 +56,99 @@ func LoadLocalGenesis() (map[string]interface{}, error) {
 	genesisMap["cChainGenesis"] = string(configBytes)
 	return genesisMap, nil
 }
+
+// NewDefaultGenesis returns a genesis JSON for [networkID] based on the local
+// network genesis, so it keeps its funded keys (eg the ewoq key) and C-Chain
+// config, where:
+// The nodes in [validators] are the only genesis validators.
+// Each of [allocations] gets its balance unlocked on the X-Chain.
+// The start time is now, and the stake offsets of the validators are
+// shortened if needed, so any number of validators is accepted.
+// The genesis is validated as avalanchego does on startup.
+func NewDefaultGenesis(
+	networkID uint32,
+	validators []ids.NodeID,
+	allocations []AddrAndBalance,
+) ([]byte, error) {
+	switch networkID {
+	case constants.TestnetID, constants.MainnetID, constants.LocalID:
+		return nil, errors.New("network ID can't be mainnet, testnet or local network ID")
+	}
+	if len(validators) == 0 {
+		return nil, errors.New("no genesis validators provided")
+	}
+
+	genesisMap, err := LoadLocalGenesis()
+	if err != nil {
+		return nil, err
+	}
+	genesisMapBytes, err := json.Marshal(genesisMap)
+	if err != nil {
+		return nil, err
+	}
+	var config genesis.UnparsedConfig
+	if err := json.Unmarshal(genesisMapBytes, &config); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal local genesis: %w", err)
+	}
+	if len(config.InitialStakers) == 0 {
+		return nil, errors.New("local genesis has no validators")
+	}
+
+	startTime := time.Now()
+	lockTime := uint64(startTime.Add(genesisLocktimeStartTimeDelta).Unix())
+	config.NetworkID = networkID
+	config.StartTime = uint64(startTime.Unix())
+	for i := range config.Allocations {
+		for j := range config.Allocations[i].UnlockSchedule {
+			if config.Allocations[i].UnlockSchedule[j].Locktime != 0 {
+				config.Allocations[i].UnlockSchedule[j].Locktime = lockTime
+			}
+		}
+	}
+
+	hrp := constants.GetHRP(networkID)
+	for _, allocation := range allocations {
+		if allocation.Balance == nil || !allocation.Balance.IsUint64() {
+			return nil, fmt.Errorf("invalid balance for genesis allocation %s", allocation.Addr)
+		}
+		avaxAddr, err := address.Format("X", hrp, allocation.Addr[:])
+		if err != nil {
+			return nil, err
+		}
+		config.Allocations = append(config.Allocations, genesis.UnparsedAllocation{
+			ETHAddr:       "0x0000000000000000000000000000000000000000",
+			AVAXAddr:      avaxAddr,
+			InitialAmount: allocation.Balance.Uint64(),
+		})
+	}
+
+	// the validators stake the funds, and get the rewards, of the local
+	// genesis validators
+	refStaker := config.InitialStakers[0]
+	config.InitialStakers = make([]genesis.UnparsedStaker, 0, len(validators))
+	for _, nodeID := range validators {
+		config.InitialStakers = append(config.InitialStakers, genesis.UnparsedStaker{
+			NodeID:        nodeID,
+			RewardAddress: refStaker.RewardAddress,
+			DelegationFee: refStaker.DelegationFee,
+		})
+	}
+	// the stake of each validator ends an offset after the previous one,
+	// and all of them must end within the initial stake duration
+	if numOffsets := uint64(len(validators) - 1); numOffsets > 0 &&
+		config.InitialStakeDurationOffset*numOffsets > config.InitialStakeDuration {
+		config.InitialStakeDurationOffset = config.InitialStakeDuration / numOffsets
+	}
+
+	genesisBytes, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	stakingConfig := genesis.GetStakingConfig(networkID)
+	// genesis content given by flag is base64 encoded
+	genesisContent := base64.StdEncoding.EncodeToString(genesisBytes)
+	if _, _, err := genesis.FromFlag(networkID, genesisContent, &stakingConfig); err != nil {
+		return nil, fmt.Errorf("invalid genesis: %w", err)
+	}
+	return genesisBytes, nil
+}
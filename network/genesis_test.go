@@ -0,0 +1,55 @@
+package network_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefaultGenesis(t *testing.T) {
+	require := require.New(t)
+
+	// more validators than the stake offsets of the local genesis allow
+	validators := make([]ids.NodeID, 10_000)
+	for i := range validators {
+		validators[i] = ids.GenerateTestNodeID()
+	}
+	addr := ids.GenerateTestShortID()
+	genesisBytes, err := network.NewDefaultGenesis(1338, validators, []network.AddrAndBalance{
+		{Addr: addr, Balance: big.NewInt(1_000)},
+	})
+	require.NoError(err)
+
+	networkID, err := utils.NetworkIDFromGenesis(genesisBytes)
+	require.NoError(err)
+	require.Equal(uint32(1338), networkID)
+
+	var unparsedConfig genesis.UnparsedConfig
+	require.NoError(json.Unmarshal(genesisBytes, &unparsedConfig))
+	config, err := unparsedConfig.Parse()
+	require.NoError(err)
+	require.Len(config.InitialStakers, len(validators))
+	for i, staker := range config.InitialStakers {
+		require.Equal(validators[i], staker.NodeID)
+	}
+	found := false
+	for _, allocation := range config.Allocations {
+		if allocation.AVAXAddr == addr {
+			require.Equal(uint64(1_000), allocation.InitialAmount)
+			found = true
+		}
+	}
+	require.True(found)
+	require.NotEmpty(config.CChainGenesis)
+
+	_, err = network.NewDefaultGenesis(1338, nil, nil)
+	require.Error(err)
+	_, err = network.NewDefaultGenesis(12345, validators[:1], nil)
+	require.Error(err)
+}
@@ -0,0 +1,27 @@
+package network
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// NodeHistory is the record kept of a node process that is no longer
+// running, either because it was stopped or because it crashed
+type NodeHistory struct {
+	Name   string     `json:"name"`
+	NodeID ids.NodeID `json:"nodeID"`
+	// Time the node process was started
+	StartTime time.Time `json:"startTime"`
+	// Time the node process exited
+	StopTime time.Time `json:"stopTime"`
+	// True if the process exited without being asked to
+	Crashed  bool `json:"crashed"`
+	ExitCode int  `json:"exitCode"`
+	// True if the process crashed during a chaos window affecting the node
+	Expected bool `json:"expected,omitempty"`
+	// Name of the signal that terminated the process, if any
+	Signal string `json:"signal,omitempty"`
+	// Last lines of the node main log
+	LastLogLines []string `json:"lastLogLines,omitempty"`
+}
@@ -0,0 +1,86 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/indexer"
+)
+
+// How often WaitAccepted asks the index for the container
+const DefaultAcceptedPollInterval = 500 * time.Millisecond
+
+var ErrNoObservers = errors.New("network has no running observer nodes")
+
+// GetObservers returns the observer nodes of [net] that are not paused,
+// by node name, or ErrNoObservers if there are none.
+// The indexes of an observer are given by its API client, eg
+// node.GetAPIClient().XChainTxIndexAPI().
+func GetObservers(net Network) (map[string]node.Node, error) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	observers := map[string]node.Node{}
+	for nodeName, node := range nodes {
+		if node.GetConfig().Observer && !node.GetPaused() {
+			observers[nodeName] = node
+		}
+	}
+	if len(observers) == 0 {
+		return nil, ErrNoObservers
+	}
+	return observers, nil
+}
+
+// WaitAccepted waits until the container (tx, vertex or block)
+// [containerID] is accepted on [index], or [ctx] is done
+func WaitAccepted(ctx context.Context, index indexer.Client, containerID ids.ID) error {
+	ticker := time.NewTicker(DefaultAcceptedPollInterval)
+	defer ticker.Stop()
+	for {
+		accepted, err := index.IsAccepted(ctx, containerID)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			return fmt.Errorf("couldn't check if %s is accepted: %w", containerID, err)
+		case accepted:
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not accepted: %w", containerID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetAccepted returns the containers accepted on [index], in acceptance
+// order, starting with the one of index [startIndex]. Returns an empty
+// list if [startIndex] is past the last accepted container.
+func GetAccepted(ctx context.Context, index indexer.Client, startIndex uint64) ([]indexer.Container, error) {
+	_, lastIndex, err := index.GetLastAccepted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get last accepted container: %w", err)
+	}
+	containers := []indexer.Container{}
+	for startIndex <= lastIndex {
+		numToFetch := lastIndex - startIndex + 1
+		if numToFetch > indexer.MaxFetchedByRange {
+			numToFetch = indexer.MaxFetchedByRange
+		}
+		fetched, err := index.GetContainerRange(ctx, startIndex, int(numToFetch))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get containers from index %d: %w", startIndex, err)
+		}
+		if len(fetched) == 0 {
+			break
+		}
+		containers = append(containers, fetched...)
+		startIndex += uint64(len(fetched))
+	}
+	return containers, nil
+}
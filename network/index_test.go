@@ -0,0 +1,110 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	nodemocks "github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/indexer"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceIndexClient is an index client over a list of accepted containers,
+// that only implements GetLastAccepted, GetContainerRange and IsAccepted
+type sliceIndexClient struct {
+	indexer.Client
+
+	containers []indexer.Container
+	// number of IsAccepted calls before the containers are seen accepted
+	acceptedAfter int
+}
+
+func (c *sliceIndexClient) GetLastAccepted(context.Context, ...rpc.Option) (indexer.Container, uint64, error) {
+	last := len(c.containers) - 1
+	return c.containers[last], uint64(last), nil
+}
+
+func (c *sliceIndexClient) GetContainerRange(_ context.Context, startIndex uint64, numToFetch int, _ ...rpc.Option) ([]indexer.Container, error) {
+	if numToFetch > indexer.MaxFetchedByRange {
+		numToFetch = indexer.MaxFetchedByRange
+	}
+	end := startIndex + uint64(numToFetch)
+	if end > uint64(len(c.containers)) {
+		end = uint64(len(c.containers))
+	}
+	return c.containers[startIndex:end], nil
+}
+
+func (c *sliceIndexClient) IsAccepted(_ context.Context, containerID ids.ID, _ ...rpc.Option) (bool, error) {
+	if c.acceptedAfter > 0 {
+		c.acceptedAfter--
+		return false, nil
+	}
+	for _, container := range c.containers {
+		if container.ID == containerID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestGetObservers(t *testing.T) {
+	require := require.New(t)
+
+	observer := nodemocks.NewNode(t)
+	observer.On("GetConfig").Return(node.Config{Observer: true})
+	observer.On("GetPaused").Return(false)
+	pausedObserver := nodemocks.NewNode(t)
+	pausedObserver.On("GetConfig").Return(node.Config{Observer: true})
+	pausedObserver.On("GetPaused").Return(true)
+	validator := nodemocks.NewNode(t)
+	validator.On("GetConfig").Return(node.Config{})
+	net := mocks.NewNetwork(t)
+	net.On("GetAllNodes").Return(map[string]node.Node{
+		"observer":        observer,
+		"paused-observer": pausedObserver,
+		"validator":       validator,
+	}, nil).Once()
+	observers, err := network.GetObservers(net)
+	require.NoError(err)
+	require.Equal(map[string]node.Node{"observer": observer}, observers)
+
+	net.On("GetAllNodes").Return(map[string]node.Node{"validator": validator}, nil).Once()
+	_, err = network.GetObservers(net)
+	require.ErrorIs(err, network.ErrNoObservers)
+}
+
+func TestGetAccepted(t *testing.T) {
+	require := require.New(t)
+
+	index := &sliceIndexClient{}
+	for i := 0; i < indexer.MaxFetchedByRange+10; i++ {
+		index.containers = append(index.containers, indexer.Container{ID: ids.GenerateTestID()})
+	}
+	// fetched over more than one range
+	containers, err := network.GetAccepted(context.Background(), index, 5)
+	require.NoError(err)
+	require.Equal(index.containers[5:], containers)
+	containers, err = network.GetAccepted(context.Background(), index, uint64(len(index.containers)))
+	require.NoError(err)
+	require.Empty(containers)
+}
+
+func TestWaitAccepted(t *testing.T) {
+	require := require.New(t)
+
+	accepted := indexer.Container{ID: ids.GenerateTestID()}
+	index := &sliceIndexClient{containers: []indexer.Container{accepted}, acceptedAfter: 1}
+	require.NoError(network.WaitAccepted(context.Background(), index, accepted.ID))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := network.WaitAccepted(ctx, index, ids.GenerateTestID())
+	require.ErrorIs(err, context.DeadlineExceeded)
+}
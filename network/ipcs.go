@@ -0,0 +1,103 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/ipcs"
+	"github.com/ava-labs/avalanchego/ipcs/socket"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+)
+
+// name of the IPC socket on which avalanchego publishes the containers
+// accepted by consensus
+const ipcConsensusSocketSuffix = "consensus"
+
+// AcceptedContainer is a container (eg a block) accepted by a node
+type AcceptedContainer struct {
+	// SHA256 of [Bytes]. It is the container ID for P-Chain and X-Chain
+	// containers, and for the blocks of chains wrapped by proposervm once
+	// it is active. Otherwise (eg C-Chain pre-fork blocks) the ID is up
+	// to the VM.
+	ID    ids.ID
+	Bytes []byte
+}
+
+// IPCFlags returns the node flags that make avalanchego publish the
+// containers accepted on [chainIDs] over IPC sockets at dir [ipcsPath],
+// to be consumed with SubscribeAcceptedContainers.
+// Note that unix socket paths are limited to about 100 chars, so
+// [ipcsPath] should be short (eg a subdir of /tmp).
+func IPCFlags(ipcsPath string, chainIDs ...ids.ID) map[string]interface{} {
+	chainIDStrs := make([]string, len(chainIDs))
+	for i, chainID := range chainIDs {
+		chainIDStrs[i] = chainID.String()
+	}
+	return map[string]interface{}{
+		config.IpcsPathKey:     ipcsPath,
+		config.IpcsChainIDsKey: strings.Join(chainIDStrs, ","),
+	}
+}
+
+// SubscribeAcceptedContainers returns a channel of the containers accepted
+// on chain [chainID] by node [n], in acceptance order, read from the
+// consensus IPC socket the node publishes (see IPCFlags).
+// Only the containers accepted after subscribing are received.
+// The channel is closed once [ctx] is done, or if the node closes the socket
+// (eg on node stop).
+func SubscribeAcceptedContainers(
+	ctx context.Context,
+	n node.Node,
+	networkID uint32,
+	chainID ids.ID,
+) (<-chan AcceptedContainer, error) {
+	ipcsPath, err := n.GetFlag(config.IpcsPathKey)
+	if err != nil {
+		return nil, err
+	}
+	if ipcsPath == "" {
+		ipcsPath = ipcs.DefaultBaseURL
+	}
+	// as named by avalanchego
+	socketPath := filepath.Join(ipcsPath, fmt.Sprintf("%d-%s-%s", networkID, chainID, ipcConsensusSocketSuffix))
+	client, err := socket.Dial(socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't connect to IPC socket of chain %s of node %s: %w", chainID, n.GetName(), err)
+	}
+
+	containersCh := make(chan AcceptedContainer)
+	doneCh := make(chan struct{})
+	go func() {
+		// unblocks Recv
+		select {
+		case <-ctx.Done():
+		case <-doneCh:
+		}
+		_ = client.Close()
+	}()
+	go func() {
+		defer close(containersCh)
+		defer close(doneCh)
+		for {
+			containerBytes, err := client.Recv()
+			if err != nil {
+				return
+			}
+			container := AcceptedContainer{
+				ID:    hashing.ComputeHash256Array(containerBytes),
+				Bytes: containerBytes,
+			}
+			select {
+			case containersCh <- container:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return containersCh, nil
+}
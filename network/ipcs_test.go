@@ -0,0 +1,88 @@
+package network_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/ipcs/socket"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPCFlags(t *testing.T) {
+	require := require.New(t)
+
+	chainID1, chainID2 := ids.GenerateTestID(), ids.GenerateTestID()
+	flags := network.IPCFlags("/tmp/ipcs", chainID1, chainID2)
+	require.Equal("/tmp/ipcs", flags[config.IpcsPathKey])
+	require.Equal(chainID1.String()+","+chainID2.String(), flags[config.IpcsChainIDsKey])
+}
+
+func TestSubscribeAcceptedContainers(t *testing.T) {
+	require := require.New(t)
+
+	// unix socket paths must be short
+	ipcsPath, err := os.MkdirTemp("", "ipcs")
+	require.NoError(err)
+	defer os.RemoveAll(ipcsPath)
+
+	networkID := uint32(1337)
+	chainID := ids.GenerateTestID()
+	// plays the node side of the IPC
+	s := socket.NewSocket(filepath.Join(ipcsPath, fmt.Sprintf("%d-%s-consensus", networkID, chainID)), logging.NoLog{})
+	require.NoError(s.Listen())
+
+	n := mocks.NewNode(t)
+	n.On("GetFlag", config.IpcsPathKey).Return(ipcsPath, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	containersCh, err := network.SubscribeAcceptedContainers(ctx, n, networkID, chainID)
+	require.NoError(err)
+
+	containers := [][]byte{[]byte("block1"), []byte("block2"), []byte("block3")}
+	// the socket accepts the connection asynchronously, so containers
+	// are sent until the first one is received
+	var received network.AcceptedContainer
+	require.Eventually(func() bool {
+		s.Send(containers[0])
+		select {
+		case received = <-containersCh:
+			return true
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, time.Millisecond)
+	require.Equal(containers[0], received.Bytes)
+	require.Equal(ids.ID(hashing.ComputeHash256Array(containers[0])), received.ID)
+	// drain duplicates of the first container
+	for _, container := range containers[1:] {
+		s.Send(container)
+	}
+	for _, container := range containers[1:] {
+		for {
+			received = <-containersCh
+			if string(received.Bytes) != string(containers[0]) {
+				break
+			}
+		}
+		require.Equal(container, received.Bytes)
+	}
+
+	// the channel is closed when the node closes the socket
+	require.NoError(s.Close())
+	for {
+		if _, ok := <-containersCh; !ok {
+			break
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LinkConditions degrade the traffic sent from a node to another
+type LinkConditions struct {
+	// Added to each packet
+	Latency time.Duration `json:"latency,omitempty"`
+	// Random variation of [Latency], up to this much either way
+	Jitter time.Duration `json:"jitter,omitempty"`
+	// Percentage (0 to 100) of packets dropped
+	Loss float64 `json:"loss,omitempty"`
+}
+
+// IsZero returns true if the conditions don't degrade the traffic
+func (c LinkConditions) IsZero() bool {
+	return c == LinkConditions{}
+}
+
+// Validate returns an error if the conditions are invalid
+func (c LinkConditions) Validate() error {
+	switch {
+	case c.Latency < 0:
+		return errors.New("negative link latency")
+	case c.Jitter < 0:
+		return errors.New("negative link jitter")
+	case c.Jitter > 0 && c.Latency == 0:
+		return errors.New("link jitter without latency")
+	case c.Loss < 0 || c.Loss > 100:
+		return fmt.Errorf("link loss %v is not a percentage", c.Loss)
+	}
+	return nil
+}
+
+// LinkSpec gives the conditions of the traffic sent by node [From] to
+// node [To]. The traffic in the opposite direction is not affected.
+type LinkSpec struct {
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Conditions LinkConditions `json:"conditions"`
+}
+
+// Validate returns an error if the spec is invalid
+func (s LinkSpec) Validate() error {
+	if s.From == "" || s.To == "" {
+		return errors.New("link without node names")
+	}
+	if s.From == s.To {
+		return fmt.Errorf("link from node %q to itself", s.From)
+	}
+	return s.Conditions.Validate()
+}
@@ -0,0 +1,31 @@
+package network_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkSpecValidate(t *testing.T) {
+	require := require.New(t)
+
+	conditions := network.LinkConditions{Latency: 50 * time.Millisecond, Jitter: 5 * time.Millisecond, Loss: 1.5}
+	require.NoError(network.LinkSpec{From: "node1", To: "node2", Conditions: conditions}.Validate())
+	// zero conditions restore the link
+	require.NoError(network.LinkSpec{From: "node1", To: "node2"}.Validate())
+	require.True(network.LinkConditions{}.IsZero())
+
+	require.Error(network.LinkSpec{From: "node1", Conditions: conditions}.Validate())
+	require.Error(network.LinkSpec{From: "node1", To: "node1", Conditions: conditions}.Validate())
+	for _, invalid := range []network.LinkConditions{
+		{Latency: -time.Second},
+		{Jitter: time.Millisecond},
+		{Latency: time.Second, Jitter: -time.Millisecond},
+		{Loss: -1},
+		{Loss: 100.5},
+	} {
+		require.Error(network.LinkSpec{From: "node1", To: "node2", Conditions: invalid}.Validate())
+	}
+}
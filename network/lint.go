@@ -0,0 +1,149 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/shirou/gopsutil/mem"
+)
+
+const (
+	// rough estimate of the memory used by a local node
+	lintNodeMemoryEstimate = 512 << 20
+	// small networks (eg the default one) can have all nodes as beacons
+	lintMaxAllBeacons = 5
+)
+
+// LintWarning is a config setting that is valid, but suspicious
+type LintWarning struct {
+	// Name (or index, if unnamed) of the node the warning refers to.
+	// Empty for network wide warnings.
+	NodeName string `json:"nodeName,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (w LintWarning) String() string {
+	if w.NodeName == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("node %q: %s", w.NodeName, w.Message)
+}
+
+// LintResult is the result of linting a network config
+type LintResult struct {
+	// Validation error. Empty if the config is valid.
+	Error    string        `json:"error,omitempty"`
+	Warnings []LintWarning `json:"warnings"`
+}
+
+// LintConfig validates [c], and looks for suspicious settings that are
+// not validation errors: all nodes of a big network being beacons, beacons
+// not fitting on the first startup wave, more
+// nodes than the host memory can likely hold, network IDs differing from
+// the genesis one, and ports used by several nodes
+func LintConfig(c *Config) LintResult {
+	result := LintResult{
+		Warnings: []LintWarning{},
+	}
+	if err := c.Validate(); err != nil {
+		result.Error = err.Error()
+	}
+	warn := func(nodeName string, format string, args ...interface{}) {
+		result.Warnings = append(result.Warnings, LintWarning{
+			NodeName: nodeName,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	numBeacons := 0
+	for _, nodeConfig := range c.NodeConfigs {
+		if nodeConfig.IsBeacon {
+			numBeacons++
+		}
+	}
+	if numBeacons > lintMaxAllBeacons && numBeacons == len(c.NodeConfigs) {
+		warn("", "all %d nodes are beacons, which slows down bootstrap as the network grows", numBeacons)
+	}
+	if c.StartupWaves != nil && c.StartupWaves.WaveSize > 0 && numBeacons > c.StartupWaves.WaveSize {
+		warn("", "%d beacons don't fit on the first startup wave of %d nodes, so it may never become healthy",
+			numBeacons, c.StartupWaves.WaveSize)
+	}
+
+	if vmStat, err := mem.VirtualMemory(); err == nil {
+		if required := uint64(len(c.NodeConfigs)) * lintNodeMemoryEstimate; required > vmStat.Total {
+			warn("", "%d nodes may need about %d MiB of memory, but the host has %d MiB",
+				len(c.NodeConfigs), required>>20, vmStat.Total>>20)
+		}
+	}
+
+	genesisNetworkID, err := utils.NetworkIDFromGenesis([]byte(c.Genesis))
+	if err == nil {
+		networkID := genesisNetworkID
+		if c.NetworkID != 0 && c.NetworkID != genesisNetworkID {
+			warn("", "network ID %d differs from genesis network ID %d, the genesis is rewritten",
+				c.NetworkID, genesisNetworkID)
+			networkID = c.NetworkID
+		}
+		if flagNetworkID, ok := lintFlag(c.Flags, nil, config.NetworkNameKey); ok && flagNetworkID != strconv.Itoa(int(networkID)) {
+			warn("", "flag %q %s differs from network ID %d", config.NetworkNameKey, flagNetworkID, networkID)
+		}
+		for i, nodeConfig := range c.NodeConfigs {
+			if flagNetworkID, ok := lintFlag(nodeConfig.Flags, nil, config.NetworkNameKey); ok && flagNetworkID != strconv.Itoa(int(networkID)) {
+				warn(lintNodeName(i, nodeConfig), "flag %q %s differs from network ID %d", config.NetworkNameKey, flagNetworkID, networkID)
+			}
+		}
+	}
+
+	// port --> names of the nodes using it
+	portUsers := map[string][]string{}
+	for i, nodeConfig := range c.NodeConfigs {
+		var configFile map[string]interface{}
+		_ = json.Unmarshal([]byte(nodeConfig.ConfigFile), &configFile)
+		for _, portKey := range []string{config.HTTPPortKey, config.StakingPortKey} {
+			port, ok := lintFlag(nodeConfig.Flags, configFile, portKey)
+			if !ok {
+				port, ok = lintFlag(c.Flags, configFile, portKey)
+			}
+			if ok && port != "0" {
+				portUsers[port] = append(portUsers[port], lintNodeName(i, nodeConfig))
+			}
+		}
+	}
+	ports := make([]string, 0, len(portUsers))
+	for port := range portUsers {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		if nodeNames := portUsers[port]; len(nodeNames) > 1 {
+			warn("", "port %s used by several nodes %v", port, nodeNames)
+		}
+	}
+	return result
+}
+
+// Returns the value of flag [key] on [flags], or else on [configFile],
+// as a string
+func lintFlag(flags map[string]interface{}, configFile map[string]interface{}, key string) (string, bool) {
+	value, ok := flags[key]
+	if !ok {
+		value, ok = configFile[key]
+	}
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// Returns the name used on lint warnings for the node at index [i]
+func lintNodeName(i int, nodeConfig node.Config) string {
+	if nodeConfig.Name != "" {
+		return nodeConfig.Name
+	}
+	return strconv.Itoa(i)
+}
@@ -0,0 +1,74 @@
+package network_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintConfig(t *testing.T) {
+	require := require.New(t)
+	config := &network.Config{
+		Genesis:   `{"networkID": 1337}`,
+		NetworkID: 1338,
+		Flags:     map[string]interface{}{"network-id": 1338},
+		StartupWaves: &network.StartupWavesConfig{
+			WaveSize: 4,
+		},
+		NodeConfigs: []node.Config{
+			{
+				Name:        "node1",
+				IsBeacon:    true,
+				StakingKey:  "key1",
+				StakingCert: "cert1",
+				Flags:       map[string]interface{}{"http-port": 9650},
+			},
+			{
+				Name:        "node2",
+				IsBeacon:    true,
+				StakingKey:  "key2",
+				StakingCert: "cert2",
+				ConfigFile:  `{"http-port": 9650}`,
+				Flags:       map[string]interface{}{"network-id": 1},
+			},
+		},
+	}
+	for i := 3; i <= 6; i++ {
+		config.NodeConfigs = append(config.NodeConfigs, node.Config{
+			Name:        fmt.Sprintf("node%d", i),
+			IsBeacon:    true,
+			StakingKey:  "key",
+			StakingCert: "cert",
+		})
+	}
+	result := network.LintConfig(config)
+	require.Empty(result.Error)
+	// the memory warning depends on the host
+	warnings := []network.LintWarning{}
+	for _, warning := range result.Warnings {
+		if !strings.Contains(warning.Message, "of memory") {
+			warnings = append(warnings, warning)
+		}
+	}
+	require.Equal([]network.LintWarning{
+		{Message: "all 6 nodes are beacons, which slows down bootstrap as the network grows"},
+		{Message: "6 beacons don't fit on the first startup wave of 4 nodes, so it may never become healthy"},
+		{Message: "network ID 1338 differs from genesis network ID 1337, the genesis is rewritten"},
+		{NodeName: "node2", Message: `flag "network-id" 1 differs from network ID 1338`},
+		{Message: "port 9650 used by several nodes [node1 node2]"},
+	}, warnings)
+
+	// too many nodes for the host memory, and validation errors
+	config = &network.Config{
+		Genesis:     `{"networkID": 1337}`,
+		NodeConfigs: make([]node.Config, 100_000),
+	}
+	result = network.LintConfig(config)
+	require.Contains(result.Error, "beacon nodes not given")
+	require.Len(result.Warnings, 1)
+	require.Contains(result.Warnings[0].Message, "100000 nodes may need about")
+}
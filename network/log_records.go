@@ -0,0 +1,132 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"golang.org/x/exp/slices"
+)
+
+// Keys of the JSON log records output by avalanchego with log-format=json
+const (
+	logRecordTimeKey    = "timestamp"
+	logRecordLevelKey   = "level"
+	logRecordModuleKey  = "logger"
+	logRecordCallerKey  = "caller"
+	logRecordMessageKey = "msg"
+)
+
+// Layouts of the times of the JSON log records, as encoded by
+// zapcore.ISO8601TimeEncoder, or else by RFC3339
+var logRecordTimeLayouts = []string{
+	"2006-01-02T15:04:05.000Z0700",
+	time.RFC3339Nano,
+}
+
+// LogRecord is a log record output by a node with JSON logging
+// (log-format=json)
+type LogRecord struct {
+	NodeName string        `json:"nodeName"`
+	Time     time.Time     `json:"time"`
+	Level    logging.Level `json:"level"`
+	// Logger the record was logged with, eg "C Chain".
+	// Empty for the main logger of the node.
+	Module string `json:"module,omitempty"`
+	// Source file and line the record was logged at
+	Caller  string `json:"caller,omitempty"`
+	Message string `json:"message"`
+	// Remaining fields of the record, as decoded by encoding/json
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// ParseLogRecord returns the JSON log record of line [line] output by node
+// [nodeName], or false if the line is not a JSON log record, eg because
+// the node doesn't log in JSON.
+func ParseLogRecord(nodeName string, line string) (LogRecord, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return LogRecord{}, false
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return LogRecord{}, false
+	}
+	levelStr, ok := fields[logRecordLevelKey].(string)
+	if !ok {
+		return LogRecord{}, false
+	}
+	level, err := logging.ToLevel(levelStr)
+	if err != nil {
+		return LogRecord{}, false
+	}
+	message, ok := fields[logRecordMessageKey].(string)
+	if !ok {
+		return LogRecord{}, false
+	}
+	record := LogRecord{
+		NodeName: nodeName,
+		Level:    level,
+		Message:  message,
+	}
+	if timeStr, ok := fields[logRecordTimeKey].(string); ok {
+		for _, layout := range logRecordTimeLayouts {
+			if t, err := time.Parse(layout, timeStr); err == nil {
+				record.Time = t
+				break
+			}
+		}
+	}
+	record.Module, _ = fields[logRecordModuleKey].(string)
+	record.Caller, _ = fields[logRecordCallerKey].(string)
+	for _, key := range []string{logRecordTimeKey, logRecordLevelKey, logRecordModuleKey, logRecordCallerKey, logRecordMessageKey} {
+		delete(fields, key)
+	}
+	if len(fields) != 0 {
+		record.Fields = fields
+	}
+	return record, true
+}
+
+// LogFilter selects log records.
+// Empty fields select all the records.
+type LogFilter struct {
+	// Names of the nodes whose records are selected
+	NodeNames []string `json:"nodeNames,omitempty"`
+	// Lowest level of the records selected, eg "warn"
+	MinLevel string `json:"minLevel,omitempty"`
+	// Modules of the records selected, eg "C Chain".
+	// The empty module selects the records of the main logger.
+	Modules []string `json:"modules,omitempty"`
+}
+
+// Validate returns an error if the min level is not a log level
+func (f LogFilter) Validate() error {
+	if f.MinLevel == "" {
+		return nil
+	}
+	if _, err := logging.ToLevel(f.MinLevel); err != nil {
+		return fmt.Errorf("invalid min level %q: %w", f.MinLevel, err)
+	}
+	return nil
+}
+
+// Matches returns true if [record] is selected by the filter.
+// Assumes the filter is valid.
+func (f LogFilter) Matches(record LogRecord) bool {
+	if len(f.NodeNames) != 0 && !slices.Contains(f.NodeNames, record.NodeName) {
+		return false
+	}
+	if f.MinLevel != "" {
+		minLevel, _ := logging.ToLevel(f.MinLevel)
+		if record.Level < minLevel {
+			return false
+		}
+	}
+	if len(f.Modules) != 0 && !slices.Contains(f.Modules, record.Module) {
+		return false
+	}
+	return true
+}
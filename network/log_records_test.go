@@ -0,0 +1,67 @@
+package network_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogRecord(t *testing.T) {
+	require := require.New(t)
+
+	record, ok := network.ParseLogRecord("node1", `{"level":"warn","timestamp":"2023-10-30T10:00:01.500+0100","logger":"P Chain","caller":"snowman/transitive.go:392","msg":"dropping message","nodeID":"NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg","retries":2}`)
+	require.True(ok)
+	require.True(time.Date(2023, 10, 30, 9, 0, 1, 500_000_000, time.UTC).Equal(record.Time))
+	record.Time = time.Time{}
+	require.Equal(network.LogRecord{
+		NodeName: "node1",
+		Level:    logging.Warn,
+		Module:   "P Chain",
+		Caller:   "snowman/transitive.go:392",
+		Message:  "dropping message",
+		Fields: map[string]interface{}{
+			"nodeID":  "NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg",
+			"retries": float64(2),
+		},
+	}, record)
+
+	// main logger
+	record, ok = network.ParseLogRecord("node1", `{"level":"info","timestamp":"2023-10-30T10:00:01.000Z","msg":"initializing node"}`)
+	require.True(ok)
+	require.Empty(record.Module)
+	require.Nil(record.Fields)
+
+	for _, line := range []string{
+		"",
+		"[10-30|10:00:01.000] INFO node/node.go:1 initializing node",
+		`{"level":"info"`,
+		`{"timestamp":"2023-10-30T10:00:01.000Z","msg":"no level"}`,
+		`{"level":"loud","msg":"unknown level"}`,
+		`{"level":"info","timestamp":"2023-10-30T10:00:01.000Z"}`,
+	} {
+		_, ok := network.ParseLogRecord("node1", line)
+		require.False(ok, line)
+	}
+}
+
+func TestLogFilter(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError(network.LogFilter{}.Validate())
+	require.NoError(network.LogFilter{MinLevel: "WARN"}.Validate())
+	require.ErrorContains(network.LogFilter{MinLevel: "loud"}.Validate(), `invalid min level "loud"`)
+
+	record := network.LogRecord{NodeName: "node1", Level: logging.Warn, Module: "C Chain"}
+	require.True(network.LogFilter{}.Matches(record))
+	require.True(network.LogFilter{NodeNames: []string{"node0", "node1"}}.Matches(record))
+	require.False(network.LogFilter{NodeNames: []string{"node0"}}.Matches(record))
+	require.True(network.LogFilter{MinLevel: "warn"}.Matches(record))
+	require.True(network.LogFilter{MinLevel: "info"}.Matches(record))
+	require.False(network.LogFilter{MinLevel: "error"}.Matches(record))
+	require.True(network.LogFilter{Modules: []string{"C Chain"}}.Matches(record))
+	require.False(network.LogFilter{Modules: []string{""}}.Matches(record))
+	require.False(network.LogFilter{NodeNames: []string{"node1"}, MinLevel: "warn", Modules: []string{"X Chain"}}.Matches(record))
+}
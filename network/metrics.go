@@ -0,0 +1,167 @@
+package network
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Metrics holds the samples exposed by a node's Prometheus endpoint.
+// Keys are sample names, followed by their labels sorted by label name
+// (i.e. `avalanche_network_peers` or `avalanche_X_blks_accepted_count`,
+// `avalanche_network_msgs{op="ping"}`).
+type Metrics map[string]float64
+
+// ParseMetrics parses metrics given in the Prometheus text exposition format.
+// Summaries and histograms are flattened into their `_sum`, `_count`,
+// quantile and bucket samples.
+func ParseMetrics(r io.Reader) (Metrics, error) {
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse metrics: %w", err)
+	}
+	metrics := Metrics{}
+	for name, family := range families {
+		for _, m := range family.GetMetric() {
+			labels := m.GetLabel()
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				metrics[metricKey(name, labels)] = m.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				metrics[metricKey(name, labels)] = m.GetGauge().GetValue()
+			case dto.MetricType_UNTYPED:
+				metrics[metricKey(name, labels)] = m.GetUntyped().GetValue()
+			case dto.MetricType_SUMMARY:
+				summary := m.GetSummary()
+				metrics[metricKey(name+"_sum", labels)] = summary.GetSampleSum()
+				metrics[metricKey(name+"_count", labels)] = float64(summary.GetSampleCount())
+				for _, q := range summary.GetQuantile() {
+					key := metricKey(name, labels, "quantile", fmt.Sprint(q.GetQuantile()))
+					metrics[key] = q.GetValue()
+				}
+			case dto.MetricType_HISTOGRAM:
+				histogram := m.GetHistogram()
+				metrics[metricKey(name+"_sum", labels)] = histogram.GetSampleSum()
+				metrics[metricKey(name+"_count", labels)] = float64(histogram.GetSampleCount())
+				for _, b := range histogram.GetBucket() {
+					key := metricKey(name+"_bucket", labels, "le", fmt.Sprint(b.GetUpperBound()))
+					metrics[key] = float64(b.GetCumulativeCount())
+				}
+			}
+		}
+	}
+	return metrics, nil
+}
+
+// Returns the Metrics key for a sample with the given name and labels.
+// [extraLabel] is an optional name/value pair added to [labels].
+func metricKey(name string, labels []*dto.LabelPair, extraLabel ...string) string {
+	pairs := make([]string, 0, len(labels)+1)
+	for _, label := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", label.GetName(), label.GetValue()))
+	}
+	if len(extraLabel) == 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", extraLabel[0], extraLabel[1]))
+	}
+	if len(pairs) == 0 {
+		return name
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// DiffMetrics returns, for each node of [after], the samples whose value
+// changed from [before] to [after], mapped to the delta (after - before).
+// Samples missing in either scrape are considered to be 0 there, so nodes
+// only in [after] (i.e. started in between) get all their samples, and
+// samples that vanished get their [before] value negated. Nodes only in
+// [before] are excluded.
+// Node name --> sample key --> delta.
+func DiffMetrics(before, after map[string]Metrics) map[string]Metrics {
+	diff := map[string]Metrics{}
+	for nodeName, afterMetrics := range after {
+		beforeMetrics := before[nodeName]
+		nodeDiff := Metrics{}
+		for key, afterValue := range afterMetrics {
+			if delta := afterValue - beforeMetrics[key]; delta != 0 {
+				nodeDiff[key] = delta
+			}
+		}
+		for key, beforeValue := range beforeMetrics {
+			if _, ok := afterMetrics[key]; !ok && beforeValue != 0 {
+				nodeDiff[key] = -beforeValue
+			}
+		}
+		diff[nodeName] = nodeDiff
+	}
+	return diff
+}
+
+// LabelMetrics merges the metrics of the nodes of a network, as returned
+// by ScrapeMetrics, into a single set of samples, adding to each sample
+// the labels MetricsNetworkLabel, with [networkName], and MetricsNodeLabel,
+// with the node name. Samples already having those labels keep them.
+func LabelMetrics(networkName string, metrics map[string]Metrics) Metrics {
+	labelled := Metrics{}
+	for nodeName, nodeMetrics := range metrics {
+		for key, value := range nodeMetrics {
+			labelled[addMetricKeyLabels(key, map[string]string{
+				MetricsNetworkLabel: networkName,
+				MetricsNodeLabel:    nodeName,
+			})] = value
+		}
+	}
+	return labelled
+}
+
+// Returns the Metrics key [key] with the given labels added,
+// keeping the ones already present.
+func addMetricKeyLabels(key string, labels map[string]string) string {
+	name, labelsStr, _ := strings.Cut(key, "{")
+	labelsStr = strings.TrimSuffix(labelsStr, "}")
+	pairs := splitMetricKeyLabels(labelsStr)
+	present := map[string]bool{}
+	for _, pair := range pairs {
+		labelName, _, _ := strings.Cut(pair, "=")
+		present[labelName] = true
+	}
+	for labelName, labelValue := range labels {
+		if !present[labelName] {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", labelName, labelValue))
+		}
+	}
+	if len(pairs) == 0 {
+		return name
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Splits the labels of a Metrics key into name=value pairs.
+// Values are quoted, and may contain commas.
+func splitMetricKeyLabels(labelsStr string) []string {
+	pairs := []string{}
+	start, inValue, escaped := 0, false, false
+	for i, c := range labelsStr {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inValue:
+			escaped = true
+		case c == '"':
+			inValue = !inValue
+		case c == ',' && !inValue:
+			pairs = append(pairs, labelsStr[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(labelsStr) {
+		pairs = append(pairs, labelsStr[start:])
+	}
+	return pairs
+}
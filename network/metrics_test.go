@@ -0,0 +1,159 @@
+package network_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testMetrics = `# HELP avalanche_network_peers Number of network peers
+# TYPE avalanche_network_peers gauge
+avalanche_network_peers 4
+# HELP avalanche_network_msgs Number of messages
+# TYPE avalanche_network_msgs counter
+avalanche_network_msgs{op="ping",io="sent"} 10
+# HELP avalanche_handle_time Time handling messages
+# TYPE avalanche_handle_time histogram
+avalanche_handle_time_bucket{le="1"} 2
+avalanche_handle_time_bucket{le="+Inf"} 3
+avalanche_handle_time_sum 4.5
+avalanche_handle_time_count 3
+`
+
+func TestParseMetrics(t *testing.T) {
+	require := require.New(t)
+	metrics, err := network.ParseMetrics(strings.NewReader(testMetrics))
+	require.NoError(err)
+	require.Equal(network.Metrics{
+		"avalanche_network_peers":                     4,
+		`avalanche_network_msgs{io="sent",op="ping"}`: 10,
+		`avalanche_handle_time_bucket{le="1"}`:        2,
+		`avalanche_handle_time_bucket{le="+Inf"}`:     3,
+		"avalanche_handle_time_sum":                   4.5,
+		"avalanche_handle_time_count":                 3,
+	}, metrics)
+
+	_, err = network.ParseMetrics(strings.NewReader("not metrics"))
+	require.Error(err)
+}
+
+func TestDiffMetrics(t *testing.T) {
+	before := map[string]network.Metrics{
+		"node1": {"a": 1, "b": 2, "d": 4, "e": 0},
+		"node2": {"a": 1},
+	}
+	after := map[string]network.Metrics{
+		"node1": {"a": 1, "b": 5, "c": 1},
+		"node3": {"a": 1, "b": 0},
+	}
+	require.Equal(t, map[string]network.Metrics{
+		// vanished samples are negated
+		"node1": {"b": 3, "c": 1, "d": -4},
+		// nodes only in after get all their samples
+		"node3": {"a": 1},
+	}, network.DiffMetrics(before, after))
+}
+
+func TestLabelMetrics(t *testing.T) {
+	metrics := map[string]network.Metrics{
+		"node1": {
+			"avalanche_network_peers":                       4,
+			`avalanche_network_msgs{io="sent",op="a,b=c"}`:  10,
+			`avalanche_network_msgs{node="other",op="get"}`: 1,
+		},
+		"node2": {"avalanche_network_peers": 3},
+	}
+	require.Equal(t, network.Metrics{
+		`avalanche_network_peers{network="net1",node="node1"}`:                     4,
+		`avalanche_network_msgs{io="sent",network="net1",node="node1",op="a,b=c"}`: 10,
+		`avalanche_network_msgs{network="net1",node="other",op="get"}`:             1,
+		`avalanche_network_peers{network="net1",node="node2"}`:                     3,
+	}, network.LabelMetrics("net1", metrics))
+}
+
+func TestPrometheusConfigs(t *testing.T) {
+	require := require.New(t)
+	targets := []network.ScrapeTarget{
+		{NodeName: "node1", Address: "127.0.0.1:9650"},
+		{NodeName: "node2", Address: "127.0.0.1:9652"},
+	}
+	scrapeConfig, err := network.PrometheusScrapeConfig("avalanchego-net1", "net1", targets)
+	require.NoError(err)
+	require.Equal(`- job_name: avalanchego-net1
+  metrics_path: /ext/metrics
+  static_configs:
+    - targets:
+        - 127.0.0.1:9650
+      labels:
+        network: net1
+        node: node1
+    - targets:
+        - 127.0.0.1:9652
+      labels:
+        network: net1
+        node: node2
+`, scrapeConfig)
+
+	relabelConfigs, err := network.PrometheusRelabelConfigs("net1", targets[:1])
+	require.NoError(err)
+	require.Equal(`- source_labels:
+    - __address__
+  regex: 127\.0\.0\.1:9650
+  target_label: network
+  replacement: net1
+- source_labels:
+    - __address__
+  regex: 127\.0\.0\.1:9650
+  target_label: node
+  replacement: node1
+`, relabelConfigs)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	require := require.New(t)
+	node1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal("/ext/metrics", r.URL.Path)
+		_, _ = io.WriteString(w, `# HELP avalanche_network_peers Number of network peers
+# TYPE avalanche_network_peers gauge
+avalanche_network_peers 4
+`)
+	}))
+	defer node1.Close()
+	node2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `# HELP avalanche_network_peers Number of network peers
+# TYPE avalanche_network_peers gauge
+avalanche_network_peers{node="custom"} 3
+`)
+	}))
+	defer node2.Close()
+	net := mocks.NewNetwork(t)
+	net.On("MetricsTargets", mock.Anything).Return([]network.ScrapeTarget{
+		{NodeName: "node1", Address: strings.TrimPrefix(node1.URL, "http://")},
+		network.NewScrapeTarget("node2", node2.URL),
+		// not reachable
+		{NodeName: "node3", Address: "127.0.0.1:1"},
+	}, nil)
+
+	server := httptest.NewServer(network.MetricsHandler(net, "net1"))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	require.NoError(err)
+	defer resp.Body.Close()
+	require.Equal(http.StatusOK, resp.StatusCode)
+	metrics, err := network.ParseMetrics(resp.Body)
+	require.NoError(err)
+	require.Equal(network.Metrics{
+		`anr_node_up{network="net1",node="node1"}`:              1,
+		`anr_node_up{network="net1",node="node2"}`:              1,
+		`anr_node_up{network="net1",node="node3"}`:              0,
+		`avalanche_network_peers{network="net1",node="node1"}`:  4,
+		`avalanche_network_peers{network="net1",node="custom"}`: 3,
+	}, metrics)
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanchego/config"
+)
+
+// CurrentConfigVersion is the version of the serialized network.Config format
+// produced by this version of the runner. It must be bumped, and a migration
+// added to [configMigrations], every time a breaking change is made to
+// the network.Config or node.Config structs.
+const CurrentConfigVersion uint32 = 1
+
+const (
+	configVersionKey                = "version"
+	configFlagsKey                  = "flags"
+	configNodeConfigsKey            = "nodeConfigs"
+	deprecatedBuildDirKey           = "build-dir"
+	deprecatedWhitelistedSubnetsKey = "whitelisted-subnets"
+)
+
+// A configMigration upgrades a generic representation of a serialized
+// network config from version N to version N+1, in place.
+type configMigration func(map[string]interface{}) error
+
+// Version N --> migration from version N to version N+1
+var configMigrations = map[uint32]configMigration{
+	0: migrateConfigV0,
+}
+
+// LoadConfig unmarshals the serialized network config [configJSON],
+// upgrading it to [CurrentConfigVersion] if it was written by an older
+// version of the runner.
+func LoadConfig(configJSON []byte) (Config, error) {
+	migratedJSON, err := MigrateConfig(configJSON)
+	if err != nil {
+		return Config{}, err
+	}
+	networkConfig := Config{}
+	if err := json.Unmarshal(migratedJSON, &networkConfig); err != nil {
+		return Config{}, fmt.Errorf("couldn't unmarshal network config: %w", err)
+	}
+	return networkConfig, nil
+}
+
+// MigrateConfig upgrades the serialized network config [configJSON] to
+// [CurrentConfigVersion]. Configs with no version are considered to be
+// version 0. Returns an error if the config was written by a newer version
+// of the runner.
+func MigrateConfig(configJSON []byte) ([]byte, error) {
+	var configMap map[string]interface{}
+	if err := json.Unmarshal(configJSON, &configMap); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal network config: %w", err)
+	}
+	version := uint32(0)
+	if versionIntf, ok := configMap[configVersionKey]; ok {
+		versionFloat, ok := versionIntf.(float64)
+		if !ok {
+			return nil, fmt.Errorf("wrong type for field %q in network config expected float64 got %T", configVersionKey, versionIntf)
+		}
+		version = uint32(versionFloat)
+	}
+	if version > CurrentConfigVersion {
+		return nil, fmt.Errorf("network config version %d is newer than supported version %d", version, CurrentConfigVersion)
+	}
+	if version == CurrentConfigVersion {
+		return configJSON, nil
+	}
+	for ; version < CurrentConfigVersion; version++ {
+		migration, ok := configMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration defined for network config version %d", version)
+		}
+		if err := migration(configMap); err != nil {
+			return nil, fmt.Errorf("couldn't migrate network config from version %d: %w", version, err)
+		}
+	}
+	configMap[configVersionKey] = CurrentConfigVersion
+	return json.Marshal(configMap)
+}
+
+// Version 0 configs, generated by older runner versions, may contain avalanchego
+// flags that have since been renamed.
+func migrateConfigV0(configMap map[string]interface{}) error {
+	if err := migrateFlagsV0(configMap); err != nil {
+		return err
+	}
+	nodeConfigsIntf, ok := configMap[configNodeConfigsKey]
+	if !ok || nodeConfigsIntf == nil {
+		return nil
+	}
+	nodeConfigs, ok := nodeConfigsIntf.([]interface{})
+	if !ok {
+		return fmt.Errorf("wrong type for field %q expected []interface{} got %T", configNodeConfigsKey, nodeConfigsIntf)
+	}
+	for _, nodeConfigIntf := range nodeConfigs {
+		nodeConfig, ok := nodeConfigIntf.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("wrong type for node config expected map[string]interface{} got %T", nodeConfigIntf)
+		}
+		if err := migrateFlagsV0(nodeConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Renames the deprecated flags found on the "flags" entry of [configMap].
+func migrateFlagsV0(configMap map[string]interface{}) error {
+	flagsIntf, ok := configMap[configFlagsKey]
+	if !ok || flagsIntf == nil {
+		return nil
+	}
+	flags, ok := flagsIntf.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("wrong type for field %q expected map[string]interface{} got %T", configFlagsKey, flagsIntf)
+	}
+	if vIntf, ok := flags[deprecatedWhitelistedSubnetsKey]; ok {
+		v, ok := vIntf.(string)
+		if !ok {
+			return fmt.Errorf("expected %q to be of type string but got %T", deprecatedWhitelistedSubnetsKey, vIntf)
+		}
+		if v != "" {
+			flags[config.TrackSubnetsKey] = v
+		}
+		delete(flags, deprecatedWhitelistedSubnetsKey)
+	}
+	if vIntf, ok := flags[deprecatedBuildDirKey]; ok {
+		v, ok := vIntf.(string)
+		if !ok {
+			return fmt.Errorf("expected %q to be of type string but got %T", deprecatedBuildDirKey, vIntf)
+		}
+		if v != "" {
+			flags[config.PluginDirKey] = filepath.Join(v, "plugins")
+		}
+		delete(flags, deprecatedBuildDirKey)
+	}
+	return nil
+}
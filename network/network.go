@@ -3,6 +3,7 @@ package network
 import (
 	"context"
 	"errors"
+	"io"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/network/node"
@@ -10,9 +11,12 @@ import (
 )
 
 var (
-	ErrUndefined    = errors.New("undefined network")
-	ErrStopped      = errors.New("network stopped")
-	ErrNodeNotFound = errors.New("node not found in network")
+	ErrUndefined     = errors.New("undefined network")
+	ErrStopped       = errors.New("network stopped")
+	ErrNodeNotFound  = errors.New("node not found in network")
+	ErrLeakDetected  = errors.New("resource leak detected")
+	ErrNodeOpTimeout = errors.New("node operation timed out")
+	ErrPartialStart  = errors.New("network partially started")
 )
 
 type PermissionlessStakerSpec struct {
@@ -70,26 +74,63 @@ type Network interface {
 	GetNetworkID() (uint32, error)
 	// Returns nil if all the nodes in the network are healthy.
 	// A stopped network is considered unhealthy.
-	// Timeout is given by the context parameter.
+	// Timeout is given by the context parameter. The poll interval and
+	// per node timeout are given by Config.HealthPollInterval and
+	// Config.NodeHealthTimeout.
 	Healthy(context.Context) error
+	// Returns nil once the node with this name is healthy, as Healthy
+	// does for all the nodes, eg to find which node blocks the network
+	// health. Paused nodes are not healthy.
+	// Returns ErrStopped if Stop() was previously called.
+	NodeHealthy(ctx context.Context, name string) error
+	// Returns the state of each node, asking the running ones once for
+	// their health.
+	// Node name --> state.
+	// Returns ErrStopped if Stop() was previously called.
+	Status(context.Context) (map[string]NodeState, error)
 	// Stop all the nodes.
 	// Returns ErrStopped if Stop() was previously called.
+	// If leak checking is enabled in the network config, returns an error
+	// wrapping ErrLeakDetected if resources were not released.
 	Stop(context.Context) error
 	// Start a new node with the given config.
+	// [ctx] bounds the node start, eg the download of its binary version
+	// or the wait for a start slot, but not the node once started.
 	// Returns ErrStopped if Stop() was previously called.
-	AddNode(node.Config) (node.Node, error)
+	AddNode(context.Context, node.Config) (node.Node, error)
 	// Stop the node with this name.
+	// Returns a *QuorumError, without stopping the node, if the healthy
+	// validators would be left with less than MinHealthyValidatorWeight of
+	// the validator weight.
 	// Returns ErrStopped if Stop() was previously called.
 	RemoveNode(ctx context.Context, name string) error
+	// Stop the node with this name, as RemoveNode does, even if the
+	// network loses its validator quorum.
+	// Returns ErrStopped if Stop() was previously called.
+	ForceRemoveNode(ctx context.Context, name string) error
+	// Kill the process of the node with this name (SIGKILL) to simulate
+	// a crash, and remove the node from the network. Unlike RemoveNode,
+	// the node is not asked to stop. Its data dir is kept, so adding back
+	// a node with the same config (see node.Node.GetConfig) recovers it.
+	// Returns ErrStopped if Stop() was previously called.
+	KillNode(ctx context.Context, name string) error
 	// Pause the node with this name.
 	// Returns ErrStopped if Stop() was previously called.
 	PauseNode(ctx context.Context, name string) error
 	// Resume the node with this name.
 	// Returns ErrStopped if Stop() was previously called.
 	ResumeNode(ctx context.Context, name string) error
+	// Freeze the process of the node with this name (SIGSTOP), so it
+	// stalls without losing its state or TCP connections.
+	// Unlike PauseNode, the process is kept, and so is the node status.
+	// Returns ErrStopped if Stop() was previously called.
+	FreezeNode(ctx context.Context, name string) error
+	// Resume the process of the node with this name, frozen by FreezeNode.
+	// Returns ErrStopped if Stop() was previously called.
+	UnfreezeNode(ctx context.Context, name string) error
 	// Return the node with this name.
 	// Returns ErrStopped if Stop() was previously called.
-	GetNode(name string) (node.Node, error)
+	GetNode(ctx context.Context, name string) (node.Node, error)
 	// Return all the nodes in this network.
 	// Node name --> Node.
 	// Returns ErrStopped if Stop() was previously called.
@@ -102,13 +143,40 @@ type Network interface {
 	// Returns the full local path to the snapshot dir
 	SaveSnapshot(context.Context, string) (string, error)
 	// Remove network snapshot
-	RemoveSnapshot(string) error
+	RemoveSnapshot(context.Context, string) error
 	// Get name of available snapshots
-	GetSnapshotNames() ([]string, error)
+	GetSnapshotNames(context.Context) ([]string, error)
+	// Get info of available snapshots, newest first
+	GetSnapshotsInfo(context.Context) ([]SnapshotInfo, error)
+	// Remove the snapshots that don't satisfy the given retention policy.
+	// Returns the names of the removed snapshots.
+	PruneSnapshots(context.Context, SnapshotRetentionPolicy) ([]string, error)
+	// Compare two snapshots of the network, eg to decide whether a
+	// snapshot is still usable after an avalanchego upgrade
+	DiffSnapshots(ctx context.Context, from string, to string) (SnapshotDiff, error)
 	// Restart a given node using the same config, optionally changing binary path, plugin dir,
 	// track subnets, a map of chain configs, a map of upgrade configs, and
-	// a map of subnet configs
+	// a map of subnet configs.
+	// The node is stopped gracefully and started again with the same data dir,
+	// database, logs dir and ports, so it keeps its state. Empty values keep
+	// the current ones, eg RestartNode(ctx, name, "", "", "", nil, nil, nil)
+	// just restarts the node.
 	RestartNode(context.Context, string, string, string, string, map[string]string, map[string]string, map[string]string) error
+	// Restart the node with this name on the avalanchego binary at
+	// [binaryPath], keeping its state as RestartNode does, and wait for
+	// the node to be healthy. See RollingUpgrade to upgrade all the nodes.
+	// Returns ErrStopped if Stop() was previously called.
+	UpgradeNode(ctx context.Context, name string, binaryPath string) error
+	// Set the log level (eg "debug") of the node with this name, through
+	// its admin API if enabled (api-admin-enabled), or else by restarting
+	// it as RestartNode does. The level is kept across restarts.
+	// Paused nodes get the level when resumed.
+	// Returns ErrStopped if Stop() was previously called.
+	SetLogLevel(ctx context.Context, nodeName string, level string) error
+	// Set the log level of all the nodes, as SetLogLevel does, and of the
+	// nodes added later, unless given their own.
+	// Returns ErrStopped if Stop() was previously called.
+	SetNetworkLogLevel(ctx context.Context, level string) error
 	// Create the specified blockchains
 	CreateBlockchains(context.Context, []BlockchainSpec) ([]ids.ID, error)
 	// Create the given numbers of subnets
@@ -125,4 +193,83 @@ type Network interface {
 	AddSubnetValidators(context.Context, []SubnetValidatorsSpec) error
 	// Get the elastic subnet tx id for the given subnet id
 	GetElasticSubnetID(context.Context, ids.ID) (ids.ID, error)
+	// Scrape the Prometheus metrics of all the running nodes.
+	// Node name --> metrics.
+	// See DiffMetrics for comparing two scrapes.
+	ScrapeMetrics(context.Context) (map[string]Metrics, error)
+	// Returns the metrics endpoints of all the running nodes, sorted by node
+	// name, eg to give them to PrometheusScrapeConfig or MetricsHandler.
+	// Returns ErrStopped if Stop() was previously called.
+	MetricsTargets(context.Context) ([]ScrapeTarget, error)
+	// Wait until the primary network validator set, as seen by all the running nodes,
+	// is equal to the given node IDs.
+	// Timeout is given by the context parameter.
+	// Returns ErrStopped if Stop() was previously called.
+	AwaitValidatorSet(context.Context, []ids.NodeID) error
+	// Returns a new subscription to the network events.
+	// Events are buffered, and dropped for subscribers that don't keep up.
+	// The channel is closed when the network is stopped.
+	Events() <-chan Event
+	// Writes the lines output by all the nodes (stdout and stderr) to [w],
+	// in the order they are output, each prefixed with "[nodeName] ".
+	// Lines are buffered, and dropped if [w] doesn't keep up.
+	// Blocks until [ctx] is done, returning its error, or the network is
+	// stopped, returning nil.
+	// Only output by node processes launched by the runner is streamed.
+	StreamLogs(ctx context.Context, w io.Writer) error
+	// Returns a new subscription to the JSON log records output by the
+	// nodes (see ParseLogRecord) selected by [filter], in the order they
+	// are output. Lines that are not JSON log records are skipped.
+	// Records are buffered, and dropped for subscribers that don't keep up.
+	// The channel is closed when [ctx] is done or the network is stopped.
+	// Returns ErrStopped if Stop() was previously called.
+	LogRecords(ctx context.Context, filter LogFilter) (<-chan LogRecord, error)
+	// Returns the records of the stopped or crashed processes of the node
+	// with this name, oldest first.
+	// Available also after Stop() is called.
+	GetNodeHistory(name string) ([]NodeHistory, error)
+	// Returns how the nodes were stopped by Stop().
+	// Returns ErrNotStopped if Stop() was not called yet.
+	GetStopResult() (StopResult, error)
+	// Returns the name, description and tags given on network creation
+	GetMetadata() Metadata
+	// Returns the paths of the directories and files created for the network.
+	// Available also after Stop() is called.
+	GetArtifactPaths() ArtifactPaths
+	// Starts the nodes not started because of a failure on network creation,
+	// when Config.KeepPartialStart is set. Does nothing if there are none.
+	// Returns an error wrapping ErrPartialStart if a node fails to start again,
+	// so it can be retried.
+	// Returns ErrStopped if Stop() was previously called.
+	ResumeCreate(context.Context) error
+	// Declares a chaos window, replacing the active one if any.
+	// Unhealthy events and crashes of the affected nodes are reported
+	// as expected until the window ends.
+	// Returns ErrStopped if Stop() was previously called.
+	BeginChaos(context.Context, ChaosWindow) error
+	// Ends the active chaos window, if any.
+	// Returns ErrStopped if Stop() was previously called.
+	EndChaos(context.Context) error
+	// Compacts the databases of all the nodes, to shrink the disk usage of
+	// long-lived networks. Running nodes are restarted one at a time, and
+	// the network is healthy again when it returns.
+	// Returns the sizes of the compacted databases.
+	// Returns ErrStopped if Stop() was previously called.
+	CompactDatabases(context.Context) ([]DBCompaction, error)
+	// Sets the latency and packet loss of the traffic sent by node [from]
+	// to node [to], replacing the previous ones. Zero conditions restore
+	// the link. Conditions are kept across node restarts.
+	// Returns ErrStopped if Stop() was previously called.
+	SetLinkConditions(ctx context.Context, from string, to string, conditions LinkConditions) error
+	// Makes the nodes with these names the bootstrap beacons of the network,
+	// replacing the previous ones. Nodes started from now on bootstrap from
+	// them, while running nodes keep their bootstraps until refreshed.
+	// Returns ErrStopped if Stop() was previously called.
+	SetBeacons(ctx context.Context, names []string) error
+	// Restarts, one at a time, the running nodes whose bootstrap IPs and
+	// IDs are not the current beacons (eg after a beacon is removed), so
+	// they don't depend on gone beacons when restarted later.
+	// Returns the names of the restarted nodes.
+	// Returns ErrStopped if Stop() was previously called.
+	RefreshBootstraps(context.Context) ([]string, error)
 }
@@ -0,0 +1,348 @@
+package node
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+)
+
+// ConfigBuilder builds a Config, validating each value as it's given.
+// The first error found is returned by Build, and the values
+// given after it are ignored.
+type ConfigBuilder struct {
+	config Config
+	// network ID of the genesis given by WithGenesis, if any
+	networkID *uint32
+	err       error
+}
+
+// NewConfigBuilder returns a builder for a node Config
+func NewConfigBuilder() *ConfigBuilder {
+	return &ConfigBuilder{
+		config: Config{
+			Flags: map[string]interface{}{},
+		},
+	}
+}
+
+// Keeps the first error found
+func (b *ConfigBuilder) fail(format string, args ...interface{}) *ConfigBuilder {
+	if b.err == nil {
+		b.err = fmt.Errorf(format, args...)
+	}
+	return b
+}
+
+// WithName sets the node name
+func (b *ConfigBuilder) WithName(name string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if name == "" {
+		return b.fail("empty node name")
+	}
+	b.config.Name = name
+	return b
+}
+
+// WithBinary sets the avalanchego binary of the node, that must exist
+func (b *ConfigBuilder) WithBinary(binaryPath string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := utils.CheckExecPath(binaryPath); err != nil {
+		return b.fail("invalid binary %q: %w", binaryPath, err)
+	}
+	b.config.BinaryPath = binaryPath
+	return b
+}
+
+// WithGenesis sets the genesis of the network the node is for,
+// used to verify the network ID of the node config file
+func (b *ConfigBuilder) WithGenesis(genesis []byte) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	networkID, err := utils.NetworkIDFromGenesis(genesis)
+	if err != nil {
+		return b.fail("invalid genesis: %w", err)
+	}
+	b.networkID = &networkID
+	return b
+}
+
+// WithStakingKeyAndCert sets the PEM encoded staking key and cert of the node
+func (b *ConfigBuilder) WithStakingKeyAndCert(stakingKey, stakingCert []byte) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if _, err := staking.LoadTLSCertFromBytes(stakingKey, stakingCert); err != nil {
+		return b.fail("invalid staking key/cert: %w", err)
+	}
+	b.config.StakingKey = string(stakingKey)
+	b.config.StakingCert = string(stakingCert)
+	return b
+}
+
+// WithStakingSigningKey sets the BLS signing key of the node
+func (b *ConfigBuilder) WithStakingSigningKey(signingKey []byte) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if _, err := bls.SecretKeyFromBytes(signingKey); err != nil {
+		return b.fail("invalid staking signing key: %w", err)
+	}
+	b.config.StakingSigningKey = base64.StdEncoding.EncodeToString(signingKey)
+	return b
+}
+
+// WithNewStakingIdentity sets a newly generated staking key, cert
+// and signing key
+func (b *ConfigBuilder) WithNewStakingIdentity() *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	stakingKey, stakingCert, _, err := utils.NewStakingIdentity()
+	if err != nil {
+		return b.fail("%w", err)
+	}
+	signingKey, err := bls.NewSecretKey()
+	if err != nil {
+		return b.fail("couldn't generate staking signing key: %w", err)
+	}
+	b.config.StakingKey = string(stakingKey)
+	b.config.StakingCert = string(stakingCert)
+	b.config.StakingSigningKey = base64.StdEncoding.EncodeToString(bls.SecretKeyToBytes(signingKey))
+	return b
+}
+
+// WithConfigFile sets the contents of the node config file,
+// that must be a JSON object
+func (b *ConfigBuilder) WithConfigFile(configFile string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	var configMap map[string]interface{}
+	if err := json.Unmarshal([]byte(configFile), &configMap); err != nil {
+		return b.fail("invalid config file: %w", err)
+	}
+	b.config.ConfigFile = configFile
+	return b
+}
+
+// WithFlag sets a node flag
+func (b *ConfigBuilder) WithFlag(key string, value interface{}) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if key == "" {
+		return b.fail("empty flag name")
+	}
+	b.config.Flags[key] = value
+	return b
+}
+
+// WithChainConfigFile sets the config file of the chain [chainAlias]
+func (b *ConfigBuilder) WithChainConfigFile(chainAlias string, chainConfig string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if chainAlias == "" {
+		return b.fail("empty chain alias for chain config file")
+	}
+	if b.config.ChainConfigFiles == nil {
+		b.config.ChainConfigFiles = map[string]string{}
+	}
+	b.config.ChainConfigFiles[chainAlias] = chainConfig
+	return b
+}
+
+// WithUpgradeConfigFile sets the upgrade file of the chain [chainAlias]
+func (b *ConfigBuilder) WithUpgradeConfigFile(chainAlias string, upgradeConfig string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if chainAlias == "" {
+		return b.fail("empty chain alias for upgrade config file")
+	}
+	if b.config.UpgradeConfigFiles == nil {
+		b.config.UpgradeConfigFiles = map[string]string{}
+	}
+	b.config.UpgradeConfigFiles[chainAlias] = upgradeConfig
+	return b
+}
+
+// WithSubnetConfigFile sets the config file of the subnet [subnetID]
+func (b *ConfigBuilder) WithSubnetConfigFile(subnetID string, subnetConfig string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if subnetID == "" {
+		return b.fail("empty subnet ID for subnet config file")
+	}
+	if b.config.SubnetConfigFiles == nil {
+		b.config.SubnetConfigFiles = map[string]string{}
+	}
+	b.config.SubnetConfigFiles[subnetID] = subnetConfig
+	return b
+}
+
+// WithFile adds an extra file written into the node data dir before start.
+// See Config.Files.
+func (b *ConfigBuilder) WithFile(pathTemplate string, contents string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if pathTemplate == "" {
+		return b.fail("empty extra file path")
+	}
+	if b.config.Files == nil {
+		b.config.Files = map[string]string{}
+	}
+	b.config.Files[pathTemplate] = contents
+	return b
+}
+
+// WithVMPlugin adds a VM binary installed into the node plugin dir before
+// start. [vm] is the VM name or ID, also known to the node by [aliases].
+// See Config.VMPlugins.
+func (b *ConfigBuilder) WithVMPlugin(path string, vm string, aliases ...string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := utils.CheckExecPath(path); err != nil {
+		return b.fail("invalid VM plugin %q: %w", path, err)
+	}
+	plugin := VMPlugin{Path: path, VM: vm, Aliases: aliases}
+	if _, err := plugin.VMID(); err != nil || vm == "" {
+		return b.fail("invalid VM %q of plugin %q", vm, path)
+	}
+	plugins := append(append([]VMPlugin{}, b.config.VMPlugins...), plugin)
+	if err := ValidateVMPlugins(plugins); err != nil {
+		return b.fail("invalid VM plugin %q: %w", path, err)
+	}
+	b.config.VMPlugins = plugins
+	return b
+}
+
+// WithTTL makes the node ephemeral, removed once [ttl] passes since its start
+func (b *ConfigBuilder) WithTTL(ttl time.Duration) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if ttl <= 0 {
+		return b.fail("non positive node TTL %s", ttl)
+	}
+	b.config.TTL = ttl
+	return b
+}
+
+// WithEnv sets an environment variable of the node process
+func (b *ConfigBuilder) WithEnv(key string, value string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if key == "" {
+		return b.fail("empty env variable name")
+	}
+	if b.config.Env == nil {
+		b.config.Env = map[string]string{}
+	}
+	b.config.Env[key] = value
+	return b
+}
+
+// WithResourcePreset sets the resource preset of the node
+func (b *ConfigBuilder) WithResourcePreset(preset ResourcePreset) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if _, err := preset.Settings(); err != nil {
+		return b.fail("invalid resource preset: %w", err)
+	}
+	b.config.ResourcePreset = preset
+	return b
+}
+
+// WithPriority sets the CPU and IO priority of the node process
+func (b *ConfigBuilder) WithPriority(priority ProcessPriority) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := priority.Validate(); err != nil {
+		return b.fail("invalid node priority: %w", err)
+	}
+	b.config.Priority = &priority
+	return b
+}
+
+// WithRestartPolicy sets how the node is restarted when its process exits
+// without being asked to
+func (b *ConfigBuilder) WithRestartPolicy(policy RestartPolicy) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := policy.Validate(); err != nil {
+		return b.fail("invalid node restart policy: %w", err)
+	}
+	b.config.RestartPolicy = &policy
+	return b
+}
+
+// WithOutputFile writes the node output to a file in its node dir,
+// rotated as given by [config]
+func (b *ConfigBuilder) WithOutputFile(config OutputFileConfig) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := config.Validate(); err != nil {
+		return b.fail("invalid node output file: %w", err)
+	}
+	b.config.OutputFile = &config
+	return b
+}
+
+// Beacon makes the node a bootstrap beacon for the other nodes
+func (b *ConfigBuilder) Beacon() *ConfigBuilder {
+	b.config.IsBeacon = true
+	return b
+}
+
+// Observer makes the node an observer, with the index API enabled and
+// the full C-chain history
+func (b *ConfigBuilder) Observer() *ConfigBuilder {
+	b.config.Observer = true
+	return b
+}
+
+// RedirectOutput directs the node stdout and stderr to the ones of
+// the runner
+func (b *ConfigBuilder) RedirectOutput() *ConfigBuilder {
+	b.config.RedirectStdout = true
+	b.config.RedirectStderr = true
+	return b
+}
+
+// APIAuth makes the node require API auth tokens
+func (b *ConfigBuilder) APIAuth() *ConfigBuilder {
+	b.config.APIAuth = true
+	return b
+}
+
+// Build returns the config, or the first error found
+func (b *ConfigBuilder) Build() (Config, error) {
+	if b.err != nil {
+		return Config{}, b.err
+	}
+	if b.networkID != nil {
+		if err := validateConfigFile([]byte(b.config.ConfigFile), *b.networkID); err != nil {
+			return Config{}, err
+		}
+	}
+	return b.config, nil
+}
@@ -0,0 +1,98 @@
+package node_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigBuilder(t *testing.T) {
+	require := require.New(t)
+	stakingCert, stakingKey, err := staking.NewCertAndKeyBytes()
+	require.NoError(err)
+	signingKey, err := bls.NewSecretKey()
+	require.NoError(err)
+	genesis := []byte(`{"networkID": 1337}`)
+
+	config, err := node.NewConfigBuilder().
+		WithName("node1").
+		WithGenesis(genesis).
+		WithStakingKeyAndCert(stakingKey, stakingCert).
+		WithStakingSigningKey(bls.SecretKeyToBytes(signingKey)).
+		WithConfigFile(`{"network-id": 1337}`).
+		WithFlag("log-level", "debug").
+		WithChainConfigFile("C", `{}`).
+		WithEnv("GOGC", "75").
+		WithResourcePreset(node.ResourcePresetSmall).
+		WithPriority(node.ProcessPriority{Nice: 10, IOClass: node.IOClassBestEffort, IOLevel: 7}).
+		Beacon().
+		Build()
+	require.NoError(err)
+	require.Equal("node1", config.Name)
+	require.True(config.IsBeacon)
+	require.Equal(string(stakingKey), config.StakingKey)
+	require.Equal(string(stakingCert), config.StakingCert)
+	require.Equal(base64.StdEncoding.EncodeToString(bls.SecretKeyToBytes(signingKey)), config.StakingSigningKey)
+	require.Equal("debug", config.Flags["log-level"])
+	require.Equal(map[string]string{"C": `{}`}, config.ChainConfigFiles)
+	require.Equal(map[string]string{"GOGC": "75"}, config.Env)
+	require.Equal(node.ResourcePresetSmall, config.ResourcePreset)
+	require.Equal(&node.ProcessPriority{Nice: 10, IOClass: node.IOClassBestEffort, IOLevel: 7}, config.Priority)
+
+	// the preset doesn't override the given env and flags
+	require.NoError(config.ApplyResourcePreset())
+	require.Equal("75", config.Env["GOGC"])
+	require.Equal("512MiB", config.Env["GOMEMLIMIT"])
+	require.Equal(1, config.Flags["consensus-app-concurrency"])
+	require.Equal("debug", config.Flags["log-level"])
+
+	config, err = node.NewConfigBuilder().WithNewStakingIdentity().Build()
+	require.NoError(err)
+	require.NotEmpty(config.StakingKey)
+	require.NotEmpty(config.StakingSigningKey)
+
+	// the first error is returned
+	_, err = node.NewConfigBuilder().
+		WithStakingKeyAndCert([]byte("bad key"), stakingCert).
+		WithName("").
+		Build()
+	require.ErrorContains(err, "invalid staking key/cert")
+
+	_, err = node.NewConfigBuilder().WithBinary("/not/existing").Build()
+	require.ErrorContains(err, "invalid binary")
+
+	_, err = node.NewConfigBuilder().WithVMPlugin("/not/existing", "subnetevm").Build()
+	require.ErrorContains(err, "invalid VM plugin")
+
+	_, err = node.NewConfigBuilder().WithResourcePreset("huge").Build()
+	require.ErrorContains(err, "unknown resource preset")
+
+	_, err = node.NewConfigBuilder().WithPriority(node.ProcessPriority{Nice: 20}).Build()
+	require.ErrorContains(err, "invalid node priority")
+	_, err = node.NewConfigBuilder().WithPriority(node.ProcessPriority{IOLevel: 3}).Build()
+	require.ErrorContains(err, "without IO class")
+
+	_, err = node.NewConfigBuilder().WithRestartPolicy(node.RestartPolicy{Mode: "sometimes"}).Build()
+	require.ErrorContains(err, "invalid node restart policy")
+
+	_, err = node.NewConfigBuilder().WithOutputFile(node.OutputFileConfig{MaxBackups: -1}).Build()
+	require.ErrorContains(err, "invalid node output file")
+
+	// the staking identity is generated on node creation if not given
+	config, err = node.NewConfigBuilder().Beacon().Build()
+	require.NoError(err)
+	require.Empty(config.StakingKey)
+	require.Empty(config.StakingCert)
+
+	// config file network ID must match the genesis one
+	_, err = node.NewConfigBuilder().
+		WithGenesis(genesis).
+		WithNewStakingIdentity().
+		WithConfigFile(`{"network-id": 1}`).
+		Build()
+	require.ErrorContains(err, "differs from genesis network id")
+}
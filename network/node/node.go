@@ -2,16 +2,22 @@ package node
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/api"
 	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanchego/config"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/peer"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"golang.org/x/mod/semver"
 )
 
 // Node represents an AvalancheGo node
@@ -29,6 +35,10 @@ type Node interface {
 	GetP2PPort() uint16
 	// Return this node's HTTP API port.
 	GetAPIPort() uint16
+	// Return the URI API clients reach this node at (e.g. http://127.0.0.1:9650),
+	// which is the one of its API proxy when the API needs auth tokens, TLS
+	// or a custom transport.
+	GetURI() string
 	// Starts a new test peer, connects it to the given node, and returns the peer.
 	// [handler] defines how the test peer handles messages it receives.
 	// The test peer can be used to send messages to the node it's attached to.
@@ -41,6 +51,9 @@ type Node interface {
 	Status() status.Status
 	// Return this node's avalanchego binary path
 	GetBinaryPath() string
+	// Return the avalanchego version of this node's binary,
+	// as given by its --version flag (eg "v1.10.15")
+	GetBinaryVersion() string
 	// Return this node's data dir
 	GetDataDir() string
 	// Return this node's db dir
@@ -57,6 +70,53 @@ type Node interface {
 	GetFlag(string) (string, error)
 	// Return this node's paused status
 	GetPaused() bool
+	// Return the paths of this node's output files, the current one first,
+	// then the rotated ones, newest first.
+	// Empty if its output is not written to a file.
+	GetOutputFilePaths() []string
+	// Return the addresses this node can be reached at, from the
+	// network where it runs and from the runner host
+	GetEndpoints() Endpoints
+	// Return the ports assigned to this node, and the ones requested
+	// by its config
+	GetPortAssignment() PortAssignment
+}
+
+// PortAssignment tells how the ports of a node were assigned.
+// Ports not given by the node config (its flags or config file) are
+// assigned free ones, and so are ports given but in use when the network
+// reassigns ports in use.
+type PortAssignment struct {
+	APIPort uint16 `json:"apiPort"`
+	P2PPort uint16 `json:"p2pPort"`
+	// Ports given by the node config, 0 if not given
+	RequestedAPIPort uint16 `json:"requestedAPIPort,omitempty"`
+	RequestedP2PPort uint16 `json:"requestedP2PPort,omitempty"`
+}
+
+// Reassigned returns true if a port given by the node config was in use,
+// and another one was assigned instead
+func (a PortAssignment) Reassigned() bool {
+	return (a.RequestedAPIPort != 0 && a.RequestedAPIPort != a.APIPort) ||
+		(a.RequestedP2PPort != 0 && a.RequestedP2PPort != a.P2PPort)
+}
+
+// Endpoint is an address a node can be reached at
+type Endpoint struct {
+	Host    string `json:"host"`
+	APIPort uint16 `json:"apiPort"`
+	P2PPort uint16 `json:"p2pPort"`
+}
+
+// Endpoints are the addresses of a node, as seen from different places.
+// Both are the same for local nodes, while for container based nodes
+// [Internal] is the container network address and [External] is the
+// host mapped one.
+type Endpoints struct {
+	// Address reachable from the network where the node runs
+	Internal Endpoint `json:"internal"`
+	// Address reachable from the host running the network runner
+	External Endpoint `json:"external"`
 }
 
 // Config encapsulates an avalanchego configuration
@@ -68,11 +128,17 @@ type Config struct {
 	// True if other nodes should use this node
 	// as a bootstrap beacon.
 	IsBeacon bool `json:"isBeacon"`
-	// Must not be nil.
+	// True if the node is an observer, that keeps the index API enabled
+	// and the full C-chain history, so tests can check what got accepted
+	// through its indexes (see network.GetObservers).
+	Observer bool `json:"observer,omitempty"`
+	// If StakingKey or StakingCert is empty, a new staking identity
+	// is generated on node creation. Network configs must give both or
+	// none. See utils.NewStakingIdentity to know the node ID up front.
 	StakingKey string `json:"stakingKey"`
-	// Must not be nil.
+	// See StakingKey.
 	StakingCert string `json:"stakingCert"`
-	// Must not be nil.
+	// If empty, a new one is generated on node creation.
 	StakingSigningKey string `json:"stakingSigningKey"`
 	// May be nil.
 	ConfigFile string `json:"configFile"`
@@ -91,22 +157,157 @@ type Config struct {
 	Flags map[string]interface{} `json:"flags"`
 	// What type of node this is
 	BinaryPath string `json:"binaryPath"`
+	// If given (eg v1.10.15) and BinaryPath is empty, the avalanchego
+	// release of this version for the host OS/arch is downloaded, cached
+	// under ~/.avalanche-network-runner/binaries, and used as BinaryPath.
+	BinaryVersion string `json:"binaryVersion,omitempty"`
+	// If given, the SHA256 (in hex) the node binary must have. It is
+	// checked before each node start, and after downloading the binary
+	// of BinaryVersion, so stale or corrupted binaries fail the start.
+	BinarySHA256 string `json:"binarySha256,omitempty"`
 	// If non-nil, direct this node's Stdout to os.Stdout
 	RedirectStdout bool `json:"redirectStdout"`
 	// If non-nil, direct this node's Stderr to os.Stderr
 	RedirectStderr bool `json:"redirectStderr"`
+	// If true, the node requires API auth tokens, using a password
+	// generated on each node start. The API client of the node attaches
+	// a valid token to each request.
+	APIAuth bool `json:"apiAuth,omitempty"`
+	// If > 0, the node is removed from the network once this time
+	// passes since its start
+	TTL time.Duration `json:"ttl,omitempty"`
+	// If not nil, periodically called while the node runs. The node is
+	// removed from the network once it returns true (eg once bootstrapped
+	// and its state copied).
+	RemoveWhen func(ctx context.Context, node Node) (bool, error) `json:"-"`
+	// If not nil, creates the API clients of this node instead of the
+	// network API client factory (eg to instrument the client of a node
+	// under study). Not kept on snapshots.
+	NewAPIClient api.NewAPIClientF `json:"-"`
+	// Extra files written into the node data dir before each node start,
+	// and removed when the node is removed (eg keystore files, VM configs).
+	// Keys are paths relative to the data dir, that may use the
+	// {{.NodeName}} and {{.NetworkID}} template fields. Values are the
+	// file contents.
+	Files map[string]string `json:"files,omitempty"`
+	// Environment variables of the node process, besides the ones of the runner
+	Env map[string]string `json:"env,omitempty"`
+	// If not empty, tunes the heap, GC and buffers of the node.
+	// Env and Flags take precedence over the preset settings.
+	ResourcePreset ResourcePreset `json:"resourcePreset,omitempty"`
+	// VM binaries copied into the plugin dir of the node before each node
+	// start. If no plugin dir is given on the flags, the node gets its own
+	// one, inside its data dir.
+	VMPlugins []VMPlugin `json:"vmPlugins,omitempty"`
+	// If not nil, the CPU and IO priority the node process runs with,
+	// instead of the ones of the runner
+	Priority *ProcessPriority `json:"priority,omitempty"`
+	// If not nil, tells whether the node is restarted when its process
+	// exits without being asked to
+	RestartPolicy *RestartPolicy `json:"restartPolicy,omitempty"`
+	// If not nil, the node output is also written to a rotated file.
+	// Only for node processes launched by the runner.
+	OutputFile *OutputFileConfig `json:"outputFile,omitempty"`
+}
+
+// VMPlugin is a VM binary to be installed as a node plugin
+type VMPlugin struct {
+	// Path of the VM binary
+	Path string `json:"path"`
+	// VM name (eg subnetevm), or VM ID. The plugin is named after the VM ID,
+	// which for VM names is given by utils.VMID.
+	VM string `json:"vm"`
+	// Other names the node knows the VM by (eg on blockchain creation),
+	// written to its VM aliases file
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// VMID returns the ID of the VM of the plugin
+func (p VMPlugin) VMID() (ids.ID, error) {
+	if vmID, err := ids.FromString(p.VM); err == nil {
+		return vmID, nil
+	}
+	return utils.VMID(p.VM)
 }
 
 // Validate returns an error if this config is invalid
 func (c *Config) Validate(expectedNetworkID uint32) error {
 	switch {
-	case c.StakingKey == "":
-		return errors.New("staking key not given")
-	case c.StakingCert == "":
-		return errors.New("staking cert not given")
-	default:
-		return validateConfigFile([]byte(c.ConfigFile), expectedNetworkID)
+	case c.StakingKey == "" && c.StakingCert != "":
+		return errors.New("staking cert given without staking key")
+	case c.StakingKey != "" && c.StakingCert == "":
+		return errors.New("staking key given without staking cert")
+	}
+	if c.ResourcePreset != "" {
+		if _, err := c.ResourcePreset.Settings(); err != nil {
+			return err
+		}
+	}
+	if c.Priority != nil {
+		if err := c.Priority.Validate(); err != nil {
+			return fmt.Errorf("invalid node priority: %w", err)
+		}
+	}
+	if c.RestartPolicy != nil {
+		if err := c.RestartPolicy.Validate(); err != nil {
+			return fmt.Errorf("invalid node restart policy: %w", err)
+		}
+	}
+	if c.OutputFile != nil {
+		if err := c.OutputFile.Validate(); err != nil {
+			return fmt.Errorf("invalid node output file: %w", err)
+		}
+	}
+	if c.Observer {
+		if err := c.validateObserver(); err != nil {
+			return err
+		}
+	}
+	if c.BinaryVersion != "" && !semver.IsValid(c.BinaryVersion) {
+		return fmt.Errorf("invalid binary version %q", c.BinaryVersion)
+	}
+	if c.BinarySHA256 != "" {
+		if sum, err := hex.DecodeString(c.BinarySHA256); err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("invalid binary sha256 %q", c.BinarySHA256)
+		}
+	}
+	if err := ValidateVMPlugins(c.VMPlugins); err != nil {
+		return err
 	}
+	return validateConfigFile([]byte(c.ConfigFile), expectedNetworkID)
+}
+
+// ValidateVMPlugins returns an error if some of [plugins] is invalid, or
+// if some VM or alias is given twice
+func ValidateVMPlugins(plugins []VMPlugin) error {
+	vmIDs := set.Set[ids.ID]{}
+	aliases := set.Set[string]{}
+	for _, plugin := range plugins {
+		if plugin.VM == "" {
+			return fmt.Errorf("no VM given for plugin %q", plugin.Path)
+		}
+		if plugin.Path == "" {
+			return fmt.Errorf("no path given for VM plugin %q", plugin.VM)
+		}
+		vmID, err := plugin.VMID()
+		if err != nil {
+			return fmt.Errorf("invalid VM of plugin %q: %w", plugin.Path, err)
+		}
+		if vmIDs.Contains(vmID) {
+			return fmt.Errorf("VM plugin %q given twice", plugin.VM)
+		}
+		vmIDs.Add(vmID)
+		for _, alias := range plugin.Aliases {
+			if alias == "" {
+				return fmt.Errorf("empty alias of VM plugin %q", plugin.VM)
+			}
+			if aliases.Contains(alias) {
+				return fmt.Errorf("VM alias %q given twice", alias)
+			}
+			aliases.Add(alias)
+		}
+	}
+	return nil
 }
 
 // Returns an error if config file [configFile] is invalid.
@@ -0,0 +1,69 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/config"
+)
+
+const (
+	// Alias of the C-chain on the chain config files
+	cChainAlias = "C"
+	// C-chain config key that makes the chain drop old state
+	cChainPruningKey = "pruning-enabled"
+)
+
+// ApplyObserverRole enables the index API and the full C-chain history
+// if [c] is an observer, keeping the flags and C-chain config values
+// already set in [c]
+func (c *Config) ApplyObserverRole() error {
+	if !c.Observer {
+		return nil
+	}
+	if c.Flags == nil {
+		c.Flags = map[string]interface{}{}
+	}
+	if _, ok := c.Flags[config.IndexEnabledKey]; !ok {
+		c.Flags[config.IndexEnabledKey] = true
+	}
+	if c.ChainConfigFiles == nil {
+		c.ChainConfigFiles = map[string]string{}
+	}
+	chainConfig := map[string]interface{}{}
+	if chainConfigFile := c.ChainConfigFiles[cChainAlias]; chainConfigFile != "" {
+		if err := json.Unmarshal([]byte(chainConfigFile), &chainConfig); err != nil {
+			return fmt.Errorf("couldn't decode C-chain config of observer node: %w", err)
+		}
+	}
+	if _, ok := chainConfig[cChainPruningKey]; ok {
+		return nil
+	}
+	chainConfig[cChainPruningKey] = false
+	chainConfigFile, err := json.Marshal(chainConfig)
+	if err != nil {
+		return err
+	}
+	c.ChainConfigFiles[cChainAlias] = string(chainConfigFile)
+	return nil
+}
+
+// Returns an error if the flags or C-chain config of observer config [c]
+// disable what observers need
+func (c *Config) validateObserver() error {
+	if enabled, ok := c.Flags[config.IndexEnabledKey]; ok && enabled != true {
+		return fmt.Errorf("observer node with %s=%v", config.IndexEnabledKey, enabled)
+	}
+	chainConfigFile := c.ChainConfigFiles[cChainAlias]
+	if chainConfigFile == "" {
+		return nil
+	}
+	chainConfig := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(chainConfigFile), &chainConfig); err != nil {
+		return fmt.Errorf("couldn't decode C-chain config of observer node: %w", err)
+	}
+	if pruning, ok := chainConfig[cChainPruningKey]; ok && pruning != false {
+		return fmt.Errorf("observer node with C-chain %s=%v", cChainPruningKey, pruning)
+	}
+	return nil
+}
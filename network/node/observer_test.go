@@ -0,0 +1,43 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyObserverRole(t *testing.T) {
+	require := require.New(t)
+
+	// non observers are left as they are
+	nodeConfig, err := node.NewConfigBuilder().Build()
+	require.NoError(err)
+	require.NoError(nodeConfig.ApplyObserverRole())
+	require.Empty(nodeConfig.Flags)
+	require.Empty(nodeConfig.ChainConfigFiles)
+
+	nodeConfig, err = node.NewConfigBuilder().
+		Observer().
+		WithChainConfigFile("C", `{"log-level": "debug"}`).
+		Build()
+	require.NoError(err)
+	require.NoError(nodeConfig.ApplyObserverRole())
+	require.Equal(true, nodeConfig.Flags[config.IndexEnabledKey])
+	require.JSONEq(`{"log-level": "debug", "pruning-enabled": false}`, nodeConfig.ChainConfigFiles["C"])
+
+	// values already set are kept
+	nodeConfig = node.Config{
+		Observer:         true,
+		ChainConfigFiles: map[string]string{"C": `{"pruning-enabled": false, "log-level": "info"}`},
+	}
+	require.NoError(nodeConfig.ApplyObserverRole())
+	require.JSONEq(`{"pruning-enabled": false, "log-level": "info"}`, nodeConfig.ChainConfigFiles["C"])
+
+	// observers can't disable indexing or keep pruning
+	nodeConfig = node.Config{Observer: true, Flags: map[string]interface{}{config.IndexEnabledKey: false}}
+	require.ErrorContains(nodeConfig.Validate(0), "observer node with index-enabled=false")
+	nodeConfig = node.Config{Observer: true, ChainConfigFiles: map[string]string{"C": `{"pruning-enabled": true}`}}
+	require.ErrorContains(nodeConfig.Validate(0), "observer node with C-chain pruning-enabled=true")
+}
@@ -0,0 +1,39 @@
+package node
+
+import "fmt"
+
+const (
+	// Name of the file a node output is written to, in its node dir
+	OutputFileName = "node.log"
+	// Default max size of a node output file before it is rotated
+	DefaultOutputFileMaxSizeMB = 100
+)
+
+// OutputFileConfig writes the output of a node (stdout and stderr) to
+// OutputFileName in its node dir, <rootDir>/<nodeName>, rotated by size.
+// Rotated files are named after their rotation time, eg
+// node-2006-01-02T15-04-05.000.log, next to the current one.
+type OutputFileConfig struct {
+	// Size the file is rotated at, in megabytes.
+	// Defaults to DefaultOutputFileMaxSizeMB.
+	MaxSizeMB int `json:"maxSizeMB,omitempty"`
+	// Maximum number of rotated files kept, 0 to keep them all
+	MaxBackups int `json:"maxBackups,omitempty"`
+	// Maximum days rotated files are kept, 0 to keep them all
+	MaxAgeDays int `json:"maxAgeDays,omitempty"`
+	// If true, rotated files are gzipped
+	Compress bool `json:"compress,omitempty"`
+}
+
+// Validate returns an error if the config is invalid
+func (c OutputFileConfig) Validate() error {
+	switch {
+	case c.MaxSizeMB < 0:
+		return fmt.Errorf("negative output file max size %d", c.MaxSizeMB)
+	case c.MaxBackups < 0:
+		return fmt.Errorf("negative output file max backups %d", c.MaxBackups)
+	case c.MaxAgeDays < 0:
+		return fmt.Errorf("negative output file max age %d", c.MaxAgeDays)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/config"
+)
+
+// ResourcePreset tunes the heap, GC and buffers of a node for the
+// resources available to it, eg "small" for many nodes on a laptop
+type ResourcePreset string
+
+const (
+	ResourcePresetSmall  ResourcePreset = "small"
+	ResourcePresetMedium ResourcePreset = "medium"
+	ResourcePresetLarge  ResourcePreset = "large"
+)
+
+// ResourcePresetSettings are the environment variables and flags
+// given to a node by a ResourcePreset
+type ResourcePresetSettings struct {
+	Env   map[string]string
+	Flags map[string]interface{}
+}
+
+// Settings returns the environment variables and flags of the preset
+func (p ResourcePreset) Settings() (ResourcePresetSettings, error) {
+	switch p {
+	case ResourcePresetSmall:
+		return ResourcePresetSettings{
+			Env: map[string]string{
+				"GOGC":       "50",
+				"GOMEMLIMIT": "512MiB",
+				"GOMAXPROCS": "2",
+			},
+			Flags: map[string]interface{}{
+				config.ConsensusAppConcurrencyKey:    1,
+				config.NetworkPeerReadBufferSizeKey:  4096,
+				config.NetworkPeerWriteBufferSizeKey: 4096,
+			},
+		}, nil
+	case ResourcePresetMedium:
+		return ResourcePresetSettings{
+			Env: map[string]string{
+				"GOGC":       "100",
+				"GOMEMLIMIT": "2GiB",
+			},
+			Flags: map[string]interface{}{},
+		}, nil
+	case ResourcePresetLarge:
+		return ResourcePresetSettings{
+			Env: map[string]string{
+				"GOGC":       "200",
+				"GOMEMLIMIT": "8GiB",
+			},
+			Flags: map[string]interface{}{},
+		}, nil
+	default:
+		return ResourcePresetSettings{}, fmt.Errorf("unknown resource preset %q", p)
+	}
+}
+
+// ApplyResourcePreset adds the environment variables and flags of the
+// preset of [c], if any, keeping the ones already set in [c]
+func (c *Config) ApplyResourcePreset() error {
+	if c.ResourcePreset == "" {
+		return nil
+	}
+	settings, err := c.ResourcePreset.Settings()
+	if err != nil {
+		return err
+	}
+	if c.Env == nil {
+		c.Env = map[string]string{}
+	}
+	for k, v := range settings.Env {
+		if _, ok := c.Env[k]; !ok {
+			c.Env[k] = v
+		}
+	}
+	if c.Flags == nil {
+		c.Flags = map[string]interface{}{}
+	}
+	for k, v := range settings.Flags {
+		if _, ok := c.Flags[k]; !ok {
+			c.Flags[k] = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package node
+
+import "fmt"
+
+// IOClass is an IO scheduling class of a node process, as given to ionice
+type IOClass string
+
+const (
+	// Served first. Needs privileges.
+	IOClassRealtime IOClass = "realtime"
+	// The default class, with its IO level given by the niceness
+	IOClassBestEffort IOClass = "best-effort"
+	// Only served when no other process needs the disk
+	IOClassIdle IOClass = "idle"
+)
+
+// ProcessPriority is the CPU and IO scheduling priority of a node process,
+// eg to keep background non-validator nodes from starving the validators
+// (or the rest of the host) during heavy bootstraps
+type ProcessPriority struct {
+	// CPU niceness, from -20 (highest priority) to 19 (lowest).
+	// Negative values need privileges.
+	Nice int `json:"nice,omitempty"`
+	// If not empty, the IO scheduling class. Only supported on linux.
+	IOClass IOClass `json:"ioClass,omitempty"`
+	// Priority within IOClass, from 0 (highest) to 7 (lowest).
+	// Not used by IOClassIdle.
+	IOLevel int `json:"ioLevel,omitempty"`
+}
+
+// Validate returns an error if the priority is invalid
+func (p ProcessPriority) Validate() error {
+	if p.Nice < -20 || p.Nice > 19 {
+		return fmt.Errorf("niceness %d out of range [-20, 19]", p.Nice)
+	}
+	switch p.IOClass {
+	case "", IOClassRealtime, IOClassBestEffort, IOClassIdle:
+	default:
+		return fmt.Errorf("unknown IO class %q", p.IOClass)
+	}
+	if p.IOLevel < 0 || p.IOLevel > 7 {
+		return fmt.Errorf("IO level %d out of range [0, 7]", p.IOLevel)
+	}
+	if p.IOLevel != 0 && p.IOClass == "" {
+		return fmt.Errorf("IO level %d given without IO class", p.IOLevel)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package node
+
+import (
+	"fmt"
+	"time"
+)
+
+// RestartMode tells which process exits a RestartPolicy restarts a node on
+type RestartMode string
+
+const (
+	// Never restart the node
+	RestartNever RestartMode = "never"
+	// Restart the node if its process exits with a non zero code
+	RestartOnFailure RestartMode = "on-failure"
+	// Restart the node on any exit it wasn't asked to
+	RestartAlways RestartMode = "always"
+)
+
+const (
+	DefaultRestartBackoff = time.Second
+	MaxRestartBackoff     = time.Minute
+)
+
+// RestartPolicy restarts a node whose process exits without being asked
+// to (eg crashed), so long running networks don't silently lose nodes.
+// Nodes are restarted on the same dirs and ports, as by ResumeNode.
+type RestartPolicy struct {
+	// Defaults to RestartNever
+	Mode RestartMode `json:"mode,omitempty"`
+	// Maximum number of restarts, 0 for no limit. Restarts are counted
+	// until the node is restarted, resumed or added again on request.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// Delay before the first restart, doubled on each following one up to
+	// MaxRestartBackoff. Defaults to DefaultRestartBackoff.
+	Backoff time.Duration `json:"backoff,omitempty"`
+}
+
+// Validate returns an error if the policy is invalid
+func (p RestartPolicy) Validate() error {
+	switch p.Mode {
+	case "", RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		return fmt.Errorf("unknown restart mode %q", p.Mode)
+	}
+	if p.MaxRetries < 0 {
+		return fmt.Errorf("negative max restart retries %d", p.MaxRetries)
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("negative restart backoff %s", p.Backoff)
+	}
+	return nil
+}
+
+// Restarts returns true if a node whose process exited with [exitCode]
+// is restarted
+func (p RestartPolicy) Restarts(exitCode int) bool {
+	switch p.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return exitCode != 0
+	default:
+		return false
+	}
+}
+
+// Delay returns the delay before restart number [restart] of a node,
+// starting from 0
+func (p RestartPolicy) Delay(restart int) time.Duration {
+	delay := p.Backoff
+	if delay == 0 {
+		delay = DefaultRestartBackoff
+	}
+	for i := 0; i < restart && delay < MaxRestartBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxRestartBackoff {
+		return MaxRestartBackoff
+	}
+	return delay
+}
@@ -0,0 +1,28 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartPolicy(t *testing.T) {
+	require := require.New(t)
+
+	require.False(node.RestartPolicy{}.Restarts(1))
+	require.False(node.RestartPolicy{Mode: node.RestartOnFailure}.Restarts(0))
+	require.True(node.RestartPolicy{Mode: node.RestartOnFailure}.Restarts(-1))
+	require.True(node.RestartPolicy{Mode: node.RestartAlways}.Restarts(0))
+
+	policy := node.RestartPolicy{Mode: node.RestartAlways, Backoff: 10 * time.Second}
+	require.Equal(10*time.Second, policy.Delay(0))
+	require.Equal(40*time.Second, policy.Delay(2))
+	require.Equal(node.MaxRestartBackoff, policy.Delay(3))
+	require.Equal(node.MaxRestartBackoff, policy.Delay(100))
+	require.Equal(node.DefaultRestartBackoff, node.RestartPolicy{}.Delay(0))
+
+	require.Error(node.RestartPolicy{MaxRetries: -1}.Validate())
+	require.Error(node.RestartPolicy{Backoff: -time.Second}.Validate())
+}
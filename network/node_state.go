@@ -0,0 +1,16 @@
+package network
+
+// NodeState is the state of a node, as returned by Network.Status
+type NodeState string
+
+const (
+	// The node process runs, and wasn't found healthy since it started
+	NodeStarting NodeState = "starting"
+	// The node process runs, and the node reports itself healthy
+	NodeHealthy NodeState = "healthy"
+	// The node process runs, and was found healthy since it started, but
+	// it's not healthy anymore, or its API can't be reached
+	NodeUnhealthy NodeState = "unhealthy"
+	// The node is paused, or its process exited
+	NodeStopped NodeState = "stopped"
+)
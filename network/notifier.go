@@ -0,0 +1,147 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+)
+
+const webhookPostTimeout = 30 * time.Second
+
+// DefaultNotifiedEvents are the events notified if
+// NotificationsConfig.Events is empty
+var DefaultNotifiedEvents = []EventType{
+	EventNetworkHealthy,
+	EventNodeCrashed,
+	EventDiskThresholdExceeded,
+}
+
+// NotificationsConfig defines the webhook notifications of a network,
+// eg for devnets left running for days
+type NotificationsConfig struct {
+	// URL the notifications are posted to, eg a Slack incoming webhook
+	WebhookURL string `json:"webhookURL"`
+	// Events notified. Defaults to DefaultNotifiedEvents if empty.
+	Events []EventType `json:"events,omitempty"`
+}
+
+// WebhookMessage is the JSON body posted for each notification.
+// [Text] makes it a valid Slack incoming webhook message.
+type WebhookMessage struct {
+	Text    string `json:"text"`
+	Network string `json:"network,omitempty"`
+	Event   Event  `json:"event"`
+}
+
+// WebhookNotifier posts network events into a webhook
+type WebhookNotifier struct {
+	events      <-chan Event
+	networkName string
+	webhookURL  string
+	notified    []EventType
+	client      *http.Client
+}
+
+// NewWebhookNotifier returns a notifier of the events of [net] selected
+// by [config]. Only the events published from now on are notified.
+func NewWebhookNotifier(net Network, config NotificationsConfig) *WebhookNotifier {
+	notified := config.Events
+	if len(notified) == 0 {
+		notified = DefaultNotifiedEvents
+	}
+	return &WebhookNotifier{
+		events:      net.Events(),
+		networkName: net.GetMetadata().Name,
+		webhookURL:  config.WebhookURL,
+		notified:    notified,
+		client:      &http.Client{Timeout: webhookPostTimeout},
+	}
+}
+
+// Run posts the events into the webhook, until [ctx] is done or the
+// network is stopped. Failures to post are logged, and don't stop
+// the notifier.
+func (n *WebhookNotifier) Run(ctx context.Context, log logging.Logger) {
+	for {
+		var event Event
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-n.events:
+			if !ok {
+				return
+			}
+			event = e
+		}
+		if !containsEventType(n.notified, event.Type) {
+			continue
+		}
+		msg := WebhookMessage{
+			Text:    describeEvent(n.networkName, event),
+			Network: n.networkName,
+			Event:   event,
+		}
+		if err := n.post(ctx, msg); err != nil && ctx.Err() == nil {
+			log.Warn("couldn't post notification",
+				zap.String("event", string(event.Type)),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func containsEventType(eventTypes []EventType, eventType EventType) bool {
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a one line human readable description of [event]
+func describeEvent(networkName string, event Event) string {
+	sb := strings.Builder{}
+	if networkName != "" {
+		fmt.Fprintf(&sb, "[%s] ", networkName)
+	}
+	sb.WriteString(string(event.Type))
+	if event.NodeName != "" {
+		fmt.Fprintf(&sb, " (node %s)", event.NodeName)
+	}
+	if event.Message != "" {
+		fmt.Fprintf(&sb, ": %s", event.Message)
+	}
+	if event.Expected {
+		sb.WriteString(" [expected]")
+	}
+	return sb.String()
+}
+
+func (n *WebhookNotifier) post(ctx context.Context, msg WebhookMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook status code %d", resp.StatusCode)
+	}
+	return nil
+}
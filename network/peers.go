@@ -0,0 +1,158 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"golang.org/x/exp/maps"
+)
+
+// max time between peer snapshots taken by AssertStablePeers
+const stablePeersSnapshotFreq = time.Second
+
+// PeerChurn counts the peer connections and disconnections seen on a node
+type PeerChurn struct {
+	Connects    int `json:"connects"`
+	Disconnects int `json:"disconnects"`
+}
+
+// PeerChurnTracker counts, through repeated info.peers snapshots, the peer
+// connections and disconnections of the nodes of a network
+type PeerChurnTracker struct {
+	net Network
+
+	lock sync.Mutex
+	// node name --> peers seen on the last snapshot
+	peers map[string]set.Set[ids.NodeID]
+	// node name --> churn since the first snapshot
+	churn map[string]PeerChurn
+}
+
+// NewPeerChurnTracker returns a tracker for the nodes of [net].
+// Call Snapshot or Track to count the churn.
+func NewPeerChurnTracker(net Network) *PeerChurnTracker {
+	return &PeerChurnTracker{
+		net:   net,
+		peers: map[string]set.Set[ids.NodeID]{},
+		churn: map[string]PeerChurn{},
+	}
+}
+
+// Snapshot gets the peers of each node, and counts the peers connected and
+// disconnected since the previous snapshot. The first snapshot of a node
+// only sets its baseline.
+func (t *PeerChurnTracker) Snapshot(ctx context.Context) error {
+	nodes, err := t.net.GetAllNodes()
+	if err != nil {
+		return err
+	}
+	snapshot := make(map[string]set.Set[ids.NodeID], len(nodes))
+	for nodeName, node := range nodes {
+		if node.GetPaused() {
+			continue
+		}
+		peers, err := node.GetAPIClient().InfoAPI().Peers(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't get peers of node %q: %w", nodeName, err)
+		}
+		peerIDs := set.NewSet[ids.NodeID](len(peers))
+		for _, peer := range peers {
+			peerIDs.Add(peer.ID)
+		}
+		snapshot[nodeName] = peerIDs
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for nodeName, peerIDs := range snapshot {
+		previousPeerIDs, ok := t.peers[nodeName]
+		t.peers[nodeName] = peerIDs
+		if !ok {
+			continue
+		}
+		churn := t.churn[nodeName]
+		for peerID := range peerIDs {
+			if !previousPeerIDs.Contains(peerID) {
+				churn.Connects++
+			}
+		}
+		for peerID := range previousPeerIDs {
+			if !peerIDs.Contains(peerID) {
+				churn.Disconnects++
+			}
+		}
+		t.churn[nodeName] = churn
+	}
+	// removed and paused nodes get a new baseline when seen again
+	for nodeName := range t.peers {
+		if _, ok := snapshot[nodeName]; !ok {
+			delete(t.peers, nodeName)
+		}
+	}
+	return nil
+}
+
+// Track takes a snapshot every [interval] until [ctx] is done.
+// Returns the first snapshot error, if any.
+func (t *PeerChurnTracker) Track(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.Snapshot(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Churn returns the peer churn seen on each node, by node name
+func (t *PeerChurnTracker) Churn() map[string]PeerChurn {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return maps.Clone(t.churn)
+}
+
+// AssertStablePeers tracks the peers of the nodes of [net] during [window],
+// and returns an error naming the nodes whose peers changed, to detect
+// flapping connectivity (eg in soak tests)
+func AssertStablePeers(ctx context.Context, net Network, window time.Duration) error {
+	interval := stablePeersSnapshotFreq
+	if interval > window/4 {
+		interval = window / 4
+	}
+	tracker := NewPeerChurnTracker(net)
+	windowCtx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+	if err := tracker.Track(windowCtx, interval); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	unstable := []string{}
+	for nodeName, churn := range tracker.Churn() {
+		if churn.Connects > 0 || churn.Disconnects > 0 {
+			unstable = append(unstable, fmt.Sprintf("%s (%d connects, %d disconnects)", nodeName, churn.Connects, churn.Disconnects))
+		}
+	}
+	if len(unstable) > 0 {
+		sort.Strings(unstable)
+		return fmt.Errorf("unstable peers on %s", strings.Join(unstable, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package network_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	nodemocks "github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// peersInfoClient is an info client that only implements Peers
+type peersInfoClient struct {
+	info.Client
+
+	lock  sync.Mutex
+	peers []ids.NodeID
+}
+
+func (c *peersInfoClient) setPeers(peers ...ids.NodeID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.peers = peers
+}
+
+func (c *peersInfoClient) Peers(context.Context, ...rpc.Option) ([]info.Peer, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	peers := []info.Peer{}
+	for _, peerID := range c.peers {
+		peer := info.Peer{}
+		peer.ID = peerID
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+func newPeersTestNetwork(t *testing.T, infoClients map[string]*peersInfoClient) *mocks.Network {
+	net := mocks.NewNetwork(t)
+	nodes := map[string]node.Node{}
+	for nodeName, infoClient := range infoClients {
+		client := &apimocks.Client{}
+		client.On("InfoAPI").Return(infoClient)
+		n := nodemocks.NewNode(t)
+		n.On("GetPaused").Return(false)
+		n.On("GetAPIClient").Return(client)
+		nodes[nodeName] = n
+	}
+	net.On("GetAllNodes").Return(nodes, nil)
+	return net
+}
+
+func TestPeerChurnTracker(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	peer1, peer2, peer3 := ids.GenerateTestNodeID(), ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	infoClient1 := &peersInfoClient{}
+	infoClient1.setPeers(peer1, peer2)
+	infoClient2 := &peersInfoClient{}
+	infoClient2.setPeers(peer1)
+	net := newPeersTestNetwork(t, map[string]*peersInfoClient{
+		"node1": infoClient1,
+		"node2": infoClient2,
+	})
+
+	tracker := network.NewPeerChurnTracker(net)
+	require.NoError(tracker.Snapshot(ctx))
+	require.Empty(tracker.Churn())
+
+	infoClient1.setPeers(peer1, peer3)
+	require.NoError(tracker.Snapshot(ctx))
+	infoClient1.setPeers(peer1, peer2, peer3)
+	require.NoError(tracker.Snapshot(ctx))
+	require.Equal(network.PeerChurn{Connects: 2, Disconnects: 1}, tracker.Churn()["node1"])
+	require.Equal(network.PeerChurn{}, tracker.Churn()["node2"])
+}
+
+func TestAssertStablePeers(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	peer1, peer2 := ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	infoClient := &peersInfoClient{}
+	infoClient.setPeers(peer1, peer2)
+	net := newPeersTestNetwork(t, map[string]*peersInfoClient{"node1": infoClient})
+
+	require.NoError(network.AssertStablePeers(ctx, net, 200*time.Millisecond))
+
+	// flap a peer while asserting
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		infoClient.setPeers(peer1)
+	}()
+	err := network.AssertStablePeers(ctx, net, 400*time.Millisecond)
+	require.ErrorContains(err, "node1 (0 connects, 1 disconnects)")
+}
@@ -0,0 +1,31 @@
+package network
+
+import "time"
+
+// StartPhase is a step on the start of a node
+type StartPhase string
+
+const (
+	// The node binary was found and its version obtained
+	PhaseBinaryChecked StartPhase = "binary-checked"
+	// The node config, genesis and staking files were written
+	PhaseFilesWritten StartPhase = "files-written"
+	// The node process was launched
+	PhaseProcessStarted StartPhase = "process-started"
+	// The node answered an API call
+	PhaseAPIReachable StartPhase = "api-reachable"
+	// The node reported itself healthy, so its chains are bootstrapped
+	PhaseBootstrapped StartPhase = "bootstrapped"
+)
+
+// Progress notifies that a node reached a start phase
+type Progress struct {
+	NodeName string
+	Phase    StartPhase
+	Time     time.Time
+}
+
+// ProgressFunc receives the progress of node starts.
+// It's called from a goroutine of the network, in the order of the
+// reports and without holding network locks, so it may call the network.
+type ProgressFunc func(Progress)
@@ -0,0 +1,261 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Label with the network name, added to the series of the node metrics
+	MetricsNetworkLabel = "network"
+	// Label with the node name, added to the series of the node metrics
+	MetricsNodeLabel = "node"
+	// Gauge served by MetricsHandler for each node, 1 if the node metrics
+	// were scraped, 0 otherwise
+	MetricsNodeUp = "anr_node_up"
+
+	metricsPath = "/ext/metrics"
+)
+
+// ScrapeTarget is a node metrics endpoint to be scraped by Prometheus
+type ScrapeTarget struct {
+	NodeName string
+	// Host and port of the node API (i.e. 127.0.0.1:9650)
+	Address string
+	// URI of the node API (i.e. http://127.0.0.1:9650).
+	// If empty, the node API is reached at http://[Address].
+	URI string
+}
+
+// NewScrapeTarget returns the scrape target of node [nodeName], whose API
+// is reached at [uri] (see node.Node.GetURI)
+func NewScrapeTarget(nodeName string, uri string) ScrapeTarget {
+	target := ScrapeTarget{
+		NodeName: nodeName,
+		URI:      uri,
+	}
+	if u, err := url.Parse(uri); err == nil {
+		target.Address = u.Host
+	}
+	return target
+}
+
+type prometheusStaticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+}
+
+type prometheusScrapeConfig struct {
+	JobName       string                   `yaml:"job_name"`
+	MetricsPath   string                   `yaml:"metrics_path"`
+	StaticConfigs []prometheusStaticConfig `yaml:"static_configs"`
+}
+
+type prometheusRelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,omitempty"`
+	Regex        string   `yaml:"regex,omitempty"`
+	TargetLabel  string   `yaml:"target_label"`
+	Replacement  string   `yaml:"replacement"`
+}
+
+// PrometheusScrapeConfig returns a Prometheus scrape config with the job
+// [jobName], in YAML, to be added to the `scrape_configs` list.
+// The series of each target are labelled with [networkName] and the node name,
+// so several networks can be scraped by the same Prometheus.
+func PrometheusScrapeConfig(jobName string, networkName string, targets []ScrapeTarget) (string, error) {
+	config := prometheusScrapeConfig{
+		JobName:       jobName,
+		MetricsPath:   metricsPath,
+		StaticConfigs: []prometheusStaticConfig{},
+	}
+	for _, target := range targets {
+		config.StaticConfigs = append(config.StaticConfigs, prometheusStaticConfig{
+			Targets: []string{target.Address},
+			Labels: map[string]string{
+				MetricsNetworkLabel: networkName,
+				MetricsNodeLabel:    target.NodeName,
+			},
+		})
+	}
+	return marshalPrometheusConfig([]prometheusScrapeConfig{config})
+}
+
+// PrometheusRelabelConfigs returns Prometheus relabel configs, in YAML, to be
+// used as the `relabel_configs` of a scrape job not generated by the runner
+// (i.e. using service discovery). They label the series of the given targets
+// with [networkName] and the node name.
+func PrometheusRelabelConfigs(networkName string, targets []ScrapeTarget) (string, error) {
+	configs := []prometheusRelabelConfig{}
+	for _, target := range targets {
+		configs = append(configs, prometheusRelabelConfig{
+			SourceLabels: []string{"__address__"},
+			Regex:        regexp.QuoteMeta(target.Address),
+			TargetLabel:  MetricsNetworkLabel,
+			Replacement:  networkName,
+		}, prometheusRelabelConfig{
+			SourceLabels: []string{"__address__"},
+			Regex:        regexp.QuoteMeta(target.Address),
+			TargetLabel:  MetricsNodeLabel,
+			Replacement:  target.NodeName,
+		})
+	}
+	return marshalPrometheusConfig(configs)
+}
+
+// MetricsHandler returns an HTTP handler serving the metrics of all the
+// running nodes of [net], in the Prometheus text format, so a single scrape
+// target covers the whole network. The nodes are scraped on each request.
+// Their series are labelled with [networkName] and the node name, as with
+// PrometheusScrapeConfig, and MetricsNodeUp tells which nodes couldn't be
+// scraped.
+func MetricsHandler(net Network, networkName string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targets, err := net.MetricsTargets(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		families := scrapeTargets(r.Context(), networkName, targets)
+		names := make([]string, 0, len(families))
+		for name := range families {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		w.Header().Set("Content-Type", string(expfmt.FmtText))
+		for _, name := range names {
+			if _, err := expfmt.MetricFamilyToText(w, families[name]); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// Scrapes [targets] and returns their metric families, by name, with their
+// series labelled with [networkName] and the node name
+func scrapeTargets(ctx context.Context, networkName string, targets []ScrapeTarget) map[string]*dto.MetricFamily {
+	upName := MetricsNodeUp
+	upHelp := "1 if the node metrics were scraped, 0 otherwise"
+	gaugeType := dto.MetricType_GAUGE
+	families := map[string]*dto.MetricFamily{
+		MetricsNodeUp: {Name: &upName, Help: &upHelp, Type: &gaugeType},
+	}
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, target := range targets {
+		target := target
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			labels := map[string]string{
+				MetricsNetworkLabel: networkName,
+				MetricsNodeLabel:    target.NodeName,
+			}
+			up := 1.0
+			nodeFamilies, err := scrapeTarget(ctx, target)
+			if err != nil {
+				up = 0
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			families[MetricsNodeUp].Metric = append(families[MetricsNodeUp].Metric, &dto.Metric{
+				Label: addLabelPairs(nil, labels),
+				Gauge: &dto.Gauge{Value: &up},
+			})
+			for name, family := range nodeFamilies {
+				for _, m := range family.Metric {
+					m.Label = addLabelPairs(m.Label, labels)
+				}
+				if merged, ok := families[name]; ok {
+					merged.Metric = append(merged.Metric, family.Metric...)
+				} else {
+					families[name] = family
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, family := range families {
+		sort.Slice(family.Metric, func(i, j int) bool {
+			return labelPairsLess(family.Metric[i].Label, family.Metric[j].Label)
+		})
+	}
+	return families
+}
+
+// Fetches and parses the metrics exposed by [target]
+func scrapeTarget(ctx context.Context, target ScrapeTarget) (map[string]*dto.MetricFamily, error) {
+	uri := target.URI
+	if uri == "" {
+		uri = "http://" + target.Address
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri+metricsPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	parser := expfmt.TextParser{}
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// Returns [pairs] with the given labels added, sorted by name, keeping
+// the ones already present
+func addLabelPairs(pairs []*dto.LabelPair, labels map[string]string) []*dto.LabelPair {
+	present := map[string]bool{}
+	for _, pair := range pairs {
+		present[pair.GetName()] = true
+	}
+	for name, value := range labels {
+		if present[name] {
+			continue
+		}
+		name, value := name, value
+		pairs = append(pairs, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].GetName() < pairs[j].GetName()
+	})
+	return pairs
+}
+
+// Orders label pairs sorted by name by their names and values
+func labelPairsLess(a, b []*dto.LabelPair) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].GetName() != b[i].GetName() {
+			return a[i].GetName() < b[i].GetName()
+		}
+		if a[i].GetValue() != b[i].GetValue() {
+			return a[i].GetValue() < b[i].GetValue()
+		}
+	}
+	return len(a) < len(b)
+}
+
+func marshalPrometheusConfig(config interface{}) (string, error) {
+	buf := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return "", fmt.Errorf("couldn't marshal prometheus config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("couldn't marshal prometheus config: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,38 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+)
+
+// Share of the validator weight that must stay on healthy nodes for the
+// primary network to be healthy, with the default consensus parameters
+var MinHealthyValidatorWeight = snowball.DefaultParameters.MinPercentConnectedHealthy()
+
+var ErrValidatorQuorum = errors.New("validator weight below the consensus threshold")
+
+// QuorumError is returned by RemoveNode when removing node [NodeName]
+// would leave less than MinHealthyValidatorWeight of the validator weight
+// on healthy nodes. It matches ErrValidatorQuorum on errors.Is.
+type QuorumError struct {
+	NodeName string
+	// Weight of the validators healthy once the node is removed
+	HealthyWeight uint64
+	// Weight of all the validators
+	TotalWeight uint64
+}
+
+func (e *QuorumError) Error() string {
+	return fmt.Sprintf(
+		"removing node %q leaves %.1f%% of the validator weight healthy, below %.1f%% (use ForceRemoveNode to remove it anyway)",
+		e.NodeName,
+		100*float64(e.HealthyWeight)/float64(e.TotalWeight),
+		100*MinHealthyValidatorWeight,
+	)
+}
+
+func (*QuorumError) Is(target error) bool {
+	return target == ErrValidatorQuorum
+}
@@ -0,0 +1,206 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+)
+
+const relayHost = "127.0.0.1"
+
+var errRelayNoRunningNodes = errors.New("no running nodes")
+
+// RelayLink defines how a relay forwards requests to a network
+type RelayLink struct {
+	// Added before forwarding each request
+	Latency time.Duration
+	// If true, requests fail with 503 Service Unavailable, as if the
+	// network were unreachable
+	Down bool
+	// Used to forward the requests. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// Relay serves the node APIs of several networks (eg independent local
+// networks with distinct network IDs) at a single address, so cross
+// network tools (bridge relayers, indexers) can be run against them on
+// one host, and the link to each network degraded independently.
+// Requests to /<network name>/<path> are forwarded to <path> of a running
+// node of that network. Node APIs are reached at their external endpoint,
+// over plain HTTP unless the link transport says otherwise.
+type Relay struct {
+	log      logging.Logger
+	listener net.Listener
+	server   *http.Server
+
+	lock     sync.RWMutex
+	networks map[string]*relayNetwork
+}
+
+type relayNetwork struct {
+	net  Network
+	link RelayLink
+}
+
+// NewRelay starts a relay listening on a random localhost port, with no
+// networks. Close must be called once done.
+func NewRelay(log logging.Logger) (*Relay, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(relayHost, "0"))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't listen for relay: %w", err)
+	}
+	r := &Relay{
+		log:      log,
+		listener: listener,
+		networks: map[string]*relayNetwork{},
+	}
+	r.server = &http.Server{
+		Handler:           http.HandlerFunc(r.serveHTTP),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func() {
+		if err := r.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warn("relay stopped", zap.Error(err))
+		}
+	}()
+	return r, nil
+}
+
+// URI of the relay
+func (r *Relay) URI() string {
+	return "http://" + r.listener.Addr().String()
+}
+
+// NetworkURI returns the URI at which the relay serves the node APIs
+// of network [name], to be given to tools as a node URI
+func (r *Relay) NetworkURI(name string) string {
+	return r.URI() + "/" + name
+}
+
+// AddNetwork makes the relay forward to [nw] the requests to network [name]
+func (r *Relay) AddNetwork(name string, nw Network, link RelayLink) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid relay network name %q", name)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.networks[name]; ok {
+		return fmt.Errorf("relay network %q already exists", name)
+	}
+	r.networks[name] = &relayNetwork{net: nw, link: link}
+	return nil
+}
+
+// SetLink changes how requests to network [name] are forwarded, eg to
+// simulate an outage of the network while a cross network tool runs
+func (r *Relay) SetLink(name string, link RelayLink) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	relayNet, ok := r.networks[name]
+	if !ok {
+		return fmt.Errorf("relay network %q not found", name)
+	}
+	relayNet.link = link
+	return nil
+}
+
+// RemoveNetwork stops forwarding requests to network [name]
+func (r *Relay) RemoveNetwork(name string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.networks[name]; !ok {
+		return fmt.Errorf("relay network %q not found", name)
+	}
+	delete(r.networks, name)
+	return nil
+}
+
+// Close stops the relay. The networks are not stopped.
+func (r *Relay) Close() error {
+	return r.server.Close()
+}
+
+func (r *Relay) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	name, path, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	r.lock.RLock()
+	relayNet, ok := r.networks[name]
+	var link RelayLink
+	if ok {
+		link = relayNet.link
+	}
+	r.lock.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("relay network %q not found", name), http.StatusNotFound)
+		return
+	}
+	if link.Down {
+		http.Error(w, fmt.Sprintf("relay network %q is down", name), http.StatusServiceUnavailable)
+		return
+	}
+	if link.Latency > 0 {
+		select {
+		case <-time.After(link.Latency):
+		case <-req.Context().Done():
+			return
+		}
+	}
+	target, err := relayTarget(relayNet.net)
+	if err != nil {
+		r.log.Debug("relay request failed", zap.String("network", name), zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	reverseProxy := httputil.NewSingleHostReverseProxy(target)
+	reverseProxy.Transport = link.Transport
+	reverseProxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		r.log.Debug("relay request failed",
+			zap.String("network", name),
+			zap.String("path", req.URL.Path),
+			zap.Error(err),
+		)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	req.URL.Path = "/" + path
+	req.URL.RawPath = ""
+	reverseProxy.ServeHTTP(w, req)
+}
+
+// Returns the API URL of the first running node of [nw], by name
+func relayTarget(nw Network) (*url.URL, error) {
+	nodes, err := nw.GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	nodeNames := make([]string, 0, len(nodes))
+	for nodeName := range nodes {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	for _, nodeName := range nodeNames {
+		n := nodes[nodeName]
+		if n.Status() != status.Running {
+			continue
+		}
+		endpoint := n.GetEndpoints().External
+		return &url.URL{
+			Scheme: "http",
+			Host:   net.JoinHostPort(endpoint.Host, strconv.Itoa(int(endpoint.APIPort))),
+		}, nil
+	}
+	return nil, errRelayNoRunningNodes
+}
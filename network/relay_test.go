@@ -0,0 +1,68 @@
+package network_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/api/apitest"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	nodemocks "github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a network whose only node API is [server]
+func relayTestNetwork(t *testing.T, server *apitest.Server) network.Network {
+	host, port := server.Addr()
+	n := nodemocks.NewNode(t)
+	n.On("Status").Return(status.Running).Maybe()
+	n.On("GetEndpoints").Return(node.Endpoints{External: node.Endpoint{Host: host, APIPort: port}}).Maybe()
+	net := mocks.NewNetwork(t)
+	net.On("GetAllNodes").Return(map[string]node.Node{"node1": n}, nil).Maybe()
+	return net
+}
+
+func TestRelay(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	serverA, serverB := apitest.NewServer(), apitest.NewServer()
+	defer serverA.Close()
+	defer serverB.Close()
+	relay, err := network.NewRelay(logging.NoLog{})
+	require.NoError(err)
+	defer relay.Close()
+	require.NoError(relay.AddNetwork("a", relayTestNetwork(t, serverA), network.RelayLink{}))
+	require.NoError(relay.AddNetwork("b", relayTestNetwork(t, serverB), network.RelayLink{}))
+	require.Error(relay.AddNetwork("a", relayTestNetwork(t, serverA), network.RelayLink{}))
+
+	// each network is reached at its relay URI
+	nodeID, _, err := info.NewClient(relay.NetworkURI("a")).GetNodeID(ctx)
+	require.NoError(err)
+	require.Equal(serverA.NodeID(), nodeID)
+	nodeID, _, err = info.NewClient(relay.NetworkURI("b")).GetNodeID(ctx)
+	require.NoError(err)
+	require.Equal(serverB.NodeID(), nodeID)
+
+	// links are degraded independently
+	require.NoError(relay.SetLink("a", network.RelayLink{Down: true}))
+	_, _, err = info.NewClient(relay.NetworkURI("a")).GetNodeID(ctx)
+	require.Error(err)
+	require.NoError(relay.SetLink("b", network.RelayLink{Latency: 100 * time.Millisecond}))
+	start := time.Now()
+	_, _, err = info.NewClient(relay.NetworkURI("b")).GetNodeID(ctx)
+	require.NoError(err)
+	require.GreaterOrEqual(time.Since(start), 100*time.Millisecond)
+
+	require.NoError(relay.RemoveNetwork("b"))
+	resp, err := http.Get(relay.NetworkURI("b") + "/ext/health")
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal(http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,162 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/mem"
+)
+
+// DefaultScalingSizes are the numbers of nodes of the networks started by
+// the scaling benchmark if none are given
+var DefaultScalingSizes = []int{5, 10, 20, 40}
+
+// ScalingSpec defines a scaling benchmark run by RunScalingBenchmark
+type ScalingSpec struct {
+	// Numbers of nodes of the networks started, in order
+	Sizes []int
+	// Returns the config of a network of [numNodes] nodes, eg a closure
+	// over local.NewDefaultConfigNNodes
+	NewConfig func(numNodes int) (Config, error)
+	// Creates and starts a network with the given config, eg a closure
+	// over local.NewNetwork
+	NewNetwork func(context.Context, Config) (Network, error)
+	// Max time for each network to be healthy. No limit if 0.
+	HealthyTimeout time.Duration
+}
+
+// ScalingRun holds the measures of the network of a given size
+type ScalingRun struct {
+	NumNodes int `json:"numNodes"`
+	// Time from the network creation until it is healthy
+	TimeToHealthy time.Duration `json:"timeToHealthy"`
+	// Host CPU usage, in percent, from the network creation until it is
+	// healthy
+	HostCPUPercent float64 `json:"hostCPUPercent"`
+	// Host memory used once the network is healthy, in bytes
+	HostMemoryUsed uint64 `json:"hostMemoryUsed"`
+	// Host memory used once the network is healthy, minus the one used
+	// before its creation, in bytes
+	NetworkMemory int64 `json:"networkMemory"`
+	// Why the network couldn't be measured, if it couldn't
+	Error string `json:"error,omitempty"`
+}
+
+// ScalingReport holds the measures of the networks of a scaling benchmark
+type ScalingReport struct {
+	Runs []ScalingRun `json:"runs"`
+}
+
+// RunScalingBenchmark starts networks of the sizes of [spec], one after
+// the other, and measures the time they take to be healthy and the host
+// resources they use. Each network is stopped once measured.
+// Stops at the first network that fails to be healthy, returning the
+// report so far, with the failure on its last run, and the error.
+func RunScalingBenchmark(ctx context.Context, spec ScalingSpec) (ScalingReport, error) {
+	if spec.NewConfig == nil || spec.NewNetwork == nil {
+		return ScalingReport{}, errors.New("scaling benchmark config and network constructors must be given")
+	}
+	sizes := spec.Sizes
+	if len(sizes) == 0 {
+		sizes = DefaultScalingSizes
+	}
+	for _, numNodes := range sizes {
+		if numNodes <= 0 {
+			return ScalingReport{}, fmt.Errorf("invalid number of nodes %d", numNodes)
+		}
+	}
+	report := ScalingReport{Runs: []ScalingRun{}}
+	for _, numNodes := range sizes {
+		run, err := runScalingNetwork(ctx, spec, numNodes)
+		if err != nil {
+			run.Error = err.Error()
+		}
+		report.Runs = append(report.Runs, run)
+		if err != nil {
+			return report, fmt.Errorf("network of %d nodes: %w", numNodes, err)
+		}
+	}
+	return report, nil
+}
+
+// Creates a network of [numNodes] nodes, measures it once healthy, and
+// stops it
+func runScalingNetwork(ctx context.Context, spec ScalingSpec, numNodes int) (ScalingRun, error) {
+	run := ScalingRun{NumNodes: numNodes}
+	config, err := spec.NewConfig(numNodes)
+	if err != nil {
+		return run, err
+	}
+	memBefore, err := mem.VirtualMemory()
+	if err != nil {
+		return run, fmt.Errorf("couldn't get host memory: %w", err)
+	}
+	// sets the start of the CPU usage returned by the next call
+	if _, err := cpu.Percent(0, false); err != nil {
+		return run, fmt.Errorf("couldn't get host CPU usage: %w", err)
+	}
+	start := time.Now()
+	net, err := spec.NewNetwork(ctx, config)
+	if net != nil {
+		// also the nodes started by a network failing to start
+		defer func() {
+			_ = net.Stop(context.Background())
+		}()
+	}
+	if err != nil {
+		return run, err
+	}
+	healthyCtx := ctx
+	if spec.HealthyTimeout > 0 {
+		var cancel context.CancelFunc
+		healthyCtx, cancel = context.WithTimeout(ctx, spec.HealthyTimeout)
+		defer cancel()
+	}
+	if err := net.Healthy(healthyCtx); err != nil {
+		if ctxErr := healthyCtx.Err(); ctxErr != nil {
+			return run, fmt.Errorf("network not healthy: %s: %w", err, ctxErr)
+		}
+		return run, fmt.Errorf("network not healthy: %w", err)
+	}
+	run.TimeToHealthy = time.Since(start)
+	cpuPercent, err := cpu.Percent(0, false)
+	if err != nil {
+		return run, fmt.Errorf("couldn't get host CPU usage: %w", err)
+	}
+	if len(cpuPercent) != 0 {
+		run.HostCPUPercent = cpuPercent[0]
+	}
+	memAfter, err := mem.VirtualMemory()
+	if err != nil {
+		return run, fmt.Errorf("couldn't get host memory: %w", err)
+	}
+	run.HostMemoryUsed = memAfter.Used
+	run.NetworkMemory = int64(memAfter.Used) - int64(memBefore.Used)
+	return run, nil
+}
+
+// String returns a human readable table of the report
+func (r ScalingReport) String() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "nodes\ttime to healthy\tper node\thost cpu\thost memory\tnetwork memory\terror")
+	for _, run := range r.Runs {
+		perNode := run.TimeToHealthy / time.Duration(run.NumNodes)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.1f%%\t%d MiB\t%d MiB\t%s\n",
+			run.NumNodes,
+			run.TimeToHealthy.Round(time.Millisecond),
+			perNode.Round(time.Millisecond),
+			run.HostCPUPercent,
+			run.HostMemoryUsed>>20,
+			run.NetworkMemory>>20,
+			run.Error,
+		)
+	}
+	_ = w.Flush()
+	return buf.String()
+}
@@ -0,0 +1,81 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunScalingBenchmark(t *testing.T) {
+	require := require.New(t)
+
+	newConfig := func(numNodes int) (network.Config, error) {
+		return network.Config{NodeConfigs: make([]node.Config, numNodes)}, nil
+	}
+	started := []int{}
+	report, err := network.RunScalingBenchmark(context.Background(), network.ScalingSpec{
+		Sizes:     []int{2, 4},
+		NewConfig: newConfig,
+		NewNetwork: func(_ context.Context, config network.Config) (network.Network, error) {
+			started = append(started, len(config.NodeConfigs))
+			net := mocks.NewNetwork(t)
+			net.On("Healthy", mock.Anything).Run(func(mock.Arguments) {
+				time.Sleep(10 * time.Millisecond)
+			}).Return(nil)
+			net.On("Stop", mock.Anything).Return(nil)
+			return net, nil
+		},
+	})
+	require.NoError(err)
+	require.Equal([]int{2, 4}, started)
+	require.Len(report.Runs, 2)
+	for i, run := range report.Runs {
+		require.Equal(started[i], run.NumNodes)
+		require.GreaterOrEqual(run.TimeToHealthy, 10*time.Millisecond)
+		require.NotZero(run.HostMemoryUsed)
+		require.Empty(run.Error)
+	}
+	require.True(strings.HasPrefix(report.String(), "nodes  time to healthy"))
+
+	// stops at the first network not healthy within the timeout, which is
+	// stopped
+	started = []int{}
+	report, err = network.RunScalingBenchmark(context.Background(), network.ScalingSpec{
+		Sizes:     []int{2, 4, 8},
+		NewConfig: newConfig,
+		NewNetwork: func(_ context.Context, config network.Config) (network.Network, error) {
+			started = append(started, len(config.NodeConfigs))
+			net := mocks.NewNetwork(t)
+			if len(config.NodeConfigs) == 4 {
+				net.On("Healthy", mock.Anything).Return(errors.New("node4 not healthy"))
+			} else {
+				net.On("Healthy", mock.Anything).Return(nil)
+			}
+			net.On("Stop", mock.Anything).Return(nil)
+			return net, nil
+		},
+		HealthyTimeout: time.Minute,
+	})
+	require.ErrorContains(err, "network of 4 nodes: network not healthy: node4 not healthy")
+	require.Equal([]int{2, 4}, started)
+	require.Len(report.Runs, 2)
+	require.Equal("network not healthy: node4 not healthy", report.Runs[1].Error)
+
+	_, err = network.RunScalingBenchmark(context.Background(), network.ScalingSpec{
+		Sizes:     []int{2, 0},
+		NewConfig: newConfig,
+		NewNetwork: func(context.Context, network.Config) (network.Network, error) {
+			require.FailNow("network started with invalid sizes")
+			return nil, nil
+		},
+	})
+	require.ErrorContains(err, "invalid number of nodes 0")
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultSignalGracePeriod is the default max time given to the network
+// to stop after a signal is received
+const DefaultSignalGracePeriod = 30 * time.Second
+
+type signalHandlerOp struct {
+	gracePeriod time.Duration
+	onStop      func(os.Signal, error)
+}
+
+type SignalHandlerOption func(*signalHandlerOp)
+
+// WithGracePeriod sets the max time given to the nodes to stop gracefully
+// before being killed
+func WithGracePeriod(gracePeriod time.Duration) SignalHandlerOption {
+	return func(op *signalHandlerOp) {
+		op.gracePeriod = gracePeriod
+	}
+}
+
+// WithStopCallback sets a function called with the received signal,
+// and the result of stopping the network, once the network is stopped
+func WithStopCallback(onStop func(os.Signal, error)) SignalHandlerOption {
+	return func(op *signalHandlerOp) {
+		op.onStop = onStop
+	}
+}
+
+// RegisterSignalHandlers stops [net] when the process receives
+// a SIGINT or SIGTERM.
+// Returns a channel that is closed after the network is stopped in response
+// to a signal, and a function that removes the handlers.
+// After the network is stopped, the handlers are removed, so a subsequent
+// signal gets the default behavior (i.e. terminating the process).
+func RegisterSignalHandlers(net Network, opts ...SignalHandlerOption) (<-chan struct{}, func()) {
+	op := &signalHandlerOp{
+		gracePeriod: DefaultSignalGracePeriod,
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	signalsCh := make(chan os.Signal, 1)
+	signal.Notify(signalsCh, syscall.SIGINT, syscall.SIGTERM)
+	stoppedCh := make(chan struct{})
+	unregisteredCh := make(chan struct{})
+	unregisterOnce := sync.Once{}
+	unregister := func() {
+		unregisterOnce.Do(func() {
+			signal.Stop(signalsCh)
+			close(unregisteredCh)
+		})
+	}
+
+	go func() {
+		var sig os.Signal
+		select {
+		case sig = <-signalsCh:
+		case <-unregisteredCh:
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), op.gracePeriod)
+		err := net.Stop(ctx)
+		cancel()
+		unregister()
+		if op.onStop != nil {
+			op.onStop(sig, err)
+		}
+		close(stoppedCh)
+	}()
+
+	return stoppedCh, unregister
+}
@@ -0,0 +1,40 @@
+package network_test
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterSignalHandlers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("can't send SIGTERM to the process on windows")
+	}
+	require := require.New(t)
+
+	net := mocks.NewNetwork(t)
+	net.On("Stop", mock.Anything).Return(nil).Once()
+	var gotSignal os.Signal
+	stoppedCh, unregister := network.RegisterSignalHandlers(net, network.WithStopCallback(func(sig os.Signal, err error) {
+		gotSignal = sig
+		require.NoError(err)
+	}))
+	defer unregister()
+
+	proc, err := os.FindProcess(os.Getpid())
+	require.NoError(err)
+	require.NoError(proc.Signal(syscall.SIGTERM))
+	select {
+	case <-stoppedCh:
+	case <-time.After(10 * time.Second):
+		require.FailNow("network not stopped on signal")
+	}
+	require.Equal(syscall.SIGTERM, gotSignal)
+}
@@ -0,0 +1,163 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// SnapshotInfo describes a saved network snapshot
+type SnapshotInfo struct {
+	Name string `json:"name"`
+	// Full local path to the snapshot dir
+	Path string `json:"path"`
+	// Total size in bytes of the snapshot files
+	Size int64 `json:"size"`
+	// Time the snapshot was saved
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// SnapshotRetentionPolicy defines which snapshots are removed when pruning.
+// Zero values disable the corresponding limit.
+type SnapshotRetentionPolicy struct {
+	// Snapshots saved longer ago than this are removed
+	MaxAge time.Duration `json:"maxAge"`
+	// Only the newest MaxCount snapshots are kept
+	MaxCount int `json:"maxCount"`
+}
+
+// SnapshotChainState is the chain state of a network when a snapshot was saved
+type SnapshotChainState struct {
+	// node name --> state of the node
+	Nodes map[string]SnapshotNodeState `json:"nodes"`
+	// IDs of the primary network validators
+	Validators []string `json:"validators"`
+}
+
+// SnapshotNodeState is the state of a node when a snapshot was saved.
+// Fields that couldn't be obtained from the node are left empty.
+type SnapshotNodeState struct {
+	NodeID string `json:"nodeID"`
+	// avalanchego version
+	Version      string `json:"version,omitempty"`
+	PChainHeight uint64 `json:"pChainHeight"`
+	CChainHeight uint64 `json:"cChainHeight"`
+	// Size in bytes of the node DB, as saved in the snapshot
+	DBSize int64 `json:"dbSize"`
+}
+
+// SnapshotDiff summarizes what changed between two snapshots of a network
+type SnapshotDiff struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// True if the snapshots have different genesis, so they are not
+	// snapshots of the same network
+	GenesisChanged bool `json:"genesisChanged"`
+	// Names of the nodes only in [To]
+	AddedNodes []string `json:"addedNodes"`
+	// Names of the nodes only in [From]
+	RemovedNodes []string `json:"removedNodes"`
+	// node name --> state of the nodes in both snapshots
+	Nodes map[string]SnapshotNodeDiff `json:"nodes"`
+	// IDs of the validators only in [To]
+	AddedValidators []string `json:"addedValidators"`
+	// IDs of the validators only in [From]
+	RemovedValidators []string `json:"removedValidators"`
+}
+
+// SnapshotNodeDiff holds the state of a node on both snapshots
+type SnapshotNodeDiff struct {
+	From SnapshotNodeState `json:"from"`
+	To   SnapshotNodeState `json:"to"`
+}
+
+// DiffSnapshotChainStates compares the chain states of the snapshots
+// [from] and [to], and fills [diff] with the differences
+func DiffSnapshotChainStates(diff *SnapshotDiff, from SnapshotChainState, to SnapshotChainState) {
+	diff.AddedNodes, diff.RemovedNodes = diffNames(maps.Keys(from.Nodes), maps.Keys(to.Nodes))
+	diff.Nodes = map[string]SnapshotNodeDiff{}
+	for nodeName, fromState := range from.Nodes {
+		if toState, ok := to.Nodes[nodeName]; ok {
+			diff.Nodes[nodeName] = SnapshotNodeDiff{From: fromState, To: toState}
+		}
+	}
+	diff.AddedValidators, diff.RemovedValidators = diffNames(from.Validators, to.Validators)
+}
+
+// Returns the sorted names only in [to], and the sorted names only in [from]
+func diffNames(from []string, to []string) ([]string, []string) {
+	fromSet := map[string]struct{}{}
+	for _, name := range from {
+		fromSet[name] = struct{}{}
+	}
+	toSet := map[string]struct{}{}
+	for _, name := range to {
+		toSet[name] = struct{}{}
+	}
+	added := []string{}
+	for name := range toSet {
+		if _, ok := fromSet[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	removed := []string{}
+	for name := range fromSet {
+		if _, ok := toSet[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// String returns a human readable summary of the differences
+func (d *SnapshotDiff) String() string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "snapshot %q -> %q\n", d.From, d.To)
+	if d.GenesisChanged {
+		sb.WriteString("genesis changed: the snapshots are not of the same network\n")
+	}
+	if len(d.AddedNodes) != 0 {
+		fmt.Fprintf(&sb, "added nodes: %s\n", strings.Join(d.AddedNodes, ", "))
+	}
+	if len(d.RemovedNodes) != 0 {
+		fmt.Fprintf(&sb, "removed nodes: %s\n", strings.Join(d.RemovedNodes, ", "))
+	}
+	if len(d.AddedValidators) != 0 {
+		fmt.Fprintf(&sb, "added validators: %s\n", strings.Join(d.AddedValidators, ", "))
+	}
+	if len(d.RemovedValidators) != 0 {
+		fmt.Fprintf(&sb, "removed validators: %s\n", strings.Join(d.RemovedValidators, ", "))
+	}
+	nodeNames := maps.Keys(d.Nodes)
+	sort.Strings(nodeNames)
+	for _, nodeName := range nodeNames {
+		nodeDiff := d.Nodes[nodeName]
+		changes := []string{}
+		if nodeDiff.From.NodeID != nodeDiff.To.NodeID {
+			changes = append(changes, fmt.Sprintf("node ID %s -> %s", nodeDiff.From.NodeID, nodeDiff.To.NodeID))
+		}
+		if nodeDiff.From.Version != nodeDiff.To.Version {
+			changes = append(changes, fmt.Sprintf("version %s -> %s", nodeDiff.From.Version, nodeDiff.To.Version))
+		}
+		if nodeDiff.From.PChainHeight != nodeDiff.To.PChainHeight {
+			changes = append(changes, fmt.Sprintf("P-chain height %d -> %d", nodeDiff.From.PChainHeight, nodeDiff.To.PChainHeight))
+		}
+		if nodeDiff.From.CChainHeight != nodeDiff.To.CChainHeight {
+			changes = append(changes, fmt.Sprintf("C-chain height %d -> %d", nodeDiff.From.CChainHeight, nodeDiff.To.CChainHeight))
+		}
+		if nodeDiff.From.DBSize != nodeDiff.To.DBSize {
+			changes = append(changes, fmt.Sprintf("db size %d -> %d (%+d)", nodeDiff.From.DBSize, nodeDiff.To.DBSize, nodeDiff.To.DBSize-nodeDiff.From.DBSize))
+		}
+		if len(changes) == 0 {
+			fmt.Fprintf(&sb, "%s: unchanged\n", nodeName)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", nodeName, strings.Join(changes, ", "))
+	}
+	return sb.String()
+}
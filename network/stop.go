@@ -0,0 +1,63 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrNotStopped = errors.New("network not stopped")
+
+// NodeStopResult tells how the process of a node was stopped
+type NodeStopResult struct {
+	Name string `json:"name"`
+	// True if the process didn't exit on its own before the stop
+	// timeout, and had to be killed
+	Killed   bool `json:"killed"`
+	ExitCode int  `json:"exitCode"`
+	// Time the process took to exit
+	Duration time.Duration `json:"duration"`
+}
+
+// StopResult tells how the nodes of a network were stopped by Stop()
+type StopResult struct {
+	// Nodes whose process was stopped, sorted by name.
+	// Paused nodes have no process to stop, and are not included.
+	Nodes []NodeStopResult `json:"nodes"`
+	// Time taken to stop the whole network
+	Duration time.Duration `json:"duration"`
+}
+
+// Graceful returns the names of the nodes that exited on their own
+func (r StopResult) Graceful() []string {
+	names := []string{}
+	for _, node := range r.Nodes {
+		if !node.Killed {
+			names = append(names, node.Name)
+		}
+	}
+	return names
+}
+
+// Killed returns the names of the nodes that had to be killed
+func (r StopResult) Killed() []string {
+	names := []string{}
+	for _, node := range r.Nodes {
+		if node.Killed {
+			names = append(names, node.Name)
+		}
+	}
+	return names
+}
+
+// StopWithResult stops [net] as Network.Stop does, and returns how its
+// nodes were stopped. If [net] was already stopped, returns the result
+// of that first stop, along with ErrStopped.
+func StopWithResult(ctx context.Context, net Network) (StopResult, error) {
+	err := net.Stop(ctx)
+	result, resultErr := net.GetStopResult()
+	if resultErr != nil && err == nil {
+		err = resultErr
+	}
+	return result, err
+}
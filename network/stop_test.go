@@ -0,0 +1,40 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopWithResult(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	result := network.StopResult{
+		Nodes: []network.NodeStopResult{
+			{Name: "node1", Duration: time.Second},
+			{Name: "node2", Killed: true, ExitCode: -1, Duration: 30 * time.Second},
+			{Name: "node3", Duration: 2 * time.Second},
+		},
+		Duration: 33 * time.Second,
+	}
+	require.Equal([]string{"node1", "node3"}, result.Graceful())
+	require.Equal([]string{"node2"}, result.Killed())
+
+	net := mocks.NewNetwork(t)
+	net.On("Stop", ctx).Return(nil).Once()
+	net.On("GetStopResult").Return(result, nil)
+	gotResult, err := network.StopWithResult(ctx, net)
+	require.NoError(err)
+	require.Equal(result, gotResult)
+
+	net = mocks.NewNetwork(t)
+	net.On("Stop", ctx).Return(nil).Once()
+	net.On("GetStopResult").Return(network.StopResult{}, network.ErrNotStopped)
+	_, err = network.StopWithResult(ctx, net)
+	require.ErrorIs(err, network.ErrNotStopped)
+}
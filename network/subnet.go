@@ -0,0 +1,24 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// CreateSubnet creates a subnet validated by the nodes of [net] named
+// [validators] (all the nodes if empty), and returns its ID.
+// As with Network.CreateSubnets, nodes not found in the network are added,
+// and it returns once the subnet is created, its validators are added and
+// the nodes track it.
+func CreateSubnet(ctx context.Context, net Network, validators []string) (ids.ID, error) {
+	subnetIDs, err := net.CreateSubnets(ctx, []SubnetSpec{{Participants: validators}})
+	if err != nil {
+		return ids.Empty, err
+	}
+	if len(subnetIDs) != 1 {
+		return ids.Empty, fmt.Errorf("expected 1 subnet to be created, got %d", len(subnetIDs))
+	}
+	return subnetIDs[0], nil
+}
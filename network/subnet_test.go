@@ -0,0 +1,30 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSubnet(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	subnetID := ids.GenerateTestID()
+	net := mocks.NewNetwork(t)
+	net.On("CreateSubnets", ctx, []network.SubnetSpec{{Participants: []string{"node1", "node2"}}}).
+		Return([]ids.ID{subnetID}, nil).Once()
+	gotSubnetID, err := network.CreateSubnet(ctx, net, []string{"node1", "node2"})
+	require.NoError(err)
+	require.Equal(subnetID, gotSubnetID)
+
+	net.On("CreateSubnets", ctx, []network.SubnetSpec{{}}).
+		Return(nil, errors.New("error on purpose for test")).Once()
+	_, err = network.CreateSubnet(ctx, net, nil)
+	require.Error(err)
+}
@@ -0,0 +1,233 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+const (
+	TextSummaryFormat     = "text"
+	JSONSummaryFormat     = "json"
+	MarkdownSummaryFormat = "markdown"
+)
+
+// NodeSummary describes a node of the network
+type NodeSummary struct {
+	Name   string `json:"name"`
+	NodeID string `json:"nodeID"`
+	URI    string `json:"uri"`
+	Paused bool   `json:"paused"`
+	// avalanchego version of the node binary
+	Version string `json:"version,omitempty"`
+}
+
+// ChainSummary describes a blockchain of the network
+type ChainSummary struct {
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	SubnetID string `json:"subnetID"`
+	VMID     string `json:"vmID"`
+}
+
+// FundedKey is a private key with funds on the network, given
+// together with its address on some chain
+type FundedKey struct {
+	Address    string `json:"address"`
+	PrivateKey string `json:"privateKey"`
+}
+
+// Summary holds the information a user needs to start interacting with a network
+type Summary struct {
+	Metadata
+	NetworkID  uint32         `json:"networkID"`
+	Nodes      []NodeSummary  `json:"nodes"`
+	FundedKeys []FundedKey    `json:"fundedKeys"`
+	Chains     []ChainSummary `json:"chains"`
+}
+
+// SummaryFormatter renders a network summary
+type SummaryFormatter func(Summary) ([]byte, error)
+
+var (
+	summaryFormattersLock sync.RWMutex
+	summaryFormatters     = map[string]SummaryFormatter{
+		TextSummaryFormat:     formatTextSummary,
+		JSONSummaryFormat:     formatJSONSummary,
+		MarkdownSummaryFormat: formatMarkdownSummary,
+	}
+)
+
+// RegisterSummaryFormatter makes [formatter] available to FormatSummary
+// under the given format name, replacing any previous formatter for it
+func RegisterSummaryFormatter(format string, formatter SummaryFormatter) {
+	summaryFormattersLock.Lock()
+	defer summaryFormattersLock.Unlock()
+
+	summaryFormatters[format] = formatter
+}
+
+// FormatSummary renders [summary] using the formatter registered for [format]
+func FormatSummary(summary Summary, format string) ([]byte, error) {
+	summaryFormattersLock.RLock()
+	formatter, ok := summaryFormatters[format]
+	summaryFormattersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown summary format %q", format)
+	}
+	return formatter(summary)
+}
+
+// NewSummary gathers the summary of the running network [net].
+// Chains are obtained from the P-Chain API of the first running node.
+// [fundedKeys] are included as is.
+func NewSummary(ctx context.Context, net Network, fundedKeys []FundedKey) (Summary, error) {
+	networkID, err := net.GetNetworkID()
+	if err != nil {
+		return Summary{}, err
+	}
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return Summary{}, err
+	}
+	summary := Summary{
+		Metadata:   net.GetMetadata(),
+		NetworkID:  networkID,
+		Nodes:      []NodeSummary{},
+		FundedKeys: fundedKeys,
+		Chains: []ChainSummary{
+			{
+				Name:     "P",
+				ID:       constants.PlatformChainID.String(),
+				SubnetID: constants.PrimaryNetworkID.String(),
+				VMID:     constants.PlatformVMID.String(),
+			},
+		},
+	}
+	nodeNames := make([]string, 0, len(nodes))
+	for nodeName := range nodes {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	var chainsSource string
+	for _, nodeName := range nodeNames {
+		node := nodes[nodeName]
+		summary.Nodes = append(summary.Nodes, NodeSummary{
+			Name:    nodeName,
+			NodeID:  node.GetNodeID().String(),
+			URI:     node.GetURI(),
+			Paused:  node.GetPaused(),
+			Version: node.GetBinaryVersion(),
+		})
+		if chainsSource == "" && !node.GetPaused() {
+			chainsSource = nodeName
+		}
+	}
+	if chainsSource == "" {
+		return summary, nil
+	}
+	blockchains, err := nodes[chainsSource].GetAPIClient().PChainAPI().GetBlockchains(ctx)
+	if err != nil {
+		return Summary{}, fmt.Errorf("couldn't get blockchains from node %q: %w", chainsSource, err)
+	}
+	for _, blockchain := range blockchains {
+		summary.Chains = append(summary.Chains, ChainSummary{
+			Name:     blockchain.Name,
+			ID:       blockchain.ID.String(),
+			SubnetID: blockchain.SubnetID.String(),
+			VMID:     blockchain.VMID.String(),
+		})
+	}
+	return summary, nil
+}
+
+func formatJSONSummary(summary Summary) ([]byte, error) {
+	return json.MarshalIndent(summary, "", "    ")
+}
+
+func formatTextSummary(summary Summary) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if summary.Name != "" {
+		fmt.Fprintf(buf, "name: %s\n", summary.Name)
+	}
+	if summary.Description != "" {
+		fmt.Fprintf(buf, "description: %s\n", summary.Description)
+	}
+	if len(summary.Tags) > 0 {
+		fmt.Fprintf(buf, "tags: %s\n", strings.Join(formatTags(summary.Tags), ", "))
+	}
+	fmt.Fprintf(buf, "network ID: %d\n", summary.NetworkID)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nnodes:")
+	for _, node := range summary.Nodes {
+		paused := ""
+		if node.Paused {
+			paused = "(paused)"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", node.Name, node.NodeID, node.URI, node.Version, paused)
+	}
+	if len(summary.FundedKeys) > 0 {
+		fmt.Fprintln(w, "\nfunded keys:")
+		for _, key := range summary.FundedKeys {
+			fmt.Fprintf(w, "  %s\t%s\n", key.Address, key.PrivateKey)
+		}
+	}
+	fmt.Fprintln(w, "\nchains:")
+	for _, chain := range summary.Chains {
+		fmt.Fprintf(w, "  %s\t%s\tsubnet %s\tvm %s\n", chain.Name, chain.ID, chain.SubnetID, chain.VMID)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func formatMarkdownSummary(summary Summary) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if summary.Name != "" {
+		fmt.Fprintf(buf, "### %s (network %d)\n", summary.Name, summary.NetworkID)
+	} else {
+		fmt.Fprintf(buf, "### Network %d\n", summary.NetworkID)
+	}
+	if summary.Description != "" {
+		fmt.Fprintf(buf, "\n%s\n", summary.Description)
+	}
+	if len(summary.Tags) > 0 {
+		fmt.Fprintf(buf, "\nTags: `%s`\n", strings.Join(formatTags(summary.Tags), "`, `"))
+	}
+	fmt.Fprintln(buf, "\n| Node | NodeID | URI | Version | Paused |")
+	fmt.Fprintln(buf, "|---|---|---|---|---|")
+	for _, node := range summary.Nodes {
+		fmt.Fprintf(buf, "| %s | `%s` | %s | %s | %t |\n", node.Name, node.NodeID, node.URI, node.Version, node.Paused)
+	}
+	if len(summary.FundedKeys) > 0 {
+		fmt.Fprintln(buf, "\n| Funded Address | Private Key |")
+		fmt.Fprintln(buf, "|---|---|")
+		for _, key := range summary.FundedKeys {
+			fmt.Fprintf(buf, "| `%s` | `%s` |\n", key.Address, key.PrivateKey)
+		}
+	}
+	fmt.Fprintln(buf, "\n| Chain | ID | Subnet | VM |")
+	fmt.Fprintln(buf, "|---|---|---|---|")
+	for _, chain := range summary.Chains {
+		fmt.Fprintf(buf, "| %s | `%s` | `%s` | `%s` |\n", chain.Name, chain.ID, chain.SubnetID, chain.VMID)
+	}
+	return buf.Bytes(), nil
+}
+
+// Returns the tags as sorted key=value strings
+func formatTags(tags map[string]string) []string {
+	formatted := make([]string, 0, len(tags))
+	for k, v := range tags {
+		formatted = append(formatted, k+"="+v)
+	}
+	sort.Strings(formatted)
+	return formatted
+}
@@ -0,0 +1,88 @@
+package network_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	nodemocks "github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSummary(t *testing.T) {
+	require := require.New(t)
+
+	summary := network.Summary{
+		Metadata: network.Metadata{
+			Name:        "devnet",
+			Description: "owned by the wallet team",
+			Tags:        map[string]string{"ci": "true"},
+		},
+		NetworkID: 1337,
+		Nodes: []network.NodeSummary{
+			{Name: "node1", NodeID: "NodeID-1", URI: "http://127.0.0.1:9650", Version: "v1.10.15"},
+		},
+		FundedKeys: []network.FundedKey{
+			{Address: "X-custom1", PrivateKey: "PrivateKey-1"},
+		},
+		Chains: []network.ChainSummary{
+			{Name: "C", ID: "chain1", SubnetID: "subnet1", VMID: "vm1"},
+		},
+	}
+
+	for _, format := range []string{network.TextSummaryFormat, network.MarkdownSummaryFormat} {
+		out, err := network.FormatSummary(summary, format)
+		require.NoError(err)
+		for _, s := range []string{"devnet", "owned by the wallet team", "ci=true", "1337", "node1", "NodeID-1", "http://127.0.0.1:9650", "v1.10.15", "X-custom1", "PrivateKey-1", "chain1"} {
+			require.True(strings.Contains(string(out), s), "%s summary doesn't contain %q", format, s)
+		}
+	}
+
+	out, err := network.FormatSummary(summary, network.JSONSummaryFormat)
+	require.NoError(err)
+	var decoded network.Summary
+	require.NoError(json.Unmarshal(out, &decoded))
+	require.Equal(summary, decoded)
+
+	_, err = network.FormatSummary(summary, "yaml")
+	require.Error(err)
+
+	network.RegisterSummaryFormatter("yaml", func(network.Summary) ([]byte, error) {
+		return []byte("networkID: 1337"), nil
+	})
+	out, err = network.FormatSummary(summary, "yaml")
+	require.NoError(err)
+	require.Equal("networkID: 1337", string(out))
+}
+
+// TestNewSummaryNodeURIs tests that the node URIs of the summary are the
+// ones API clients use, eg the API proxy when the node API uses TLS
+func TestNewSummaryNodeURIs(t *testing.T) {
+	require := require.New(t)
+
+	nodeID := ids.GenerateTestNodeID()
+	node1 := nodemocks.NewNode(t)
+	node1.On("GetNodeID").Return(nodeID)
+	node1.On("GetURI").Return("http://127.0.0.1:40000")
+	node1.On("GetPaused").Return(true)
+	node1.On("GetBinaryVersion").Return("v1.10.15")
+	net := mocks.NewNetwork(t)
+	net.On("GetNetworkID").Return(uint32(1337), nil)
+	net.On("GetAllNodes").Return(map[string]node.Node{"node1": node1}, nil)
+	net.On("GetMetadata").Return(network.Metadata{})
+
+	summary, err := network.NewSummary(context.Background(), net, nil)
+	require.NoError(err)
+	require.Equal([]network.NodeSummary{{
+		Name:    "node1",
+		NodeID:  nodeID.String(),
+		URI:     "http://127.0.0.1:40000",
+		Paused:  true,
+		Version: "v1.10.15",
+	}}, summary.Nodes)
+}
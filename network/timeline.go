@@ -0,0 +1,208 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// NodeStatus is the status of a node on a StatusSample
+type NodeStatus struct {
+	Name    string `json:"name"`
+	Paused  bool   `json:"paused,omitempty"`
+	Healthy bool   `json:"healthy"`
+	// Heights of the last accepted P-chain and C-chain blocks
+	PChainHeight uint64 `json:"pChainHeight"`
+	CChainHeight uint64 `json:"cChainHeight"`
+	// Number of connected peers
+	Peers int `json:"peers"`
+	// Errors found getting the status, if any.
+	// The values that couldn't be got are left zero.
+	Error string `json:"error,omitempty"`
+}
+
+// StatusSample is the status of the nodes of a network at some time
+type StatusSample struct {
+	Time time.Time `json:"time"`
+	// True if all the nodes not paused are healthy
+	Healthy bool `json:"healthy"`
+	// Sorted by name
+	Nodes []NodeStatus `json:"nodes"`
+}
+
+// SampleStatus gets the health, chain heights and peers of the nodes
+// of [net]. Nodes that can't be reached are reported as unhealthy, with
+// the errors found in their status.
+func SampleStatus(ctx context.Context, net Network) (StatusSample, error) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return StatusSample{}, err
+	}
+	sample := StatusSample{
+		Time:    time.Now(),
+		Healthy: true,
+		Nodes:   make([]NodeStatus, 0, len(nodes)),
+	}
+	for nodeName, node := range nodes {
+		nodeStatus := NodeStatus{Name: nodeName}
+		if node.GetPaused() {
+			nodeStatus.Paused = true
+			sample.Nodes = append(sample.Nodes, nodeStatus)
+			continue
+		}
+		client := node.GetAPIClient()
+		errs := wrappers.Errs{}
+		if reply, err := client.HealthAPI().Health(ctx, nil); err != nil {
+			errs.Add(fmt.Errorf("couldn't get health: %w", err))
+		} else {
+			nodeStatus.Healthy = reply.Healthy
+		}
+		if height, err := client.PChainAPI().GetHeight(ctx); err != nil {
+			errs.Add(fmt.Errorf("couldn't get P-chain height: %w", err))
+		} else {
+			nodeStatus.PChainHeight = height
+		}
+		if height, err := client.CChainEthAPI().BlockNumber(ctx); err != nil {
+			errs.Add(fmt.Errorf("couldn't get C-chain height: %w", err))
+		} else {
+			nodeStatus.CChainHeight = height
+		}
+		if peers, err := client.InfoAPI().Peers(ctx); err != nil {
+			errs.Add(fmt.Errorf("couldn't get peers: %w", err))
+		} else {
+			nodeStatus.Peers = len(peers)
+		}
+		if errs.Errored() {
+			nodeStatus.Error = errs.Err.Error()
+		}
+		sample.Healthy = sample.Healthy && nodeStatus.Healthy
+		sample.Nodes = append(sample.Nodes, nodeStatus)
+	}
+	sort.Slice(sample.Nodes, func(i, j int) bool {
+		return sample.Nodes[i].Name < sample.Nodes[j].Name
+	})
+	return sample, nil
+}
+
+// StatusTimeline persists status samples of a network into an embedded
+// database, so soak tests can be looked into once done, eg to find when
+// the network degraded. The database outlives the network, and can be
+// opened again to be queried.
+// It is safe for concurrent use.
+type StatusTimeline struct {
+	db database.Database
+}
+
+// OpenStatusTimeline opens the timeline stored at dir [dbDir], creating
+// it if needed. Close must be called once done.
+func OpenStatusTimeline(dbDir string) (*StatusTimeline, error) {
+	db, err := leveldb.New(dbDir, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open status timeline at %s: %w", dbDir, err)
+	}
+	return &StatusTimeline{db: db}, nil
+}
+
+// Close closes the timeline database
+func (t *StatusTimeline) Close() error {
+	return t.db.Close()
+}
+
+// Samples are keyed by time, so iteration follows the timeline
+func timelineKey(sampleTime time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(sampleTime.UnixNano()))
+	return key
+}
+
+// Add stores [sample] in the timeline, replacing the sample of the
+// same time if any
+func (t *StatusTimeline) Add(sample StatusSample) error {
+	value, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	return t.db.Put(timelineKey(sample.Time), value)
+}
+
+// Record samples the status of [net], and adds the sample to the timeline
+func (t *StatusTimeline) Record(ctx context.Context, net Network) (StatusSample, error) {
+	sample, err := SampleStatus(ctx, net)
+	if err != nil {
+		return StatusSample{}, err
+	}
+	return sample, t.Add(sample)
+}
+
+// Run records the status of [net] every [interval], until [ctx] is done
+// or the network is stopped. Failures to record are logged, and don't
+// stop the recording.
+func (t *StatusTimeline) Run(ctx context.Context, log logging.Logger, net Network, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sampleCtx, cancel := context.WithTimeout(ctx, interval)
+		_, err := t.Record(sampleCtx, net)
+		cancel()
+		switch {
+		case errors.Is(err, ErrStopped):
+			return
+		case err != nil && ctx.Err() == nil:
+			log.Warn("couldn't record network status", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Query returns the samples taken from [start] to [end], oldest first.
+// A zero [start] means from the first sample, and a zero [end] up to
+// the last one.
+func (t *StatusTimeline) Query(start time.Time, end time.Time) ([]StatusSample, error) {
+	var it database.Iterator
+	if start.IsZero() {
+		it = t.db.NewIterator()
+	} else {
+		it = t.db.NewIteratorWithStart(timelineKey(start))
+	}
+	defer it.Release()
+
+	samples := []StatusSample{}
+	for it.Next() {
+		sample := StatusSample{}
+		if err := json.Unmarshal(it.Value(), &sample); err != nil {
+			return nil, fmt.Errorf("couldn't decode status sample: %w", err)
+		}
+		if !end.IsZero() && sample.Time.After(end) {
+			break
+		}
+		samples = append(samples, sample)
+	}
+	return samples, it.Error()
+}
+
+// HealthChanges returns the samples of [samples] where the network health
+// differs from the previous sample, starting with the first sample
+func HealthChanges(samples []StatusSample) []StatusSample {
+	changes := []StatusSample{}
+	for i, sample := range samples {
+		if i == 0 || sample.Healthy != samples[i-1].Healthy {
+			changes = append(changes, sample)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,157 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	nodemocks "github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/ava-labs/avalanchego/api/health"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// statusHealthClient is a health client that only implements Health
+type statusHealthClient struct {
+	health.Client
+
+	healthy bool
+}
+
+func (c *statusHealthClient) Health(context.Context, []string, ...rpc.Option) (*health.APIReply, error) {
+	return &health.APIReply{Healthy: c.healthy}, nil
+}
+
+// statusPChainClient is a P-chain client that only implements GetHeight
+type statusPChainClient struct {
+	platformvm.Client
+
+	height uint64
+}
+
+func (c *statusPChainClient) GetHeight(context.Context, ...rpc.Option) (uint64, error) {
+	return c.height, nil
+}
+
+// Returns a network with a healthy node1, an unreachable node2 on
+// C-chain, and a paused node3
+func newStatusTestNetwork(t *testing.T) *mocks.Network {
+	infoClient := &peersInfoClient{}
+	infoClient.setPeers(ids.GenerateTestNodeID(), ids.GenerateTestNodeID())
+	ethClient := &apimocks.EthClient{}
+	ethClient.On("BlockNumber", mock.Anything).Return(uint64(7), nil)
+	unreachableEthClient := &apimocks.EthClient{}
+	unreachableEthClient.On("BlockNumber", mock.Anything).Return(uint64(0), errors.New("connection refused"))
+
+	nodes := map[string]node.Node{}
+	for nodeName, ethClient := range map[string]*apimocks.EthClient{"node1": ethClient, "node2": unreachableEthClient} {
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(&statusHealthClient{healthy: nodeName == "node1"})
+		client.On("PChainAPI").Return(&statusPChainClient{height: 5})
+		client.On("CChainEthAPI").Return(ethClient)
+		client.On("InfoAPI").Return(infoClient)
+		n := nodemocks.NewNode(t)
+		n.On("GetPaused").Return(false)
+		n.On("GetAPIClient").Return(client)
+		nodes[nodeName] = n
+	}
+	paused := nodemocks.NewNode(t)
+	paused.On("GetPaused").Return(true)
+	nodes["node3"] = paused
+	net := mocks.NewNetwork(t)
+	net.On("GetAllNodes").Return(nodes, nil)
+	return net
+}
+
+func TestSampleStatus(t *testing.T) {
+	require := require.New(t)
+
+	sample, err := network.SampleStatus(context.Background(), newStatusTestNetwork(t))
+	require.NoError(err)
+	require.False(sample.Healthy)
+	require.Len(sample.Nodes, 3)
+	require.Equal(network.NodeStatus{
+		Name:         "node1",
+		Healthy:      true,
+		PChainHeight: 5,
+		CChainHeight: 7,
+		Peers:        2,
+	}, sample.Nodes[0])
+	require.False(sample.Nodes[1].Healthy)
+	require.Equal(uint64(5), sample.Nodes[1].PChainHeight)
+	require.Zero(sample.Nodes[1].CChainHeight)
+	require.Contains(sample.Nodes[1].Error, "connection refused")
+	require.Equal(network.NodeStatus{Name: "node3", Paused: true}, sample.Nodes[2])
+}
+
+func TestStatusTimeline(t *testing.T) {
+	require := require.New(t)
+	dbDir := t.TempDir()
+
+	timeline, err := network.OpenStatusTimeline(dbDir)
+	require.NoError(err)
+	// samples added before the recorded one
+	start := time.Now().Add(-time.Hour)
+	samples := []network.StatusSample{}
+	for i, healthy := range []bool{true, true, false, false, true} {
+		sample := network.StatusSample{
+			Time:    start.Add(time.Duration(i) * time.Minute),
+			Healthy: healthy,
+			Nodes:   []network.NodeStatus{{Name: "node1", Healthy: healthy, PChainHeight: uint64(i)}},
+		}
+		require.NoError(timeline.Add(sample))
+		samples = append(samples, sample)
+	}
+	recorded, err := timeline.Record(context.Background(), newStatusTestNetwork(t))
+	require.NoError(err)
+	require.NoError(timeline.Close())
+
+	// the timeline can be queried once reopened
+	timeline, err = network.OpenStatusTimeline(dbDir)
+	require.NoError(err)
+	defer timeline.Close()
+	all, err := timeline.Query(time.Time{}, time.Time{})
+	require.NoError(err)
+	require.Len(all, len(samples)+1)
+	require.Equal(recorded.Nodes, all[len(samples)].Nodes)
+	queried, err := timeline.Query(start.Add(time.Minute), start.Add(3*time.Minute))
+	require.NoError(err)
+	require.Len(queried, 3)
+	for i, sample := range queried {
+		require.True(samples[i+1].Time.Equal(sample.Time))
+		require.Equal(samples[i+1].Nodes, sample.Nodes)
+	}
+
+	changes := network.HealthChanges(all[:len(samples)])
+	require.Len(changes, 3)
+	require.True(samples[0].Time.Equal(changes[0].Time))
+	require.True(samples[2].Time.Equal(changes[1].Time))
+	require.True(samples[4].Time.Equal(changes[2].Time))
+}
+
+func TestStatusTimelineRun(t *testing.T) {
+	require := require.New(t)
+
+	timeline, err := network.OpenStatusTimeline(t.TempDir())
+	require.NoError(err)
+	defer timeline.Close()
+
+	net := mocks.NewNetwork(t)
+	net.On("GetAllNodes").Return(map[string]node.Node{}, nil).Twice()
+	net.On("GetAllNodes").Return(nil, network.ErrStopped).Once()
+	// returns once the network is stopped
+	timeline.Run(context.Background(), logging.NoLog{}, net, 10*time.Millisecond)
+	samples, err := timeline.Query(time.Time{}, time.Time{})
+	require.NoError(err)
+	require.Len(samples, 2)
+	require.True(samples[0].Healthy)
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"golang.org/x/exp/maps"
+)
+
+// UpgradeSpec defines the rule set a network is upgraded to by UpgradeNetwork
+type UpgradeSpec struct {
+	// If not empty, all nodes are restarted with this binary
+	BinaryPath string
+	// If not empty, all nodes are restarted with this plugin dir
+	PluginDir string
+	// Chain alias or ID --> upgrade file contents, written for all nodes
+	UpgradeConfigs map[string]string
+	// Chain alias or ID --> chain config file contents, written for all nodes
+	ChainConfigs map[string]string
+	// If non-nil, called for each node after the network is healthy again,
+	// to check that the node accepted the upgrade
+	Verify func(context.Context, node.Node) error
+}
+
+// UpgradeNetwork restarts every node of [net] with the binary and config
+// files given by [spec], and then checks the upgrade with VerifyUpgrade.
+// Nodes are restarted one after the other, while the others run. To stop
+// the whole network and start it again with the new files, see
+// local.UpgradeNetwork.
+// Nodes keep their identity and database, so chain genesis can't be changed
+// this way: rule changes must be given as upgrade or chain config files.
+func UpgradeNetwork(ctx context.Context, net Network, spec UpgradeSpec) error {
+	nodeNames, err := net.GetNodeNames()
+	if err != nil {
+		return err
+	}
+	for _, nodeName := range nodeNames {
+		if err := restartNodeWithSpec(ctx, net, nodeName, spec); err != nil {
+			return err
+		}
+	}
+	return VerifyUpgrade(ctx, net, spec)
+}
+
+// VerifyUpgrade waits for upgraded network [net] to be healthy, and then
+// verifies each node with [spec.Verify]
+func VerifyUpgrade(ctx context.Context, net Network, spec UpgradeSpec) error {
+	if err := net.Healthy(ctx); err != nil {
+		return fmt.Errorf("network not healthy after upgrade: %w", err)
+	}
+	if spec.Verify == nil {
+		return nil
+	}
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return err
+	}
+	nodeNames := maps.Keys(nodes)
+	sort.Strings(nodeNames)
+	for _, nodeName := range nodeNames {
+		if err := spec.Verify(ctx, nodes[nodeName]); err != nil {
+			return fmt.Errorf("node %q failed upgrade verification: %w", nodeName, err)
+		}
+	}
+	return nil
+}
+
+// UpgradeNode restarts node [nodeName] of [net] with the binary and config
+// files given by [spec], waits for the network to be healthy, and then
+// verifies the node with [spec.Verify].
+func UpgradeNode(ctx context.Context, net Network, nodeName string, spec UpgradeSpec) error {
+	if err := restartNodeWithSpec(ctx, net, nodeName, spec); err != nil {
+		return err
+	}
+	if err := net.Healthy(ctx); err != nil {
+		return fmt.Errorf("network not healthy after upgrading node %q: %w", nodeName, err)
+	}
+	if spec.Verify == nil {
+		return nil
+	}
+	node, err := net.GetNode(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+	if err := spec.Verify(ctx, node); err != nil {
+		return fmt.Errorf("node %q failed upgrade verification: %w", nodeName, err)
+	}
+	return nil
+}
+
+// RollingUpgrade upgrades the nodes of [net] one at a time with UpgradeNode,
+// waiting for the network to be healthy between node restarts, so the
+// network keeps running during the upgrade, with nodes of both versions.
+// Stops at the first node failing to upgrade, leaving the following ones
+// with the previous binary and config files.
+func RollingUpgrade(ctx context.Context, net Network, spec UpgradeSpec) error {
+	nodeNames, err := net.GetNodeNames()
+	if err != nil {
+		return err
+	}
+	for _, nodeName := range nodeNames {
+		if err := UpgradeNode(ctx, net, nodeName, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restarts node [nodeName] with the binary and config files of [spec]
+func restartNodeWithSpec(ctx context.Context, net Network, nodeName string, spec UpgradeSpec) error {
+	if err := net.RestartNode(
+		ctx,
+		nodeName,
+		spec.BinaryPath,
+		spec.PluginDir,
+		"",
+		maps.Clone(spec.ChainConfigs),
+		maps.Clone(spec.UpgradeConfigs),
+		nil,
+	); err != nil {
+		return fmt.Errorf("failure restarting node %q: %w", nodeName, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	nodemocks "github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeNetwork(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	upgradeConfigs := map[string]string{"C": `{"precompileUpgrades":[]}`}
+
+	net := mocks.NewNetwork(t)
+	node1 := nodemocks.NewNode(t)
+	node2 := nodemocks.NewNode(t)
+	net.On("GetNodeNames").Return([]string{"node1", "node2"}, nil)
+	for _, nodeName := range []string{"node1", "node2"} {
+		net.On("RestartNode", ctx, nodeName, "/new/avalanchego", "", "", map[string]string(nil), upgradeConfigs, map[string]string(nil)).Return(nil).Once()
+	}
+	net.On("Healthy", ctx).Return(nil)
+	net.On("GetAllNodes").Return(map[string]node.Node{"node1": node1, "node2": node2}, nil)
+
+	verified := []node.Node{}
+	require.NoError(network.UpgradeNetwork(ctx, net, network.UpgradeSpec{
+		BinaryPath:     "/new/avalanchego",
+		UpgradeConfigs: upgradeConfigs,
+		Verify: func(_ context.Context, n node.Node) error {
+			verified = append(verified, n)
+			return nil
+		},
+	}))
+	require.Equal([]node.Node{node1, node2}, verified)
+
+	// verification failure is reported
+	errVerify := errors.New("upgrade not activated")
+	net.On("RestartNode", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	err := network.UpgradeNetwork(ctx, net, network.UpgradeSpec{
+		Verify: func(context.Context, node.Node) error {
+			return errVerify
+		},
+	})
+	require.ErrorIs(err, errVerify)
+}
+
+func TestRollingUpgrade(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	net := mocks.NewNetwork(t)
+	node1 := nodemocks.NewNode(t)
+	node2 := nodemocks.NewNode(t)
+	// records the order of the node restarts and health checks
+	calls := []string{}
+	net.On("GetNodeNames").Return([]string{"node1", "node2", "node3"}, nil)
+	for _, nodeName := range []string{"node1", "node2"} {
+		nodeName := nodeName
+		net.On("RestartNode", ctx, nodeName, "/new/avalanchego", "", "", map[string]string(nil), map[string]string(nil), map[string]string(nil)).
+			Run(func(mock.Arguments) {
+				calls = append(calls, "restart "+nodeName)
+			}).
+			Return(nil).Once()
+	}
+	net.On("Healthy", ctx).Run(func(mock.Arguments) {
+		calls = append(calls, "healthy")
+	}).Return(nil)
+	net.On("GetNode", mock.Anything, "node1").Return(node1, nil)
+	net.On("GetNode", mock.Anything, "node2").Return(node2, nil)
+
+	// the upgrade stops at the first node failing verification
+	errVerify := errors.New("upgrade not activated")
+	err := network.RollingUpgrade(ctx, net, network.UpgradeSpec{
+		BinaryPath: "/new/avalanchego",
+		Verify: func(_ context.Context, n node.Node) error {
+			if n == node2 {
+				return errVerify
+			}
+			return nil
+		},
+	})
+	require.ErrorIs(err, errVerify)
+	require.Equal([]string{"restart node1", "healthy", "restart node2", "healthy"}, calls)
+}
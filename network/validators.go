@@ -0,0 +1,259 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoutils "github.com/ava-labs/avalanchego/utils"
+)
+
+// How often WaitValidatorChanges gets the validator set
+const DefaultValidatorsPollInterval = time.Second
+
+var ErrNoRunningNodes = errors.New("network has no running nodes")
+
+// Validator is a validator of a ValidatorSet
+type Validator struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	// ID of the tx that added the validator
+	TxID ids.ID `json:"txID"`
+	// Weight of the validator stake, without its delegations
+	Weight uint64 `json:"weight"`
+	// Weight delegated to the validator
+	DelegatorWeight uint64    `json:"delegatorWeight,omitempty"`
+	StartTime       time.Time `json:"startTime"`
+	EndTime         time.Time `json:"endTime"`
+}
+
+// ValidatorSet is the set of current validators of a subnet at a point in
+// time, as seen by a node
+type ValidatorSet struct {
+	// constants.PrimaryNetworkID for the primary network
+	SubnetID ids.ID `json:"subnetID"`
+	// P-chain height of the node the set was got from
+	Height uint64 `json:"height"`
+	// Name of the node the set was got from
+	NodeName   string                   `json:"nodeName"`
+	Validators map[ids.NodeID]Validator `json:"validators"`
+}
+
+// ValidatorChanges are changes between two validator sets of a subnet
+type ValidatorChanges struct {
+	// Nodes that became validators
+	Added []ids.NodeID `json:"added,omitempty"`
+	// Nodes that stopped being validators
+	Removed []ids.NodeID `json:"removed,omitempty"`
+	// New weights of the validators whose weight changed.
+	// When expected, may also give the weights of added validators.
+	Weights map[ids.NodeID]uint64 `json:"weights,omitempty"`
+	// New end times of the validators whose end time changed.
+	// When expected, may also give the end times of added validators.
+	EndTimes map[ids.NodeID]time.Time `json:"endTimes,omitempty"`
+}
+
+// GetValidatorSet returns the current validators of subnet [subnetID]
+// (constants.PrimaryNetworkID for the primary network), as seen by the
+// first running node of [net] by name.
+// Returns ErrNoRunningNodes if all the nodes are paused.
+func GetValidatorSet(ctx context.Context, net Network, subnetID ids.ID) (ValidatorSet, error) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return ValidatorSet{}, err
+	}
+	nodeNames := make([]string, 0, len(nodes))
+	for nodeName, node := range nodes {
+		if !node.GetPaused() {
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+	if len(nodeNames) == 0 {
+		return ValidatorSet{}, ErrNoRunningNodes
+	}
+	sort.Strings(nodeNames)
+	return getNodeValidatorSet(ctx, nodeNames[0], nodes[nodeNames[0]], subnetID)
+}
+
+// Returns the current validators of subnet [subnetID] as seen by [node]
+func getNodeValidatorSet(ctx context.Context, nodeName string, node node.Node, subnetID ids.ID) (ValidatorSet, error) {
+	pClient := node.GetAPIClient().PChainAPI()
+	height, err := pClient.GetHeight(ctx)
+	if err != nil {
+		return ValidatorSet{}, fmt.Errorf("couldn't get P-chain height of node %q: %w", nodeName, err)
+	}
+	vdrs, err := pClient.GetCurrentValidators(ctx, subnetID, nil)
+	if err != nil {
+		return ValidatorSet{}, fmt.Errorf("couldn't get validators of subnet %s from node %q: %w", subnetID, nodeName, err)
+	}
+	set := ValidatorSet{
+		SubnetID:   subnetID,
+		Height:     height,
+		NodeName:   nodeName,
+		Validators: make(map[ids.NodeID]Validator, len(vdrs)),
+	}
+	for _, vdr := range vdrs {
+		validator := Validator{
+			NodeID:    vdr.NodeID,
+			TxID:      vdr.TxID,
+			Weight:    vdr.Weight,
+			StartTime: time.Unix(int64(vdr.StartTime), 0),
+			EndTime:   time.Unix(int64(vdr.EndTime), 0),
+		}
+		if vdr.DelegatorWeight != nil {
+			validator.DelegatorWeight = *vdr.DelegatorWeight
+		}
+		set.Validators[vdr.NodeID] = validator
+	}
+	return set, nil
+}
+
+// TotalWeight returns the sum of the validator weights, without delegations
+func (s ValidatorSet) TotalWeight() uint64 {
+	total := uint64(0)
+	for _, validator := range s.Validators {
+		total += validator.Weight
+	}
+	return total
+}
+
+// Changes returns the changes from [s] to the later set [later].
+// Node IDs are sorted.
+func (s ValidatorSet) Changes(later ValidatorSet) ValidatorChanges {
+	changes := ValidatorChanges{}
+	for nodeID, validator := range later.Validators {
+		before, ok := s.Validators[nodeID]
+		if !ok {
+			changes.Added = append(changes.Added, nodeID)
+			continue
+		}
+		if validator.Weight != before.Weight {
+			if changes.Weights == nil {
+				changes.Weights = map[ids.NodeID]uint64{}
+			}
+			changes.Weights[nodeID] = validator.Weight
+		}
+		if !validator.EndTime.Equal(before.EndTime) {
+			if changes.EndTimes == nil {
+				changes.EndTimes = map[ids.NodeID]time.Time{}
+			}
+			changes.EndTimes[nodeID] = validator.EndTime
+		}
+	}
+	for nodeID := range s.Validators {
+		if _, ok := later.Validators[nodeID]; !ok {
+			changes.Removed = append(changes.Removed, nodeID)
+		}
+	}
+	avagoutils.Sort(changes.Added)
+	avagoutils.Sort(changes.Removed)
+	return changes
+}
+
+// AssertChanges returns an error describing how the changes from [s] to
+// the later set [later] differ from [expected], or nil if they are the
+// ones expected. Validators not in [expected] must be unchanged.
+func (s ValidatorSet) AssertChanges(later ValidatorSet, expected ValidatorChanges) error {
+	if s.SubnetID != later.SubnetID {
+		return fmt.Errorf("validator sets of different subnets %s and %s", s.SubnetID, later.SubnetID)
+	}
+	changes := s.Changes(later)
+	mismatches := []string{}
+	mismatches = append(mismatches, nodeIDsMismatches("added", expected.Added, changes.Added)...)
+	mismatches = append(mismatches, nodeIDsMismatches("removed", expected.Removed, changes.Removed)...)
+	for _, nodeID := range sortedKeys(expected.Weights, changes.Weights) {
+		expectedWeight, isExpected := expected.Weights[nodeID]
+		validator, isValidator := later.Validators[nodeID]
+		switch {
+		case !isExpected:
+			mismatches = append(mismatches, fmt.Sprintf("weight of %s unexpectedly changed from %d to %d", nodeID, s.Validators[nodeID].Weight, validator.Weight))
+		case !isValidator:
+			mismatches = append(mismatches, fmt.Sprintf("%s expected with weight %d, but is not a validator", nodeID, expectedWeight))
+		case validator.Weight != expectedWeight:
+			mismatches = append(mismatches, fmt.Sprintf("weight of %s expected to be %d, but is %d", nodeID, expectedWeight, validator.Weight))
+		}
+	}
+	for _, nodeID := range sortedKeys(expected.EndTimes, changes.EndTimes) {
+		expectedEndTime, isExpected := expected.EndTimes[nodeID]
+		validator, isValidator := later.Validators[nodeID]
+		switch {
+		case !isExpected:
+			mismatches = append(mismatches, fmt.Sprintf("end time of %s unexpectedly changed from %s to %s", nodeID, s.Validators[nodeID].EndTime, validator.EndTime))
+		case !isValidator:
+			mismatches = append(mismatches, fmt.Sprintf("%s expected with end time %s, but is not a validator", nodeID, expectedEndTime))
+		case !validator.EndTime.Equal(expectedEndTime):
+			mismatches = append(mismatches, fmt.Sprintf("end time of %s expected to be %s, but is %s", nodeID, expectedEndTime, validator.EndTime))
+		}
+	}
+	if len(mismatches) != 0 {
+		return fmt.Errorf("unexpected validator set changes of subnet %s from height %d to %d: %s",
+			s.SubnetID, s.Height, later.Height, strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// WaitValidatorChanges gets the validator set of the subnet of [before]
+// from [net] until its changes from [before] are the ones [expected], or
+// [ctx] is done, in which case the last mismatch is returned.
+// Returns the validator set with the expected changes.
+func WaitValidatorChanges(ctx context.Context, net Network, before ValidatorSet, expected ValidatorChanges) (ValidatorSet, error) {
+	ticker := time.NewTicker(DefaultValidatorsPollInterval)
+	defer ticker.Stop()
+	for {
+		later, err := GetValidatorSet(ctx, net, before.SubnetID)
+		if err == nil {
+			err = before.AssertChanges(later, expected)
+			if err == nil {
+				return later, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ValidatorSet{}, fmt.Errorf("%w: %s", ctx.Err(), err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns the mismatches between the [expected] and [actual] node IDs
+// that were [kind] (eg added)
+func nodeIDsMismatches(kind string, expected []ids.NodeID, actual []ids.NodeID) []string {
+	actualSet := make(map[ids.NodeID]struct{}, len(actual))
+	for _, nodeID := range actual {
+		actualSet[nodeID] = struct{}{}
+	}
+	expectedSet := make(map[ids.NodeID]struct{}, len(expected))
+	mismatches := []string{}
+	for _, nodeID := range expected {
+		expectedSet[nodeID] = struct{}{}
+		if _, ok := actualSet[nodeID]; !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s expected to be %s, but was not", nodeID, kind))
+		}
+	}
+	for _, nodeID := range actual {
+		if _, ok := expectedSet[nodeID]; !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s unexpectedly %s", nodeID, kind))
+		}
+	}
+	return mismatches
+}
+
+// Returns the sorted node IDs that are keys of any of [maps]
+func sortedKeys[T any](maps ...map[ids.NodeID]T) []ids.NodeID {
+	keySet := map[ids.NodeID]struct{}{}
+	for _, m := range maps {
+		for nodeID := range m {
+			keySet[nodeID] = struct{}{}
+		}
+	}
+	keys := make([]ids.NodeID, 0, len(keySet))
+	for nodeID := range keySet {
+		keys = append(keys, nodeID)
+	}
+	avagoutils.Sort(keys)
+	return keys
+}
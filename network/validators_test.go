@@ -0,0 +1,126 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	nodemocks "github.com/ava-labs/avalanche-network-runner/network/node/mocks"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+// validatorsPChainClient is a P-chain client that only implements
+// GetHeight and GetCurrentValidators, whose validators are given by
+// the successive calls to [validators]
+type validatorsPChainClient struct {
+	platformvm.Client
+
+	height     uint64
+	validators func() []platformvm.ClientPermissionlessValidator
+}
+
+func (c *validatorsPChainClient) GetHeight(context.Context, ...rpc.Option) (uint64, error) {
+	c.height++
+	return c.height, nil
+}
+
+func (c *validatorsPChainClient) GetCurrentValidators(context.Context, ids.ID, []ids.NodeID, ...rpc.Option) ([]platformvm.ClientPermissionlessValidator, error) {
+	return c.validators(), nil
+}
+
+func newTestValidator(nodeID ids.NodeID, weight uint64, endTime uint64) platformvm.ClientPermissionlessValidator {
+	return platformvm.ClientPermissionlessValidator{
+		ClientStaker: platformvm.ClientStaker{
+			NodeID:    nodeID,
+			Weight:    weight,
+			StartTime: 1,
+			EndTime:   endTime,
+		},
+	}
+}
+
+func TestValidatorSet(t *testing.T) {
+	require := require.New(t)
+
+	node1ID, node2ID, node3ID := ids.GenerateTestNodeID(), ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	calls := 0
+	pClient := &validatorsPChainClient{
+		validators: func() []platformvm.ClientPermissionlessValidator {
+			calls++
+			if calls == 1 {
+				return []platformvm.ClientPermissionlessValidator{
+					newTestValidator(node1ID, 100, 1000),
+					newTestValidator(node2ID, 100, 1000),
+				}
+			}
+			// node2 left, node3 joined, and node1 stakes for longer,
+			// seen from the third call on
+			if calls == 2 {
+				return []platformvm.ClientPermissionlessValidator{
+					newTestValidator(node1ID, 100, 1000),
+				}
+			}
+			return []platformvm.ClientPermissionlessValidator{
+				newTestValidator(node1ID, 100, 2000),
+				newTestValidator(node3ID, 50, 1000),
+			}
+		},
+	}
+	client := &apimocks.Client{}
+	client.On("PChainAPI").Return(pClient)
+	node1 := nodemocks.NewNode(t)
+	node1.On("GetPaused").Return(false)
+	node1.On("GetAPIClient").Return(client)
+	paused := nodemocks.NewNode(t)
+	paused.On("GetPaused").Return(true)
+	net := mocks.NewNetwork(t)
+	net.On("GetAllNodes").Return(map[string]node.Node{"node1": node1, "node0": paused}, nil)
+
+	before, err := network.GetValidatorSet(context.Background(), net, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal("node1", before.NodeName)
+	require.Equal(uint64(1), before.Height)
+	require.Len(before.Validators, 2)
+	require.Equal(time.Unix(1000, 0), before.Validators[node2ID].EndTime)
+	require.Equal(uint64(200), before.TotalWeight())
+
+	expected := network.ValidatorChanges{
+		Added:    []ids.NodeID{node3ID},
+		Removed:  []ids.NodeID{node2ID},
+		Weights:  map[ids.NodeID]uint64{node3ID: 50},
+		EndTimes: map[ids.NodeID]time.Time{node1ID: time.Unix(2000, 0)},
+	}
+	later, err := network.WaitValidatorChanges(context.Background(), net, before, expected)
+	require.NoError(err)
+	require.Equal(3, calls)
+	require.Equal(uint64(3), later.Height)
+	require.NoError(before.AssertChanges(later, expected))
+
+	changes := before.Changes(later)
+	require.Equal([]ids.NodeID{node3ID}, changes.Added)
+	require.Equal([]ids.NodeID{node2ID}, changes.Removed)
+	require.Empty(changes.Weights)
+	require.Equal(map[ids.NodeID]time.Time{node1ID: time.Unix(2000, 0)}, changes.EndTimes)
+
+	err = before.AssertChanges(later, network.ValidatorChanges{
+		Added:   []ids.NodeID{node3ID},
+		Weights: map[ids.NodeID]uint64{node3ID: 60},
+	})
+	require.ErrorContains(err, node2ID.String()+" unexpectedly removed")
+	require.ErrorContains(err, "weight of "+node3ID.String()+" expected to be 60, but is 50")
+	require.ErrorContains(err, "end time of "+node1ID.String()+" unexpectedly changed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = network.WaitValidatorChanges(ctx, net, before, network.ValidatorChanges{})
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.ErrorContains(err, "unexpectedly added")
+}
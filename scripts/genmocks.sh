@@ -9,6 +9,8 @@ fi
 mockery --dir api --name Client --output api/mocks/ --filename client.go
 mockery --dir api --name EthClient --output api/mocks/ --filename EthClient.go
 mockery --dir local --name NodeProcess --output local/mocks/ --filename node_process.go
+mockery --dir network --name Network --output network/mocks/ --filename network.go
+mockery --dir network/node --name Node --output network/node/mocks/ --filename node.go
 mockery --dir k8s --name dnsReachableChecker --output k8s/mocks/ --filename dns_checker.go --structname DnsReachableChecker
 
 echo "Successfully generated mock files"
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	avago_constants "github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 )
 
@@ -43,11 +45,8 @@ scrape_configs:
        - localhost:9100
        labels:
          alias: machine
-  - job_name: avalanchego
-    metrics_path: /ext/metrics
-    static_configs:
-      - targets:
 `
+	prometheusJobName = "avalanchego"
 )
 
 type localNetwork struct {
@@ -112,6 +111,15 @@ type localNetworkOptions struct {
 	dynamicPorts bool
 
 	networkID uint32
+
+	summaryFormat string
+	summaryFile   string
+
+	metadata network.Metadata
+
+	notifications      *network.NotificationsConfig
+	diskUsageThreshold int64
+	nodeOpenFilesLimit uint64
 }
 
 func newLocalNetwork(opts localNetworkOptions) (*localNetwork, error) {
@@ -165,6 +173,14 @@ func (lc *localNetwork) createConfig() error {
 	}
 
 	cfg.NetworkID = lc.options.networkID
+	cfg.Metadata = lc.options.metadata
+	cfg.Notifications = lc.options.notifications
+	cfg.DiskUsageThreshold = lc.options.diskUsageThreshold
+	cfg.NodeOpenFilesLimit = lc.options.nodeOpenFilesLimit
+
+	cfg.OnProgress = func(progress network.Progress) {
+		lc.log.Info(logging.Cyan.Wrap("node start progress"), zap.String("node", progress.NodeName), zap.String("phase", string(progress.Phase)))
+	}
 
 	for k, v := range lc.options.chainConfigs {
 		ov, ok := cfg.ChainConfigFiles[k]
@@ -219,6 +235,10 @@ func (lc *localNetwork) createConfig() error {
 		}
 	}
 
+	for _, warning := range network.LintConfig(&cfg).Warnings {
+		lc.log.Warn("suspicious network config", zap.String("warning", warning.String()))
+	}
+
 	lc.cfg = cfg
 	return nil
 }
@@ -247,7 +267,7 @@ func (lc *localNetwork) Start(ctx context.Context) error {
 	}
 
 	ux.Print(lc.log, logging.Blue.Wrap(logging.Bold.Wrap("create and run local network")))
-	nw, err := local.NewNetwork(lc.log, lc.cfg, lc.options.rootDataDir, lc.options.snapshotsDir, lc.options.reassignPortsIfUsed, lc.options.redirectNodesOutput, lc.options.redirectNodesOutput)
+	nw, err := local.NewNetwork(ctx, lc.log, lc.cfg, lc.options.rootDataDir, lc.options.snapshotsDir, lc.options.reassignPortsIfUsed, lc.options.redirectNodesOutput, lc.options.redirectNodesOutput)
 	if err != nil {
 		return err
 	}
@@ -545,7 +565,7 @@ func (lc *localNetwork) CreateSubnets(ctx context.Context, subnetSpecs []network
 
 // Loads a snapshot and sets [l.nw] to the network created from the snapshot.
 // Assumes [lc.lock] isn't held.
-func (lc *localNetwork) LoadSnapshot(snapshotName string) error {
+func (lc *localNetwork) LoadSnapshot(ctx context.Context, snapshotName string) error {
 	lc.lock.Lock()
 	defer lc.lock.Unlock()
 
@@ -559,6 +579,7 @@ func (lc *localNetwork) LoadSnapshot(snapshotName string) error {
 	}
 
 	nw, err := local.NewNetworkFromSnapshot(
+		ctx,
 		lc.log,
 		snapshotName,
 		lc.options.rootDataDir,
@@ -748,6 +769,40 @@ func (lc *localNetwork) awaitHealthyAndUpdateNetworkInfo(ctx context.Context) er
 		lc.log.Debug(fmt.Sprintf(logging.Cyan.Wrap("node-info: node-name %s, node-ID: %s, URI: %s"), nodeName, nodeInfo.Id, nodeInfo.Uri))
 	}
 
+	return lc.outputSummary(ctx)
+}
+
+// Prints the network summary and writes it into the summary file,
+// if enabled by options. Failing to write the file is only logged.
+// Assumes [lc.lock] is held.
+func (lc *localNetwork) outputSummary(ctx context.Context) error {
+	if lc.options.summaryFormat == "" && lc.options.summaryFile == "" {
+		return nil
+	}
+	format := lc.options.summaryFormat
+	if format == "" {
+		format = network.TextSummaryFormat
+	}
+	fundedKeys, err := local.FundedKeys(lc.nw)
+	if err != nil {
+		return err
+	}
+	summary, err := network.NewSummary(ctx, lc.nw, fundedKeys)
+	if err != nil {
+		return err
+	}
+	summaryBytes, err := network.FormatSummary(summary, format)
+	if err != nil {
+		return err
+	}
+	if lc.options.summaryFormat != "" {
+		fmt.Println(string(summaryBytes))
+	}
+	if lc.options.summaryFile != "" {
+		if err := os.WriteFile(lc.options.summaryFile, summaryBytes, 0o600); err != nil {
+			lc.log.Warn("couldn't write network summary", zap.String("path", lc.options.summaryFile), zap.Error(err))
+		}
+	}
 	return nil
 }
 
@@ -777,7 +832,7 @@ func (lc *localNetwork) updateNodeInfo() error {
 
 		lc.nodeInfos[name] = &rpcpb.NodeInfo{
 			Name:               node.GetName(),
-			Uri:                fmt.Sprintf("http://%s:%d", node.GetURL(), node.GetAPIPort()),
+			Uri:                node.GetURI(),
 			Id:                 node.GetNodeID().String(),
 			ExecPath:           node.GetBinaryPath(),
 			LogDir:             node.GetLogsDir(),
@@ -804,10 +859,23 @@ func (lc *localNetwork) generatePrometheusConf() error {
 		lc.prometheusConfPath = filepath.Join(lc.options.rootDataDir, prometheusConfFname)
 		lc.log.Info(fmt.Sprintf(logging.Cyan.Wrap("prometheus conf file %s"), lc.prometheusConfPath))
 	}
-	prometheusConf := prometheusConfCommon
+	targets := []network.ScrapeTarget{}
 	for _, nodeInfo := range lc.nodeInfos {
 		if !nodeInfo.Paused {
-			prometheusConf += "        - " + strings.TrimPrefix(nodeInfo.Uri, "http://") + "\n"
+			targets = append(targets, network.NewScrapeTarget(nodeInfo.Name, nodeInfo.Uri))
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].NodeName < targets[j].NodeName
+	})
+	scrapeConf, err := network.PrometheusScrapeConfig(prometheusJobName, lc.prometheusNetworkName(), targets)
+	if err != nil {
+		return err
+	}
+	prometheusConf := prometheusConfCommon
+	for _, line := range strings.SplitAfter(scrapeConf, "\n") {
+		if line != "" {
+			prometheusConf += "  " + line
 		}
 	}
 	file, err := os.Create(lc.prometheusConfPath)
@@ -819,6 +887,15 @@ func (lc *localNetwork) generatePrometheusConf() error {
 	return err
 }
 
+// Returns the network name used to label the node metrics in
+// the prometheus conf. Defaults to the network ID.
+func (lc *localNetwork) prometheusNetworkName() string {
+	if lc.options.metadata.Name != "" {
+		return lc.options.metadata.Name
+	}
+	return strconv.FormatUint(uint64(lc.networkID), 10)
+}
+
 // Assumes [lc.lock] isn't held.
 func (lc *localNetwork) Stop(ctx context.Context) {
 	lc.stopOnce.Do(func() {
@@ -50,6 +50,8 @@ const (
 	stopTimeout           = 30 * time.Second
 	defaultStartTimeout   = 5 * time.Minute
 	waitForHealthyTimeout = 5 * time.Minute
+	// max time between the expiry warning and the network expiry
+	networkExpiryWarningLead = 5 * time.Minute
 
 	networkRootDirPrefix   = "network"
 	TimeParseLayout        = "2006-01-02 15:04:05"
@@ -69,6 +71,7 @@ var (
 	ErrNoSubnetID             = errors.New("subnetID is missing")
 	ErrNoElasticSubnetSpec    = errors.New("no elastic subnet spec was provided")
 	ErrNoValidatorSpec        = errors.New("no validator spec was provided")
+	ErrNetworkExpired         = errors.New("network expired")
 )
 
 type Config struct {
@@ -80,6 +83,31 @@ type Config struct {
 	RedirectNodesOutput bool
 	SnapshotsDir        string
 	LogLevel            logging.Level
+	// If not empty, a network summary in this format (see network.FormatSummary)
+	// is printed each time the network becomes healthy
+	SummaryFormat string
+	// If not empty, the network summary is also written to this file
+	SummaryFile string
+	// Retention policy applied to the snapshots dir after each snapshot save
+	SnapshotsRetention network.SnapshotRetentionPolicy
+	// Name, description and tags given to the networks started by the server
+	NetworkMetadata network.Metadata
+	// If > 0, networks are stopped and removed once this time passes
+	// since they were started or loaded
+	NetworkTTL time.Duration
+	// If not nil, notifications about the networks started by the server
+	// are posted to a webhook
+	Notifications *network.NotificationsConfig
+	// If > 0, an EventDiskThresholdExceeded event is published when the root
+	// dir of a network started by the server grows larger than this
+	DiskUsageThreshold int64
+	// If > 0, open files limit of the nodes of the networks started by the server
+	NodeOpenFilesLimit uint64
+	// If not empty, the events and the matching node log lines of the networks
+	// are published into the broker at this url (see network.NewEventPublisher)
+	EventBridgeURL string
+	// Topics and log patterns of the event bridge
+	EventBridge network.EventBridgeConfig
 }
 
 type Server interface {
@@ -344,6 +372,12 @@ func (s *server) Start(_ context.Context, req *rpcpb.StartRequest) (*rpcpb.Start
 		reassignPortsIfUsed: req.GetReassignPortsIfUsed(),
 		dynamicPorts:        req.GetDynamicPorts(),
 		snapshotsDir:        s.cfg.SnapshotsDir,
+		summaryFormat:       s.cfg.SummaryFormat,
+		summaryFile:         s.cfg.SummaryFile,
+		metadata:            s.cfg.NetworkMetadata,
+		notifications:       s.cfg.Notifications,
+		diskUsageThreshold:  s.cfg.DiskUsageThreshold,
+		nodeOpenFilesLimit:  s.cfg.NodeOpenFilesLimit,
 	})
 	if err != nil {
 		return nil, err
@@ -378,6 +412,8 @@ func (s *server) Start(_ context.Context, req *rpcpb.StartRequest) (*rpcpb.Start
 	}
 	s.updateClusterInfo()
 	s.log.Info("network healthy")
+	s.scheduleNetworkExpiry(s.network)
+	s.startEventBridge(s.network)
 
 	strChainIDs := []string{}
 	for _, chainID := range chainIDs {
@@ -950,6 +986,69 @@ func (s *server) stopAndRemoveNetwork(err error) {
 	s.network = nil
 }
 
+// Stops and removes [nw] once [s.cfg.NetworkTTL] passes, unless it was
+// already removed, logging a warning shortly before.
+// Does nothing if no TTL is set.
+// Assumes [s.mu] is held.
+func (s *server) scheduleNetworkExpiry(nw *localNetwork) {
+	ttl := s.cfg.NetworkTTL
+	if ttl <= 0 {
+		return
+	}
+	s.log.Info("network will expire", zap.Duration("ttl", ttl))
+	warningLead := networkExpiryWarningLead
+	if warningLead > ttl/2 {
+		warningLead = ttl / 2
+	}
+	go func() {
+		select {
+		case <-nw.stopCh:
+			return
+		case <-time.After(ttl - warningLead):
+		}
+		s.log.Warn("network is about to expire", zap.Duration("remaining", warningLead))
+		select {
+		case <-nw.stopCh:
+			return
+		case <-time.After(warningLead):
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.network != nw {
+			return
+		}
+		s.log.Warn("network expired, stopping it", zap.Duration("ttl", ttl))
+		s.stopAndRemoveNetwork(ErrNetworkExpired)
+	}()
+}
+
+// Publishes the events and the matching node log lines of [nw] into the
+// broker at [s.cfg.EventBridgeURL], until the network is stopped.
+// Does nothing if no broker url is set.
+func (s *server) startEventBridge(nw *localNetwork) {
+	if s.cfg.EventBridgeURL == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-nw.stopCh
+		cancel()
+	}()
+	go func() {
+		defer cancel()
+		publisher, err := network.NewEventPublisher(ctx, s.cfg.EventBridgeURL)
+		if err != nil {
+			s.log.Warn("couldn't start event bridge", zap.Error(err))
+			return
+		}
+		defer publisher.Close()
+		s.log.Info("publishing network events", zap.String("broker", s.cfg.EventBridgeURL))
+		network.RunEventBridge(ctx, s.log, nw.nw, publisher, s.cfg.EventBridge)
+	}()
+}
+
 // TODO document this
 func (s *server) StreamStatus(req *rpcpb.StreamStatusRequest, stream rpcpb.ControlService_StreamStatusServer) (err error) {
 	s.log.Debug("StreamStatus")
@@ -1049,7 +1148,7 @@ func (s *server) recvLoop(stream rpcpb.ControlService_StreamStatusServer) error
 	}
 }
 
-func (s *server) AddNode(_ context.Context, req *rpcpb.AddNodeRequest) (*rpcpb.AddNodeResponse, error) {
+func (s *server) AddNode(ctx context.Context, req *rpcpb.AddNodeRequest) (*rpcpb.AddNodeResponse, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -1082,7 +1181,7 @@ func (s *server) AddNode(_ context.Context, req *rpcpb.AddNodeRequest) (*rpcpb.A
 		SubnetConfigFiles:  req.SubnetConfigs,
 	}
 
-	if _, err := s.network.nw.AddNode(nodeConfig); err != nil {
+	if _, err := s.network.nw.AddNode(ctx, nodeConfig); err != nil {
 		return nil, err
 	}
 
@@ -1260,7 +1359,7 @@ func (s *server) AttachPeer(ctx context.Context, req *rpcpb.AttachPeerRequest) (
 		return nil, ErrNotBootstrapped
 	}
 
-	node, err := s.network.nw.GetNode(req.NodeName)
+	node, err := s.network.nw.GetNode(ctx, req.NodeName)
 	if err != nil {
 		return nil, err
 	}
@@ -1303,7 +1402,7 @@ func (s *server) SendOutboundMessage(ctx context.Context, req *rpcpb.SendOutboun
 		return nil, ErrNotBootstrapped
 	}
 
-	node, err := s.network.nw.GetNode(req.NodeName)
+	node, err := s.network.nw.GetNode(ctx, req.NodeName)
 	if err != nil {
 		return nil, err
 	}
@@ -1312,7 +1411,7 @@ func (s *server) SendOutboundMessage(ctx context.Context, req *rpcpb.SendOutboun
 	return &rpcpb.SendOutboundMessageResponse{Sent: sent}, err
 }
 
-func (s *server) LoadSnapshot(_ context.Context, req *rpcpb.LoadSnapshotRequest) (*rpcpb.LoadSnapshotResponse, error) {
+func (s *server) LoadSnapshot(ctx context.Context, req *rpcpb.LoadSnapshotRequest) (*rpcpb.LoadSnapshotResponse, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -1351,6 +1450,8 @@ func (s *server) LoadSnapshot(_ context.Context, req *rpcpb.LoadSnapshotRequest)
 		logLevel:            s.cfg.LogLevel,
 		reassignPortsIfUsed: req.GetReassignPortsIfUsed(),
 		snapshotsDir:        s.cfg.SnapshotsDir,
+		summaryFormat:       s.cfg.SummaryFormat,
+		summaryFile:         s.cfg.SummaryFile,
 	})
 	if err != nil {
 		return nil, err
@@ -1361,7 +1462,7 @@ func (s *server) LoadSnapshot(_ context.Context, req *rpcpb.LoadSnapshotRequest)
 	}
 
 	// blocking load snapshot to soon get not found snapshot errors
-	if err := s.network.LoadSnapshot(req.SnapshotName); err != nil {
+	if err := s.network.LoadSnapshot(ctx, req.SnapshotName); err != nil {
 		s.log.Warn("snapshot load failed to complete", zap.Error(err))
 		s.stopAndRemoveNetwork(nil)
 		return nil, err
@@ -1377,6 +1478,8 @@ func (s *server) LoadSnapshot(_ context.Context, req *rpcpb.LoadSnapshotRequest)
 	}
 	s.updateClusterInfo()
 	s.log.Info("network healthy")
+	s.scheduleNetworkExpiry(s.network)
+	s.startEventBridge(s.network)
 
 	clusterInfo, err := deepCopy(s.clusterInfo)
 	if err != nil {
@@ -1401,12 +1504,21 @@ func (s *server) SaveSnapshot(ctx context.Context, req *rpcpb.SaveSnapshotReques
 		return nil, err
 	}
 
+	if s.cfg.SnapshotsRetention.MaxAge > 0 || s.cfg.SnapshotsRetention.MaxCount > 0 {
+		removed, err := s.network.nw.PruneSnapshots(ctx, s.cfg.SnapshotsRetention)
+		if err != nil {
+			s.log.Warn("snapshot pruning failed to complete", zap.Error(err))
+		} else if len(removed) > 0 {
+			s.log.Info("pruned snapshots", zap.Strings("snapshot-names", removed))
+		}
+	}
+
 	s.stopAndRemoveNetwork(nil)
 
 	return &rpcpb.SaveSnapshotResponse{SnapshotPath: snapshotPath}, nil
 }
 
-func (s *server) RemoveSnapshot(_ context.Context, req *rpcpb.RemoveSnapshotRequest) (*rpcpb.RemoveSnapshotResponse, error) {
+func (s *server) RemoveSnapshot(ctx context.Context, req *rpcpb.RemoveSnapshotRequest) (*rpcpb.RemoveSnapshotResponse, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -1416,14 +1528,14 @@ func (s *server) RemoveSnapshot(_ context.Context, req *rpcpb.RemoveSnapshotRequ
 		return nil, ErrNotBootstrapped
 	}
 
-	if err := s.network.nw.RemoveSnapshot(req.SnapshotName); err != nil {
+	if err := s.network.nw.RemoveSnapshot(ctx, req.SnapshotName); err != nil {
 		s.log.Warn("snapshot remove failed to complete", zap.Error(err))
 		return nil, err
 	}
 	return &rpcpb.RemoveSnapshotResponse{}, nil
 }
 
-func (s *server) GetSnapshotNames(context.Context, *rpcpb.GetSnapshotNamesRequest) (*rpcpb.GetSnapshotNamesResponse, error) {
+func (s *server) GetSnapshotNames(ctx context.Context, _ *rpcpb.GetSnapshotNamesRequest) (*rpcpb.GetSnapshotNamesResponse, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -1433,7 +1545,7 @@ func (s *server) GetSnapshotNames(context.Context, *rpcpb.GetSnapshotNamesReques
 		return nil, ErrNotBootstrapped
 	}
 
-	snapshotNames, err := s.network.nw.GetSnapshotNames()
+	snapshotNames, err := s.network.nw.GetSnapshotNames(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -11,8 +11,12 @@ const (
 	RootDirPrefix          = "network-runner-root-data"
 	DefaultExecPathEnvVar  = "AVALANCHEGO_EXEC_PATH"
 	DefaultPluginDirEnvVar = "AVALANCHEGO_PLUGIN_PATH"
-	LocalGenesisFile       = "genesis.json"
+	NodeNameEnvVar         = "ANR_NODE_NAME"
+	SharedDirEnvVar        = "ANR_SHARED_DIR"
 	IPv4Lookback           = "127.0.0.1"
+	// Deprecated: default genesis is embedded in the binary, see network.LoadLocalGenesis
+	LocalGenesisFile = "genesis.json"
 )
 
+// Deprecated: default network config is embedded in the binary, see local.NewDefaultConfig
 var LocalConfigDir = filepath.Join("local", "default")
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var flagDefaultRegexp = regexp.MustCompile(`\(default (.*?)\)(?: \(DEPRECATED: .*\))?$`)
+
+// FlagDiff is a flag whose value differs from its default
+type FlagDiff struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Default string `json:"default"`
+}
+
+// ParseFlagDefaults parses the default value of each flag
+// described in [usage], as printed by a binary's `--help`
+// (pflag format). Flags with no printed default get the zero value for
+// their type.
+// Flag name --> default value.
+func ParseFlagDefaults(usage string) map[string]string {
+	defaults := map[string]string{}
+	var (
+		name     string
+		flagType string
+		text     string
+	)
+	addFlag := func() {
+		if name == "" {
+			return
+		}
+		matches := flagDefaultRegexp.FindStringSubmatch(strings.TrimSpace(text))
+		if len(matches) == 2 {
+			value := matches[1]
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			defaults[name] = value
+		} else {
+			defaults[name] = zeroFlagValue(flagType)
+		}
+	}
+	for _, line := range strings.Split(usage, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if len(trimmed) > 2 && trimmed[0] == '-' && trimmed[1] != '-' && strings.Contains(trimmed, ", --") {
+			// remove shorthand
+			trimmed = trimmed[strings.Index(trimmed, ", --")+2:]
+		}
+		if !strings.HasPrefix(trimmed, "--") {
+			// continuation of a multi line usage
+			text += " " + trimmed
+			continue
+		}
+		addFlag()
+		head, rest, _ := strings.Cut(trimmed, "  ")
+		fields := strings.Fields(head)
+		if len(fields) == 0 {
+			name, text = "", ""
+			continue
+		}
+		name, _, _ = strings.Cut(strings.TrimPrefix(fields[0], "--"), "[")
+		flagType = ""
+		if len(fields) > 1 {
+			flagType, _, _ = strings.Cut(fields[1], "[")
+		}
+		text = rest
+	}
+	addFlag()
+	return defaults
+}
+
+// Returns the value pflag considers zero, and so doesn't print as
+// default, for a flag of type [flagType]
+func zeroFlagValue(flagType string) string {
+	switch flagType {
+	case "":
+		// bool flags have no type on usage
+		return "false"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "0"
+	case "duration":
+		return "0s"
+	case "strings", "stringSlice", "ints", "uints", "durationSlice":
+		return "[]"
+	default:
+		return ""
+	}
+}
+
+// DiffFlags returns the entries of [flags] whose value differs from the
+// one in [defaults], sorted by name. Values are compared as numbers,
+// durations or bools when possible.
+// Flags not present in [defaults] are reported with an empty default.
+func DiffFlags(flags map[string]string, defaults map[string]string) []FlagDiff {
+	diffs := []FlagDiff{}
+	for name, value := range flags {
+		defaultValue := defaults[name]
+		if flagValuesEqual(value, defaultValue) {
+			continue
+		}
+		diffs = append(diffs, FlagDiff{
+			Name:    name,
+			Value:   value,
+			Default: defaultValue,
+		})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func flagValuesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if af, err := strconv.ParseFloat(a, 64); err == nil {
+		bf, err := strconv.ParseFloat(b, 64)
+		return err == nil && af == bf
+	}
+	if ad, err := time.ParseDuration(a); err == nil {
+		bd, err := time.ParseDuration(b)
+		return err == nil && ad == bd
+	}
+	if ab, err := strconv.ParseBool(a); err == nil {
+		bb, err := strconv.ParseBool(b)
+		return err == nil && ab == bb
+	}
+	return false
+}
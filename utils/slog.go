@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/exp/slog"
+)
+
+// NewSlogLogger returns a logger that sends its records to [handler],
+// so runner logs can be routed into an slog based logging pipeline
+func NewSlogLogger(handler slog.Handler) logging.Logger {
+	atomicLevel := zap.NewAtomicLevelAt(zapcore.Level(logging.Verbo))
+	core := &slogCore{
+		handler:     handler,
+		atomicLevel: atomicLevel,
+	}
+	return logging.NewLogger("", logging.WrappedCore{
+		Core:        core,
+		Writer:      &slogWriter{handler: handler},
+		AtomicLevel: atomicLevel,
+	})
+}
+
+// Maps an avalanchego log level into an slog level
+func slogLevel(level zapcore.Level) slog.Level {
+	switch logging.Level(level) {
+	case logging.Verbo:
+		return slog.LevelDebug - 2
+	case logging.Debug:
+		return slog.LevelDebug
+	case logging.Trace:
+		return slog.LevelDebug + 2
+	case logging.Info:
+		return slog.LevelInfo
+	case logging.Warn:
+		return slog.LevelWarn
+	case logging.Error:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
+
+// slogCore is a zap core that writes into an slog handler
+type slogCore struct {
+	handler     slog.Handler
+	atomicLevel zap.AtomicLevel
+	fields      []zapcore.Field
+}
+
+func (c *slogCore) Enabled(level zapcore.Level) bool {
+	return c.atomicLevel.Enabled(level) && c.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+func (c *slogCore) With(fields []zapcore.Field) zapcore.Core {
+	return &slogCore{
+		handler:     c.handler,
+		atomicLevel: c.atomicLevel,
+		fields:      append(append([]zapcore.Field{}, c.fields...), fields...),
+	}
+}
+
+func (c *slogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *slogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	record := slog.NewRecord(entry.Time, slogLevel(entry.Level), entry.Message, 0)
+	if entry.LoggerName != "" {
+		record.AddAttrs(slog.String("logger", entry.LoggerName))
+	}
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	keys := make([]string, 0, len(encoder.Fields))
+	for key := range encoder.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.AddAttrs(slog.Any(key, encoder.Fields[key]))
+	}
+	return c.handler.Handle(context.Background(), record)
+}
+
+func (*slogCore) Sync() error {
+	return nil
+}
+
+// slogWriter logs pre-formatted messages as info records
+type slogWriter struct {
+	handler slog.Handler
+}
+
+func (w *slogWriter) Write(p []byte) (int, error) {
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, strings.TrimSuffix(string(p), "\n"), 0)
+	if err := w.handler.Handle(context.Background(), record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (*slogWriter) Close() error {
+	return nil
+}
@@ -28,6 +28,21 @@ var (
 	ErrorNoNetworkIDKey = fmt.Errorf("couldn't find key %q in genesis", genesisNetworkIDKey)
 )
 
+// NewStakingIdentity generates a new PEM encoded staking key and cert,
+// and returns them with the node ID they give, so node IDs can be known
+// before the nodes are started (eg to make them genesis validators)
+func NewStakingIdentity() ([]byte, []byte, ids.NodeID, error) {
+	stakingCert, stakingKey, err := staking.NewCertAndKeyBytes()
+	if err != nil {
+		return nil, nil, ids.EmptyNodeID, fmt.Errorf("couldn't generate staking key/cert: %w", err)
+	}
+	nodeID, err := ToNodeID(stakingKey, stakingCert)
+	if err != nil {
+		return nil, nil, ids.EmptyNodeID, err
+	}
+	return stakingKey, stakingCert, nodeID, nil
+}
+
 func ToNodeID(stakingKey, stakingCert []byte) (ids.NodeID, error) {
 	tlsCert, err := staking.LoadTLSCertFromBytes(stakingKey, stakingCert)
 	if err != nil {
@@ -1,11 +1,15 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"testing"
 
+	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slog"
 )
 
 var genesis = []byte(
@@ -123,3 +127,73 @@ func TestCheckPluginPaths(t *testing.T) {
 		require.Equal(t, tv.expectedErr, err, fmt.Sprintf("[%d] unexpected error", i))
 	}
 }
+
+func TestParseFlagDefaults(t *testing.T) {
+	usage := `Usage of avalanchego:
+      --api-admin-enabled                 If true, this node exposes the Admin API
+      --api-health-enabled                If true, this node exposes the Health API (default true)
+      --bootstrap-ids string              Comma separated list of bootstrap peer ids
+      --http-port uint                    Port of the HTTP server (default 9650)
+      --log-level string                  The log level (default "info")
+      --network-health-max-time-since-msg-received duration   Network layer returns unhealthy if haven't received a message
+                                          for at least this much time (default 1m0s)
+      --whitelisted-subnets string        Whitelist of subnets (DEPRECATED: use track-subnets)
+      --    
+  -h, --help                              help for avalanchego
+`
+	require.Equal(t, map[string]string{
+		"api-admin-enabled":  "false",
+		"api-health-enabled": "true",
+		"bootstrap-ids":      "",
+		"http-port":          "9650",
+		"log-level":          "info",
+		"network-health-max-time-since-msg-received": "1m0s",
+		"whitelisted-subnets":                        "",
+		"help":                                       "false",
+	}, ParseFlagDefaults(usage))
+}
+
+func TestDiffFlags(t *testing.T) {
+	defaults := map[string]string{
+		"http-port": "9650",
+		"log-level": "info",
+		"timeout":   "1m0s",
+		"enabled":   "true",
+	}
+	flags := map[string]string{
+		"http-port": "9650",
+		"log-level": "debug",
+		"timeout":   "60s",
+		"enabled":   "false",
+		"data-dir":  "/tmp/node1",
+	}
+	require.Equal(t, []FlagDiff{
+		{Name: "data-dir", Value: "/tmp/node1", Default: ""},
+		{Name: "enabled", Value: "false", Default: "true"},
+		{Name: "log-level", Value: "debug", Default: "info"},
+	}, DiffFlags(flags, defaults))
+}
+
+func TestSlogLogger(t *testing.T) {
+	require := require.New(t)
+	buf := &bytes.Buffer{}
+	log := NewSlogLogger(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	log.Info("node started", zap.String("node-name", "node1"), zap.Int("port", 9650))
+	require.Contains(buf.String(), "level=INFO")
+	require.Contains(buf.String(), `msg="node started"`)
+	require.Contains(buf.String(), "node-name=node1")
+	require.Contains(buf.String(), "port=9650")
+
+	// filtered by the handler level
+	buf.Reset()
+	log.Debug("debug message")
+	require.Empty(buf.String())
+
+	// filtered by the logger level
+	log.SetLevel(logging.Error)
+	log.Warn("warn message")
+	require.Empty(buf.String())
+	log.Error("error message")
+	require.Contains(buf.String(), "level=ERROR")
+}